@@ -43,6 +43,7 @@ func Command() *cobra.Command {
 			}
 
 			tracker := task.NewBeadsTracker("")
+			tracker.StatusMap = cfg.Beads.StatusMap
 			runStore := db.NewStore(storeDB)
 			pdcaFactory := pdca.NewFactory(cfg, runStore, tracker)
 
@@ -70,6 +70,12 @@ func loadRawConfig(repoRoot string) (config.Config, error) {
 		return config.Config{}, fmt.Errorf("parse config: %w", err)
 	}
 
+	overlay, err := config.LoadAgentsOverlay(filepath.Join(repoRoot, config.AgentsOverlayPath))
+	if err != nil {
+		return config.Config{}, err
+	}
+	cfg.Agents = config.MergeAgentConfigs(cfg.Agents, overlay)
+
 	executablePath, err := os.Executable()
 	if err != nil {
 		return config.Config{}, fmt.Errorf("resolve executable path: %w", err)
@@ -116,7 +116,7 @@ func runStructuredPlayground(
 	if err != nil {
 		return fmt.Errorf("resolve executable path: %w", err)
 	}
-	normalized, err := agentconfig.NormalizeACPConfig(preconfigured, executablePath)
+	normalized, err := agentconfig.NormalizeACPConfig(preconfigured, executablePath, agentconfig.SafetyProfileInteractive)
 	if err != nil {
 		return fmt.Errorf("normalize opencode_acp config: %w", err)
 	}
@@ -36,6 +36,7 @@ func Command() *cobra.Command {
 			}
 
 			tracker := task.NewBeadsTracker("")
+			tracker.StatusMap = cfg.Beads.StatusMap
 			runStore := db.NewStore(storeDB)
 			pdcaFactory := pdca.NewFactory(cfg, runStore, tracker)
 			runner, err := run.NewADKRunner(repoRoot, cfg, runStore, tracker, pdcaFactory)
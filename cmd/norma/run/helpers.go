@@ -68,6 +68,7 @@ func loadConfig(repoRoot string) (config.Config, error) {
 	if cfg.Budgets.MaxIterations <= 0 {
 		return config.Config{}, fmt.Errorf("budgets.max_iterations must be > 0")
 	}
+	log.Info().Str("profile", selectedProfile).Interface("models", cfg.EffectiveModels()).Msg("resolved role models")
 	return cfg, nil
 }
 
@@ -99,6 +100,15 @@ func loadRawConfig(repoRoot string) (config.Config, error) {
 	if err := viper.Unmarshal(&cfg); err != nil {
 		return config.Config{}, fmt.Errorf("parse config: %w", err)
 	}
+	if err := task.ValidateStatusMap(cfg.Beads.StatusMap, cfg.Beads.CustomStatuses); err != nil {
+		return config.Config{}, fmt.Errorf("validate config: %w", err)
+	}
+
+	overlay, err := config.LoadAgentsOverlay(filepath.Join(repoRoot, config.AgentsOverlayPath))
+	if err != nil {
+		return config.Config{}, err
+	}
+	cfg.Agents = config.MergeAgentConfigs(cfg.Agents, overlay)
 
 	executablePath, err := os.Executable()
 	if err != nil {
@@ -2,9 +2,12 @@ package runscmd
 
 import (
 	"fmt"
+	"os"
 	"path/filepath"
 
+	"github.com/metalagman/norma/internal/db"
 	"github.com/metalagman/norma/internal/run"
+	"github.com/metalagman/norma/internal/task"
 	"github.com/rs/zerolog/log"
 	"github.com/spf13/cobra"
 )
@@ -16,6 +19,9 @@ func Command() *cobra.Command {
 		Short: "Manage norma runs",
 	}
 	cmd.AddCommand(pruneCommand())
+	cmd.AddCommand(approveCommand())
+	cmd.AddCommand(traceCommand())
+	cmd.AddCommand(verdictCommand())
 	return cmd
 }
 
@@ -77,3 +83,98 @@ func pruneCommand() *cobra.Command {
 	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "report what would be pruned without deleting")
 	return cmd
 }
+
+func approveCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "approve <run_id>",
+		Short: "Approve applying the changes of a run awaiting approval",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			storeDB, repoRoot, closeFn, err := openDB(cmd.Context())
+			if err != nil {
+				return err
+			}
+			defer closeFn()
+
+			if err := run.ApproveRun(cmd.Context(), db.NewStore(storeDB), filepath.Join(repoRoot, ".norma"), args[0]); err != nil {
+				return err
+			}
+			log.Info().Str("run_id", args[0]).Msg("run approved")
+			return nil
+		},
+	}
+}
+
+func traceCommand() *cobra.Command {
+	var output string
+	cmd := &cobra.Command{
+		Use:   "trace <run_id>",
+		Short: "Export step timings of a run as Chrome Tracing JSON",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			storeDB, _, closeFn, err := openDB(cmd.Context())
+			if err != nil {
+				return err
+			}
+			defer closeFn()
+
+			spans, err := db.NewStore(storeDB).TimingProfile(cmd.Context(), args[0])
+			if err != nil {
+				return err
+			}
+			if len(spans) == 0 {
+				return fmt.Errorf("run %s has no recorded steps", args[0])
+			}
+			if output == "" {
+				return db.WriteChromeTrace(cmd.OutOrStdout(), spans)
+			}
+			f, err := os.Create(output)
+			if err != nil {
+				return fmt.Errorf("create trace file: %w", err)
+			}
+			if err := db.WriteChromeTrace(f, spans); err != nil {
+				_ = f.Close()
+				return err
+			}
+			return f.Close()
+		},
+	}
+	cmd.Flags().StringVarP(&output, "output", "o", "", "write the trace to this file instead of stdout")
+	return cmd
+}
+
+func verdictCommand() *cobra.Command {
+	var reason string
+	var apply bool
+	cmd := &cobra.Command{
+		Use:   "verdict <run_id> <PASS|FAIL|PARTIAL>",
+		Short: "Override the verdict of a finished run",
+		Args:  cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			storeDB, repoRoot, closeFn, err := openDB(cmd.Context())
+			if err != nil {
+				return err
+			}
+			defer closeFn()
+
+			cfg, err := loadConfig(repoRoot)
+			if err != nil {
+				return err
+			}
+			tracker := task.NewBeadsTracker("")
+			tracker.StatusMap = cfg.Beads.StatusMap
+			runner, err := run.NewADKRunner(repoRoot, cfg, db.NewStore(storeDB), tracker, nil)
+			if err != nil {
+				return err
+			}
+			if err := runner.SetVerdict(cmd.Context(), args[0], args[1], reason, apply); err != nil {
+				return err
+			}
+			log.Info().Str("run_id", args[0]).Str("verdict", args[1]).Bool("apply", apply).Msg("run verdict set")
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&reason, "reason", "", "why the verdict was overridden, recorded in the run events")
+	cmd.Flags().BoolVar(&apply, "apply", false, "apply the run's changes and close its task on a PASS verdict")
+	return cmd
+}
@@ -8,6 +8,7 @@ import (
 	"iter"
 	"strings"
 	"sync"
+	"time"
 
 	acp "github.com/coder/acp-go-sdk"
 	"github.com/rs/zerolog"
@@ -38,12 +39,16 @@ type Config struct {
 	Command []string
 	// WorkingDir is the directory where the ACP subprocess is executed.
 	WorkingDir string
+	// Env lists extra KEY=value entries added to the ACP subprocess's inherited environment.
+	Env []string
 	// Stderr is an optional writer for the ACP subprocess's standard error.
 	Stderr io.Writer
 	// PermissionHandler decides how to respond to ACP permission requests.
 	PermissionHandler PermissionHandler
 	// Logger is the zerolog logger to use for this agent.
 	Logger *zerolog.Logger
+	// ShutdownGrace is how long the subprocess gets after SIGTERM before it is killed.
+	ShutdownGrace time.Duration
 }
 
 // Agent adapts an Agentic Computing Protocol (ACP) runtime to the ADK agent interface.
@@ -96,11 +101,13 @@ func New(cfg Config) (*Agent, error) {
 	client, err := NewClient(ctx, ClientConfig{
 		Command:           cfg.Command,
 		WorkingDir:        cfg.WorkingDir,
+		Env:               cfg.Env,
 		ClientName:        cfg.ClientName,
 		ClientVersion:     cfg.ClientVersion,
 		Stderr:            cfg.Stderr,
 		PermissionHandler: cfg.PermissionHandler,
 		Logger:            cfg.Logger,
+		ShutdownGrace:     cfg.ShutdownGrace,
 	})
 	if err != nil {
 		return nil, err
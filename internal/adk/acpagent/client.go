@@ -7,6 +7,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"os"
 	"os/exec"
 	"strings"
 	"sync"
@@ -48,6 +49,8 @@ type ClientConfig struct {
 	Command []string
 	// WorkingDir is the directory where the ACP subprocess is executed.
 	WorkingDir string
+	// Env lists extra KEY=value entries added to the ACP subprocess's inherited environment.
+	Env []string
 	// ClientName is the name reported to the ACP server. Defaults to "norma-acpagent".
 	ClientName string
 	// ClientVersion is the version reported to the ACP server. Defaults to "dev".
@@ -58,6 +61,9 @@ type ClientConfig struct {
 	PermissionHandler PermissionHandler
 	// Logger is the zerolog logger to use for this client.
 	Logger *zerolog.Logger
+	// ShutdownGrace is how long the subprocess gets after SIGTERM before it is killed.
+	// Zero kills the subprocess immediately.
+	ShutdownGrace time.Duration
 }
 
 // ExtendedSessionNotification wraps an ACP notification with its raw JSON representation
@@ -78,6 +84,7 @@ type Client struct {
 	clientName        string
 	clientVersion     string
 	logger            zerolog.Logger
+	shutdownGrace     time.Duration
 
 	stateMu         sync.Mutex
 	activeBySession map[acp.SessionId]*activePrompt
@@ -140,6 +147,12 @@ func NewClient(ctx context.Context, cfg ClientConfig) (*Client, error) {
 
 	cmd := exec.CommandContext(ctx, cfg.Command[0], cfg.Command[1:]...)
 	cmd.Dir = cfg.WorkingDir
+	if len(cfg.Env) > 0 {
+		cmd.Env = append(os.Environ(), cfg.Env...)
+	}
+	if cfg.ShutdownGrace > 0 {
+		configureGracefulShutdown(cmd, cfg.ShutdownGrace)
+	}
 	stdin, err := cmd.StdinPipe()
 	if err != nil {
 		return nil, fmt.Errorf("acp stdin pipe: %w", err)
@@ -171,6 +184,7 @@ func NewClient(ctx context.Context, cfg ClientConfig) (*Client, error) {
 		clientName:        clientName,
 		clientVersion:     clientVersion,
 		logger:            l,
+		shutdownGrace:     cfg.ShutdownGrace,
 		activeBySession:   make(map[acp.SessionId]*activePrompt),
 		updates:           make(chan ExtendedSessionNotification, 256),
 		deactivate:        make(chan acp.SessionId, 256),
@@ -397,7 +411,7 @@ func (c *Client) Prompt(ctx context.Context, sessionID, prompt string) (<-chan E
 func (c *Client) Close() error {
 	_ = c.stdin.Close()
 	if c.cmd.Process != nil {
-		_ = c.cmd.Process.Kill()
+		c.terminate()
 	}
 	<-c.closed
 	if c.closeErr != nil && !errors.Is(c.closeErr, io.EOF) {
@@ -0,0 +1,49 @@
+package acpagent
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"syscall"
+	"time"
+)
+
+// configureGracefulShutdown starts cmd in its own process group and makes context
+// cancellation send SIGTERM to the group, escalating to SIGKILL after grace.
+func configureGracefulShutdown(cmd *exec.Cmd, grace time.Duration) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	cmd.Cancel = func() error {
+		return signalProcessGroup(cmd, syscall.SIGTERM)
+	}
+	cmd.WaitDelay = grace
+}
+
+// terminate stops the subprocess. With a shutdown grace it sends SIGTERM first
+// and only kills the process group if it has not exited within the grace period.
+func (c *Client) terminate() {
+	if c.shutdownGrace > 0 {
+		if err := signalProcessGroup(c.cmd, syscall.SIGTERM); err == nil {
+			select {
+			case <-c.closed:
+				return
+			case <-time.After(c.shutdownGrace):
+				c.logger.Debug().Dur("grace", c.shutdownGrace).Msg("acp process ignored SIGTERM, killing")
+			}
+		}
+		_ = signalProcessGroup(c.cmd, syscall.SIGKILL)
+	}
+	_ = c.cmd.Process.Kill()
+}
+
+func signalProcessGroup(cmd *exec.Cmd, sig syscall.Signal) error {
+	if cmd.Process == nil {
+		return nil
+	}
+	if err := syscall.Kill(-cmd.Process.Pid, sig); err != nil {
+		if errors.Is(err, syscall.ESRCH) {
+			return os.ErrProcessDone
+		}
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,168 @@
+package acpagent
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestClientShutdownSendsSIGTERMBeforeSIGKILL(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		onTerm     string
+		wantKilled bool
+	}{
+		{name: "exits_on_term", onTerm: "exit 0", wantKilled: false},
+		{name: "ignores_term", onTerm: ":", wantKilled: true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			dir := t.TempDir()
+			marker := filepath.Join(dir, "signals")
+			ready := filepath.Join(dir, "ready")
+			script := `trap 'echo TERM >> "$1"; ` + tc.onTerm + `' TERM; touch "$2"; while :; do sleep 0.05; done`
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			grace := 300 * time.Millisecond
+			c, err := NewClient(ctx, ClientConfig{
+				Command:       []string{"sh", "-c", script, "sh", marker, ready},
+				WorkingDir:    dir,
+				ShutdownGrace: grace,
+			})
+			if err != nil {
+				t.Fatalf("NewClient() error = %v", err)
+			}
+			waitForFile(t, ready)
+
+			start := time.Now()
+			cancel()
+			select {
+			case <-c.closed:
+			case <-time.After(5 * time.Second):
+				t.Fatal("acp process did not exit after cancellation")
+			}
+			elapsed := time.Since(start)
+
+			data, err := os.ReadFile(marker)
+			if err != nil {
+				t.Fatalf("read signal marker: %v", err)
+			}
+			if !strings.Contains(string(data), "TERM") {
+				t.Fatalf("signal marker = %q, want TERM", data)
+			}
+
+			ws, ok := c.cmd.ProcessState.Sys().(syscall.WaitStatus)
+			if !ok {
+				t.Fatalf("ProcessState.Sys() = %T, want syscall.WaitStatus", c.cmd.ProcessState.Sys())
+			}
+			killed := ws.Signaled() && ws.Signal() == syscall.SIGKILL
+			if killed != tc.wantKilled {
+				t.Fatalf("killed by SIGKILL = %v, want %v (status %v)", killed, tc.wantKilled, ws)
+			}
+			if tc.wantKilled && elapsed < grace {
+				t.Fatalf("SIGKILL after %v, want at least grace %v", elapsed, grace)
+			}
+		})
+	}
+}
+
+func TestClientCloseSendsSIGTERMWithGrace(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	marker := filepath.Join(dir, "signals")
+	ready := filepath.Join(dir, "ready")
+	script := `trap 'echo TERM >> "$1"; exit 0' TERM; touch "$2"; while :; do sleep 0.05; done`
+
+	c, err := NewClient(context.Background(), ClientConfig{
+		Command:       []string{"sh", "-c", script, "sh", marker, ready},
+		WorkingDir:    dir,
+		ShutdownGrace: 2 * time.Second,
+	})
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	waitForFile(t, ready)
+
+	_ = c.Close()
+
+	data, err := os.ReadFile(marker)
+	if err != nil {
+		t.Fatalf("read signal marker: %v", err)
+	}
+	if !strings.Contains(string(data), "TERM") {
+		t.Fatalf("signal marker = %q, want TERM", data)
+	}
+	if !c.cmd.ProcessState.Success() {
+		t.Fatalf("process state = %v, want clean exit after SIGTERM", c.cmd.ProcessState)
+	}
+}
+
+func waitForFile(t *testing.T, path string) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		if _, err := os.Stat(path); err == nil {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("timed out waiting for %s", path)
+}
+
+func TestClientCapturesStderrSeparately(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	ready := filepath.Join(dir, "ready")
+	script := `echo "agent diagnostics" >&2; echo "protocol" ; touch "$1"; while :; do sleep 0.05; done`
+
+	stderr := &syncBuffer{}
+	c, err := NewClient(context.Background(), ClientConfig{
+		Command:    []string{"sh", "-c", script, "sh", ready},
+		WorkingDir: dir,
+		Stderr:     stderr,
+	})
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	waitForFile(t, ready)
+	_ = c.Close()
+
+	got := stderr.String()
+	if !strings.Contains(got, "agent diagnostics") {
+		t.Fatalf("stderr = %q, want agent diagnostics", got)
+	}
+	if strings.Contains(got, "protocol") {
+		t.Fatalf("stderr = %q, must not contain stdout output", got)
+	}
+}
+
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
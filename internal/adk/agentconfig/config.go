@@ -3,6 +3,8 @@ package agentconfig
 import (
 	"fmt"
 	"reflect"
+	"regexp"
+	"slices"
 	"sort"
 	"strings"
 
@@ -11,15 +13,21 @@ import (
 
 // Config describes how to run an agent.
 type Config struct {
-	Type      string   `json:"type"                 mapstructure:"type"       validate:"required,oneof=generic_acp codex_acp opencode_acp gemini_acp copilot_acp"`
-	Cmd       []string `json:"cmd,omitempty"        mapstructure:"cmd"`
-	ExtraArgs []string `json:"extra_args,omitempty" mapstructure:"extra_args"`
-	Model     string   `json:"model,omitempty"      mapstructure:"model"      validate:"omitempty,min=1"`
-	Mode      string   `json:"mode,omitempty"       mapstructure:"mode"       validate:"omitempty,min=1"`
-	BaseURL   string   `json:"base_url,omitempty"   mapstructure:"base_url"   validate:"omitempty,min=1"`
-	APIKey    string   `json:"api_key,omitempty"    mapstructure:"api_key"    validate:"omitempty,min=1"`
-	Timeout   int      `json:"timeout,omitempty"    mapstructure:"timeout"    validate:"omitempty,min=1"`
-	UseTTY    *bool    `json:"use_tty,omitempty"    mapstructure:"use_tty"`
+	Type                  string   `json:"type"                              mapstructure:"type"              validate:"required,oneof=generic_acp codex_acp opencode_acp gemini_acp copilot_acp"`
+	Cmd                   []string `json:"cmd,omitempty"                     mapstructure:"cmd"`
+	ExtraArgs             []string `json:"extra_args,omitempty"              mapstructure:"extra_args"`
+	Model                 string   `json:"model,omitempty"                   mapstructure:"model"             validate:"omitempty,min=1"`
+	EscalationModels      []string `json:"escalation_models,omitempty"       mapstructure:"escalation_models"`
+	Mode                  string   `json:"mode,omitempty"                    mapstructure:"mode"              validate:"omitempty,min=1"`
+	BaseURL               string   `json:"base_url,omitempty"                mapstructure:"base_url"          validate:"omitempty,min=1"`
+	APIKey                string   `json:"api_key,omitempty"                 mapstructure:"api_key"           validate:"omitempty,min=1"`
+	Timeout               int      `json:"timeout,omitempty"                 mapstructure:"timeout"           validate:"omitempty,min=1"`
+	UseTTY                *bool    `json:"use_tty,omitempty"                 mapstructure:"use_tty"`
+	ResponseMode          string   `json:"response_mode,omitempty"           mapstructure:"response_mode"     validate:"omitempty,oneof=stdout file"`
+	MaxAttempts           int      `json:"max_attempts,omitempty"            mapstructure:"max_attempts"      validate:"omitempty,min=1"`
+	ResponseConflict      string   `json:"response_conflict,omitempty"       mapstructure:"response_conflict" validate:"omitempty,oneof=warn error"`
+	ReasoningMarker       string   `json:"reasoning_marker,omitempty"        mapstructure:"reasoning_marker"`
+	StderrWarningPatterns []string `json:"stderr_warning_patterns,omitempty" mapstructure:"stderr_warning_patterns"`
 }
 
 var configValidator = newConfigValidator()
@@ -70,6 +78,16 @@ func (c Config) Validate() error {
 			errs = append(errs, fmt.Sprintf("extra_args[%d] must have at least 1 character", i))
 		}
 	}
+	for i, model := range c.EscalationModels {
+		if model == "" {
+			errs = append(errs, fmt.Sprintf("escalation_models[%d] must have at least 1 character", i))
+		}
+	}
+	for i, pattern := range c.StderrWarningPatterns {
+		if _, err := regexp.Compile(pattern); err != nil {
+			errs = append(errs, fmt.Sprintf("stderr_warning_patterns[%d] must be a valid regular expression: %v", i, err))
+		}
+	}
 
 	if len(errs) == 0 {
 		return nil
@@ -93,6 +111,45 @@ func formatValidationError(err validator.FieldError) string {
 	}
 }
 
+// DefaultMaxAttempts is how many times a step agent runs when max_attempts is unset.
+const DefaultMaxAttempts = 3
+
+// Attempts returns how many times a step agent may run before its step fails.
+func (c Config) Attempts() int {
+	if c.MaxAttempts < 1 {
+		return DefaultMaxAttempts
+	}
+	return c.MaxAttempts
+}
+
+// ModelForIteration returns the model for a 1-based PDCA iteration.
+// Iteration n uses EscalationModels[n-1]; iterations past the list keep its last model.
+// Without escalation models it returns Model.
+func (c Config) ModelForIteration(iteration int) string {
+	if len(c.EscalationModels) == 0 {
+		return c.Model
+	}
+	idx := min(max(iteration, 1)-1, len(c.EscalationModels)-1)
+	return c.EscalationModels[idx]
+}
+
+// WithModel returns a copy of c that uses model.
+// Model flags baked into Cmd by NormalizeACPConfig are rewritten as well.
+func (c Config) WithModel(model string) Config {
+	out := c
+	out.Model = model
+	out.Cmd = slices.Clone(c.Cmd)
+	if c.Model == "" {
+		return out
+	}
+	for i := 1; i < len(out.Cmd); i++ {
+		if (out.Cmd[i-1] == "--model" || out.Cmd[i-1] == "--codex-model") && out.Cmd[i] == c.Model {
+			out.Cmd[i] = model
+		}
+	}
+	return out
+}
+
 const (
 	// AgentTypeGenericACP is the type for custom ACP CLI executables.
 	AgentTypeGenericACP = "generic_acp"
@@ -107,6 +164,63 @@ const (
 	AgentTypeCopilotACP = "copilot_acp"
 )
 
+const (
+	// ResponseModeStdout reads the agent response from its final text output (default).
+	ResponseModeStdout = "stdout"
+	// ResponseModeFile reads the agent response from ResponseFileName in the step run directory.
+	ResponseModeFile = "file"
+
+	// ResponseFileName is the response file agents write in ResponseModeFile.
+	ResponseFileName = "response.json"
+
+	// ResponseConflictWarn logs a response file that disagrees with the stdout response (default).
+	ResponseConflictWarn = "warn"
+	// ResponseConflictError fails the attempt when the response file disagrees with the stdout response.
+	ResponseConflictError = "error"
+)
+
+// Safety profiles select the default flags of provider aliases and how ACP permission requests are answered.
+const (
+	// SafetyProfileInteractive keeps the provider defaults and auto-approves permission requests (default).
+	SafetyProfileInteractive = "interactive"
+	// SafetyProfileCI confines agents to the workspace and rejects permission requests.
+	SafetyProfileCI = "ci"
+	// SafetyProfileLocked keeps agents read-only and rejects permission requests.
+	SafetyProfileLocked = "locked"
+)
+
+// safetyProfileArgs are the default flags each safety profile appends per provider alias.
+// Providers without a matching flag rely on permission requests being rejected.
+var safetyProfileArgs = map[string]map[string][]string{
+	SafetyProfileInteractive: {},
+	SafetyProfileCI: {
+		AgentTypeCodexACP:  {"--codex-sandbox", "workspace-write", "--codex-approval-policy", "never"},
+		AgentTypeGeminiACP: {"--approval-mode", "default"},
+	},
+	SafetyProfileLocked: {
+		AgentTypeCodexACP:  {"--codex-sandbox", "read-only", "--codex-approval-policy", "never"},
+		AgentTypeGeminiACP: {"--approval-mode", "default", "--sandbox"},
+	},
+}
+
+// NormalizeSafetyProfile returns the canonical safety profile, defaulting to interactive.
+func NormalizeSafetyProfile(profile string) (string, error) {
+	profile = strings.ToLower(strings.TrimSpace(profile))
+	if profile == "" {
+		return SafetyProfileInteractive, nil
+	}
+	if _, ok := safetyProfileArgs[profile]; !ok {
+		return "", fmt.Errorf("unknown safety profile %q (want interactive, ci, or locked)", profile)
+	}
+	return profile, nil
+}
+
+// AutoApprovesPermissions reports whether agents running under profile get ACP permission requests approved.
+func AutoApprovesPermissions(profile string) bool {
+	normalized, err := NormalizeSafetyProfile(profile)
+	return err == nil && normalized == SafetyProfileInteractive
+}
+
 // IsACPType reports whether an agent type uses the ACP runtime.
 func IsACPType(agentType string) bool {
 	switch strings.TrimSpace(agentType) {
@@ -123,15 +237,24 @@ func IsPlannerSupportedType(agentType string) bool {
 }
 
 // NormalizeACPConfig canonicalizes ACP aliases to generic_acp while preserving behavior.
-func NormalizeACPConfig(cfg Config, executablePath string) (Config, error) {
+// Alias commands get their model flag and the default flags of the safety profile;
+// generic_acp commands are left as configured. ExtraArgs are appended after these
+// flags when the command is resolved, and a default flag that ExtraArgs already sets
+// is not injected, so ExtraArgs can override it.
+func NormalizeACPConfig(cfg Config, executablePath, profile string) (Config, error) {
+	profile, err := NormalizeSafetyProfile(profile)
+	if err != nil {
+		return Config{}, err
+	}
 	normalized := cfg
 
-	switch strings.TrimSpace(cfg.Type) {
+	agentType := strings.TrimSpace(cfg.Type)
+	switch agentType {
 	case AgentTypeGeminiACP:
 		normalized.Type = AgentTypeGenericACP
 		normalized.Cmd = []string{"gemini", "--experimental-acp"}
 		if cfg.Model != "" {
-			normalized.Cmd = append(normalized.Cmd, "--model", cfg.Model)
+			normalized.Cmd = appendDefaultFlags(normalized.Cmd, []string{"--model", cfg.Model}, cfg.ExtraArgs)
 		}
 	case AgentTypeOpenCodeACP:
 		normalized.Type = AgentTypeGenericACP
@@ -144,25 +267,54 @@ func NormalizeACPConfig(cfg Config, executablePath string) (Config, error) {
 		normalized.Type = AgentTypeGenericACP
 		normalized.Cmd = []string{exePath, "tool", "codex-acp-bridge"}
 		if cfg.Model != "" {
-			normalized.Cmd = append(normalized.Cmd, "--codex-model", cfg.Model)
+			normalized.Cmd = appendDefaultFlags(normalized.Cmd, []string{"--codex-model", cfg.Model}, cfg.ExtraArgs)
 		}
 	case AgentTypeCopilotACP:
 		normalized.Type = AgentTypeGenericACP
 		normalized.Cmd = []string{"copilot", "--acp"}
 	}
+	if args := safetyProfileArgs[profile][agentType]; len(args) > 0 {
+		normalized.Cmd = appendDefaultFlags(normalized.Cmd, args, cfg.ExtraArgs)
+	}
 
 	return normalized, nil
 }
 
-// NormalizeACPConfigs canonicalizes ACP aliases for a map of named agent configs.
-func NormalizeACPConfigs(cfgs map[string]Config, executablePath string) (map[string]Config, error) {
+// appendDefaultFlags appends each flag of defaults, with the values following it,
+// to cmd unless extraArgs already sets that flag.
+func appendDefaultFlags(cmd, defaults, extraArgs []string) []string {
+	for i := 0; i < len(defaults); {
+		end := i + 1
+		for end < len(defaults) && !strings.HasPrefix(defaults[end], "-") {
+			end++
+		}
+		if !hasFlag(extraArgs, defaults[i]) {
+			cmd = append(cmd, defaults[i:end]...)
+		}
+		i = end
+	}
+	return cmd
+}
+
+// hasFlag reports whether args contain flag, either on its own or as flag=value.
+func hasFlag(args []string, flag string) bool {
+	for _, arg := range args {
+		if arg == flag || strings.HasPrefix(arg, flag+"=") {
+			return true
+		}
+	}
+	return false
+}
+
+// NormalizeACPConfigs canonicalizes ACP aliases for a map of named agent configs under a safety profile.
+func NormalizeACPConfigs(cfgs map[string]Config, executablePath, profile string) (map[string]Config, error) {
 	if len(cfgs) == 0 {
 		return cfgs, nil
 	}
 
 	normalized := make(map[string]Config, len(cfgs))
 	for name, cfg := range cfgs {
-		normCfg, err := NormalizeACPConfig(cfg, executablePath)
+		normCfg, err := NormalizeACPConfig(cfg, executablePath, profile)
 		if err != nil {
 			return nil, fmt.Errorf("normalize agent %q: %w", name, err)
 		}
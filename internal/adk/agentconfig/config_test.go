@@ -2,6 +2,7 @@ package agentconfig
 
 import (
 	"reflect"
+	"slices"
 	"strings"
 	"testing"
 )
@@ -21,6 +22,15 @@ func TestConfigValidate(t *testing.T) {
 				Cmd:  []string{"custom-acp", "--stdio"},
 			},
 		},
+		{
+			name: "invalid_max_attempts",
+			cfg: Config{
+				Type:        AgentTypeGenericACP,
+				Cmd:         []string{"custom-acp"},
+				MaxAttempts: -1,
+			},
+			wantErr: "max_attempts must be at least 1",
+		},
 		{
 			name: "missing_type",
 			cfg: Config{
@@ -85,6 +95,15 @@ func TestConfigValidate(t *testing.T) {
 			},
 			wantErr: "timeout must be at least 1",
 		},
+		{
+			name: "stderr_warning_patterns_must_compile",
+			cfg: Config{
+				Type:                  AgentTypeGenericACP,
+				Cmd:                   []string{"custom-acp"},
+				StderrWarningPatterns: []string{"^WARN", "(unclosed"},
+			},
+			wantErr: "stderr_warning_patterns[1] must be a valid regular expression",
+		},
 	}
 
 	for _, tt := range tests {
@@ -182,6 +201,36 @@ func TestNormalizeACPConfig(t *testing.T) {
 				ExtraArgs: []string{"--trace"},
 			},
 		},
+		{
+			name: "gemini_alias_model_in_extra_args",
+			cfg: Config{
+				Type:      AgentTypeGeminiACP,
+				Model:     "gemini-3-flash-preview",
+				ExtraArgs: []string{"--model", "gemini-3-pro-preview"},
+			},
+			exec: execPath,
+			want: Config{
+				Type:      AgentTypeGenericACP,
+				Cmd:       []string{"gemini", "--experimental-acp"},
+				Model:     "gemini-3-flash-preview",
+				ExtraArgs: []string{"--model", "gemini-3-pro-preview"},
+			},
+		},
+		{
+			name: "codex_alias_model_in_extra_args",
+			cfg: Config{
+				Type:      AgentTypeCodexACP,
+				Model:     "gpt-5-codex",
+				ExtraArgs: []string{"--codex-model=gpt-5", "--codex-profile", "deep"},
+			},
+			exec: execPath,
+			want: Config{
+				Type:      AgentTypeGenericACP,
+				Cmd:       []string{execPath, "tool", "codex-acp-bridge"},
+				Model:     "gpt-5-codex",
+				ExtraArgs: []string{"--codex-model=gpt-5", "--codex-profile", "deep"},
+			},
+		},
 		{
 			name: "codex_alias_empty_exec_path",
 			cfg: Config{
@@ -209,7 +258,7 @@ func TestNormalizeACPConfig(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			t.Parallel()
 
-			got, err := NormalizeACPConfig(tt.cfg, tt.exec)
+			got, err := NormalizeACPConfig(tt.cfg, tt.exec, "")
 			if tt.wantErr != "" {
 				if err == nil {
 					t.Fatalf("NormalizeACPConfig returned nil error, want %q", tt.wantErr)
@@ -253,7 +302,7 @@ func TestNormalizeACPConfigs(t *testing.T) {
 			Type: AgentTypeGenericACP,
 			Cmd:  []string{"custom-acp"},
 		},
-	}, execPath)
+	}, execPath, SafetyProfileInteractive)
 	if err != nil {
 		t.Fatalf("NormalizeACPConfigs returned error: %v", err)
 	}
@@ -290,3 +339,121 @@ func TestNormalizeACPConfigs(t *testing.T) {
 		t.Fatalf("act cmd = %v, want copilot --acp", actCfg.Cmd)
 	}
 }
+
+func TestNormalizeACPConfigSafetyProfiles(t *testing.T) {
+	t.Parallel()
+
+	const execPath = "/tmp/norma"
+	bridge := []string{execPath, "tool", "codex-acp-bridge", "--codex-model", "gpt-5-codex"}
+
+	tests := []struct {
+		profile   string
+		agentType string
+		want      []string
+	}{
+		{profile: "", agentType: AgentTypeCodexACP, want: bridge},
+		{profile: SafetyProfileInteractive, agentType: AgentTypeCodexACP, want: bridge},
+		{profile: SafetyProfileInteractive, agentType: AgentTypeGeminiACP, want: []string{"gemini", "--experimental-acp", "--model", "gpt-5-codex"}},
+		{profile: SafetyProfileInteractive, agentType: AgentTypeOpenCodeACP, want: []string{"opencode", "acp"}},
+		{profile: SafetyProfileInteractive, agentType: AgentTypeCopilotACP, want: []string{"copilot", "--acp"}},
+		{profile: SafetyProfileCI, agentType: AgentTypeCodexACP, want: append(slices.Clone(bridge), "--codex-sandbox", "workspace-write", "--codex-approval-policy", "never")},
+		{profile: SafetyProfileCI, agentType: AgentTypeGeminiACP, want: []string{"gemini", "--experimental-acp", "--model", "gpt-5-codex", "--approval-mode", "default"}},
+		{profile: SafetyProfileCI, agentType: AgentTypeOpenCodeACP, want: []string{"opencode", "acp"}},
+		{profile: SafetyProfileCI, agentType: AgentTypeCopilotACP, want: []string{"copilot", "--acp"}},
+		{profile: SafetyProfileLocked, agentType: AgentTypeCodexACP, want: append(slices.Clone(bridge), "--codex-sandbox", "read-only", "--codex-approval-policy", "never")},
+		{profile: SafetyProfileLocked, agentType: AgentTypeGeminiACP, want: []string{"gemini", "--experimental-acp", "--model", "gpt-5-codex", "--approval-mode", "default", "--sandbox"}},
+		{profile: SafetyProfileLocked, agentType: AgentTypeOpenCodeACP, want: []string{"opencode", "acp"}},
+		{profile: SafetyProfileLocked, agentType: AgentTypeCopilotACP, want: []string{"copilot", "--acp"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.profile+"/"+tt.agentType, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := NormalizeACPConfig(Config{Type: tt.agentType, Model: "gpt-5-codex"}, execPath, tt.profile)
+			if err != nil {
+				t.Fatalf("NormalizeACPConfig returned error: %v", err)
+			}
+			if !slices.Equal(got.Cmd, tt.want) {
+				t.Fatalf("cmd = %q, want %q", got.Cmd, tt.want)
+			}
+		})
+	}
+
+	override := Config{Type: AgentTypeCodexACP, Model: "gpt-5-codex", ExtraArgs: []string{"--codex-sandbox", "danger-full-access"}}
+	got, err := NormalizeACPConfig(override, execPath, SafetyProfileCI)
+	if want := append(slices.Clone(bridge), "--codex-approval-policy", "never"); err != nil || !slices.Equal(got.Cmd, want) {
+		t.Fatalf("codex_acp with sandbox in extra_args = (%q, %v), want %q", got.Cmd, err, want)
+	}
+
+	generic := Config{Type: AgentTypeGenericACP, Cmd: []string{"custom-acp", "--yolo"}}
+	got, err = NormalizeACPConfig(generic, execPath, SafetyProfileLocked)
+	if err != nil || !slices.Equal(got.Cmd, generic.Cmd) {
+		t.Fatalf("generic_acp under locked = (%q, %v), want cmd unchanged", got.Cmd, err)
+	}
+
+	if _, err := NormalizeACPConfig(generic, execPath, "yolo"); err == nil {
+		t.Fatal("NormalizeACPConfig accepted an unknown safety profile")
+	}
+}
+
+func TestAutoApprovesPermissions(t *testing.T) {
+	t.Parallel()
+
+	for profile, want := range map[string]bool{
+		"":                       true,
+		SafetyProfileInteractive: true,
+		SafetyProfileCI:          false,
+		SafetyProfileLocked:      false,
+		"unknown":                false,
+	} {
+		if got := AutoApprovesPermissions(profile); got != want {
+			t.Fatalf("AutoApprovesPermissions(%q) = %t, want %t", profile, got, want)
+		}
+	}
+}
+
+func TestConfigModelForIteration(t *testing.T) {
+	t.Parallel()
+
+	cfg := Config{Model: "base", EscalationModels: []string{"cheap", "mid", "strong"}}
+	tests := []struct {
+		iteration int
+		want      string
+	}{
+		{iteration: 0, want: "cheap"},
+		{iteration: 1, want: "cheap"},
+		{iteration: 2, want: "mid"},
+		{iteration: 3, want: "strong"},
+		{iteration: 7, want: "strong"},
+	}
+	for _, tc := range tests {
+		if got := cfg.ModelForIteration(tc.iteration); got != tc.want {
+			t.Fatalf("ModelForIteration(%d) = %q, want %q", tc.iteration, got, tc.want)
+		}
+	}
+
+	if got := (Config{Model: "base"}).ModelForIteration(5); got != "base" {
+		t.Fatalf("ModelForIteration() without escalation = %q, want %q", got, "base")
+	}
+}
+
+func TestConfigWithModelRewritesModelFlags(t *testing.T) {
+	t.Parallel()
+
+	cfg, err := NormalizeACPConfig(Config{Type: AgentTypeGeminiACP, Model: "flash"}, "/tmp/norma", "")
+	if err != nil {
+		t.Fatalf("NormalizeACPConfig() error = %v", err)
+	}
+
+	got := cfg.WithModel("pro")
+	if got.Model != "pro" {
+		t.Fatalf("Model = %q, want pro", got.Model)
+	}
+	if want := []string{"gemini", "--experimental-acp", "--model", "pro"}; !slices.Equal(got.Cmd, want) {
+		t.Fatalf("Cmd = %v, want %v", got.Cmd, want)
+	}
+	if cfg.Cmd[3] != "flash" {
+		t.Fatalf("original Cmd mutated: %v", cfg.Cmd)
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"io"
 	"strings"
+	"time"
 
 	"github.com/coder/acp-go-sdk"
 	"github.com/metalagman/norma/internal/adk/acpagent"
@@ -19,9 +20,11 @@ type CreationRequest struct {
 	Description       string
 	SystemInstruction string
 	WorkingDirectory  string
+	Env               []string
 	Stdout            io.Writer
 	Stderr            io.Writer
 	PermissionHandler func(context.Context, acp.RequestPermissionRequest) (acp.RequestPermissionResponse, error)
+	ShutdownGrace     time.Duration
 }
 
 // constructor is a function that creates a new agent instance.
@@ -84,8 +87,10 @@ var acpConstructor = func(ctx context.Context, cfg agentconfig.Config, req Creat
 		SystemPrompt:      req.SystemInstruction,
 		Command:           cmd,
 		WorkingDir:        req.WorkingDirectory,
+		Env:               req.Env,
 		Stderr:            req.Stderr,
 		PermissionHandler: req.PermissionHandler,
+		ShutdownGrace:     req.ShutdownGrace,
 	})
 }
 
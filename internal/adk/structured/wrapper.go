@@ -41,8 +41,9 @@ const (
 - Read output JSON schema (text below).
 - Read input JSON content (text below).
 - Produce output JSON that conforms to the output schema.
-- Return only output JSON text.
-
+{{ if .OutputFile }}- Write only output JSON to the file {{ .OutputFile }}; do not return it as text.
+{{ else }}- Return only output JSON text.
+{{ end }}
 Input JSON Schema:
 {{ .InputSchema }}
 
@@ -65,13 +66,17 @@ type wrapperAgent struct {
 	wrapped                   adkagent.Agent
 	inputSchema               string
 	outputSchema              string
+	outputFile                string
 	maxAccumulatedOutputBytes int
+	outputFilter              func(string) string
 }
 
 type options struct {
 	inputSchema               string
 	outputSchema              string
+	outputFile                string
 	maxAccumulatedOutputBytes int
+	outputFilter              func(string) string
 }
 
 // Option customizes wrapper behavior at creation time.
@@ -91,6 +96,15 @@ func WithOutputSchema(outputSchema string) Option {
 	}
 }
 
+// WithOutputFile asks the wrapped agent to write its output JSON to path instead
+// of returning it as text. Text output is then not validated; the caller is
+// responsible for reading and validating the file.
+func WithOutputFile(path string) Option {
+	return func(o *options) {
+		o.outputFile = strings.TrimSpace(path)
+	}
+}
+
 // WithMaxAccumulatedOutputBytes sets the maximum number of output text bytes
 // accumulated for schema validation in a single turn.
 func WithMaxAccumulatedOutputBytes(maxBytes int) Option {
@@ -99,6 +113,15 @@ func WithMaxAccumulatedOutputBytes(maxBytes int) Option {
 	}
 }
 
+// WithOutputFilter transforms accumulated text output before it is validated,
+// e.g. to drop lines that are not part of the JSON response. Emitted events are
+// left unchanged.
+func WithOutputFilter(filter func(string) string) Option {
+	return func(o *options) {
+		o.outputFilter = filter
+	}
+}
+
 // NewAgent creates an ADK agent wrapper around another agent and validates
 // structured input/output using configured schemas.
 func NewAgent(wrapped adkagent.Agent, setters ...Option) (adkagent.Agent, error) {
@@ -152,7 +175,9 @@ func NewAgent(wrapped adkagent.Agent, setters ...Option) (adkagent.Agent, error)
 		wrapped:                   wrapped,
 		inputSchema:               opts.inputSchema,
 		outputSchema:              opts.outputSchema,
+		outputFile:                opts.outputFile,
 		maxAccumulatedOutputBytes: opts.maxAccumulatedOutputBytes,
+		outputFilter:              opts.outputFilter,
 	}, nil
 }
 
@@ -192,6 +217,7 @@ func (w *wrapperAgent) Run(ctx adkagent.InvocationContext) iter.Seq2[*session.Ev
 			Input:        rawInput,
 			InputSchema:  w.inputSchema,
 			OutputSchema: w.outputSchema,
+			OutputFile:   w.outputFile,
 		})
 		if err != nil {
 			yield(nil, fmt.Errorf("build structured prompt: %w", err))
@@ -269,10 +295,15 @@ func (w *wrapperAgent) Run(ctx adkagent.InvocationContext) iter.Seq2[*session.Ev
 			Int("accumulated_output_len", len(accumulatedText)).
 			Str("accumulated_output_preview", truncateForLog(accumulatedText, 320)).
 			Msg("collected accumulated output from inner agent")
-		if err := validateOutputSchema(w.outputSchema, accumulatedText); err != nil {
-			logger.Debug().Err(err).Msg("structured wrapper output validation failed")
-			yield(nil, fmt.Errorf("validate structured output: %w", err))
-			return
+		if w.outputFile == "" {
+			if w.outputFilter != nil {
+				accumulatedText = w.outputFilter(accumulatedText)
+			}
+			if err := validateOutputSchema(w.outputSchema, accumulatedText); err != nil {
+				logger.Debug().Err(err).Msg("structured wrapper output validation failed")
+				yield(nil, fmt.Errorf("validate structured output: %w", err))
+				return
+			}
 		}
 
 		for _, ev := range buffered {
@@ -314,6 +345,7 @@ type promptData struct {
 	Input        string
 	InputSchema  string
 	OutputSchema string
+	OutputFile   string
 }
 
 func buildPrompt(data promptData) (string, error) {
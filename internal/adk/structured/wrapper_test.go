@@ -322,6 +322,57 @@ func TestWrapperAgentRejectsAccumulatedOutputOverLimit(t *testing.T) {
 	}
 }
 
+func TestWrapperAgentOutputFileSkipsTextValidation(t *testing.T) {
+	t.Parallel()
+
+	inner := newStaticOutputAgent(t, "wrote the file", nil)
+	wrapped, err := NewAgent(inner, WithOutputFile("/tmp/run/response.json"))
+	if err != nil {
+		t.Fatalf("NewAgent() error = %v", err)
+	}
+
+	if _, runErr := runSingleTurn(t, wrapped, `{"input":"hello"}`); runErr != nil {
+		t.Fatalf("runSingleTurn() error = %v", runErr)
+	}
+
+	prompt, err := buildPrompt(promptData{OutputFile: "/tmp/run/response.json"})
+	if err != nil {
+		t.Fatalf("buildPrompt() error = %v", err)
+	}
+	if !strings.Contains(prompt, "Write only output JSON to the file /tmp/run/response.json") {
+		t.Fatalf("prompt = %q, want output file instruction", prompt)
+	}
+	if strings.Contains(prompt, "Return only output JSON text") {
+		t.Fatalf("prompt = %q, want no text output instruction", prompt)
+	}
+}
+
+func TestWrapperAgentFiltersOutputBeforeValidation(t *testing.T) {
+	t.Parallel()
+
+	inner := newStaticOutputAgent(t, "THINK: drafting\n{\"output\":\"done\"}", nil)
+	dropThinking := func(text string) string {
+		_, rest, _ := strings.Cut(text, "\n")
+		return rest
+	}
+
+	unfiltered, err := NewAgent(inner)
+	if err != nil {
+		t.Fatalf("NewAgent() error = %v", err)
+	}
+	if _, runErr := runSingleTurn(t, unfiltered, `{"input":"hello"}`); runErr == nil {
+		t.Fatal("expected validation error without filter, got nil")
+	}
+
+	filtered, err := NewAgent(inner, WithOutputFilter(dropThinking))
+	if err != nil {
+		t.Fatalf("NewAgent() error = %v", err)
+	}
+	if _, runErr := runSingleTurn(t, filtered, `{"input":"hello"}`); runErr != nil {
+		t.Fatalf("runSingleTurn() error = %v", runErr)
+	}
+}
+
 func newStaticOutputAgent(t *testing.T, output string, called *int32) adkagent.Agent {
 	t.Helper()
 
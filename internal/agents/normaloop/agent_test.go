@@ -11,6 +11,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/metalagman/norma/internal/config"
 	"github.com/metalagman/norma/internal/db"
 	runpkg "github.com/metalagman/norma/internal/run"
 	"github.com/metalagman/norma/internal/task"
@@ -117,6 +118,11 @@ func (m *mockTracker) SetNotes(context.Context, string, string) error    { retur
 
 type mockRunStore struct {
 	statusByRunID map[string]string
+	runsByTaskID  map[string]int
+	failedRuns    map[string]int
+	failureKinds  []string
+	setStatuses   []string
+	loopState     db.LoopState
 	err           error
 }
 
@@ -126,9 +132,45 @@ func (m *mockRunStore) GetRunStatus(_ context.Context, runID string) (string, er
 	}
 	return m.statusByRunID[runID], nil
 }
-func (m *mockRunStore) CreateRun(context.Context, string, string, string, int) error  { return nil }
+func (m *mockRunStore) CreateRun(context.Context, string, string, string, string, int) error {
+	return nil
+}
+func (m *mockRunStore) SetRunCorrelationID(context.Context, string, string) error {
+	return nil
+}
+func (m *mockRunStore) SetRunACSnapshot(context.Context, string, string) error {
+	return nil
+}
+func (m *mockRunStore) RunACSnapshot(context.Context, string) (string, error) {
+	return "", nil
+}
+func (m *mockRunStore) RunCountForTask(_ context.Context, taskID string) (int, error) {
+	return m.runsByTaskID[taskID], nil
+}
+func (m *mockRunStore) FailedRunCountForTask(_ context.Context, taskID string) (int, error) {
+	return m.failedRuns[taskID], nil
+}
 func (m *mockRunStore) UpdateRun(context.Context, string, db.Update, *db.Event) error { return nil }
-func (m *mockRunStore) DB() *sql.DB                                                   { return nil }
+func (m *mockRunStore) MarkRunFailed(ctx context.Context, _ string, failureKind, _ string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	m.failureKinds = append(m.failureKinds, failureKind)
+	return nil
+}
+func (m *mockRunStore) SetRunStatus(_ context.Context, _ string, status, _ string) error {
+	m.setStatuses = append(m.setStatuses, status)
+	return nil
+}
+func (m *mockRunStore) AddEvent(context.Context, string, db.Event) error           { return nil }
+func (m *mockRunStore) SaveLoopState(_ context.Context, state db.LoopState) error {
+	m.loopState = state
+	return nil
+}
+func (m *mockRunStore) LoadLoopState(context.Context) (db.LoopState, error) {
+	return m.loopState, nil
+}
+func (m *mockRunStore) DB() *sql.DB { return nil }
 
 type mockFactory struct {
 	outcome runpkg.AgentOutcome
@@ -249,12 +291,13 @@ func TestRunTaskByIDRunnerErrorMarksFailed(t *testing.T) {
 		},
 	}
 	tmp := t.TempDir()
+	store := &mockRunStore{statusByRunID: map[string]string{}}
 	w := &loopRuntime{
 		logger:     zerolog.Nop(),
 		workingDir: "", // skip git
 		normaDir:   tmp,
 		tracker:    tracker,
-		runStore:   &mockRunStore{statusByRunID: map[string]string{}},
+		runStore:   store,
 		factory: &mockFactory{
 			err: errors.New("runner failed"),
 		},
@@ -269,6 +312,149 @@ func TestRunTaskByIDRunnerErrorMarksFailed(t *testing.T) {
 	if !slices.Equal(tracker.markStatusCalls, wantCalls) {
 		t.Fatalf("mark status calls = %v, want %v", tracker.markStatusCalls, wantCalls)
 	}
+	if got := runpkg.FailureKindOf(err, ""); got != runpkg.FailureInfrastructure {
+		t.Fatalf("FailureKindOf() = %q, want %q", got, runpkg.FailureInfrastructure)
+	}
+	if want := []string{string(runpkg.FailureInfrastructure)}; !slices.Equal(store.failureKinds, want) {
+		t.Fatalf("recorded failure kinds = %v, want %v", store.failureKinds, want)
+	}
+}
+
+func TestFailRunRecordsFailureAfterCancel(t *testing.T) {
+	t.Parallel()
+
+	store := &mockRunStore{statusByRunID: map[string]string{}}
+	w := &loopRuntime{logger: zerolog.Nop(), runStore: store}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	err := w.failRun(ctx, "run-1", runpkg.FailureInfrastructure, context.Canceled)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("failRun() error = %v, want %v", err, context.Canceled)
+	}
+	if want := []string{string(runpkg.FailureInfrastructure)}; !slices.Equal(store.failureKinds, want) {
+		t.Fatalf("recorded failure kinds = %v, want %v", store.failureKinds, want)
+	}
+}
+
+func TestRunTaskByIDApprovalTimeoutStopsTask(t *testing.T) {
+	t.Parallel()
+
+	taskID := "norma-4"
+	tracker := &mockTracker{
+		tasksByID: map[string]task.Task{
+			taskID: {
+				ID:     taskID,
+				Status: statusTodo,
+				Goal:   "test goal",
+			},
+		},
+	}
+	v := "PASS"
+	store := &mockRunStore{statusByRunID: map[string]string{}}
+	w := &loopRuntime{
+		logger:     zerolog.Nop(),
+		cfg:        config.Config{RequireApprovalToApply: true, ApprovalTimeout: 1},
+		workingDir: "", // skip git
+		normaDir:   t.TempDir(),
+		tracker:    tracker,
+		runStore:   store,
+		factory: &mockFactory{
+			outcome: runpkg.AgentOutcome{Status: runpkg.StatusPassed, Verdict: &v},
+		},
+	}
+
+	// norma run returns the same error and marks the task the same way.
+	err := w.runTaskByID(context.Background(), taskID)
+	if !errors.Is(err, runpkg.ErrApprovalTimeout) {
+		t.Fatalf("runTaskByID() error = %v, want ErrApprovalTimeout", err)
+	}
+	wantCalls := []string{statusPlanning, runpkg.StatusStopped}
+	if !slices.Equal(tracker.markStatusCalls, wantCalls) {
+		t.Fatalf("mark status calls = %v, want %v", tracker.markStatusCalls, wantCalls)
+	}
+	if len(store.failureKinds) != 0 {
+		t.Fatalf("recorded failure kinds = %v, want none for an unapproved run", store.failureKinds)
+	}
+}
+
+func TestRunTaskByIDFailedVerdictRecordsTaskNotMet(t *testing.T) {
+	t.Parallel()
+
+	taskID := "norma-3"
+	tracker := &mockTracker{
+		tasksByID: map[string]task.Task{
+			taskID: {
+				ID:     taskID,
+				Status: statusTodo,
+				Goal:   "test goal",
+			},
+		},
+	}
+	v := "FAIL"
+	store := &mockRunStore{statusByRunID: map[string]string{}}
+	w := &loopRuntime{
+		logger:     zerolog.Nop(),
+		workingDir: "", // skip git
+		normaDir:   t.TempDir(),
+		tracker:    tracker,
+		runStore:   store,
+		factory: &mockFactory{
+			outcome: runpkg.AgentOutcome{Status: runpkg.StatusFailed, Verdict: &v},
+		},
+	}
+
+	err := w.runTaskByID(context.Background(), taskID)
+	if err == nil {
+		t.Fatal("runTaskByID() error = nil, want error")
+	}
+	if got := runpkg.FailureKindOf(err, ""); got != runpkg.FailureTaskNotMet {
+		t.Fatalf("FailureKindOf() = %q, want %q", got, runpkg.FailureTaskNotMet)
+	}
+	if want := []string{string(runpkg.FailureTaskNotMet)}; !slices.Equal(store.failureKinds, want) {
+		t.Fatalf("recorded failure kinds = %v, want %v", store.failureKinds, want)
+	}
+}
+
+func TestRunTaskByIDAppliedPartialStopsTask(t *testing.T) {
+	t.Parallel()
+
+	taskID := "norma-4"
+	tracker := &mockTracker{
+		tasksByID: map[string]task.Task{
+			taskID: {
+				ID:     taskID,
+				Status: statusTodo,
+				Goal:   "test goal",
+			},
+		},
+	}
+	v := "PARTIAL"
+	store := &mockRunStore{statusByRunID: map[string]string{}}
+	w := &loopRuntime{
+		logger:     zerolog.Nop(),
+		cfg:        config.Config{ApplyOnPartial: config.PartialApplyPolicy{Enabled: true}},
+		workingDir: "", // skip git
+		normaDir:   t.TempDir(),
+		tracker:    tracker,
+		runStore:   store,
+		factory: &mockFactory{
+			outcome: runpkg.AgentOutcome{Status: runpkg.StatusFailed, Verdict: &v, PassedRequired: 1},
+		},
+	}
+
+	if err := w.runTaskByID(context.Background(), taskID); err != nil {
+		t.Fatalf("runTaskByID() error = %v", err)
+	}
+	if want := []string{statusPlanning, runpkg.StatusStopped}; !slices.Equal(tracker.markStatusCalls, want) {
+		t.Fatalf("mark status calls = %v, want %v", tracker.markStatusCalls, want)
+	}
+	if len(store.failureKinds) != 0 {
+		t.Fatalf("recorded failure kinds = %v, want none for an applied partial run", store.failureKinds)
+	}
+	if !slices.Contains(store.setStatuses, runpkg.StatusStopped) {
+		t.Fatalf("run statuses = %v, want %s", store.setStatuses, runpkg.StatusStopped)
+	}
 }
 
 type mockInvocationContext struct {
@@ -37,19 +37,31 @@ func (w *loopRuntime) runIteration(ctx agent.InvocationContext) iter.Seq2[*sessi
 			return
 		}
 
-		iteration := 1
-		if value, err := ctx.Session().State().Get("iteration"); err == nil {
-			if parsed, ok := value.(int); ok && parsed > 0 {
-				iteration = parsed
-			}
-		}
+		iteration := sessionIteration(ctx.Session().State())
 
 		l.Info().
 			Int("iteration", iteration).
 			Str("task_id", taskID).
 			Msg("starting iteration")
 
+		w.updateLoopStatus(func(s *LoopStatus) {
+			s.State = LoopStateRunning
+			s.CurrentTaskID = taskID
+			s.Iteration = iteration
+		})
+
+		w.startPrefetch(ctx, taskID)
+		stopStatus := w.startStatusRefresh()
 		err = w.runTaskByID(ctx, taskID)
+		stopStatus()
+		w.updateLoopStatus(func(s *LoopStatus) {
+			s.State = LoopStateIdle
+			s.CurrentTaskID = ""
+			s.LastError = ""
+			if err != nil {
+				s.LastError = err.Error()
+			}
+		})
 		if err != nil {
 			if !w.continueOnFail {
 				yield(nil, err)
@@ -65,5 +77,6 @@ func (w *loopRuntime) runIteration(ctx agent.InvocationContext) iter.Seq2[*sessi
 
 		// Clear the task ID so selector can pick a new one (or sleep) next time
 		_ = ctx.Session().State().Set("selected_task_id", "")
+		w.saveLoopState(ctx, iteration+1, "")
 	}
 }
@@ -5,6 +5,7 @@ import (
 	"database/sql"
 	"fmt"
 	"path/filepath"
+	"sync"
 	"time"
 
 	"github.com/metalagman/norma/internal/config"
@@ -23,12 +24,28 @@ const (
 	statusPlanning = "planning"
 )
 
+// labelNeedsHuman marks tasks the loop stopped running after max_runs_per_task runs.
+const labelNeedsHuman = "norma-needs-human"
+
+// labelQuarantined marks tasks the selector stopped picking after loop.quarantine_after failed runs.
+const labelQuarantined = "norma-quarantined"
+
 const maxLoopIterations uint = 1_000_000
 
 type runStatusStore interface {
 	GetRunStatus(ctx context.Context, runID string) (string, error)
-	CreateRun(ctx context.Context, runID, goal, runDir string, iteration int) error
+	CreateRun(ctx context.Context, runID, taskID, goal, runDir string, iteration int) error
+	SetRunCorrelationID(ctx context.Context, runID, correlationID string) error
+	SetRunACSnapshot(ctx context.Context, runID, snapshotJSON string) error
+	RunACSnapshot(ctx context.Context, runID string) (string, error)
+	RunCountForTask(ctx context.Context, taskID string) (int, error)
+	FailedRunCountForTask(ctx context.Context, taskID string) (int, error)
 	UpdateRun(ctx context.Context, runID string, update db.Update, event *db.Event) error
+	MarkRunFailed(ctx context.Context, runID, failureKind, message string) error
+	SetRunStatus(ctx context.Context, runID, status, message string) error
+	AddEvent(ctx context.Context, runID string, event db.Event) error
+	SaveLoopState(ctx context.Context, state db.LoopState) error
+	LoadLoopState(ctx context.Context) (db.LoopState, error)
 	DB() *sql.DB
 }
 
@@ -43,16 +60,35 @@ type loopRuntime struct {
 	continueOnFail       bool
 	policy               task.SelectionPolicy
 	overrideBackoffSteps []time.Duration
+	overrideSleep        sleepFunc
+	overrideSelect       selectFunc
+	overrideStatusTick   time.Duration
+
+	// restored is set once the persisted loop state was loaded.
+	restored bool
+	// prefetch is the selection started during the last iteration when loop.prefetch is enabled.
+	prefetch *taskPrefetch
+
+	statusMu sync.Mutex
+	status   LoopStatus
 }
 
 // New constructs the normaloop ADK loop agent runtime.
 func New(logger zerolog.Logger, cfg config.Config, workingDir string, tracker task.Tracker, runStore runStatusStore, factory runpkg.AgentFactory, continueOnFail bool, policy task.SelectionPolicy) (agent.Agent, error) {
+	w, err := newLoopRuntime(logger, cfg, workingDir, tracker, runStore, factory, continueOnFail, policy)
+	if err != nil {
+		return nil, err
+	}
+	return w.newAgent()
+}
+
+func newLoopRuntime(logger zerolog.Logger, cfg config.Config, workingDir string, tracker task.Tracker, runStore runStatusStore, factory runpkg.AgentFactory, continueOnFail bool, policy task.SelectionPolicy) (*loopRuntime, error) {
 	absWorkingDir, err := filepath.Abs(workingDir)
 	if err != nil {
 		return nil, fmt.Errorf("resolve absolute working dir: %w", err)
 	}
 
-	w := &loopRuntime{
+	return &loopRuntime{
 		logger:         logger.With().Str("component", "normaloop").Logger(),
 		cfg:            cfg,
 		workingDir:     absWorkingDir,
@@ -62,8 +98,11 @@ func New(logger zerolog.Logger, cfg config.Config, workingDir string, tracker ta
 		factory:        factory,
 		continueOnFail: continueOnFail,
 		policy:         policy,
-	}
+	}, nil
+}
 
+// newAgent assembles the selector and iteration agents into the loop agent.
+func (w *loopRuntime) newAgent() (agent.Agent, error) {
 	iterationAgent, err := w.newIterationAgent()
 	if err != nil {
 		return nil, fmt.Errorf("create normaloop iteration agent: %w", err)
@@ -0,0 +1,745 @@
+package normaloop
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/metalagman/norma/internal/adkrunner"
+	"github.com/metalagman/norma/internal/config"
+	"github.com/metalagman/norma/internal/db"
+	runpkg "github.com/metalagman/norma/internal/run"
+	"github.com/metalagman/norma/internal/task"
+	"github.com/rs/zerolog"
+
+	"google.golang.org/adk/session"
+)
+
+// loopTracker is a task.Tracker fake whose task statuses follow MarkStatus calls,
+// so tasks leave the ready set once the loop marks them done.
+type loopTracker struct {
+	*mockTracker
+
+	mu    sync.Mutex
+	order []string
+	tasks map[string]task.Task
+	done  []string
+}
+
+func newLoopTracker(items ...task.Task) *loopTracker {
+	tr := &loopTracker{mockTracker: &mockTracker{}, tasks: make(map[string]task.Task)}
+	for _, item := range items {
+		tr.order = append(tr.order, item.ID)
+		tr.tasks[item.ID] = item
+	}
+	return tr
+}
+
+func (t *loopTracker) LeafTasks(context.Context) ([]task.Task, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	out := make([]task.Task, 0, len(t.order))
+	for _, id := range t.order {
+		if item := t.tasks[id]; item.Status == statusTodo {
+			out = append(out, item)
+		}
+	}
+	return out, nil
+}
+
+func (t *loopTracker) Task(_ context.Context, id string) (task.Task, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	item, ok := t.tasks[id]
+	if !ok {
+		return task.Task{}, errors.New("task not found")
+	}
+	return item, nil
+}
+
+func (t *loopTracker) MarkStatus(_ context.Context, id, status string) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	item := t.tasks[id]
+	item.Status = status
+	t.tasks[id] = item
+	if status == "done" {
+		t.done = append(t.done, id)
+	}
+	return nil
+}
+
+func (t *loopTracker) AddLabel(_ context.Context, id, label string) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	item := t.tasks[id]
+	item.Labels = append(item.Labels, label)
+	t.tasks[id] = item
+	return nil
+}
+
+func (t *loopTracker) Children(_ context.Context, parentID string) ([]task.Task, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var out []task.Task
+	for _, id := range t.order {
+		if item := t.tasks[id]; item.ParentID == parentID {
+			out = append(out, item)
+		}
+	}
+	return out, nil
+}
+
+func (t *loopTracker) doneIDs() []string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return slices.Clone(t.done)
+}
+
+// loopFactory records the tasks it builds and always finalizes with a PASS verdict.
+type loopFactory struct {
+	mockFactory
+
+	mu    sync.Mutex
+	built []string
+}
+
+func (f *loopFactory) Build(ctx context.Context, meta runpkg.RunMeta, payload runpkg.TaskPayload) (runpkg.AgentBuild, error) {
+	f.mu.Lock()
+	f.built = append(f.built, payload.ID)
+	f.mu.Unlock()
+	return f.mockFactory.Build(ctx, meta, payload)
+}
+
+func (f *loopFactory) Finalize(context.Context, runpkg.RunMeta, runpkg.TaskPayload, session.Session) (runpkg.AgentOutcome, error) {
+	verdict := "PASS"
+	return runpkg.AgentOutcome{Status: "passed", Verdict: &verdict}, nil
+}
+
+func (f *loopFactory) builtIDs() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return slices.Clone(f.built)
+}
+
+func TestLoopRunsReadyTasksAndSleepsWhenEmpty(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	repo := newLoopRepo(t, ctx, "norma-a1", "norma-b2")
+	tracker := newLoopTracker(
+		task.Task{ID: "norma-a1", Type: "task", Status: statusTodo, Goal: "first"},
+		task.Task{ID: "norma-b2", Type: "task", Status: statusTodo, Goal: "second"},
+	)
+	factory := &loopFactory{}
+
+	w, err := newLoopRuntime(zerolog.Nop(), config.Config{}, repo, tracker, &mockRunStore{statusByRunID: map[string]string{}}, factory, false, task.SelectionPolicy{})
+	if err != nil {
+		t.Fatalf("newLoopRuntime() error = %v", err)
+	}
+	w.overrideBackoffSteps = []time.Duration{time.Minute}
+
+	var sleeps []time.Duration
+	w.overrideSleep = func(_ context.Context, d time.Duration) bool {
+		sleeps = append(sleeps, d)
+		cancel()
+		return false
+	}
+
+	loopAgent, err := w.newAgent()
+	if err != nil {
+		t.Fatalf("newAgent() error = %v", err)
+	}
+	_, _, err = adkrunner.Run(ctx, adkrunner.RunInput{
+		Agent:        loopAgent,
+		InitialState: map[string]any{"iteration": 1},
+	})
+	if err != nil && !errors.Is(err, context.Canceled) {
+		t.Fatalf("adkrunner.Run() error = %v", err)
+	}
+
+	want := []string{"norma-a1", "norma-b2"}
+	if got := factory.builtIDs(); !slices.Equal(got, want) {
+		t.Fatalf("built tasks = %v, want %v", got, want)
+	}
+	if got := tracker.doneIDs(); !slices.Equal(got, want) {
+		t.Fatalf("done tasks = %v, want %v", got, want)
+	}
+	for _, id := range want {
+		if _, err := os.Stat(filepath.Join(repo, id+".txt")); err != nil {
+			t.Fatalf("changes for %s not applied: %v", id, err)
+		}
+	}
+	if want := []time.Duration{time.Minute}; !slices.Equal(sleeps, want) {
+		t.Fatalf("sleeps = %v, want %v", sleeps, want)
+	}
+}
+
+// flakyTracker fails LeafTasks with a transient error a set number of times before
+// delegating to the wrapped loopTracker.
+type flakyTracker struct {
+	*loopTracker
+
+	mu       sync.Mutex
+	failures int
+	calls    int
+}
+
+func (t *flakyTracker) LeafTasks(ctx context.Context) ([]task.Task, error) {
+	t.mu.Lock()
+	t.calls++
+	fail := t.calls <= t.failures
+	t.mu.Unlock()
+	if fail {
+		return nil, fmt.Errorf("exec bd [ready]: %w: database is locked", task.ErrTrackerUnavailable)
+	}
+	return t.loopTracker.LeafTasks(ctx)
+}
+
+func TestLoopRetriesTransientTrackerFailures(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	repo := newLoopRepo(t, ctx, "norma-a1")
+	tracker := &flakyTracker{
+		loopTracker: newLoopTracker(task.Task{ID: "norma-a1", Type: "task", Status: statusTodo, Goal: "first"}),
+		failures:    2,
+	}
+	factory := &loopFactory{}
+	cfg := config.Config{Loop: config.LoopConfig{TrackerRetries: 2}}
+
+	w, err := newLoopRuntime(zerolog.Nop(), cfg, repo, tracker, &mockRunStore{statusByRunID: map[string]string{}}, factory, false, task.SelectionPolicy{})
+	if err != nil {
+		t.Fatalf("newLoopRuntime() error = %v", err)
+	}
+	w.overrideBackoffSteps = []time.Duration{time.Second, time.Minute}
+
+	var sleeps []time.Duration
+	w.overrideSleep = func(_ context.Context, d time.Duration) bool {
+		sleeps = append(sleeps, d)
+		if len(sleeps) > 2 {
+			cancel()
+			return false
+		}
+		return true
+	}
+
+	loopAgent, err := w.newAgent()
+	if err != nil {
+		t.Fatalf("newAgent() error = %v", err)
+	}
+	_, _, err = adkrunner.Run(ctx, adkrunner.RunInput{
+		Agent:        loopAgent,
+		InitialState: map[string]any{"iteration": 1},
+	})
+	if err != nil && !errors.Is(err, context.Canceled) {
+		t.Fatalf("adkrunner.Run() error = %v", err)
+	}
+
+	if got, want := factory.builtIDs(), []string{"norma-a1"}; !slices.Equal(got, want) {
+		t.Fatalf("built tasks = %v, want %v", got, want)
+	}
+	if want := []time.Duration{time.Second, time.Minute, time.Second}; !slices.Equal(sleeps, want) {
+		t.Fatalf("sleeps = %v, want %v", sleeps, want)
+	}
+}
+
+func TestLoopFailsAfterTrackerRetries(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	tracker := &flakyTracker{loopTracker: newLoopTracker(), failures: 3}
+	cfg := config.Config{Loop: config.LoopConfig{TrackerRetries: 2}}
+
+	w, err := newLoopRuntime(zerolog.Nop(), cfg, t.TempDir(), tracker, &mockRunStore{statusByRunID: map[string]string{}}, &loopFactory{}, false, task.SelectionPolicy{})
+	if err != nil {
+		t.Fatalf("newLoopRuntime() error = %v", err)
+	}
+	w.overrideBackoffSteps = []time.Duration{time.Millisecond}
+	sleeps := 0
+	w.overrideSleep = func(context.Context, time.Duration) bool {
+		sleeps++
+		return true
+	}
+
+	loopAgent, err := w.newAgent()
+	if err != nil {
+		t.Fatalf("newAgent() error = %v", err)
+	}
+	_, _, err = adkrunner.Run(ctx, adkrunner.RunInput{
+		Agent:        loopAgent,
+		InitialState: map[string]any{"iteration": 1},
+	})
+	if !errors.Is(err, task.ErrTrackerUnavailable) {
+		t.Fatalf("adkrunner.Run() error = %v, want %v", err, task.ErrTrackerUnavailable)
+	}
+	if sleeps != 2 {
+		t.Fatalf("sleeps = %d, want 2", sleeps)
+	}
+}
+
+// slowTracker delays LeafTasks to make task selection measurably slow.
+type slowTracker struct {
+	*loopTracker
+
+	delay time.Duration
+}
+
+func (t *slowTracker) LeafTasks(ctx context.Context) ([]task.Task, error) {
+	time.Sleep(t.delay)
+	return t.loopTracker.LeafTasks(ctx)
+}
+
+// timedFactory records when each task's run is built and finalized. Building a run
+// blocks for buildDelay to simulate a long iteration.
+type timedFactory struct {
+	loopFactory
+
+	buildDelay  time.Duration
+	builtAt     map[string]time.Time
+	finalizedAt map[string]time.Time
+}
+
+func (f *timedFactory) Build(ctx context.Context, meta runpkg.RunMeta, payload runpkg.TaskPayload) (runpkg.AgentBuild, error) {
+	f.mu.Lock()
+	f.builtAt[payload.ID] = time.Now()
+	f.mu.Unlock()
+	time.Sleep(f.buildDelay)
+	return f.loopFactory.Build(ctx, meta, payload)
+}
+
+func (f *timedFactory) Finalize(ctx context.Context, meta runpkg.RunMeta, payload runpkg.TaskPayload, sess session.Session) (runpkg.AgentOutcome, error) {
+	f.mu.Lock()
+	f.finalizedAt[payload.ID] = time.Now()
+	f.mu.Unlock()
+	return f.loopFactory.Finalize(ctx, meta, payload, sess)
+}
+
+func TestLoopPrefetchShortensGapBetweenTasks(t *testing.T) {
+	t.Parallel()
+
+	const selectDelay = 300 * time.Millisecond
+
+	// gap runs two tasks and returns the time from finalizing the first run to building the second.
+	gap := func(t *testing.T, prefetch bool) time.Duration {
+		t.Helper()
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+
+		repo := newLoopRepo(t, ctx, "norma-a1", "norma-b2")
+		tracker := &slowTracker{
+			loopTracker: newLoopTracker(
+				task.Task{ID: "norma-a1", Type: "task", Status: statusTodo, Goal: "first"},
+				task.Task{ID: "norma-b2", Type: "task", Status: statusTodo, Goal: "second"},
+			),
+			delay: selectDelay,
+		}
+		factory := &timedFactory{buildDelay: 2 * selectDelay, builtAt: map[string]time.Time{}, finalizedAt: map[string]time.Time{}}
+		cfg := config.Config{Loop: config.LoopConfig{Prefetch: prefetch}}
+
+		w, err := newLoopRuntime(zerolog.Nop(), cfg, repo, tracker, &mockRunStore{statusByRunID: map[string]string{}}, factory, false, task.SelectionPolicy{})
+		if err != nil {
+			t.Fatalf("newLoopRuntime() error = %v", err)
+		}
+		w.overrideSleep = func(context.Context, time.Duration) bool {
+			cancel()
+			return false
+		}
+
+		loopAgent, err := w.newAgent()
+		if err != nil {
+			t.Fatalf("newAgent() error = %v", err)
+		}
+		_, _, err = adkrunner.Run(ctx, adkrunner.RunInput{
+			Agent:        loopAgent,
+			InitialState: map[string]any{"iteration": 1},
+		})
+		if err != nil && !errors.Is(err, context.Canceled) {
+			t.Fatalf("adkrunner.Run() error = %v", err)
+		}
+
+		want := []string{"norma-a1", "norma-b2"}
+		if got := factory.builtIDs(); !slices.Equal(got, want) {
+			t.Fatalf("prefetch=%t: built tasks = %v, want %v", prefetch, got, want)
+		}
+		if got := tracker.doneIDs(); !slices.Equal(got, want) {
+			t.Fatalf("prefetch=%t: done tasks = %v, want %v", prefetch, got, want)
+		}
+		return factory.builtAt["norma-b2"].Sub(factory.finalizedAt["norma-a1"])
+	}
+
+	sequential := gap(t, false)
+	prefetched := gap(t, true)
+	if sequential < selectDelay {
+		t.Fatalf("gap without prefetch = %v, want at least the selection delay %v", sequential, selectDelay)
+	}
+	if prefetched >= sequential-selectDelay/2 {
+		t.Fatalf("gap with prefetch = %v, want well below %v without prefetch", prefetched, sequential)
+	}
+}
+
+func TestPrefetchNeverSelectsRunningTask(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	// Both tasks report todo, as a tracker that still lists an in-progress task as ready would.
+	tracker := newLoopTracker(
+		task.Task{ID: "norma-a1", Type: "task", Status: statusTodo, Goal: "running"},
+		task.Task{ID: "norma-b2", Type: "task", Status: statusTodo, Goal: "next"},
+	)
+	cfg := config.Config{Loop: config.LoopConfig{Prefetch: true}}
+
+	w, err := newLoopRuntime(zerolog.Nop(), cfg, t.TempDir(), tracker, &mockRunStore{statusByRunID: map[string]string{}}, &loopFactory{}, false, task.SelectionPolicy{})
+	if err != nil {
+		t.Fatalf("newLoopRuntime() error = %v", err)
+	}
+
+	w.startPrefetch(ctx, "norma-a1")
+	selected, _, ok := w.takePrefetched(ctx)
+	if !ok || selected.ID != "norma-b2" {
+		t.Fatalf("takePrefetched() = %s, %t, want norma-b2, true", selected.ID, ok)
+	}
+	if w.prefetch != nil {
+		t.Fatal("prefetch still pending after it was taken")
+	}
+
+	// A prefetched task that another run picked up meanwhile is dropped.
+	w.startPrefetch(ctx, "norma-a1")
+	<-w.prefetch.done
+	if err := tracker.MarkStatus(ctx, "norma-b2", statusDoing); err != nil {
+		t.Fatalf("MarkStatus() error = %v", err)
+	}
+	if selected, _, ok := w.takePrefetched(ctx); ok {
+		t.Fatalf("takePrefetched() = %s, want no task", selected.ID)
+	}
+
+	// With only the running task left, the prefetch finds nothing.
+	w.startPrefetch(ctx, "norma-a1")
+	<-w.prefetch.done
+	if !errors.Is(w.prefetch.err, errNoTasks) {
+		t.Fatalf("prefetch error = %v, want %v", w.prefetch.err, errNoTasks)
+	}
+}
+
+func TestPrefetchedTaskRecheckedForQuarantineAndRunCap(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	tracker := newLoopTracker(
+		task.Task{ID: "norma-a1", Type: "task", Status: statusTodo, Goal: "running"},
+		task.Task{ID: "norma-b2", Type: "task", Status: statusTodo, Goal: "next"},
+	)
+	store := &mockRunStore{
+		statusByRunID: map[string]string{},
+		runsByTaskID:  map[string]int{},
+		failedRuns:    map[string]int{},
+	}
+	cfg := config.Config{MaxRunsPerTask: 5, Loop: config.LoopConfig{Prefetch: true, QuarantineAfter: 3}}
+
+	w, err := newLoopRuntime(zerolog.Nop(), cfg, t.TempDir(), tracker, store, &loopFactory{}, false, task.SelectionPolicy{})
+	if err != nil {
+		t.Fatalf("newLoopRuntime() error = %v", err)
+	}
+
+	// The prefetched task fails often enough to be quarantined before it is taken.
+	w.startPrefetch(ctx, "norma-a1")
+	<-w.prefetch.done
+	store.failedRuns["norma-b2"] = 3
+	if selected, _, ok := w.takePrefetched(ctx); ok {
+		t.Fatalf("takePrefetched() = %s, want the quarantined task dropped", selected.ID)
+	}
+	if item, _ := tracker.Task(ctx, "norma-b2"); !slices.Contains(item.Labels, labelQuarantined) {
+		t.Fatalf("labels = %v, want %s", item.Labels, labelQuarantined)
+	}
+
+	// A prefetched task that reaches the run cap meanwhile is dropped as well.
+	tracker.tasks["norma-b2"] = task.Task{ID: "norma-b2", Type: "task", Status: statusTodo, Goal: "next"}
+	store.failedRuns["norma-b2"] = 0
+	w.startPrefetch(ctx, "norma-a1")
+	<-w.prefetch.done
+	store.runsByTaskID["norma-b2"] = 5
+	if selected, _, ok := w.takePrefetched(ctx); ok {
+		t.Fatalf("takePrefetched() = %s, want the capped task dropped", selected.ID)
+	}
+	if item, _ := tracker.Task(ctx, "norma-b2"); !slices.Contains(item.Labels, labelNeedsHuman) {
+		t.Fatalf("labels = %v, want %s", item.Labels, labelNeedsHuman)
+	}
+}
+
+func TestLoopSkipsTaskAtRunCap(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	repo := newLoopRepo(t, ctx, "norma-a1", "norma-b2")
+	tracker := newLoopTracker(
+		task.Task{ID: "norma-a1", Type: "task", Status: statusTodo, Goal: "capped"},
+		task.Task{ID: "norma-b2", Type: "task", Status: statusTodo, Goal: "below cap"},
+	)
+	factory := &loopFactory{}
+	store := &mockRunStore{
+		statusByRunID: map[string]string{},
+		runsByTaskID:  map[string]int{"norma-a1": 3, "norma-b2": 2},
+	}
+
+	w, err := newLoopRuntime(zerolog.Nop(), config.Config{MaxRunsPerTask: 3}, repo, tracker, store, factory, false, task.SelectionPolicy{})
+	if err != nil {
+		t.Fatalf("newLoopRuntime() error = %v", err)
+	}
+
+	for _, id := range []string{"norma-a1", "norma-b2"} {
+		if err := w.runTaskByID(ctx, id); err != nil {
+			t.Fatalf("runTaskByID(%s) error = %v", id, err)
+		}
+	}
+
+	if got, want := factory.builtIDs(), []string{"norma-b2"}; !slices.Equal(got, want) {
+		t.Fatalf("built tasks = %v, want %v", got, want)
+	}
+	capped, _ := tracker.Task(ctx, "norma-a1")
+	if !slices.Contains(capped.Labels, labelNeedsHuman) {
+		t.Fatalf("capped task labels = %v, want %s", capped.Labels, labelNeedsHuman)
+	}
+	if capped.Status != statusTodo {
+		t.Fatalf("capped task status = %q, want %q", capped.Status, statusTodo)
+	}
+	if isRunnableTask(capped) {
+		t.Fatal("capped task is still runnable")
+	}
+}
+
+func TestSelectNextTaskQuarantinesFailingTask(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	tracker := newLoopTracker(
+		task.Task{ID: "norma-a1", Type: "task", Status: statusTodo, Goal: "keeps failing"},
+		task.Task{ID: "norma-b2", Type: "task", Status: statusTodo, Goal: "below limit"},
+	)
+	store := &mockRunStore{
+		statusByRunID: map[string]string{},
+		failedRuns:    map[string]int{"norma-a1": 3, "norma-b2": 2},
+	}
+	cfg := config.Config{Loop: config.LoopConfig{QuarantineAfter: 3}}
+
+	w, err := newLoopRuntime(zerolog.Nop(), cfg, t.TempDir(), tracker, store, &loopFactory{}, false, task.SelectionPolicy{})
+	if err != nil {
+		t.Fatalf("newLoopRuntime() error = %v", err)
+	}
+
+	selected, _, err := w.selectNextTask(ctx)
+	if err != nil {
+		t.Fatalf("selectNextTask() error = %v", err)
+	}
+	if selected.ID != "norma-b2" {
+		t.Fatalf("selectNextTask() = %s, want norma-b2", selected.ID)
+	}
+	quarantined, _ := tracker.Task(ctx, "norma-a1")
+	if !slices.Contains(quarantined.Labels, labelQuarantined) {
+		t.Fatalf("quarantined task labels = %v, want %s", quarantined.Labels, labelQuarantined)
+	}
+	if isRunnableTask(quarantined) {
+		t.Fatal("quarantined task is still runnable")
+	}
+
+	store.failedRuns["norma-b2"] = 3
+	if _, _, err := w.selectNextTask(ctx); !errors.Is(err, errNoTasks) {
+		t.Fatalf("selectNextTask() error = %v, want %v", err, errNoTasks)
+	}
+}
+
+func TestSelectNextTaskHonoursTaskAllowlist(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	tracker := newLoopTracker(
+		task.Task{ID: "norma-a1", Type: "task", Status: statusTodo, Goal: "not listed"},
+		task.Task{ID: "norma-b2", Type: "task", Status: statusTodo, Goal: "listed first"},
+		task.Task{ID: "norma-c3", Type: "task", Status: statusTodo, Goal: "listed second"},
+	)
+	cfg := config.Config{Loop: config.LoopConfig{TaskAllowlist: []string{"norma-c3", "norma-b2"}}}
+
+	w, err := newLoopRuntime(zerolog.Nop(), cfg, t.TempDir(), tracker, &mockRunStore{statusByRunID: map[string]string{}}, &loopFactory{}, false, task.SelectionPolicy{})
+	if err != nil {
+		t.Fatalf("newLoopRuntime() error = %v", err)
+	}
+
+	for _, want := range []string{"norma-b2", "norma-c3"} {
+		selected, _, err := w.selectNextTask(ctx)
+		if err != nil {
+			t.Fatalf("selectNextTask() error = %v", err)
+		}
+		if selected.ID != want {
+			t.Fatalf("selectNextTask() = %s, want %s", selected.ID, want)
+		}
+		if err := tracker.MarkStatus(ctx, selected.ID, "done"); err != nil {
+			t.Fatalf("MarkStatus(%s) error = %v", selected.ID, err)
+		}
+	}
+
+	if _, _, err := w.selectNextTask(ctx); !errors.Is(err, errNoTasks) {
+		t.Fatalf("selectNextTask() error = %v, want %v", err, errNoTasks)
+	}
+}
+
+func TestLoopClosesParentsOnlyWhenEnabled(t *testing.T) {
+	t.Parallel()
+
+	for _, enabled := range []bool{false, true} {
+		ctx := context.Background()
+		repo := newLoopRepo(t, ctx, "norma-t1")
+		tracker := newLoopTracker(
+			task.Task{ID: "norma-e1", Type: "epic", Status: "doing"},
+			task.Task{ID: "norma-f1", Type: "feature", ParentID: "norma-e1", Status: "doing"},
+			task.Task{ID: "norma-t1", Type: "task", ParentID: "norma-f1", Status: statusTodo, Goal: "last child"},
+		)
+
+		cfg := config.Config{AutoCloseParents: enabled}
+		w, err := newLoopRuntime(zerolog.Nop(), cfg, repo, tracker, &mockRunStore{statusByRunID: map[string]string{}}, &loopFactory{}, false, task.SelectionPolicy{})
+		if err != nil {
+			t.Fatalf("newLoopRuntime() error = %v", err)
+		}
+		if err := w.runTaskByID(ctx, "norma-t1"); err != nil {
+			t.Fatalf("runTaskByID() error = %v", err)
+		}
+
+		want := []string{"norma-t1"}
+		if enabled {
+			want = []string{"norma-t1", "norma-f1", "norma-e1"}
+		}
+		if got := tracker.doneIDs(); !slices.Equal(got, want) {
+			t.Fatalf("auto_close_parents=%v: done = %v, want %v", enabled, got, want)
+		}
+	}
+}
+
+func TestLoopResumesPersistedLoopState(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		saved      db.LoopState
+		failedRuns map[string]int
+		wantBuilt  []string
+	}{
+		{
+			name:      "resumes_selected_task",
+			saved:     db.LoopState{Iteration: 7, SelectedTaskID: "norma-b2"},
+			wantBuilt: []string{"norma-b2", "norma-a1"},
+		},
+		{
+			name:      "skips_task_no_longer_runnable",
+			saved:     db.LoopState{Iteration: 7, SelectedTaskID: "norma-gone"},
+			wantBuilt: []string{"norma-a1", "norma-b2"},
+		},
+		{
+			name:       "skips_quarantined_task",
+			saved:      db.LoopState{Iteration: 7, SelectedTaskID: "norma-b2"},
+			failedRuns: map[string]int{"norma-b2": 3},
+			wantBuilt:  []string{"norma-a1"},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+			defer cancel()
+
+			repo := newLoopRepo(t, ctx, "norma-a1", "norma-b2")
+			tracker := newLoopTracker(
+				task.Task{ID: "norma-a1", Type: "task", Status: statusTodo, Goal: "first"},
+				task.Task{ID: "norma-b2", Type: "task", Status: statusTodo, Goal: "second"},
+			)
+			factory := &loopFactory{}
+			store := &mockRunStore{statusByRunID: map[string]string{}, loopState: tc.saved, failedRuns: tc.failedRuns}
+			cfg := config.Config{Loop: config.LoopConfig{QuarantineAfter: 3}}
+
+			w, err := newLoopRuntime(zerolog.Nop(), cfg, repo, tracker, store, factory, false, task.SelectionPolicy{})
+			if err != nil {
+				t.Fatalf("newLoopRuntime() error = %v", err)
+			}
+			w.overrideSleep = func(context.Context, time.Duration) bool {
+				cancel()
+				return false
+			}
+
+			loopAgent, err := w.newAgent()
+			if err != nil {
+				t.Fatalf("newAgent() error = %v", err)
+			}
+			_, _, err = adkrunner.Run(ctx, adkrunner.RunInput{
+				Agent:        loopAgent,
+				InitialState: map[string]any{"iteration": 1},
+			})
+			if err != nil && !errors.Is(err, context.Canceled) {
+				t.Fatalf("adkrunner.Run() error = %v", err)
+			}
+
+			if got := factory.builtIDs(); !slices.Equal(got, tc.wantBuilt) {
+				t.Fatalf("built tasks = %v, want %v", got, tc.wantBuilt)
+			}
+			if want := (db.LoopState{Iteration: 7 + len(tc.wantBuilt)}); store.loopState != want {
+				t.Fatalf("persisted loop state = %+v, want %+v", store.loopState, want)
+			}
+		})
+	}
+}
+
+// newLoopRepo creates a git repo with one task branch per id, each adding <id>.txt.
+func newLoopRepo(t *testing.T, ctx context.Context, ids ...string) string {
+	t.Helper()
+	repo := t.TempDir()
+	runLoopGit(t, ctx, repo, "init", "-b", "master")
+	runLoopGit(t, ctx, repo, "config", "user.email", "norma@example.com")
+	runLoopGit(t, ctx, repo, "config", "user.name", "Norma Test")
+	writeLoopFile(t, filepath.Join(repo, ".gitignore"), ".norma/\n")
+	runLoopGit(t, ctx, repo, "add", "-A")
+	runLoopGit(t, ctx, repo, "commit", "-m", "chore: initial")
+
+	for _, id := range ids {
+		runLoopGit(t, ctx, repo, "checkout", "-b", "norma/task/"+task.BranchSlug(id), "master")
+		writeLoopFile(t, filepath.Join(repo, id+".txt"), id+"\n")
+		runLoopGit(t, ctx, repo, "add", "-A")
+		runLoopGit(t, ctx, repo, "commit", "-m", "chore: do step 1")
+	}
+	runLoopGit(t, ctx, repo, "checkout", "master")
+	return repo
+}
+
+func runLoopGit(t *testing.T, ctx context.Context, dir string, args ...string) {
+	t.Helper()
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = dir
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("git %s: %v\n%s", strings.Join(args, " "), err, out)
+	}
+}
+
+func writeLoopFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("write %s: %v", path, err)
+	}
+}
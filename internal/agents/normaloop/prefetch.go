@@ -0,0 +1,98 @@
+package normaloop
+
+import (
+	"context"
+
+	runpkg "github.com/metalagman/norma/internal/run"
+	"github.com/metalagman/norma/internal/task"
+)
+
+// taskPrefetch is a task selection started while the iteration of afterID was running.
+type taskPrefetch struct {
+	afterID string
+	done    chan struct{}
+
+	// task, reason and err are set before done is closed.
+	task   task.Task
+	reason string
+	err    error
+}
+
+// startPrefetch selects the next task in the background while the iteration of
+// runningID runs, when loop.prefetch is enabled. The selection skips runningID and
+// does not take the run lock.
+func (w *loopRuntime) startPrefetch(ctx context.Context, runningID string) {
+	if !w.cfg.Loop.Prefetch {
+		return
+	}
+	p := &taskPrefetch{afterID: runningID, done: make(chan struct{})}
+	w.prefetch = p
+	go func() {
+		defer close(p.done)
+		if w.overrideSelect != nil {
+			p.task, p.reason, p.err = w.overrideSelect(ctx)
+			return
+		}
+		p.task, p.reason, p.err = w.selectNextTaskExcept(ctx, runningID)
+	}()
+}
+
+// takePrefetched waits for the pending prefetch and returns its task if it may still run.
+// The re-read task goes through the same eligibleTasks rules as a fresh selection.
+// It reports false when there is no prefetch, the prefetch failed, or the task was
+// taken, finished, relabeled, quarantined or capped since it was selected; the
+// selector then selects afresh.
+func (w *loopRuntime) takePrefetched(ctx context.Context) (task.Task, string, bool) {
+	p := w.prefetch
+	if p == nil {
+		return task.Task{}, "", false
+	}
+	w.prefetch = nil
+
+	select {
+	case <-p.done:
+	case <-ctx.Done():
+		return task.Task{}, "", false
+	}
+	if p.err != nil {
+		w.logger.Debug().Err(p.err).Msg("discarding failed task prefetch")
+		return task.Task{}, "", false
+	}
+	if p.task.ID == p.afterID {
+		return task.Task{}, "", false
+	}
+
+	item, err := w.tracker.Task(ctx, p.task.ID)
+	if err != nil {
+		w.logger.Warn().Err(err).Str("task_id", p.task.ID).Msg("failed to re-read prefetched task")
+		return task.Task{}, "", false
+	}
+	if !prefetchedStatusRunnable(item.Status) {
+		w.logger.Info().
+			Str("task_id", item.ID).
+			Str("status", item.Status).
+			Msg("prefetched task is no longer runnable")
+		return task.Task{}, "", false
+	}
+	eligible, err := w.eligibleTasks(ctx, []task.Task{item})
+	if err != nil {
+		w.logger.Warn().Err(err).Str("task_id", item.ID).Msg("failed to recheck prefetched task")
+		return task.Task{}, "", false
+	}
+	if len(eligible) == 0 {
+		w.logger.Info().Str("task_id", item.ID).Msg("prefetched task is no longer eligible")
+		return task.Task{}, "", false
+	}
+	return eligible[0], p.reason, true
+}
+
+// prefetchedStatusRunnable reports whether a prefetched task still has a status the
+// tracker lists as ready, so it does not conflict with a run that picked it up meanwhile.
+func prefetchedStatusRunnable(status string) bool {
+	switch status {
+	case statusTodo, runpkg.StatusFailed, runpkg.StatusStopped:
+		return true
+	default:
+		return false
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"iter"
+	"slices"
 	"strings"
 	"time"
 
@@ -25,6 +26,12 @@ var defaultBackoffSteps = []time.Duration{
 	60 * time.Second,
 }
 
+// sleepFunc waits for d and reports whether the wait completed before ctx was done.
+type sleepFunc func(ctx context.Context, d time.Duration) bool
+
+// selectFunc picks the next task to run, returning errNoTasks when none is runnable.
+type selectFunc func(ctx context.Context) (task.Task, string, error)
+
 func (w *loopRuntime) backoffSteps() []time.Duration {
 	if len(w.overrideBackoffSteps) > 0 {
 		return w.overrideBackoffSteps
@@ -32,6 +39,33 @@ func (w *loopRuntime) backoffSteps() []time.Duration {
 	return defaultBackoffSteps
 }
 
+func (w *loopRuntime) sleep(ctx context.Context, d time.Duration) bool {
+	if w.overrideSleep != nil {
+		return w.overrideSleep(ctx, d)
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
+
+func (w *loopRuntime) selectTask(ctx context.Context, state session.State) (task.Task, string, error) {
+	if resumed, ok := w.restoreLoopState(ctx, state); ok {
+		return resumed, "resumed", nil
+	}
+	if prefetched, reason, ok := w.takePrefetched(ctx); ok {
+		return prefetched, reason, nil
+	}
+	if w.overrideSelect != nil {
+		return w.overrideSelect(ctx)
+	}
+	return w.selectNextTask(ctx)
+}
+
 func (w *loopRuntime) newSelectorAgent() (agent.Agent, error) {
 	return agent.New(agent.Config{
 		Name:        "Selector",
@@ -52,14 +86,21 @@ func (w *loopRuntime) runSelector(ctx agent.InvocationContext) iter.Seq2[*sessio
 		}
 
 		for {
-			selected, reason, err := w.selectNextTask(ctx)
+			w.updateLoopStatus(func(s *LoopStatus) { s.State = LoopStateSelecting })
+			selected, reason, err := w.selectTask(ctx, ctx.Session().State())
+			selectedAt := time.Now().UTC()
 			if err == nil {
+				w.updateLoopStatus(func(s *LoopStatus) {
+					s.LastSelectionAt = selectedAt
+					s.CurrentTaskID = selected.ID
+				})
 				l.Info().
 					Str("task_id", selected.ID).
 					Str("selection_reason", reason).
 					Msg("selector picked task")
 
 				_ = ctx.Session().State().Set("selector_backoff_step", 0)
+				_ = ctx.Session().State().Set("selector_tracker_failures", 0)
 
 				if err := ctx.Session().State().Set("selected_task_id", selected.ID); err != nil {
 					yield(nil, fmt.Errorf("set selected_task_id in session: %w", err))
@@ -69,17 +110,44 @@ func (w *loopRuntime) runSelector(ctx agent.InvocationContext) iter.Seq2[*sessio
 					yield(nil, fmt.Errorf("set selection_reason in session: %w", err))
 					return
 				}
+				w.saveLoopState(ctx, sessionIteration(ctx.Session().State()), selected.ID)
 				return
 			}
 
-			if !errors.Is(err, errNoTasks) {
-				yield(nil, err)
-				return
+			state := ctx.Session().State()
+			var message string
+			if errors.Is(err, errNoTasks) {
+				w.updateLoopStatus(func(s *LoopStatus) {
+					s.State = LoopStateIdle
+					s.LastSelectionAt = selectedAt
+					s.CurrentTaskID = ""
+				})
+				_ = state.Set("selector_tracker_failures", 0)
+				message = "No runnable tasks found."
+			} else {
+				w.updateLoopStatus(func(s *LoopStatus) {
+					s.LastSelectionAt = selectedAt
+					s.LastError = err.Error()
+				})
+				failuresVal, _ := state.Get("selector_tracker_failures")
+				failures, _ := failuresVal.(int)
+				if !errors.Is(err, task.ErrTrackerUnavailable) || failures >= w.cfg.Loop.TrackerRetries {
+					yield(nil, err)
+					return
+				}
+				failures++
+				_ = state.Set("selector_tracker_failures", failures)
+				l.Warn().
+					Err(err).
+					Int("tracker_failures", failures).
+					Int("tracker_retries", w.cfg.Loop.TrackerRetries).
+					Msg("tracker unavailable, retrying with backoff")
+				message = "Tracker unavailable."
 			}
 
-			// No runnable tasks, start backoff
+			// Start or continue backoff
 			steps := w.backoffSteps()
-			stepVal, _ := ctx.Session().State().Get("selector_backoff_step")
+			stepVal, _ := state.Get("selector_backoff_step")
 			step, _ := stepVal.(int)
 			if step >= len(steps) {
 				step = len(steps) - 1
@@ -89,43 +157,54 @@ func (w *loopRuntime) runSelector(ctx agent.InvocationContext) iter.Seq2[*sessio
 			l.Info().
 				Dur("wait_duration", wait).
 				Int("backoff_step", step).
-				Msg("no runnable tasks found, waiting with backoff")
+				Msg("selector waiting with backoff")
 
 			ev := session.NewEvent(ctx.InvocationID())
 			ev.Partial = true
 			ev.Content = &genai.Content{
 				Parts: []*genai.Part{
-					{Text: fmt.Sprintf("No runnable tasks found. Waiting %v before retrying...", wait)},
+					{Text: fmt.Sprintf("%s Waiting %v before retrying...", message, wait)},
 				},
 			}
 			if !yield(ev, nil) {
 				return
 			}
 
-			timer := time.NewTimer(wait)
-			select {
-			case <-ctx.Done():
-				timer.Stop()
+			if !w.sleep(ctx, wait) {
+				// Escalate so the loop agent stops instead of spinning on a cancelled context.
+				stop := session.NewEvent(ctx.InvocationID())
+				stop.Actions.Escalate = true
+				yield(stop, nil)
 				return
-			case <-timer.C:
 			}
 
 			// Increment backoff step for next iteration
 			if step < len(steps)-1 {
 				step++
 			}
-			_ = ctx.Session().State().Set("selector_backoff_step", step)
+			_ = state.Set("selector_backoff_step", step)
 		}
 	}
 }
 
 func (w *loopRuntime) selectNextTask(ctx context.Context) (task.Task, string, error) {
+	return w.selectNextTaskExcept(ctx, "")
+}
+
+// selectNextTaskExcept selects like selectNextTask but never returns the task skipID.
+func (w *loopRuntime) selectNextTaskExcept(ctx context.Context, skipID string) (task.Task, string, error) {
 	items, err := w.tracker.LeafTasks(ctx)
 	if err != nil {
 		return task.Task{}, "", err
 	}
 
-	items = filterRunnableTasks(items)
+	if skipID != "" {
+		items = slices.DeleteFunc(items, func(item task.Task) bool { return item.ID == skipID })
+	}
+	items, err = w.eligibleTasks(ctx, items)
+	if err != nil {
+		return task.Task{}, "", err
+	}
 	if len(items) == 0 {
 		return task.Task{}, "", errNoTasks
 	}
@@ -138,6 +217,65 @@ func (w *loopRuntime) selectNextTask(ctx context.Context) (task.Task, string, er
 	return selected, reason, nil
 }
 
+// eligibleTasks keeps the tasks the loop may start: runnable, allowlisted, not
+// quarantined and below max_runs_per_task. Fresh selections and prefetched picks
+// both go through it, so a prefetch cannot bypass any of these rules.
+func (w *loopRuntime) eligibleTasks(ctx context.Context, items []task.Task) ([]task.Task, error) {
+	items = filterAllowlistedTasks(filterRunnableTasks(items), w.cfg.Loop.TaskAllowlist)
+	items, err := w.skipQuarantinedTasks(ctx, items)
+	if err != nil {
+		return nil, err
+	}
+	return w.skipCappedTasks(ctx, items)
+}
+
+// skipCappedTasks drops tasks that reached max_runs_per_task; runCapReached labels them.
+func (w *loopRuntime) skipCappedTasks(ctx context.Context, items []task.Task) ([]task.Task, error) {
+	if w.cfg.MaxRunsPerTask <= 0 || w.runStore == nil {
+		return items, nil
+	}
+	out := make([]task.Task, 0, len(items))
+	for _, item := range items {
+		capped, err := w.runCapReached(ctx, item.ID)
+		if err != nil {
+			return nil, fmt.Errorf("check run cap for task %s: %w", item.ID, err)
+		}
+		if !capped {
+			out = append(out, item)
+		}
+	}
+	return out, nil
+}
+
+// skipQuarantinedTasks drops tasks with at least loop.quarantine_after failed runs
+// and labels them so later selections skip them without querying the run store.
+func (w *loopRuntime) skipQuarantinedTasks(ctx context.Context, items []task.Task) ([]task.Task, error) {
+	limit := w.cfg.Loop.QuarantineAfter
+	if limit <= 0 || w.runStore == nil {
+		return items, nil
+	}
+	out := make([]task.Task, 0, len(items))
+	for _, item := range items {
+		failed, err := w.runStore.FailedRunCountForTask(ctx, item.ID)
+		if err != nil {
+			return nil, fmt.Errorf("count failed runs for task %s: %w", item.ID, err)
+		}
+		if failed < limit {
+			out = append(out, item)
+			continue
+		}
+		w.logger.Warn().
+			Str("task_id", item.ID).
+			Int("failed_runs", failed).
+			Int("quarantine_after", limit).
+			Msg("task quarantined after repeated failures")
+		if err := w.tracker.AddLabel(ctx, item.ID, labelQuarantined); err != nil {
+			return nil, fmt.Errorf("label task %s %s: %w", item.ID, labelQuarantined, err)
+		}
+	}
+	return out, nil
+}
+
 func filterRunnableTasks(items []task.Task) []task.Task {
 	out := make([]task.Task, 0, len(items))
 	for _, item := range items {
@@ -148,7 +286,25 @@ func filterRunnableTasks(items []task.Task) []task.Task {
 	return out
 }
 
+// filterAllowlistedTasks keeps the tasks listed in allowlist, preserving their ready order.
+// An empty allowlist keeps every task.
+func filterAllowlistedTasks(items []task.Task, allowlist []string) []task.Task {
+	if len(allowlist) == 0 {
+		return items
+	}
+	out := make([]task.Task, 0, len(items))
+	for _, item := range items {
+		if slices.Contains(allowlist, item.ID) {
+			out = append(out, item)
+		}
+	}
+	return out
+}
+
 func isRunnableTask(item task.Task) bool {
+	if slices.Contains(item.Labels, labelNeedsHuman) || slices.Contains(item.Labels, labelQuarantined) {
+		return false
+	}
 	typ := strings.ToLower(strings.TrimSpace(item.Type))
 	switch typ {
 	case "epic", "feature":
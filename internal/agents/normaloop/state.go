@@ -0,0 +1,78 @@
+package normaloop
+
+import (
+	"context"
+	"slices"
+
+	"github.com/metalagman/norma/internal/db"
+	"github.com/metalagman/norma/internal/task"
+
+	"google.golang.org/adk/session"
+)
+
+// sessionIteration returns the loop iteration stored in session state, defaulting to 1.
+func sessionIteration(state session.State) int {
+	if value, err := state.Get("iteration"); err == nil {
+		if parsed, ok := value.(int); ok && parsed > 0 {
+			return parsed
+		}
+	}
+	return 1
+}
+
+// saveLoopState persists the loop position so a restarted loop can resume it.
+func (w *loopRuntime) saveLoopState(ctx context.Context, iteration int, selectedTaskID string) {
+	if w.runStore == nil {
+		return
+	}
+	if err := w.runStore.SaveLoopState(ctx, db.LoopState{Iteration: iteration, SelectedTaskID: selectedTaskID}); err != nil {
+		w.logger.Warn().Err(err).Int("iteration", iteration).Msg("failed to persist loop state")
+	}
+}
+
+// restoreLoopState runs once per loop runtime. It carries the persisted iteration
+// into session state and returns the task that was selected when the previous loop
+// stopped, if the selector would still pick that task.
+func (w *loopRuntime) restoreLoopState(ctx context.Context, state session.State) (task.Task, bool) {
+	if w.restored || w.runStore == nil {
+		return task.Task{}, false
+	}
+	w.restored = true
+
+	saved, err := w.runStore.LoadLoopState(ctx)
+	if err != nil {
+		w.logger.Warn().Err(err).Msg("failed to load loop state")
+		return task.Task{}, false
+	}
+	if saved.Iteration > sessionIteration(state) {
+		if err := state.Set("iteration", saved.Iteration); err != nil {
+			w.logger.Warn().Err(err).Msg("failed to restore loop iteration")
+		} else {
+			w.logger.Info().Int("iteration", saved.Iteration).Msg("restored loop iteration")
+		}
+	}
+	if saved.SelectedTaskID == "" {
+		return task.Task{}, false
+	}
+
+	items, err := w.tracker.LeafTasks(ctx)
+	if err != nil {
+		w.logger.Warn().Err(err).Msg("failed to list tasks for loop resume")
+		return task.Task{}, false
+	}
+	idx := slices.IndexFunc(items, func(item task.Task) bool { return item.ID == saved.SelectedTaskID })
+	if idx < 0 {
+		w.logger.Info().Str("task_id", saved.SelectedTaskID).Msg("previously selected task is no longer runnable")
+		return task.Task{}, false
+	}
+	eligible, err := w.eligibleTasks(ctx, items[idx:idx+1])
+	if err != nil {
+		w.logger.Warn().Err(err).Msg("failed to check previously selected task")
+		return task.Task{}, false
+	}
+	if len(eligible) == 0 {
+		w.logger.Info().Str("task_id", saved.SelectedTaskID).Msg("previously selected task is no longer runnable")
+		return task.Task{}, false
+	}
+	return eligible[0], true
+}
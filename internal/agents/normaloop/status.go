@@ -0,0 +1,93 @@
+package normaloop
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/metalagman/norma/internal/agents/pdca"
+	"github.com/metalagman/norma/internal/fsutil"
+)
+
+// loopStatusRefreshInterval is how often updated_at is refreshed while an iteration runs.
+const loopStatusRefreshInterval = 30 * time.Second
+
+// LoopStatusFile is the name of the loop status file inside the .norma directory.
+const LoopStatusFile = "loop-status.json"
+
+// Loop states reported in LoopStatus.
+const (
+	LoopStateSelecting = "selecting"
+	LoopStateIdle      = "idle"
+	LoopStateRunning   = "running"
+)
+
+// LoopStatus is a liveness snapshot written by the loop so it can be monitored without a server.
+type LoopStatus struct {
+	PID             int       `json:"pid"`
+	State           string    `json:"state"`
+	UpdatedAt       time.Time `json:"updated_at"`
+	LastSelectionAt time.Time `json:"last_selection_at"`
+	CurrentTaskID   string    `json:"current_task_id,omitempty"`
+	Iteration       int       `json:"iteration"`
+	LastError       string    `json:"last_error,omitempty"`
+}
+
+// ReadLoopStatus reads the loop status file from normaDir.
+func ReadLoopStatus(normaDir string) (LoopStatus, error) {
+	data, err := os.ReadFile(filepath.Join(normaDir, LoopStatusFile))
+	if err != nil {
+		return LoopStatus{}, fmt.Errorf("read loop status: %w", err)
+	}
+	var status LoopStatus
+	if err := json.Unmarshal(data, &status); err != nil {
+		return LoopStatus{}, fmt.Errorf("parse loop status: %w", err)
+	}
+	return status, nil
+}
+
+// updateLoopStatus applies mutate to the in-memory status and rewrites the status file.
+// Write failures are logged and never interrupt the loop.
+func (w *loopRuntime) updateLoopStatus(mutate func(*LoopStatus)) {
+	if w.normaDir == "" {
+		return
+	}
+
+	w.statusMu.Lock()
+	defer w.statusMu.Unlock()
+
+	mutate(&w.status)
+	w.status.PID = os.Getpid()
+	w.status.UpdatedAt = time.Now().UTC()
+
+	if err := writeLoopStatus(w.normaDir, w.status); err != nil {
+		w.logger.Warn().Err(err).Msg("failed to write loop status")
+	}
+}
+
+// startStatusRefresh rewrites the status file every loopStatusRefreshInterval until
+// the returned stop func is called, so updated_at keeps advancing during a long iteration.
+func (w *loopRuntime) startStatusRefresh() func() {
+	if w.normaDir == "" {
+		return func() {}
+	}
+	interval := loopStatusRefreshInterval
+	if w.overrideStatusTick > 0 {
+		interval = w.overrideStatusTick
+	}
+	return pdca.StartHeartbeat(interval, func(time.Duration) {
+		w.updateLoopStatus(func(*LoopStatus) {})
+	})
+}
+
+func writeLoopStatus(normaDir string, status LoopStatus) error {
+	if err := os.MkdirAll(normaDir, 0o700); err != nil {
+		return fmt.Errorf("create .norma: %w", err)
+	}
+	if err := fsutil.WriteJSONAtomic(filepath.Join(normaDir, LoopStatusFile), status); err != nil {
+		return fmt.Errorf("write loop status: %w", err)
+	}
+	return nil
+}
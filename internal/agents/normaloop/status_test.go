@@ -0,0 +1,201 @@
+package normaloop
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	runpkg "github.com/metalagman/norma/internal/run"
+	"github.com/metalagman/norma/internal/task"
+	"github.com/rs/zerolog"
+	"google.golang.org/adk/session"
+)
+
+func newStatusTestSession(t *testing.T) session.Session {
+	t.Helper()
+	sess, err := session.InMemoryService().Create(context.Background(), &session.CreateRequest{
+		AppName: "test",
+		UserID:  "test-user",
+	})
+	if err != nil {
+		t.Fatalf("sessionService.Create() error = %v", err)
+	}
+	return sess.Session
+}
+
+func TestLoopStatusUpdatesAcrossSelections(t *testing.T) {
+	t.Parallel()
+
+	normaDir := t.TempDir()
+	tracker := &mockTracker{}
+	w := &loopRuntime{
+		logger:   zerolog.Nop(),
+		normaDir: normaDir,
+		tracker:  tracker,
+	}
+	ag, _ := w.newSelectorAgent()
+	sess := newStatusTestSession(t)
+	mctx := &mockInvocationContext{ctx: context.Background(), session: sess, agent: ag}
+
+	tracker.setLeafState(nil, []task.Task{{ID: "norma-1", Type: "task"}})
+	for range w.runSelector(mctx) {
+	}
+
+	first, err := ReadLoopStatus(normaDir)
+	if err != nil {
+		t.Fatalf("ReadLoopStatus() error = %v", err)
+	}
+	if first.CurrentTaskID != "norma-1" {
+		t.Fatalf("CurrentTaskID = %q, want norma-1", first.CurrentTaskID)
+	}
+	if first.LastSelectionAt.IsZero() {
+		t.Fatal("LastSelectionAt is zero after selection")
+	}
+	if first.PID != os.Getpid() {
+		t.Fatalf("PID = %d, want %d", first.PID, os.Getpid())
+	}
+
+	time.Sleep(5 * time.Millisecond)
+	tracker.setLeafState(nil, []task.Task{{ID: "norma-2", Type: "task"}})
+	for range w.runSelector(mctx) {
+	}
+
+	second, err := ReadLoopStatus(normaDir)
+	if err != nil {
+		t.Fatalf("ReadLoopStatus() error = %v", err)
+	}
+	if second.CurrentTaskID != "norma-2" {
+		t.Fatalf("CurrentTaskID = %q, want norma-2", second.CurrentTaskID)
+	}
+	if !second.LastSelectionAt.After(first.LastSelectionAt) {
+		t.Fatalf("LastSelectionAt = %v, want after %v", second.LastSelectionAt, first.LastSelectionAt)
+	}
+}
+
+func TestLoopStatusRecordsIterationError(t *testing.T) {
+	t.Parallel()
+
+	normaDir := t.TempDir()
+	taskID := "norma-9"
+	tracker := &mockTracker{
+		tasksByID: map[string]task.Task{
+			taskID: {ID: taskID, Status: statusTodo, Goal: "test goal"},
+		},
+	}
+	w := &loopRuntime{
+		logger:         zerolog.Nop(),
+		normaDir:       normaDir,
+		tracker:        tracker,
+		runStore:       &mockRunStore{statusByRunID: map[string]string{}},
+		factory:        &mockFactory{err: errors.New("runner failed")},
+		continueOnFail: true,
+	}
+	ag, _ := w.newIterationAgent()
+	sess := newStatusTestSession(t)
+	if err := sess.State().Set("selected_task_id", taskID); err != nil {
+		t.Fatalf("set selected_task_id: %v", err)
+	}
+	if err := sess.State().Set("iteration", 3); err != nil {
+		t.Fatalf("set iteration: %v", err)
+	}
+	mctx := &mockInvocationContext{ctx: context.Background(), session: sess, agent: ag}
+
+	for range w.runIteration(mctx) {
+	}
+
+	status, err := ReadLoopStatus(normaDir)
+	if err != nil {
+		t.Fatalf("ReadLoopStatus() error = %v", err)
+	}
+	if status.State != LoopStateIdle {
+		t.Fatalf("State = %q, want %q", status.State, LoopStateIdle)
+	}
+	if status.Iteration != 3 {
+		t.Fatalf("Iteration = %d, want 3", status.Iteration)
+	}
+	if status.CurrentTaskID != "" {
+		t.Fatalf("CurrentTaskID = %q, want empty after iteration", status.CurrentTaskID)
+	}
+	if status.LastError == "" {
+		t.Fatal("LastError is empty, want runner failure")
+	}
+}
+
+// blockingFactory fails Build once release is closed, after signalling started.
+type blockingFactory struct {
+	mockFactory
+
+	started chan struct{}
+	release chan struct{}
+}
+
+func (f *blockingFactory) Build(context.Context, runpkg.RunMeta, runpkg.TaskPayload) (runpkg.AgentBuild, error) {
+	close(f.started)
+	<-f.release
+	return runpkg.AgentBuild{}, errors.New("runner failed")
+}
+
+func TestLoopStatusRefreshedDuringIteration(t *testing.T) {
+	t.Parallel()
+
+	normaDir := t.TempDir()
+	taskID := "norma-7"
+	tracker := &mockTracker{
+		tasksByID: map[string]task.Task{
+			taskID: {ID: taskID, Status: statusTodo, Goal: "test goal"},
+		},
+	}
+	factory := &blockingFactory{started: make(chan struct{}), release: make(chan struct{})}
+	w := &loopRuntime{
+		logger:             zerolog.Nop(),
+		normaDir:           normaDir,
+		tracker:            tracker,
+		runStore:           &mockRunStore{statusByRunID: map[string]string{}},
+		factory:            factory,
+		continueOnFail:     true,
+		overrideStatusTick: 5 * time.Millisecond,
+	}
+	ag, _ := w.newIterationAgent()
+	sess := newStatusTestSession(t)
+	if err := sess.State().Set("selected_task_id", taskID); err != nil {
+		t.Fatalf("set selected_task_id: %v", err)
+	}
+	mctx := &mockInvocationContext{ctx: context.Background(), session: sess, agent: ag}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for range w.runIteration(mctx) {
+		}
+	}()
+	<-factory.started
+
+	first, err := ReadLoopStatus(normaDir)
+	if err != nil {
+		t.Fatalf("ReadLoopStatus() error = %v", err)
+	}
+	time.Sleep(50 * time.Millisecond)
+	second, err := ReadLoopStatus(normaDir)
+	if err != nil {
+		t.Fatalf("ReadLoopStatus() error = %v", err)
+	}
+	close(factory.release)
+	<-done
+
+	if second.State != LoopStateRunning || second.CurrentTaskID != taskID {
+		t.Fatalf("status = %+v, want running %s", second, taskID)
+	}
+	if !second.UpdatedAt.After(first.UpdatedAt) {
+		t.Fatalf("UpdatedAt = %v, want after %v while the iteration runs", second.UpdatedAt, first.UpdatedAt)
+	}
+}
+
+func TestReadLoopStatusMissingFile(t *testing.T) {
+	t.Parallel()
+
+	if _, err := ReadLoopStatus(t.TempDir()); err == nil {
+		t.Fatal("ReadLoopStatus() error = nil, want error for missing file")
+	}
+}
@@ -5,10 +5,10 @@ import (
 	"crypto/rand"
 	"database/sql"
 	"encoding/hex"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
-	"regexp"
 	"strings"
 	"time"
 
@@ -16,13 +16,12 @@ import (
 	"github.com/metalagman/norma/internal/git"
 	"github.com/metalagman/norma/internal/reconcile"
 	runpkg "github.com/metalagman/norma/internal/run"
+	"github.com/metalagman/norma/internal/task"
 )
 
-var taskIDPattern = regexp.MustCompile(`^norma-[a-z0-9]+(?:\.[a-z0-9]+)*$`)
-
 func (w *loopRuntime) runTaskByID(ctx context.Context, id string) error {
-	if !taskIDPattern.MatchString(id) {
-		return fmt.Errorf("invalid task id: %s", id)
+	if err := task.ValidateID(id); err != nil {
+		return err
 	}
 
 	item, err := w.tracker.Task(ctx, id)
@@ -30,6 +29,14 @@ func (w *loopRuntime) runTaskByID(ctx context.Context, id string) error {
 		return err
 	}
 
+	item.Criteria, err = runpkg.EnsureAcceptanceCriteria(item.Criteria, item.Goal, w.cfg.RequireAcceptanceCriteria)
+	if err != nil {
+		if lErr := w.tracker.AddLabel(ctx, id, runpkg.LabelNeedsAC); lErr != nil {
+			w.logger.Warn().Err(lErr).Str("task_id", id).Str("label", runpkg.LabelNeedsAC).Msg("failed to add label to task")
+		}
+		return runpkg.WithFailureKind(runpkg.FailureTaskNotMet, fmt.Errorf("task %s: %w", id, err))
+	}
+
 	switch item.Status {
 	case statusTodo, runpkg.StatusFailed, runpkg.StatusStopped:
 	case statusDoing:
@@ -49,21 +56,31 @@ func (w *loopRuntime) runTaskByID(ctx context.Context, id string) error {
 		return fmt.Errorf("task %s status is %s", id, item.Status)
 	}
 
+	if capped, err := w.runCapReached(ctx, id); err != nil || capped {
+		return err
+	}
+
 	startedAt := time.Now().UTC()
 	runID, err := newRunID()
 	if err != nil {
 		return err
 	}
+	correlationID, err := runpkg.NewCorrelationID()
+	if err != nil {
+		return err
+	}
+	ctx = runpkg.WithCorrelationID(ctx, correlationID)
+	logger := runpkg.ContextLogger(ctx, w.logger)
 
-	w.logger.Info().Str("task_id", id).Str("run_id", runID).Msg("starting task run")
+	logger.Info().Str("task_id", id).Str("run_id", runID).Msg("starting task run")
 
-	lock, err := runpkg.AcquireRunLock(w.normaDir)
+	lock, err := runpkg.AcquireTaskRunLock(ctx, w.normaDir, w.cfg.Git)
 	if err != nil {
 		return fmt.Errorf("acquire run lock: %w", err)
 	}
 	defer func() {
 		if lErr := lock.Release(); lErr != nil {
-			w.logger.Warn().Err(lErr).Msg("failed to release run lock")
+			logger.Warn().Err(lErr).Msg("failed to release run lock")
 		}
 	}()
 
@@ -72,21 +89,31 @@ func (w *loopRuntime) runTaskByID(ctx context.Context, id string) error {
 	}
 
 	baseBranch := ""
+	baseHead := ""
 	if w.workingDir != "" {
 		var err error
 		baseBranch, err = git.CurrentBranch(ctx, w.workingDir)
 		if err != nil {
 			return fmt.Errorf("resolve base branch: %w", err)
 		}
+		baseHead, err = git.GitRunCmdOutput(ctx, w.workingDir, "git", "rev-parse", "HEAD")
+		if err != nil {
+			return fmt.Errorf("resolve base HEAD: %w", err)
+		}
+		baseHead = strings.TrimSpace(baseHead)
 		// Prune stalled worktrees
 		_ = git.GitRunCmdErr(ctx, w.workingDir, "git", "worktree", "prune")
 	}
 
-	if w.runStore != nil && w.runStore.DB() != nil {
+	// Reconciling while other runs are active would mistake their in-flight steps for lost ones.
+	if lock.Exclusive() && w.runStore != nil && w.runStore.DB() != nil {
 		if err := reconcile.Run(ctx, w.runStore.DB(), w.normaDir); err != nil {
 			return err
 		}
 	}
+	if err := lock.Downgrade(); err != nil {
+		return err
+	}
 
 	runDir := filepath.Join(w.normaDir, "runs", runID)
 	if err := os.MkdirAll(runDir, 0o700); err != nil {
@@ -94,13 +121,19 @@ func (w *loopRuntime) runTaskByID(ctx context.Context, id string) error {
 	}
 
 	if w.runStore != nil {
-		if err := w.runStore.CreateRun(ctx, runID, item.Goal, runDir, 1); err != nil {
+		if err := w.runStore.CreateRun(ctx, runID, id, item.Goal, runDir, 1); err != nil {
 			return fmt.Errorf("create run in store: %w", err)
 		}
+		if err := w.runStore.SetRunCorrelationID(ctx, runID, correlationID); err != nil {
+			return err
+		}
+		if err := runpkg.SnapshotAcceptanceCriteria(ctx, w.runStore, runID, item.Criteria); err != nil {
+			return err
+		}
 	}
 
 	if err := w.tracker.SetRun(ctx, id, runID); err != nil {
-		w.logger.Warn().Err(err).Msg("failed to set run id in tracker")
+		logger.Warn().Err(err).Msg("failed to set run id in tracker")
 	}
 
 	if err := w.tracker.MarkStatus(ctx, id, statusPlanning); err != nil {
@@ -122,7 +155,7 @@ func (w *loopRuntime) runTaskByID(ctx context.Context, id string) error {
 	build, err := w.factory.Build(ctx, meta, payload)
 	if err != nil {
 		_ = w.tracker.MarkStatus(ctx, id, runpkg.StatusFailed)
-		return fmt.Errorf("build run agent: %w", err)
+		return w.failRun(ctx, runID, runpkg.FailureInfrastructure, fmt.Errorf("build run agent: %w", err))
 	}
 
 	finalSession, _, err := adkrunner.Run(ctx, adkrunner.RunInput{
@@ -136,154 +169,198 @@ func (w *loopRuntime) runTaskByID(ctx context.Context, id string) error {
 	})
 	if err != nil {
 		_ = w.tracker.MarkStatus(ctx, id, runpkg.StatusFailed)
-		return fmt.Errorf("execute ADK agent: %w", err)
+		return w.failRun(ctx, runID, runpkg.FailureAgentError, fmt.Errorf("execute ADK agent: %w", err))
 	}
 
 	outcome, err := w.factory.Finalize(ctx, meta, payload, finalSession)
 	if err != nil {
 		_ = w.tracker.MarkStatus(ctx, id, runpkg.StatusFailed)
-		return fmt.Errorf("finalize run: %w", err)
+		return w.failRun(ctx, runID, runpkg.FailureInfrastructure, fmt.Errorf("finalize run: %w", err))
+	}
+
+	if w.runStore != nil {
+		if err := runpkg.CheckACChange(ctx, w.runStore, w.tracker, w.cfg.ACChangePolicy, runID, id); errors.Is(err, runpkg.ErrACChanged) {
+			logger.Warn().Str("task_id", id).Str("run_id", runID).Msg("acceptance criteria changed during run, stopping with replan_required")
+			if sErr := w.runStore.SetRunStatus(ctx, runID, runpkg.StatusStopped, err.Error()); sErr != nil {
+				logger.Warn().Err(sErr).Str("run_id", runID).Msg("failed to record stopped run")
+			}
+			_ = w.tracker.MarkStatus(ctx, id, runpkg.StatusStopped)
+			return fmt.Errorf("task %s stopped (run %s): %w", id, runID, err)
+		} else if err != nil {
+			logger.Warn().Err(err).Str("run_id", runID).Msg("failed to check acceptance criteria for changes")
+		}
+	}
+
+	if outcome.Verdict != nil && *outcome.Verdict == "PASS" && w.cfg.RequireApprovalToApply {
+		timeout := time.Duration(w.cfg.ApprovalTimeout) * time.Second
+		if err := runpkg.AwaitApproval(ctx, w.runStore, w.normaDir, runID, outcome.Status, timeout); err != nil {
+			if errors.Is(err, runpkg.ErrApprovalTimeout) {
+				logger.Warn().Str("task_id", id).Str("run_id", runID).Msg("run was not approved in time, changes not applied")
+				return runpkg.StopUnapproved(ctx, w.tracker, id, runID)
+			}
+			_ = w.tracker.MarkStatus(ctx, id, runpkg.StatusFailed)
+			return w.failRun(ctx, runID, runpkg.FailureInfrastructure, fmt.Errorf("await approval: %w", err))
+		}
 	}
 
 	if outcome.Verdict != nil && *outcome.Verdict == "PASS" {
-		w.logger.Info().Str("task_id", id).Str("run_id", runID).Msg("verdict is PASS, applying changes")
-		err = w.applyChanges(ctx, runID, item.Goal, id)
+		if w.workingDir != "" {
+			if err := runpkg.PushTaskBranch(ctx, w.runStore, w.workingDir, w.cfg.Git, runID, runpkg.TaskBranch(w.cfg.Git, id, runID)); err != nil {
+				_ = w.tracker.MarkStatus(ctx, id, runpkg.StatusFailed)
+				return w.failRun(ctx, runID, runpkg.FailureInfrastructure, fmt.Errorf("push task branch: %w", err))
+			}
+		}
+		logger.Info().Str("task_id", id).Str("run_id", runID).Msg("verdict is PASS, applying changes")
+		err = w.applyChanges(ctx, runID, item.Goal, id, baseHead, outcome.PassedCriteria)
 		if err != nil {
-			w.logger.Error().Err(err).Msg("failed to apply changes")
+			logger.Error().Err(err).Msg("failed to apply changes")
 			_ = w.tracker.MarkStatus(ctx, id, runpkg.StatusFailed)
-			return fmt.Errorf("apply changes: %w", err)
+			return w.failRun(ctx, runID, runpkg.FailureInfrastructure, fmt.Errorf("apply changes: %w", err))
 		}
 		if err := w.tracker.MarkStatus(ctx, id, "done"); err != nil {
-			w.logger.Warn().Err(err).Msg("failed to mark task as done in tracker")
-		} else {
-			// Try to finalize parent feature/epic if all children are done.
-			if err := w.finalizeAncestors(ctx, item.ParentID); err != nil {
-				w.logger.Warn().Err(err).Str("parent_id", item.ParentID).Msg("failed to finalize ancestors")
+			logger.Warn().Err(err).Msg("failed to mark task as done in tracker")
+		} else if w.cfg.AutoCloseParents {
+			if err := runpkg.CloseCompletedParents(ctx, w.tracker, id); err != nil {
+				logger.Warn().Err(err).Str("parent_id", item.ParentID).Msg("failed to close completed parents")
 			}
 		}
-		w.logger.Info().Str("task_id", id).Str("run_id", runID).Str("duration", time.Since(startedAt).String()).Msg("task passed")
+		logger.Info().Str("task_id", id).Str("run_id", runID).Str("duration", time.Since(startedAt).String()).Msg("task passed")
 		return nil
 	}
 
-	w.logger.Warn().Str("task_id", id).Str("run_id", runID).Str("status", outcome.Status).Msg("task did not pass")
+	if runpkg.ShouldApplyPartial(w.cfg.ApplyOnPartial, outcome) {
+		logger.Info().Str("task_id", id).Str("run_id", runID).Int("passed_required", outcome.PassedRequired).Msg("verdict is PARTIAL, applying changes")
+		if err := w.applyChanges(ctx, runID, item.Goal, id, baseHead, outcome.PassedCriteria); err != nil {
+			logger.Error().Err(err).Msg("failed to apply partial changes")
+			_ = w.tracker.MarkStatus(ctx, id, runpkg.StatusFailed)
+			return w.failRun(ctx, runID, runpkg.FailureInfrastructure, fmt.Errorf("apply partial changes: %w", err))
+		}
+		runpkg.StopPartial(ctx, w.runStore, w.tracker, id, runID)
+		logger.Info().Str("task_id", id).Str("run_id", runID).Str("duration", time.Since(startedAt).String()).Msg("task partially applied")
+		return nil
+	}
+
+	logger.Warn().Str("task_id", id).Str("run_id", runID).Str("status", outcome.Status).Msg("task did not pass")
 	if outcome.Status == runpkg.StatusFailed {
 		_ = w.tracker.MarkStatus(ctx, id, runpkg.StatusFailed)
-		return fmt.Errorf("task %s failed (run %s)", id, runID)
+		return w.failRun(ctx, runID, runpkg.FailureTaskNotMet, fmt.Errorf("task %s failed (run %s)", id, runID))
 	}
 	_ = w.tracker.MarkStatus(ctx, id, runpkg.StatusStopped)
 	return fmt.Errorf("task %s stopped (run %s)", id, runID)
 }
 
-func (w *loopRuntime) finalizeAncestors(ctx context.Context, parentID string) error {
-	if strings.TrimSpace(parentID) == "" {
-		return nil
-	}
-
-	parent, err := w.tracker.Task(ctx, parentID)
-	if err != nil {
-		return fmt.Errorf("fetch parent %s: %w", parentID, err)
+// failRun classifies err and records the failure kind on the run.
+// Errors that already carry a kind keep it.
+func (w *loopRuntime) failRun(ctx context.Context, runID string, kind runpkg.FailureKind, err error) error {
+	logger := runpkg.ContextLogger(ctx, w.logger)
+	err = runpkg.WithFailureKind(kind, err)
+	if w.runStore != nil {
+		failureKind := runpkg.FailureKindOf(err, kind)
+		if mErr := w.runStore.MarkRunFailed(context.WithoutCancel(ctx), runID, string(failureKind), err.Error()); mErr != nil {
+			logger.Warn().Err(mErr).Str("run_id", runID).Msg("failed to record run failure kind")
+		}
 	}
+	return err
+}
 
-	// Completion Rules (AGENTS.md):
-	// - Feature complete: All descendant leaf issues are closed.
-	// - Epic complete: All features under it are complete.
-	// We only auto-close features and epics.
-	if parent.Type != "feature" && parent.Type != "epic" {
+// applyChanges merges the task branch into the checked out base branch.
+// baseHead is the base HEAD recorded at run start; empty skips the moved-base check.
+// passed lists the acceptance criteria met, recorded in the changelog fragment.
+func (w *loopRuntime) applyChanges(ctx context.Context, runID, goal, taskID, baseHead string, passed []task.AcceptanceCriterion) error {
+	logger := runpkg.ContextLogger(ctx, w.logger)
+	if w.workingDir == "" {
 		return nil
 	}
-
-	children, err := w.tracker.Children(ctx, parentID)
+	applyLock, err := runpkg.AcquireApplyLock(ctx, w.normaDir)
 	if err != nil {
-		return fmt.Errorf("list children for parent %s: %w", parentID, err)
+		return fmt.Errorf("acquire apply lock: %w", err)
 	}
-
-	allDone := true
-	for _, child := range children {
-		if child.Status != "done" {
-			allDone = false
-			break
+	defer func() {
+		if lErr := applyLock.Release(); lErr != nil {
+			logger.Warn().Err(lErr).Msg("failed to release apply lock")
 		}
+	}()
+	branchName := runpkg.TaskBranch(w.cfg.Git, taskID, runID)
+	stepIndex, err := w.currentStepIndex(ctx, runID)
+	if err != nil {
+		return err
 	}
+	commitMsg := runpkg.BuildApplyCommitMessage(goal, runID, stepIndex, taskID, runpkg.ApplyCommitTrailers(w.cfg.Git, runID, taskID, stepIndex)...)
 
-	if allDone && len(children) > 0 {
-		w.logger.Info().Str("id", parentID).Str("type", parent.Type).Msg("all children completed, closing parent")
-		if err := w.tracker.MarkStatus(ctx, parentID, "done"); err != nil {
-			return fmt.Errorf("mark parent %s as done: %w", parentID, err)
+	if err := git.CheckApplyBranch(ctx, w.workingDir, w.cfg.Git.AllowedApplyBranches); err != nil {
+		return err
+	}
+	if baseHead != "" {
+		baseBranch, err := git.CurrentBranch(ctx, w.workingDir)
+		if err != nil {
+			return err
+		}
+		if err := runpkg.HandleBaseMoved(ctx, w.workingDir, baseBranch, baseHead, branchName, w.cfg.Git.OnBaseMoved); err != nil {
+			return err
 		}
-		// Recurse to parent of parent
-		return w.finalizeAncestors(ctx, parent.ParentID)
 	}
 
-	return nil
-}
-
-func (w *loopRuntime) applyChanges(ctx context.Context, runID, goal, taskID string) error {
-	if w.workingDir == "" {
-		return nil
-	}
-	branchName := fmt.Sprintf("norma/task/%s", taskID)
-	stepIndex, err := w.currentStepIndex(ctx, runID)
-	if err != nil {
+	if err := runpkg.CheckPatchBudget(ctx, w.workingDir, branchName, w.cfg.Budgets); err != nil {
 		return err
 	}
-	commitMsg := runpkg.BuildApplyCommitMessage(goal, runID, stepIndex, taskID)
 
-	w.logger.Info().Str("branch", branchName).Msg("applying changes from workspace")
+	logger.Info().Str("branch", branchName).Msg("applying changes from workspace")
 
-	dirty := strings.TrimSpace(git.GitRunCmd(ctx, w.workingDir, "git", "status", "--porcelain"))
-	stashed := false
-	if dirty != "" {
-		w.logger.Info().Msg("stashing local changes before merge")
-		if err := git.GitRunCmdErr(ctx, w.workingDir, "git", "stash", "push", "-u", "-m", fmt.Sprintf("norma pre-apply %s", runID)); err != nil {
-			return fmt.Errorf("git stash push: %w", err)
-		}
-		stashed = true
+	stash, err := git.StashLocalChanges(ctx, w.workingDir, w.cfg.Git.StashPolicy, fmt.Sprintf("norma pre-apply %s", runID))
+	if err != nil {
+		return err
+	}
+	if stash != "" {
+		logger.Info().Msg("stashed local changes before merge")
 	}
 
 	restoreStash := func() error {
-		if !stashed {
+		if stash == "" {
 			return nil
 		}
-		if err := git.GitRunCmdErr(ctx, w.workingDir, "git", "stash", "pop"); err != nil {
-			return fmt.Errorf("git stash pop: %w", err)
+		if err := git.StashPop(ctx, w.workingDir, stash); err != nil {
+			logger.Error().Err(err).Msg("failed to restore stashed local changes")
+			return err
 		}
-		stashed = false
+		stash = ""
 		return nil
 	}
 
 	beforeHash := strings.TrimSpace(git.GitRunCmd(ctx, w.workingDir, "git", "rev-parse", "HEAD"))
 
-	if err := git.GitRunCmdErr(ctx, w.workingDir, "git", "merge", "--squash", branchName); err != nil {
-		_ = git.GitRunCmdErr(ctx, w.workingDir, "git", "reset", "--hard", beforeHash)
-		_ = restoreStash()
-		return fmt.Errorf("git merge --squash: %w", err)
-	}
-
-	if err := git.GitRunCmdErr(ctx, w.workingDir, "git", "add", "-A"); err != nil {
-		_ = git.GitRunCmdErr(ctx, w.workingDir, "git", "reset", "--hard", beforeHash)
-		_ = restoreStash()
-		return fmt.Errorf("git add -A: %w", err)
-	}
-
-	status := git.GitRunCmd(ctx, w.workingDir, "git", "status", "--porcelain")
-	if strings.TrimSpace(status) == "" {
-		_ = restoreStash()
-		w.logger.Info().Msg("nothing to commit after merge")
-		return nil
+	committed, err := git.MergeBranch(ctx, w.workingDir, branchName, w.cfg.Git.MergeStrategy, commitMsg)
+	if err != nil {
+		if restoreErr := restoreStash(); restoreErr != nil {
+			return fmt.Errorf("%w (failed to restore stashed changes: %w)", err, restoreErr)
+		}
+		return err
 	}
-
-	if err := git.GitRunCmdErr(ctx, w.workingDir, "git", "commit", "-m", commitMsg); err != nil {
-		_ = git.GitRunCmdErr(ctx, w.workingDir, "git", "reset", "--hard", beforeHash)
-		_ = restoreStash()
-		return fmt.Errorf("git commit: %w", err)
+	if committed {
+		entry := runpkg.ChangelogEntry{Goal: goal, TaskID: taskID, RunID: runID, Criteria: passed}
+		if err := runpkg.CommitChangelog(ctx, w.workingDir, w.cfg.Git.MergeStrategy, w.cfg.Changelog, entry); err != nil {
+			logger.Error().Err(err).Str("path", w.cfg.Changelog.Path).Msg("failed to write changelog fragment, rolling back apply")
+			err = fmt.Errorf("write changelog fragment: %w", err)
+			if resetErr := git.GitRunCmdErr(ctx, w.workingDir, "git", "reset", "--hard", beforeHash); resetErr != nil {
+				err = fmt.Errorf("%w (rollback failed: %w)", err, resetErr)
+			}
+			if restoreErr := restoreStash(); restoreErr != nil {
+				return fmt.Errorf("%w (failed to restore stashed changes: %w)", err, restoreErr)
+			}
+			return err
+		}
 	}
 
 	if err := restoreStash(); err != nil {
-		return err
+		return fmt.Errorf("merged %s but did not restore local changes: %w", branchName, err)
+	}
+	runpkg.CleanupRunBranch(ctx, w.workingDir, w.cfg.Git, branchName)
+	if !committed {
+		logger.Info().Msg("nothing to commit after merge")
+		return nil
 	}
 
 	afterHash := strings.TrimSpace(git.GitRunCmd(ctx, w.workingDir, "git", "rev-parse", "HEAD"))
-	w.logger.Info().
+	logger.Info().
 		Str("before_hash", beforeHash).
 		Str("after_hash", afterHash).
 		Msg("changes applied and committed successfully")
@@ -306,6 +383,30 @@ func (w *loopRuntime) currentStepIndex(ctx context.Context, runID string) (int,
 	return stepIndex, nil
 }
 
+// runCapReached reports whether the task already has max_runs_per_task runs.
+// A capped task is labelled for human attention so the selector skips it.
+func (w *loopRuntime) runCapReached(ctx context.Context, id string) (bool, error) {
+	if w.cfg.MaxRunsPerTask <= 0 || w.runStore == nil {
+		return false, nil
+	}
+	count, err := w.runStore.RunCountForTask(ctx, id)
+	if err != nil {
+		return false, err
+	}
+	if count < w.cfg.MaxRunsPerTask {
+		return false, nil
+	}
+	w.logger.Warn().
+		Str("task_id", id).
+		Int("runs", count).
+		Int("max_runs_per_task", w.cfg.MaxRunsPerTask).
+		Msg("task reached run cap, skipping until a human intervenes")
+	if err := w.tracker.AddLabel(ctx, id, labelNeedsHuman); err != nil {
+		return true, fmt.Errorf("label task %s %s: %w", id, labelNeedsHuman, err)
+	}
+	return true, nil
+}
+
 func newRunID() (string, error) {
 	suffix, err := randomHex(3)
 	if err != nil {
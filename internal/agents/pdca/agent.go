@@ -3,11 +3,16 @@ package pdca
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"io/fs"
 	"iter"
 	"os"
+	"os/exec"
 	"path/filepath"
+	"regexp"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
@@ -15,15 +20,19 @@ import (
 	"github.com/go-viper/mapstructure/v2"
 
 	"github.com/metalagman/norma/internal/agents/pdca/contracts"
+	"github.com/metalagman/norma/internal/agents/pdca/roles"
 	"github.com/metalagman/norma/internal/agents/pdca/roles/act"
 	"github.com/metalagman/norma/internal/agents/pdca/roles/check"
 	"github.com/metalagman/norma/internal/agents/pdca/roles/do"
 	"github.com/metalagman/norma/internal/agents/pdca/roles/plan"
 	"github.com/metalagman/norma/internal/config"
 	"github.com/metalagman/norma/internal/db"
+	"github.com/metalagman/norma/internal/fsutil"
 	"github.com/metalagman/norma/internal/git"
 	"github.com/metalagman/norma/internal/logging"
+	runpkg "github.com/metalagman/norma/internal/run"
 	"github.com/metalagman/norma/internal/task"
+	"github.com/metalagman/norma/internal/verify"
 	"github.com/rs/zerolog/log"
 
 	"google.golang.org/adk/agent"
@@ -38,33 +47,76 @@ type runtime struct {
 	tracker    task.Tracker
 	runInput   AgentInput
 	baseBranch string
+	steps      []string
+	observers  []string
+	consensus  []string
+	suspicious []*regexp.Regexp
+	roles      map[string]contracts.Role
+
+	overrideRunStep     func(ctx agent.InvocationContext, iteration int, roleName string) (*contracts.AgentResponse, error)
+	overrideRunObserver func(ctx agent.InvocationContext, iteration, index int, name string) (*contracts.AgentResponse, error)
+
+	overrideRunConsensusAgent func(ctx context.Context, req contracts.AgentRequest, dir, name string, iteration int) (*contracts.AgentResponse, error)
 }
 
-// NewLoopAgent creates and configures the PDCA loop agent with role subagents.
+// NewLoopAgent creates and configures the PDCA loop agent with role subagents
+// in the order of workflow.steps.
 func NewLoopAgent(ctx context.Context, cfg config.Config, store *db.Store, tracker task.Tracker, runInput AgentInput, baseBranch string, maxIterations int) (agent.Agent, error) {
+	steps, err := workflowSteps(cfg.Workflow)
+	if err != nil {
+		return nil, err
+	}
+	observers, err := observerAgents(cfg)
+	if err != nil {
+		return nil, err
+	}
+	consensus, err := consensusAgents(cfg)
+	if err != nil {
+		return nil, err
+	}
+	suspicious, err := compileSuspiciousPatterns(cfg.Safety.SuspiciousPatterns)
+	if err != nil {
+		return nil, err
+	}
+	if err := compileSecretPatterns(cfg.Safety.SecretPatterns); err != nil {
+		return nil, err
+	}
+	roleSet, err := roles.LoadRoles(filepath.Join(runInput.WorkingDir, roles.SchemaOverrideDir))
+	if err != nil {
+		return nil, err
+	}
 	rt := &runtime{
 		cfg:        cfg,
 		store:      store,
 		tracker:    tracker,
 		runInput:   runInput,
 		baseBranch: baseBranch,
+		steps:      steps,
+		observers:  observers,
+		consensus:  consensus,
+		suspicious: suspicious,
+		roles:      roleSet,
 	}
+	return rt.newLoopAgent(ctx, maxIterations)
+}
 
-	planAgent, err := rt.createSubAgent(ctx, RolePlan)
-	if err != nil {
-		return nil, fmt.Errorf("create %s subagent: %w", RolePlan, err)
-	}
-	doAgent, err := rt.createSubAgent(ctx, RoleDo)
-	if err != nil {
-		return nil, fmt.Errorf("create %s subagent: %w", RoleDo, err)
-	}
-	checkAgent, err := rt.createSubAgent(ctx, RoleCheck)
-	if err != nil {
-		return nil, fmt.Errorf("create %s subagent: %w", RoleCheck, err)
+// role returns the role loaded for this run, falling back to the registered role.
+func (a *runtime) role(name string) contracts.Role {
+	if role, ok := a.roles[name]; ok {
+		return role
 	}
-	actAgent, err := rt.createSubAgent(ctx, RoleAct)
-	if err != nil {
-		return nil, fmt.Errorf("create %s subagent: %w", RoleAct, err)
+	return GetRole(name)
+}
+
+func (a *runtime) newLoopAgent(ctx context.Context, maxIterations int) (agent.Agent, error) {
+	names := subAgentNames(a.steps)
+	subAgents := make([]agent.Agent, 0, len(a.steps))
+	for i, roleName := range a.steps {
+		subAgent, err := a.createSubAgent(ctx, names[i], roleName, i == len(a.steps)-1)
+		if err != nil {
+			return nil, fmt.Errorf("create %s subagent: %w", roleName, err)
+		}
+		subAgents = append(subAgents, subAgent)
 	}
 
 	ag, err := loopagent.New(loopagent.Config{
@@ -72,7 +124,7 @@ func NewLoopAgent(ctx context.Context, cfg config.Config, store *db.Store, track
 		AgentConfig: agent.Config{
 			Name:        "PDCALoop",
 			Description: "ADK loop agent for PDCA",
-			SubAgents:   []agent.Agent{planAgent, doAgent, checkAgent, actAgent},
+			SubAgents:   subAgents,
 		},
 	})
 	if err != nil {
@@ -81,27 +133,30 @@ func NewLoopAgent(ctx context.Context, cfg config.Config, store *db.Store, track
 	return ag, nil
 }
 
-func (a *runtime) createSubAgent(ctx context.Context, roleName string) (agent.Agent, error) {
-	pascalName := ""
+func pascalRoleName(roleName string) string {
 	switch roleName {
 	case RolePlan:
-		pascalName = "Plan"
+		return "Plan"
 	case RoleDo:
-		pascalName = "Do"
+		return "Do"
 	case RoleCheck:
-		pascalName = "Check"
+		return "Check"
 	case RoleAct:
-		pascalName = "Act"
+		return "Act"
 	default:
 		// Simple manual title case to avoid deprecated strings.Title
 		if len(roleName) > 0 {
-			pascalName = strings.ToUpper(roleName[:1]) + roleName[1:]
+			return strings.ToUpper(roleName[:1]) + roleName[1:]
 		}
+		return ""
 	}
+}
+
+func (a *runtime) createSubAgent(ctx context.Context, name, roleName string, last bool) (agent.Agent, error) {
 	ag, err := agent.New(agent.Config{
-		Name:        pascalName,
-		Description: fmt.Sprintf("Norma %s agent", pascalName),
-		Run:         a.runRoleLoop(ctx, roleName),
+		Name:        name,
+		Description: fmt.Sprintf("Norma %s agent", pascalRoleName(roleName)),
+		Run:         a.runRoleLoop(ctx, roleName, last),
 	})
 	if err != nil {
 		return nil, err
@@ -109,9 +164,9 @@ func (a *runtime) createSubAgent(ctx context.Context, roleName string) (agent.Ag
 	return ag, nil
 }
 
-func (a *runtime) runRoleLoop(ctx context.Context, roleName string) func(ctx agent.InvocationContext) iter.Seq2[*session.Event, error] {
+func (a *runtime) runRoleLoop(ctx context.Context, roleName string, last bool) func(ctx agent.InvocationContext) iter.Seq2[*session.Event, error] {
 	return func(ctx agent.InvocationContext) iter.Seq2[*session.Event, error] {
-		l := log.With().
+		l := runpkg.ContextLogger(ctx, log.Logger).With().
 			Str("component", "pdca").
 			Str("agent_name", ctx.Agent().Name()).
 			Str("invocation_id", ctx.InvocationID()).
@@ -129,7 +184,11 @@ func (a *runtime) runRoleLoop(ctx context.Context, roleName string) func(ctx age
 			}
 
 			l.Info().Int("iteration", itNum).Msg("starting step")
-			resp, err := a.runStep(ctx, itNum, roleName)
+			step := a.runStep
+			if a.overrideRunStep != nil {
+				step = a.overrideRunStep
+			}
+			resp, err := step(ctx, itNum, roleName)
 			if err != nil {
 				l.Error().Err(err).Msg("step failed")
 				yield(nil, err)
@@ -143,13 +202,20 @@ func (a *runtime) runRoleLoop(ctx context.Context, roleName string) func(ctx age
 
 			l.Debug().Str("status", resp.Status).Msg("step completed")
 
-			a.processRoleResult(ctx, yield, roleName, resp, itNum)
+			if last {
+				a.recordIterationDiff(ctx, itNum, roleName)
+				a.runObservers(ctx, itNum)
+			}
+
+			a.processRoleResult(ctx, yield, roleName, resp, itNum, last)
 		}
 	}
 }
 
-func (a *runtime) processRoleResult(ctx agent.InvocationContext, yield func(*session.Event, error) bool, roleName string, resp *contracts.AgentResponse, itNum int) {
-	l := log.With().
+// processRoleResult records a step result in session state. last marks the final
+// step of the workflow, which ends the iteration when the workflow has no act step.
+func (a *runtime) processRoleResult(ctx agent.InvocationContext, yield func(*session.Event, error) bool, roleName string, resp *contracts.AgentResponse, itNum int, last bool) {
+	l := runpkg.ContextLogger(ctx, log.Logger).With().
 		Str("component", "pdca").
 		Str("agent_name", ctx.Agent().Name()).
 		Str("invocation_id", ctx.InvocationID()).
@@ -157,6 +223,14 @@ func (a *runtime) processRoleResult(ctx agent.InvocationContext, yield func(*ses
 
 	// Communicate results via session state
 	if roleName == RoleCheck && resp.Check != nil {
+		a.explain(decisionRecord{
+			Iteration: itNum,
+			Point:     decisionVerdict,
+			Role:      roleName,
+			Outcome:   resp.Check.Verdict.Status,
+			Reason:    "check verdict recorded for act",
+			Inputs:    verdictInputs(resp),
+		})
 		l.Debug().Str("verdict", resp.Check.Verdict.Status).Msg("setting check verdict in state")
 		if err := ctx.Session().State().Set("verdict", resp.Check.Verdict.Status); err != nil {
 			yield(nil, fmt.Errorf("set verdict in session state: %w", err))
@@ -164,6 +238,29 @@ func (a *runtime) processRoleResult(ctx agent.InvocationContext, yield func(*ses
 		}
 	}
 	if roleName == RoleAct && resp.Act != nil {
+		requested := resp.Act.Decision
+		reason := "act decision applied"
+		if a.closesTooEarly(ctx, resp, itNum) {
+			l.Warn().
+				Int("iteration", itNum).
+				Int("min_iterations_before_close", a.cfg.MinIterationsBeforeClose).
+				Msg("act closed before min_iterations_before_close without a verified PASS, continuing")
+			resp.Act.Decision = "continue"
+			reason = "close before min_iterations_before_close without a verified PASS"
+		}
+		verdict, _ := stateString(ctx.Session().State(), "verdict")
+		a.explain(decisionRecord{
+			Iteration: itNum,
+			Point:     decisionAct,
+			Role:      roleName,
+			Outcome:   resp.Act.Decision,
+			Reason:    reason,
+			Inputs: map[string]any{
+				"requested_decision":          requested,
+				"verdict":                     verdict,
+				"min_iterations_before_close": a.cfg.MinIterationsBeforeClose,
+			},
+		})
 		l.Debug().Str("decision", resp.Act.Decision).Msg("setting act decision in state")
 		if err := ctx.Session().State().Set("decision", resp.Act.Decision); err != nil {
 			yield(nil, fmt.Errorf("set decision in session state: %w", err))
@@ -185,7 +282,27 @@ func (a *runtime) processRoleResult(ctx agent.InvocationContext, yield func(*ses
 			return
 		}
 	}
+	if checksPartialDo(a.cfg.CheckOnPartialDo, roleName, resp) {
+		l.Info().Str("stop_reason", resp.StopReason).Msg("do stopped with partial work, proceeding to check")
+		a.explain(decisionRecord{
+			Iteration: itNum,
+			Point:     decisionStepStatus,
+			Role:      roleName,
+			Outcome:   "continue",
+			Reason:    "do stopped with partial work and check_on_partial_do is set",
+			Inputs:    map[string]any{"status": resp.Status, "stop_reason": resp.StopReason},
+		})
+		return
+	}
 	if resp.Status != "ok" {
+		a.explain(decisionRecord{
+			Iteration: itNum,
+			Point:     decisionStepStatus,
+			Role:      roleName,
+			Outcome:   "stop",
+			Reason:    "non-ok step status",
+			Inputs:    map[string]any{"status": resp.Status, "stop_reason": resp.StopReason},
+		})
 		l.Warn().Str("role", roleName).Str("status", resp.Status).Msg("non-ok status, stopping loop")
 		if err := ctx.Session().State().Set("stop", true); err != nil {
 			yield(nil, fmt.Errorf("set stop flag in session state: %w", err))
@@ -196,6 +313,68 @@ func (a *runtime) processRoleResult(ctx agent.InvocationContext, yield func(*ses
 		_ = yield(ev, nil)
 		return
 	}
+	if last && !slices.Contains(a.steps, RoleAct) {
+		a.endIteration(ctx, yield, itNum)
+	}
+}
+
+// closesTooEarly reports whether an Act close comes before min_iterations_before_close
+// without a verified PASS: a PASS verdict with every task acceptance criterion passed.
+func (a *runtime) closesTooEarly(ctx agent.InvocationContext, resp *contracts.AgentResponse, itNum int) bool {
+	if resp.Act.Decision != "close" || itNum >= a.cfg.MinIterationsBeforeClose {
+		return false
+	}
+	verdict, err := stateString(ctx.Session().State(), "verdict")
+	if err != nil || verdict != "PASS" {
+		return true
+	}
+	required := a.runInput.AcceptanceCriteria
+	return len(passedRequiredCriteria(a.getTaskState(ctx), required)) < len(required)
+}
+
+// endIteration stands in for Act in workflows without it: a PASS verdict stops
+// the loop, anything else starts the next iteration.
+func (a *runtime) endIteration(ctx agent.InvocationContext, yield func(*session.Event, error) bool, itNum int) {
+	verdict, err := stateString(ctx.Session().State(), "verdict")
+	if err != nil {
+		yield(nil, fmt.Errorf("read verdict from session state: %w", err))
+		return
+	}
+	passed := strings.EqualFold(verdict, "PASS")
+	rec := decisionRecord{
+		Iteration: itNum,
+		Point:     decisionIterationEnd,
+		Outcome:   "continue",
+		Reason:    "workflow has no act step and verdict is not PASS",
+		Inputs:    map[string]any{"verdict": verdict},
+	}
+	if passed {
+		rec.Outcome, rec.Reason = "close", "workflow has no act step and verdict is PASS"
+	}
+	a.explain(rec)
+	if passed {
+		log.Info().Str("component", "pdca").Msg("workflow has no act step and verdict is PASS, stopping loop")
+		if err := ctx.Session().State().Set("stop", true); err != nil {
+			yield(nil, fmt.Errorf("set stop flag in session state: %w", err))
+			return
+		}
+		ev := session.NewEvent(ctx.InvocationID())
+		ev.Actions.Escalate = true
+		_ = yield(ev, nil)
+		return
+	}
+	if err := ctx.Session().State().Set("iteration", itNum+1); err != nil {
+		yield(nil, fmt.Errorf("update iteration in session state: %w", err))
+	}
+}
+
+// checksPartialDo reports whether a Do step that stopped after executing some of its
+// steps proceeds to Check instead of stopping the loop. It requires check_on_partial_do.
+func checksPartialDo(enabled bool, roleName string, resp *contracts.AgentResponse) bool {
+	if !enabled || roleName != RoleDo || resp.Status != "stop" {
+		return false
+	}
+	return resp.Do != nil && resp.Do.Execution != nil && len(resp.Do.Execution.ExecutedStepIds) > 0
 }
 
 func (a *runtime) shouldStop(ctx agent.InvocationContext) bool {
@@ -239,10 +418,14 @@ func (a *runtime) runStep(ctx agent.InvocationContext, iteration int, roleName s
 		case RolePlan:
 			skipLabel = "norma-has-plan"
 		case RoleDo:
-			skipLabel = "norma-has-do"
+			skipLabel = labelHasDo
 		case RoleCheck:
 			skipLabel = "norma-has-check"
 		}
+		if a.cfg.Git.PerRunBranches && roleName != RolePlan {
+			// A fresh per-run branch lacks earlier Do work, so only the plan is reused.
+			skipLabel = ""
+		}
 		if skipLabel != "" {
 			item, err := a.tracker.Task(ctx, a.runInput.TaskID)
 			if err == nil {
@@ -253,6 +436,18 @@ func (a *runtime) runStep(ctx agent.InvocationContext, iteration int, roleName s
 						break
 					}
 				}
+				outcome, reason := "run", fmt.Sprintf("label %s absent", skipLabel)
+				if hasLabel {
+					outcome, reason = "skip", fmt.Sprintf("label %s present", skipLabel)
+				}
+				a.explain(decisionRecord{
+					Iteration: iteration,
+					Point:     decisionSkipStep,
+					Role:      roleName,
+					Outcome:   outcome,
+					Reason:    reason,
+					Inputs:    map[string]any{"skip_label": skipLabel, "task_labels": item.Labels},
+				})
 				if hasLabel {
 					log.Info().Str("task_id", a.runInput.TaskID).Str("role", roleName).Msg("skipping step due to label")
 					state := a.getTaskState(ctx)
@@ -312,7 +507,10 @@ func (a *runtime) runStep(ctx agent.InvocationContext, iteration int, roleName s
 					}
 
 					// Update journal
-					_ = a.updateTaskState(ctx, resp, roleName, iteration, index)
+					applySkippedStepToTaskState(state, resp, roleName, a.runInput.RunID, iteration, index, fmt.Sprintf("label %s present", skipLabel), time.Now())
+					if err := a.persistTaskState(ctx, state); err != nil {
+						log.Warn().Err(err).Str("task_id", a.runInput.TaskID).Str("role", roleName).Msg("failed to journal skipped step")
+					}
 
 					return resp, nil
 				}
@@ -330,10 +528,10 @@ func (a *runtime) runStep(ctx agent.InvocationContext, iteration int, roleName s
 	index++
 
 	if err := ctx.Session().State().Set("current_step_index", index); err != nil {
-		return nil, fmt.Errorf("set current_step_index in session state: %w", err)
+		return nil, infraErr(fmt.Errorf("set current_step_index in session state: %w", err))
 	}
 
-	role := GetRole(roleName)
+	role := a.role(roleName)
 	if role == nil {
 		return nil, fmt.Errorf("unknown role %q", roleName)
 	}
@@ -342,34 +540,8 @@ func (a *runtime) runStep(ctx agent.InvocationContext, iteration int, roleName s
 
 	// Enrich request based on role and current state
 	state := a.getTaskState(ctx)
-	switch roleName {
-	case RolePlan:
-		req.Plan = &plan.PlanInput{Task: &plan.PlanTaskID{Id: a.runInput.TaskID}}
-	case RoleDo:
-		if state.Plan == nil || state.Plan.WorkPlan == nil || state.Plan.AcceptanceCriteria == nil {
-			return nil, fmt.Errorf("missing plan for do step")
-		}
-		req.Do = &do.DoInput{
-			WorkPlan:                    planWorkPlanToDo(state.Plan.WorkPlan),
-			AcceptanceCriteriaEffective: planEffectiveToDo(state.Plan.AcceptanceCriteria.Effective),
-		}
-	case RoleCheck:
-		if state.Plan == nil || state.Plan.WorkPlan == nil || state.Plan.AcceptanceCriteria == nil || state.Do == nil || state.Do.Execution == nil {
-			return nil, fmt.Errorf("missing plan or do for check step")
-		}
-		req.Check = &check.CheckInput{
-			WorkPlan:                    planWorkPlanToCheck(state.Plan.WorkPlan),
-			AcceptanceCriteriaEffective: planEffectiveToCheck(state.Plan.AcceptanceCriteria.Effective),
-			DoExecution:                 doExecutionToCheck(state.Do.Execution),
-		}
-	case RoleAct:
-		if state.Check == nil || state.Check.Verdict == nil {
-			return nil, fmt.Errorf("missing check verdict for act step")
-		}
-		req.Act = &act.ActInput{
-			CheckVerdict:      checkVerdictToAct(state.Check.Verdict),
-			AcceptanceResults: checkAcceptanceResultsToAct(state.Check.AcceptanceResults),
-		}
+	if err := a.enrichRequest(&req, roleName, state); err != nil {
+		return nil, err
 	}
 
 	// Prepare step directory and workspace
@@ -377,38 +549,39 @@ func (a *runtime) runStep(ctx agent.InvocationContext, iteration int, roleName s
 	stepDirName := fmt.Sprintf("%03d-%s", index, roleName)
 	stepDir := filepath.Join(stepsDir, stepDirName)
 	if err := os.MkdirAll(filepath.Join(stepDir, "logs"), 0o700); err != nil {
-		return nil, err
+		return nil, infraErr(err)
 	}
 	if err := os.MkdirAll(filepath.Join(stepDir, "artifacts"), 0o700); err != nil {
-		return nil, err
+		return nil, infraErr(err)
 	}
 
-	l := log.With().
+	l := runpkg.ContextLogger(ctx, log.Logger).With().
 		Str("component", "pdca").
 		Str("agent_name", ctx.Agent().Name()).
 		Str("invocation_id", ctx.InvocationID()).
 		Logger()
 
 	workspaceDir := filepath.Join(stepDir, "workspace")
-	branchName := fmt.Sprintf("norma/task/%s", a.runInput.TaskID)
+	branchName := runpkg.TaskBranch(a.cfg.Git, a.runInput.TaskID, a.runInput.RunID)
 	l.Debug().Str("workspace", workspaceDir).Str("branch", branchName).Msg("mounting worktree")
-	if _, err := git.MountWorktree(ctx, a.runInput.WorkingDir, workspaceDir, branchName, a.baseBranch); err != nil {
-		return nil, fmt.Errorf("mount worktree: %w", err)
+	removeWorktree, err := mountStepWorktree(ctx, worktreeSlots, a.cfg.MaxWorktrees, a.runInput.WorkingDir, workspaceDir, branchName, a.baseBranch)
+	if err != nil {
+		return nil, infraErr(fmt.Errorf("mount worktree: %w", err))
 	}
 	defer func() {
 		l.Debug().Str("workspace", workspaceDir).Msg("removing worktree")
-		if err := git.RemoveWorktree(ctx, a.runInput.WorkingDir, workspaceDir); err != nil {
+		if err := removeWorktree(); err != nil {
 			l.Warn().Err(err).Str("workspace", workspaceDir).Msg("failed to remove worktree")
 		}
 	}()
 
 	absStepDir, err := filepath.Abs(stepDir)
 	if err != nil {
-		return nil, fmt.Errorf("resolve step dir path: %w", err)
+		return nil, infraErr(fmt.Errorf("resolve step dir path: %w", err))
 	}
 	absWorkspaceDir, err := filepath.Abs(workspaceDir)
 	if err != nil {
-		return nil, fmt.Errorf("resolve workspace dir path: %w", err)
+		return nil, infraErr(fmt.Errorf("resolve workspace dir path: %w", err))
 	}
 
 	req.Paths = contracts.RequestPaths{
@@ -417,12 +590,11 @@ func (a *runtime) runStep(ctx agent.InvocationContext, iteration int, roleName s
 	}
 
 	// Create input.json
-	inputData, err := json.MarshalIndent(req, "", "  ")
-	if err != nil {
-		return nil, fmt.Errorf("marshal input.json: %w", err)
+	if err := fsutil.WriteJSONAtomic(filepath.Join(stepDir, "input.json"), req); err != nil {
+		return nil, infraErr(err)
 	}
-	if err := os.WriteFile(filepath.Join(stepDir, "input.json"), inputData, 0o600); err != nil {
-		return nil, fmt.Errorf("write input.json: %w", err)
+	if err := recordStepEnv(stepDir, a.cfg.RecordEnv); err != nil {
+		return nil, infraErr(err)
 	}
 
 	// Create runner for this step
@@ -430,31 +602,69 @@ func (a *runtime) runStep(ctx agent.InvocationContext, iteration int, roleName s
 	if err != nil {
 		return nil, err
 	}
-	runner, err := NewRunner(agentCfg, role)
+	agentCfg = resolveModel(agentCfg, iteration)
+	runner, err := NewRunner(agentCfg, role,
+		WithShutdownGrace(time.Duration(a.cfg.AgentShutdownGrace)*time.Second),
+		WithSafetyProfile(a.cfg.Safety.Profile),
+		WithSystemPromptPreamble(a.cfg.SystemPromptPreamble),
+		WithSanitizeOutput(a.cfg.SanitizeAgentOutput),
+	)
 	if err != nil {
 		return nil, fmt.Errorf("create runner for role %q: %w", roleName, err)
 	}
+	runner = withConcurrencyLimit(runner, agentSlots, a.cfg.MaxConcurrentAgents)
+	runner = withHeartbeat(runner, time.Duration(a.cfg.StepHeartbeatInterval)*time.Second, func(elapsed time.Duration) {
+		l.Info().
+			Str("role", roleName).
+			Int("step_index", index).
+			Int("iteration", iteration).
+			Dur("elapsed", elapsed).
+			Msg("step still running")
+		if a.runInput.OnStepHeartbeat != nil {
+			a.runInput.OnStepHeartbeat(StepHeartbeat{
+				RunID:     a.runInput.RunID,
+				TaskID:    a.runInput.TaskID,
+				Role:      roleName,
+				StepIndex: index,
+				Iteration: iteration,
+				Elapsed:   elapsed,
+			})
+		}
+	})
 	l.Debug().Str("role", roleName).Str("agent_type", agentCfg.Type).Msg("running step runner")
 
 	// Prepare log files
 	stdoutFile, err := os.OpenFile(filepath.Join(stepDir, "logs", "stdout.txt"), os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o600)
 	if err != nil {
-		return nil, fmt.Errorf("create stdout log file: %w", err)
+		return nil, infraErr(fmt.Errorf("create stdout log file: %w", err))
 	}
 	defer func() { _ = stdoutFile.Close() }()
 
 	stderrFile, err := os.OpenFile(filepath.Join(stepDir, "logs", "stderr.txt"), os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o600)
 	if err != nil {
-		return nil, fmt.Errorf("create stderr log file: %w", err)
+		return nil, infraErr(fmt.Errorf("create stderr log file: %w", err))
 	}
 	defer func() { _ = stderrFile.Close() }()
 
-	multiStdout, multiStderr := agentOutputWriters(logging.DebugEnabled(), stdoutFile, stderrFile)
+	mirrorStdout, mirrorStderr := mirrorAgentOutput(a.cfg.Logging, logging.DebugEnabled())
+	multiStdout, multiStderr := agentOutputWriters(mirrorStdout, mirrorStderr, stdoutFile, stderrFile)
+
+	baseHead, err := recordStepBase(ctx, workspaceDir, stepDir)
+	if err != nil {
+		return nil, infraErr(err)
+	}
+	preStepRef := ""
+	if roleName == RoleDo {
+		preStepRef = baseHead
+	}
 
 	startTime := time.Now()
-	lastOut, _, exitCode, err := runner.Run(ctx, req, multiStdout, multiStderr)
+	maxAttempts := agentCfg.Attempts()
+	lastOut, err := runAttempts(ctx, runner, req, maxAttempts, multiStdout, multiStderr, responseAcceptor(role, a.cfg.RetryInvalidResponse), func(attempt int, err error) {
+		l.Warn().Err(err).Str("role", roleName).Int("attempt", attempt).Int("max_attempts", maxAttempts).Msg("step agent failed, retrying")
+	})
 	if err != nil {
-		return nil, fmt.Errorf("run role %q agent (exit code %d): %w", roleName, exitCode, err)
+		return nil, agentRunError(roleName, err)
 	}
 	endTime := time.Now()
 
@@ -464,20 +674,169 @@ func (a *runtime) runStep(ctx agent.InvocationContext, iteration int, roleName s
 		return nil, fmt.Errorf("map response: %w", err)
 	}
 
-	// Persist output.json
-	respJSON, err := json.MarshalIndent(resp, "", "  ")
+	warnings, err := scanStderrWarnings(stepDir, agentCfg.StderrWarningPatterns)
 	if err != nil {
-		return nil, fmt.Errorf("marshal output.json: %w", err)
+		return nil, infraErr(err)
 	}
-	if err := os.WriteFile(filepath.Join(stepDir, "output.json"), respJSON, 0o600); err != nil {
-		return nil, fmt.Errorf("write output.json: %w", err)
+	for _, w := range warnings {
+		l.Warn().Str("role", roleName).Str("pattern", w.Pattern).Str("line", w.Line).Msg("agent stderr warning")
 	}
+	applyStderrWarnings(&resp, warnings)
 
-	// Persist Do workspace changes before worktree cleanup.
-	if roleName == RoleDo && resp.Status == "ok" {
-		if err := commitWorkspaceChanges(ctx, workspaceDir, a.runInput.RunID, a.runInput.TaskID, index); err != nil {
+	if len(a.suspicious) > 0 {
+		stdoutLog, err := os.ReadFile(filepath.Join(stepDir, "logs", "stdout.txt"))
+		if err != nil {
+			return nil, infraErr(fmt.Errorf("read stdout log for safety scan: %w", err))
+		}
+		if matches := scanOutputLines(append(stdoutLog, lastOut...), a.suspicious); len(matches) > 0 {
+			for _, m := range matches {
+				l.Warn().Str("role", roleName).Str("pattern", m.Pattern).Str("line", m.Line).Msg("suspicious agent output")
+			}
+			state := a.getTaskState(ctx)
+			applySuspiciousMatches(state, &resp, matches, a.cfg.Safety.StopOnMatch, roleName, a.runInput.RunID, index, time.Now())
+			if err := ctx.Session().State().Set("task_state", state); err != nil {
+				return nil, infraErr(fmt.Errorf("set task state in session: %w", err))
+			}
+		}
+	}
+
+	if roleName == RolePlan && a.cfg.VerifyHints.SeedChecks {
+		seedHintChecks(resp.Plan, a.runInput.AcceptanceCriteria, a.cfg.VerifyHints.CommandPrefixes)
+	}
+
+	if roleName == RolePlan {
+		if missing := enforceNonEmptyPlan(&resp, a.cfg.PlanValidation.EmptyPlan); len(missing) > 0 {
+			l.Warn().Strs("missing", missing).Str("status", resp.Status).Msg("plan is empty")
+		}
+		if total, exceeded := enforceMaxDoSteps(&resp, a.cfg.Budgets.MaxDoSteps, a.cfg.PlanValidation.DoStepsOverflow); exceeded {
+			l.Warn().
+				Int("do_steps", total).
+				Int("max_do_steps", a.cfg.Budgets.MaxDoSteps).
+				Str("status", resp.Status).
+				Msg("plan exceeds do step budget")
+		}
+	}
+
+	if roleName == RolePlan && resp.Status == "ok" {
+		dangling, err := validatePlanACRefs(resp.Plan, a.cfg.PlanValidation.DanglingACRefs)
+		if err != nil {
 			return nil, err
 		}
+		for _, ref := range dangling {
+			l.Warn().Str("do_step", ref.StepID).Str("ac_id", ref.ACID).Msg("plan do step targets unknown acceptance criterion")
+		}
+	}
+
+	if roleName == RoleCheck && resp.Check != nil && req.Check != nil {
+		if skipped := enforceACCoverage(&resp, req.Check.AcceptanceCriteriaEffective, a.cfg.RequireFullACCoverage); len(skipped) > 0 {
+			l.Warn().Strs("ac_ids", skipped).Bool("require_full_ac_coverage", a.cfg.RequireFullACCoverage).Msg("check omitted acceptance criteria")
+		}
+	}
+
+	if roleName == RoleCheck && resp.Check != nil {
+		if state := a.getTaskState(ctx); state.Plan != nil && state.Plan.AcceptanceCriteria != nil {
+			failed, err := runDeterministicChecks(ctx, a.cfg, workspaceDir, stepDir, state.Plan.AcceptanceCriteria.Effective, &resp)
+			if err != nil {
+				return nil, infraErr(err)
+			}
+			if len(failed) > 0 {
+				l.Warn().Strs("ac_ids", failed).Msg("deterministic acceptance checks failed")
+			}
+		}
+	}
+
+	if roleName == RoleCheck && resp.Status == "ok" && len(a.consensus) > 0 &&
+		resp.Check != nil && resp.Check.Verdict != nil && strings.EqualFold(resp.Check.Verdict.Status, check.VerdictPass) {
+		if err := a.runCheckConsensus(ctx, req, &resp, stepDir, iteration); err != nil {
+			return nil, infraErr(err)
+		}
+	}
+
+	if roleName == RoleCheck && resp.Check != nil {
+		refs := resolveEvidenceRefs(absStepDir, a.runInput.RunDir, resp.Check.AcceptanceResults)
+		for _, ref := range refs {
+			if ref.Missing {
+				l.Warn().Str("ac_id", ref.ACID).Str("log_ref", ref.LogRef).Msg("check evidence log_ref does not resolve to a file in the run directory")
+			}
+		}
+		if err := recordEvidence(stepDir, refs); err != nil {
+			return nil, infraErr(err)
+		}
+	}
+
+	// Record which files the Do step touched before committing them.
+	var changes []git.FileChange
+	if preStepRef != "" {
+		changes, err = captureStepChanges(ctx, workspaceDir, stepDir, preStepRef)
+		if err != nil {
+			return nil, infraErr(err)
+		}
+		state := a.getTaskState(ctx)
+		state.DoChangedFiles = changedPaths(changes)
+		if err := ctx.Session().State().Set("task_state", state); err != nil {
+			return nil, infraErr(fmt.Errorf("set task state in session: %w", err))
+		}
+	}
+
+	// Persist Do workspace changes before worktree cleanup.
+	doCommitted := false
+	if roleName == RoleDo && (resp.Status == "ok" || checksPartialDo(a.cfg.CheckOnPartialDo, roleName, &resp)) {
+		secretsBlocked := false
+		if a.cfg.Safety.BlockSecrets {
+			hits, err := revertSecrets(ctx, workspaceDir, secretPatterns(a.cfg.Safety.SecretPatterns))
+			if err != nil {
+				return nil, infraErr(err)
+			}
+			for _, hit := range hits {
+				l.Warn().Str("file", hit.File).Int("line", hit.Line).Str("pattern", hit.Pattern).Msg("possible secret in Do changes, reverting")
+			}
+			if len(hits) > 0 {
+				if err := blockSecrets(stepDir, hits, &resp); err != nil {
+					return nil, infraErr(err)
+				}
+				secretsBlocked = true
+			}
+		}
+		preCommit, err := resolvePreCommit(ctx, a.cfg.Git, workspaceDir)
+		if err != nil {
+			return nil, infraErr(err)
+		}
+		doCommitted, err = commitWorkspaceChanges(ctx, workspaceDir, a.runInput.RunID, a.runInput.TaskID, index, preCommit)
+		var hookFailure *preCommitError
+		if errors.As(err, &hookFailure) {
+			l.Warn().Str("command", hookFailure.Command).Int("exit_code", hookFailure.ExitCode).Msg("pre-commit check failed")
+			if err := blockPreCommit(stepDir, hookFailure, &resp); err != nil {
+				return nil, infraErr(err)
+			}
+		} else if err != nil {
+			return nil, infraErr(err)
+		}
+		head, err := git.GitRunCmdOutput(ctx, workspaceDir, "git", "rev-parse", "HEAD")
+		if err != nil {
+			return nil, infraErr(fmt.Errorf("resolve post-step workspace HEAD: %w", err))
+		}
+		if head = strings.TrimSpace(head); head != preStepRef {
+			state := a.getTaskState(ctx)
+			state.DoCommits = append(state.DoCommits, head)
+			if err := ctx.Session().State().Set("task_state", state); err != nil {
+				return nil, infraErr(fmt.Errorf("set task state in session: %w", err))
+			}
+		}
+
+		if command := strings.TrimSpace(a.cfg.DoPostCommand); command != "" && hookFailure == nil && !secretsBlocked {
+			passed, err := runDoPostCommand(ctx, workspaceDir, stepDir, command, a.cfg.DoPostCommandFailure, &resp)
+			if err != nil {
+				return nil, infraErr(err)
+			}
+			if !passed {
+				l.Warn().Str("command", command).Str("status", resp.Status).Msg("do post command failed")
+			}
+		}
+	}
+
+	// Persist output.json
+	if err := fsutil.WriteJSONAtomic(filepath.Join(stepDir, "output.json"), resp); err != nil {
+		return nil, infraErr(err)
 	}
 
 	// Commit to DB
@@ -498,25 +857,21 @@ func (a *runtime) runStep(ctx agent.InvocationContext, iteration int, roleName s
 		Status:           "running",
 	}
 	if err := a.store.CommitStep(ctx, stepRec, nil, update); err != nil {
-		return nil, fmt.Errorf("commit step %d (%s): %w", index, roleName, err)
+		return nil, infraErr(fmt.Errorf("commit step %d (%s): %w", index, roleName, err))
+	}
+	if preStepRef != "" {
+		if err := a.store.RecordStepChanges(ctx, a.runInput.RunID, index, toStepChanges(changes)); err != nil {
+			return nil, infraErr(fmt.Errorf("record step %d changes: %w", index, err))
+		}
 	}
 
 	// Update Task State and persist to Beads.
 	if err := a.updateTaskState(ctx, &resp, roleName, iteration, index); err != nil {
-		return nil, err
+		return nil, infraErr(err)
 	}
 
 	if a.tracker != nil && resp.Status == "ok" {
-		label := ""
-		switch roleName {
-		case RolePlan:
-			label = "norma-has-plan"
-		case RoleDo:
-			label = "norma-has-do"
-		case RoleCheck:
-			label = "norma-has-check"
-		}
-		if label != "" {
+		if label := completedStepLabel(roleName, doCommitted); label != "" {
 			if err := a.tracker.AddLabel(ctx, a.runInput.TaskID, label); err != nil {
 				log.Warn().Err(err).Str("task_id", a.runInput.TaskID).Str("label", label).Msg("failed to add label to task")
 			}
@@ -526,14 +881,63 @@ func (a *runtime) runStep(ctx agent.InvocationContext, iteration int, roleName s
 	return &resp, nil
 }
 
-func agentOutputWriters(debugEnabled bool, stdoutLog io.Writer, stderrLog io.Writer) (io.Writer, io.Writer) {
-	if !debugEnabled {
-		return stdoutLog, stderrLog
+// completedStepLabel returns the label recording a successful step, or "" if none applies.
+// Do only earns its label when it committed changes, so a resume never skips an empty Do.
+func completedStepLabel(roleName string, doCommitted bool) string {
+	switch roleName {
+	case RolePlan:
+		return "norma-has-plan"
+	case RoleDo:
+		if doCommitted {
+			return labelHasDo
+		}
+	case RoleCheck:
+		return "norma-has-check"
 	}
-	return io.MultiWriter(os.Stdout, stdoutLog), io.MultiWriter(os.Stderr, stderrLog)
+	return ""
+}
+
+// infraErr marks err as an infrastructure failure so the run is not counted as an agent or task failure.
+func infraErr(err error) error {
+	return runpkg.WithFailureKind(runpkg.FailureInfrastructure, err)
+}
+
+// agentRunError wraps the error of a failed step agent. A process that could not be
+// started, e.g. because the agent binary is missing, is an infrastructure failure;
+// anything else is left for the run to classify as an agent error.
+func agentRunError(roleName string, err error) error {
+	err = fmt.Errorf("run role %q agent: %w", roleName, err)
+	var pathErr *fs.PathError
+	if errors.Is(err, exec.ErrNotFound) || (errors.As(err, &pathErr) && pathErr.Op == "fork/exec") {
+		return infraErr(err)
+	}
+	return err
+}
+
+// mirrorAgentOutput reports whether agent stdout and stderr are mirrored to the console.
+// Debug logging mirrors both streams; logging.mirror_stdout and logging.mirror_stderr enable each one on its own.
+func mirrorAgentOutput(cfg config.LoggingConfig, debugEnabled bool) (bool, bool) {
+	return debugEnabled || cfg.MirrorStdout, debugEnabled || cfg.MirrorStderr
+}
+
+// agentOutputWriters returns the writers for agent stdout and stderr.
+// The step log files always receive the output; the console only when mirrored.
+func agentOutputWriters(mirrorStdout, mirrorStderr bool, stdoutLog io.Writer, stderrLog io.Writer) (io.Writer, io.Writer) {
+	return mirroredWriter(mirrorStdout, os.Stdout, stdoutLog), mirroredWriter(mirrorStderr, os.Stderr, stderrLog)
+}
+
+func mirroredWriter(mirror bool, console io.Writer, log io.Writer) io.Writer {
+	if !mirror {
+		return log
+	}
+	return io.MultiWriter(console, log)
 }
 
 func (a *runtime) baseRequest(iteration, index int, role string) contracts.AgentRequest {
+	var seeded map[string][]plan.CriterionCheck
+	if role == RolePlan && a.cfg.VerifyHints.SeedChecks {
+		seeded = baselineHintChecks(a.runInput.AcceptanceCriteria, a.cfg.VerifyHints.CommandPrefixes)
+	}
 	return contracts.AgentRequest{
 		Run: contracts.RunInfo{
 			ID:        a.runInput.RunID,
@@ -544,6 +948,7 @@ func (a *runtime) baseRequest(iteration, index int, role string) contracts.Agent
 			Title:              a.runInput.Goal,
 			Description:        a.runInput.Goal,
 			AcceptanceCriteria: a.runInput.AcceptanceCriteria,
+			SeededChecks:       seeded,
 		},
 		Step: contracts.StepInfo{
 			Index: index,
@@ -551,6 +956,7 @@ func (a *runtime) baseRequest(iteration, index int, role string) contracts.Agent
 		},
 		Budgets: contracts.Budgets{
 			MaxIterations: a.cfg.Budgets.MaxIterations,
+			MaxDoSteps:    a.cfg.Budgets.MaxDoSteps,
 		},
 		StopReasonsAllowed: []string{
 			"budget_exceeded",
@@ -627,11 +1033,17 @@ func planWorkPlanToDo(src *plan.PlanWorkPlan) *do.DoWorkPlan {
 	}
 }
 
+// planEffectiveToDo maps the effective acceptance criteria into the Do input.
+// Webhook checks are left out: Do has no command to run for them, they are run by
+// the Check step verifier.
 func planEffectiveToDo(src []plan.EffectiveAcceptanceCriteria) []do.DoEffectiveAcceptanceCriteria {
 	out := make([]do.DoEffectiveAcceptanceCriteria, 0, len(src))
 	for _, ac := range src {
 		checks := make([]do.DoAcceptanceCriteriaCheck, 0, len(ac.Checks))
 		for _, c := range ac.Checks {
+			if c.Mode == verify.ModeWebhook {
+				continue
+			}
 			checks = append(checks, do.DoAcceptanceCriteriaCheck{
 				Id:              c.Id,
 				Cmd:             c.Cmd,
@@ -650,6 +1062,69 @@ func planEffectiveToDo(src []plan.EffectiveAcceptanceCriteria) []do.DoEffectiveA
 	return out
 }
 
+// enrichRequest fills the role-specific input of req from the current task state.
+func (a *runtime) enrichRequest(req *contracts.AgentRequest, roleName string, state *contracts.TaskState) error {
+	switch roleName {
+	case RolePlan:
+		req.Plan = &plan.PlanInput{Task: &plan.PlanTaskID{Id: a.runInput.TaskID}}
+		digestState := *state
+		digestState.Journal = recentJournal(state.Journal, time.Duration(a.cfg.Budgets.JournalMaxAge)*time.Second, time.Now())
+		req.Context.FailureDigest = SummarizeFailures(digestState)
+	case RoleDo:
+		if state.Plan == nil || state.Plan.WorkPlan == nil || state.Plan.AcceptanceCriteria == nil {
+			return fmt.Errorf("missing plan for do step")
+		}
+		req.Do = &do.DoInput{
+			WorkPlan:                    planWorkPlanToDo(state.Plan.WorkPlan),
+			AcceptanceCriteriaEffective: planEffectiveToDo(state.Plan.AcceptanceCriteria.Effective),
+		}
+		req.Context.Constraints = planConstraints(state.Plan)
+	case RoleCheck:
+		checkInput, err := checkInputFromState(state)
+		if err != nil {
+			return err
+		}
+		req.Check = checkInput
+		req.Context.Constraints = planConstraints(state.Plan)
+	case RoleAct:
+		if state.Check == nil || state.Check.Verdict == nil {
+			return fmt.Errorf("missing check verdict for act step")
+		}
+		req.Act = &act.ActInput{
+			CheckVerdict:      checkVerdictToAct(state.Check.Verdict),
+			AcceptanceResults: checkAcceptanceResultsToAct(state.Check.AcceptanceResults),
+		}
+	}
+	return nil
+}
+
+// planConstraints returns the non-empty constraints the plan set for later steps.
+func planConstraints(src *plan.PlanOutput) []string {
+	if src == nil {
+		return nil
+	}
+	var out []string
+	for _, constraint := range src.Constraints {
+		if constraint = strings.TrimSpace(constraint); constraint != "" {
+			out = append(out, constraint)
+		}
+	}
+	return out
+}
+
+// checkInputFromState builds the Check input from the plan and the latest Do step.
+func checkInputFromState(state *contracts.TaskState) (*check.CheckInput, error) {
+	if state.Plan == nil || state.Plan.WorkPlan == nil || state.Plan.AcceptanceCriteria == nil || state.Do == nil || state.Do.Execution == nil {
+		return nil, fmt.Errorf("missing plan or do for check step")
+	}
+	return &check.CheckInput{
+		WorkPlan:                    planWorkPlanToCheck(state.Plan.WorkPlan),
+		AcceptanceCriteriaEffective: planEffectiveToCheck(state.Plan.AcceptanceCriteria.Effective),
+		DoExecution:                 doExecutionToCheck(state.Do.Execution),
+		ChangedFiles:                state.DoChangedFiles,
+	}, nil
+}
+
 func planWorkPlanToCheck(src *plan.PlanWorkPlan) *check.CheckWorkPlan {
 	if src == nil {
 		return nil
@@ -723,6 +1198,7 @@ func checkAcceptanceResultsToAct(src []check.CheckAcceptanceResult) []act.ActAcc
 			AcId:   ar.AcId,
 			Result: ar.Result,
 			Notes:  ar.Notes,
+			Score:  ar.Score,
 		})
 	}
 	return out
@@ -740,6 +1216,16 @@ func resolvedAgentForRole(registry map[string]config.AgentConfig, roleIDs map[st
 	return agentCfg, nil
 }
 
+// resolveModel picks the model for iteration from the agent's escalation list.
+func resolveModel(cfg config.AgentConfig, iteration int) config.AgentConfig {
+	model := cfg.ModelForIteration(iteration)
+	if model == cfg.Model {
+		return cfg
+	}
+	log.Debug().Int("iteration", iteration).Str("from_model", cfg.Model).Str("to_model", model).Msg("escalating agent model")
+	return cfg.WithModel(model)
+}
+
 func (a *runtime) getTaskState(ctx agent.InvocationContext) *contracts.TaskState {
 	s, err := ctx.Session().State().Get("task_state")
 	if err != nil {
@@ -785,7 +1271,11 @@ func (a *runtime) updateTaskState(ctx agent.InvocationContext, resp *contracts.A
 
 	state := a.getTaskState(ctx)
 	applyAgentResponseToTaskState(state, resp, role, a.runInput.RunID, iteration, index, time.Now())
+	return a.persistTaskState(ctx, state)
+}
 
+// persistTaskState stores state in the session and, with a tracker, in the task notes.
+func (a *runtime) persistTaskState(ctx agent.InvocationContext, state *contracts.TaskState) error {
 	if err := ctx.Session().State().Set("task_state", state); err != nil {
 		return fmt.Errorf("set task state in session: %w", err)
 	}
@@ -829,27 +1319,64 @@ func applyAgentResponseToTaskState(state *contracts.TaskState, resp *contracts.A
 	if entry.Title == "" {
 		entry.Title = fmt.Sprintf("%s step completed", role)
 	}
-	state.Journal = append(state.Journal, entry)
+	state.Journal = upsertJournalEntry(state.Journal, entry)
+}
+
+// applySkippedStepToTaskState journals a step reused instead of run as a skipped entry
+// that records why it was skipped, so the journal has no gaps on resume.
+func applySkippedStepToTaskState(state *contracts.TaskState, resp *contracts.AgentResponse, role, runID string, iteration, index int, reason string, now time.Time) {
+	applyAgentResponseToTaskState(state, resp, role, runID, iteration, index, now)
+	for i := range state.Journal {
+		entry := &state.Journal[i]
+		if entry.RunID == runID && entry.StepIndex == index && entry.Role == role {
+			entry.Type = contracts.JournalEntrySkipped
+			entry.Reason = reason
+		}
+	}
 }
 
-func commitWorkspaceChanges(ctx context.Context, workspaceDir, runID, taskID string, stepIndex int) error {
+// upsertJournalEntry replaces the entry for the same run, step index, and role,
+// so a step re-run on resume does not duplicate its journal entry.
+func upsertJournalEntry(journal []contracts.JournalEntry, entry contracts.JournalEntry) []contracts.JournalEntry {
+	for i, existing := range journal {
+		if existing.RunID == entry.RunID && existing.StepIndex == entry.StepIndex && existing.Role == entry.Role {
+			journal[i] = entry
+			return journal
+		}
+	}
+	return append(journal, entry)
+}
+
+// commitWorkspaceChanges commits all workspace changes and reports whether a commit was made.
+// A non-empty preCommit command runs against the staged changes first; when it exits nonzero
+// nothing is committed and a *preCommitError is returned.
+func commitWorkspaceChanges(ctx context.Context, workspaceDir, runID, taskID string, stepIndex int, preCommit string) (bool, error) {
 	statusOut, err := git.GitRunCmdOutput(ctx, workspaceDir, "git", "status", "--porcelain")
 	if err != nil {
-		return fmt.Errorf("read workspace status: %w", err)
+		return false, fmt.Errorf("read workspace status: %w", err)
 	}
 	status := strings.TrimSpace(statusOut)
 	if status == "" {
-		return nil
+		return false, nil
 	}
 
 	if err := git.GitRunCmdErr(ctx, workspaceDir, "git", "add", "-A"); err != nil {
-		return fmt.Errorf("stage workspace changes: %w", err)
+		return false, fmt.Errorf("stage workspace changes: %w", err)
+	}
+
+	commitArgs := []string{"commit"}
+	if preCommit != "" {
+		if err := runPreCommit(ctx, workspaceDir, preCommit); err != nil {
+			return false, err
+		}
+		// The check already ran; do not run the repository hook a second time.
+		commitArgs = append(commitArgs, "--no-verify")
 	}
 
 	commitMsg := fmt.Sprintf("chore: do step %03d\n\nRun: %s\nTask: %s", stepIndex, runID, taskID)
-	if err := git.GitRunCmdErr(ctx, workspaceDir, "git", "commit", "-m", commitMsg); err != nil {
-		return fmt.Errorf("commit workspace changes: %w", err)
+	if err := git.GitRunCmdErr(ctx, workspaceDir, "git", append(commitArgs, "-m", commitMsg)...); err != nil {
+		return false, fmt.Errorf("commit workspace changes: %w", err)
 	}
 
-	return nil
+	return true, nil
 }
@@ -3,6 +3,7 @@ package pdca
 import (
 	"bytes"
 	"context"
+	"io"
 	"os"
 	"os/exec"
 	"path/filepath"
@@ -50,46 +51,76 @@ func TestResolvedAgentForRoleReturnsRoleSpecificError(t *testing.T) {
 	}
 }
 
-func TestAgentOutputWritersNoDebug(t *testing.T) {
+func TestAgentOutputWriters(t *testing.T) {
 	t.Parallel()
 
-	var stdoutLog bytes.Buffer
-	var stderrLog bytes.Buffer
-	stdout, stderr := agentOutputWriters(false, &stdoutLog, &stderrLog)
-
-	if stdout != &stdoutLog {
-		t.Fatalf("stdout writer should be log-only writer when debug is disabled")
+	tests := []struct {
+		name         string
+		mirrorStdout bool
+		mirrorStderr bool
+	}{
+		{name: "file only"},
+		{name: "stdout mirrored", mirrorStdout: true},
+		{name: "stderr mirrored", mirrorStderr: true},
+		{name: "both mirrored", mirrorStdout: true, mirrorStderr: true},
 	}
-	if stderr != &stderrLog {
-		t.Fatalf("stderr writer should be log-only writer when debug is disabled")
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			var stdoutLog bytes.Buffer
+			var stderrLog bytes.Buffer
+			stdout, stderr := agentOutputWriters(tc.mirrorStdout, tc.mirrorStderr, &stdoutLog, &stderrLog)
+
+			if got := stdout != io.Writer(&stdoutLog); got != tc.mirrorStdout {
+				t.Fatalf("stdout mirrored = %t, want %t", got, tc.mirrorStdout)
+			}
+			if got := stderr != io.Writer(&stderrLog); got != tc.mirrorStderr {
+				t.Fatalf("stderr mirrored = %t, want %t", got, tc.mirrorStderr)
+			}
+
+			if _, err := stdout.Write([]byte("out")); err != nil {
+				t.Fatalf("write stdout: %v", err)
+			}
+			if _, err := stderr.Write([]byte("err")); err != nil {
+				t.Fatalf("write stderr: %v", err)
+			}
+			if stdoutLog.String() != "out" {
+				t.Fatalf("stdout log captured %q, want %q", stdoutLog.String(), "out")
+			}
+			if stderrLog.String() != "err" {
+				t.Fatalf("stderr log captured %q, want %q", stderrLog.String(), "err")
+			}
+		})
 	}
 }
 
-func TestAgentOutputWritersDebug(t *testing.T) {
+func TestMirrorAgentOutput(t *testing.T) {
 	t.Parallel()
 
-	var stdoutLog bytes.Buffer
-	var stderrLog bytes.Buffer
-	stdout, stderr := agentOutputWriters(true, &stdoutLog, &stderrLog)
-
-	if stdout == &stdoutLog {
-		t.Fatalf("stdout writer should include console + log writer when debug is enabled")
-	}
-	if stderr == &stderrLog {
-		t.Fatalf("stderr writer should include console + log writer when debug is enabled")
+	tests := []struct {
+		name       string
+		cfg        config.LoggingConfig
+		debug      bool
+		wantStdout bool
+		wantStderr bool
+	}{
+		{name: "defaults"},
+		{name: "stderr only", cfg: config.LoggingConfig{MirrorStderr: true}, wantStderr: true},
+		{name: "stdout only", cfg: config.LoggingConfig{MirrorStdout: true}, wantStdout: true},
+		{name: "debug mirrors both", debug: true, wantStdout: true, wantStderr: true},
 	}
 
-	if _, err := stdout.Write([]byte("out")); err != nil {
-		t.Fatalf("write debug stdout: %v", err)
-	}
-	if _, err := stderr.Write([]byte("err")); err != nil {
-		t.Fatalf("write debug stderr: %v", err)
-	}
-	if stdoutLog.String() != "out" {
-		t.Fatalf("stdout log captured %q, want %q", stdoutLog.String(), "out")
-	}
-	if stderrLog.String() != "err" {
-		t.Fatalf("stderr log captured %q, want %q", stderrLog.String(), "err")
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			gotStdout, gotStderr := mirrorAgentOutput(tc.cfg, tc.debug)
+			if gotStdout != tc.wantStdout || gotStderr != tc.wantStderr {
+				t.Fatalf("mirrorAgentOutput() = (%t, %t), want (%t, %t)", gotStdout, gotStderr, tc.wantStdout, tc.wantStderr)
+			}
+		})
 	}
 }
 
@@ -143,6 +174,35 @@ func TestApplyAgentResponseToTaskStateActPersistsOutputAndJournal(t *testing.T)
 	}
 }
 
+func TestApplyAgentResponseToTaskStateReplacesRerunStepEntry(t *testing.T) {
+	t.Parallel()
+
+	ts := time.Date(2026, time.February, 12, 13, 14, 15, 0, time.UTC)
+	state := &contracts.TaskState{}
+	applyAgentResponseToTaskState(state, &contracts.AgentResponse{Status: "ok", Progress: contracts.StepProgress{Title: "planned"}}, RolePlan, "run-1", 1, 1, ts)
+	applyAgentResponseToTaskState(state, &contracts.AgentResponse{Status: "error", Progress: contracts.StepProgress{Title: "do failed"}}, RoleDo, "run-1", 1, 2, ts)
+
+	// Resume re-runs the Do step with the same run, step index, and role.
+	rerun := ts.Add(time.Minute)
+	applyAgentResponseToTaskState(state, &contracts.AgentResponse{Status: "ok", Progress: contracts.StepProgress{Title: "do done"}}, RoleDo, "run-1", 1, 2, rerun)
+	// The same step index in another run is a distinct entry.
+	applyAgentResponseToTaskState(state, &contracts.AgentResponse{Status: "ok", Progress: contracts.StepProgress{Title: "do again"}}, RoleDo, "run-2", 1, 2, rerun)
+
+	if len(state.Journal) != 3 {
+		t.Fatalf("len(state.Journal) = %d, want 3: %+v", len(state.Journal), state.Journal)
+	}
+	got := state.Journal[1]
+	if got.RunID != "run-1" || got.Role != RoleDo || got.Status != "ok" || got.Title != "do done" {
+		t.Fatalf("re-run journal entry = %+v, want replaced ok entry", got)
+	}
+	if got.Timestamp != "2026-02-12T13:15:15Z" {
+		t.Fatalf("re-run journal timestamp = %q, want re-run time", got.Timestamp)
+	}
+	if state.Journal[2].RunID != "run-2" {
+		t.Fatalf("journal[2].RunID = %q, want run-2", state.Journal[2].RunID)
+	}
+}
+
 func TestApplyAgentResponseToTaskStateDefaultsJournalTitle(t *testing.T) {
 	t.Parallel()
 
@@ -169,6 +229,49 @@ func TestApplyAgentResponseToTaskStateDefaultsJournalTitle(t *testing.T) {
 	}
 }
 
+func TestApplySkippedStepToTaskStateJournalsSkippedPlan(t *testing.T) {
+	t.Parallel()
+
+	previous := &plan.PlanOutput{}
+	state := &contracts.TaskState{
+		Plan: previous,
+		Journal: []contracts.JournalEntry{
+			{RunID: "run-1", StepIndex: 1, Role: RolePlan, Status: "ok", Title: "plan step completed"},
+		},
+	}
+	resp := &contracts.AgentResponse{
+		Status: "ok",
+		Plan:   previous,
+		Progress: contracts.StepProgress{
+			Title:   "plan skipped (resumed)",
+			Details: []string{"Label norma-has-plan is present on task"},
+		},
+	}
+
+	ts := time.Date(2026, time.February, 12, 13, 14, 15, 0, time.UTC)
+	applySkippedStepToTaskState(state, resp, RolePlan, "run-2", 1, 1, "label norma-has-plan present", ts)
+
+	if len(state.Journal) != 2 {
+		t.Fatalf("len(state.Journal) = %d, want 2", len(state.Journal))
+	}
+	if state.Journal[0].Type != "" {
+		t.Fatalf("journal[0].Type = %q, want the earlier run entry untouched", state.Journal[0].Type)
+	}
+	entry := state.Journal[1]
+	if entry.Type != contracts.JournalEntrySkipped {
+		t.Fatalf("journal type = %q, want %q", entry.Type, contracts.JournalEntrySkipped)
+	}
+	if entry.Role != RolePlan || entry.RunID != "run-2" {
+		t.Fatalf("journal entry = %+v, want plan entry for run-2", entry)
+	}
+	if entry.Reason != "label norma-has-plan present" {
+		t.Fatalf("journal reason = %q, want %q", entry.Reason, "label norma-has-plan present")
+	}
+	if state.Plan != previous {
+		t.Fatalf("state.Plan was replaced, want the reused plan kept")
+	}
+}
+
 func TestCoerceTaskStatePointerAndValue(t *testing.T) {
 	t.Parallel()
 
@@ -423,9 +526,13 @@ func TestCommitWorkspaceChangesCommitsDirtyWorkspace(t *testing.T) {
 	writeTestFile(t, filepath.Join(workingDir, "a.txt"), "one\ntwo\n")
 	writeTestFile(t, filepath.Join(workingDir, "b.txt"), "new\n")
 
-	if err := commitWorkspaceChanges(ctx, workingDir, "run-1", "norma-8sl", 2); err != nil {
+	committed, err := commitWorkspaceChanges(ctx, workingDir, "run-1", "norma-8sl", 2, "")
+	if err != nil {
 		t.Fatalf("commitWorkspaceChanges() error = %v", err)
 	}
+	if !committed {
+		t.Fatal("commitWorkspaceChanges() committed = false, want true")
+	}
 
 	after := strings.TrimSpace(runGit(t, ctx, workingDir, "rev-parse", "HEAD"))
 	if after == before {
@@ -461,9 +568,13 @@ func TestCommitWorkspaceChangesNoopForCleanWorkspace(t *testing.T) {
 	runGit(t, ctx, workingDir, "commit", "-m", "chore: initial")
 	before := strings.TrimSpace(runGit(t, ctx, workingDir, "rev-parse", "HEAD"))
 
-	if err := commitWorkspaceChanges(ctx, workingDir, "run-2", "norma-8sl", 3); err != nil {
+	committed, err := commitWorkspaceChanges(ctx, workingDir, "run-2", "norma-8sl", 3, "")
+	if err != nil {
 		t.Fatalf("commitWorkspaceChanges() error = %v", err)
 	}
+	if committed {
+		t.Fatal("commitWorkspaceChanges() committed = true, want false")
+	}
 
 	after := strings.TrimSpace(runGit(t, ctx, workingDir, "rev-parse", "HEAD"))
 	if after != before {
@@ -477,7 +588,7 @@ func TestCommitWorkspaceChangesReturnsErrorWhenStatusFails(t *testing.T) {
 	ctx := context.Background()
 	nonRepoDir := t.TempDir()
 
-	err := commitWorkspaceChanges(ctx, nonRepoDir, "run-3", "norma-8sl", 4)
+	_, err := commitWorkspaceChanges(ctx, nonRepoDir, "run-3", "norma-8sl", 4, "")
 	if err == nil {
 		t.Fatal("commitWorkspaceChanges() error = nil, want error")
 	}
@@ -486,6 +597,69 @@ func TestCommitWorkspaceChangesReturnsErrorWhenStatusFails(t *testing.T) {
 	}
 }
 
+func TestChecksPartialDo(t *testing.T) {
+	t.Parallel()
+
+	partial := &contracts.AgentResponse{
+		Status:     "stop",
+		StopReason: "dependency_blocked",
+		Do:         &do.DoOutput{Execution: &do.DoExecution{ExecutedStepIds: []string{"DO-1"}, SkippedStepIds: []string{"DO-2"}}},
+	}
+	nothingDone := &contracts.AgentResponse{
+		Status: "stop",
+		Do:     &do.DoOutput{Execution: &do.DoExecution{SkippedStepIds: []string{"DO-1"}}},
+	}
+	failed := &contracts.AgentResponse{
+		Status: "error",
+		Do:     &do.DoOutput{Execution: &do.DoExecution{ExecutedStepIds: []string{"DO-1"}}},
+	}
+
+	tests := []struct {
+		name    string
+		enabled bool
+		role    string
+		resp    *contracts.AgentResponse
+		want    bool
+	}{
+		{name: "default_stops_partial_do", role: RoleDo, resp: partial},
+		{name: "enabled_checks_partial_do", enabled: true, role: RoleDo, resp: partial, want: true},
+		{name: "enabled_stops_do_without_work", enabled: true, role: RoleDo, resp: nothingDone},
+		{name: "enabled_stops_errored_do", enabled: true, role: RoleDo, resp: failed},
+		{name: "enabled_ignores_other_roles", enabled: true, role: RoleCheck, resp: partial},
+	}
+	for _, tc := range tests {
+		if got := checksPartialDo(tc.enabled, tc.role, tc.resp); got != tc.want {
+			t.Fatalf("%s: checksPartialDo() = %v, want %v", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestCompletedStepLabel(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		role        string
+		doCommitted bool
+		want        string
+	}{
+		{name: "plan", role: RolePlan, want: "norma-has-plan"},
+		{name: "productive_do", role: RoleDo, doCommitted: true, want: labelHasDo},
+		{name: "empty_do", role: RoleDo, doCommitted: false, want: ""},
+		{name: "check", role: RoleCheck, want: "norma-has-check"},
+		{name: "act", role: RoleAct, want: ""},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			if got := completedStepLabel(tc.role, tc.doCommitted); got != tc.want {
+				t.Fatalf("completedStepLabel(%q, %v) = %q, want %q", tc.role, tc.doCommitted, got, tc.want)
+			}
+		})
+	}
+}
+
 func initTestRepo(t *testing.T, ctx context.Context, workingDir string) {
 	t.Helper()
 	runGit(t, ctx, workingDir, "init")
@@ -510,3 +684,25 @@ func runGit(t *testing.T, ctx context.Context, workingDir string, args ...string
 	}
 	return string(out)
 }
+
+func TestResolveModelEscalatesAcrossIterations(t *testing.T) {
+	t.Parallel()
+
+	cfg := config.AgentConfig{
+		Type:             config.AgentTypeGenericACP,
+		Cmd:              []string{"agent", "--model", "{{.Model}}"},
+		Model:            "small",
+		EscalationModels: []string{"small", "large"},
+	}
+
+	want := []string{"small", "large", "large", "large"}
+	for i, model := range want {
+		iteration := i + 1
+		if got := resolveModel(cfg, iteration).Model; got != model {
+			t.Fatalf("resolveModel(iteration %d).Model = %q, want %q", iteration, got, model)
+		}
+	}
+	if got := resolveModel(config.AgentConfig{Model: "only"}, 4).Model; got != "only" {
+		t.Fatalf("resolveModel() without escalation = %q, want only", got)
+	}
+}
@@ -0,0 +1,60 @@
+package pdca
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/metalagman/norma/internal/agents/pdca/contracts"
+)
+
+// ErrRetryable marks a rejected agent response that a new attempt may fix.
+var ErrRetryable = errors.New("retryable agent response")
+
+// runAttempts runs a step agent up to maxAttempts times and returns the output of the
+// first successful run. Each run sees its 1-based number in req.Context.Attempt.
+// Failures are retried unless ctx is done; onRetry is called before each retry.
+// A non-nil accept vets each output: an error wrapping ErrRetryable is retried with the
+// error passed to the next attempt in req.Context.PreviousResponseError, any other error is returned.
+func runAttempts(ctx context.Context, runner Runner, req contracts.AgentRequest, maxAttempts int, stdout, stderr io.Writer, accept func(out []byte) error, onRetry func(attempt int, err error)) ([]byte, error) {
+	maxAttempts = max(maxAttempts, 1)
+	var lastErr error
+	for attempt := 1; attempt <= maxAttempts; attempt++ {
+		req.Context.Attempt = attempt
+		out, _, exitCode, err := runner.Run(ctx, req, stdout, stderr)
+		if err == nil && accept != nil {
+			if err = accept(out); err != nil && !errors.Is(err, ErrRetryable) {
+				return nil, err
+			}
+			if err != nil {
+				req.Context.PreviousResponseError = err.Error()
+			}
+		}
+		if err == nil {
+			return out, nil
+		}
+		lastErr = fmt.Errorf("attempt %d/%d (exit code %d): %w", attempt, maxAttempts, exitCode, err)
+		if ctx.Err() != nil || attempt == maxAttempts {
+			break
+		}
+		if onRetry != nil {
+			onRetry(attempt, err)
+		}
+	}
+	return nil, lastErr
+}
+
+// responseAcceptor returns the runAttempts accept hook for role. With retryInvalid, an
+// output that does not map to the role's response contract is retried; otherwise it is nil.
+func responseAcceptor(role contracts.Role, retryInvalid bool) func(out []byte) error {
+	if !retryInvalid {
+		return nil
+	}
+	return func(out []byte) error {
+		if _, err := role.MapResponse(out); err != nil {
+			return fmt.Errorf("%w: %s response is not valid JSON for its output schema: %w", ErrRetryable, role.Name(), err)
+		}
+		return nil
+	}
+}
@@ -0,0 +1,144 @@
+package pdca
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/metalagman/norma/internal/adk/agentconfig"
+	"github.com/metalagman/norma/internal/agents/pdca/contracts"
+)
+
+// flakyRunner fails until it has been called succeedOn times; zero never succeeds.
+type flakyRunner struct {
+	succeedOn int
+	attempts  []int
+}
+
+func (r *flakyRunner) Run(_ context.Context, req contracts.AgentRequest, _, _ io.Writer) ([]byte, []byte, int, error) {
+	r.attempts = append(r.attempts, req.Context.Attempt)
+	if len(r.attempts) == r.succeedOn {
+		return []byte(`{"status":"ok"}`), nil, 0, nil
+	}
+	return nil, nil, 1, errors.New("agent crashed")
+}
+
+func TestRunAttemptsHonoursRoleMaxAttempts(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		maxAttempts int
+		succeedOn   int
+		wantRuns    int
+		wantErr     bool
+	}{
+		{name: "single_attempt_does_not_retry", maxAttempts: 1, wantRuns: 1, wantErr: true},
+		{name: "retries_up_to_five", maxAttempts: 5, wantRuns: 5, wantErr: true},
+		{name: "stops_after_success", maxAttempts: 5, succeedOn: 2, wantRuns: 2},
+		{name: "unset_uses_default", wantRuns: agentconfig.DefaultMaxAttempts, wantErr: true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			runner := &flakyRunner{succeedOn: tc.succeedOn}
+			cfg := agentconfig.Config{MaxAttempts: tc.maxAttempts}
+			retries := 0
+			_, err := runAttempts(context.Background(), runner, contracts.AgentRequest{}, cfg.Attempts(), io.Discard, io.Discard, nil, func(int, error) {
+				retries++
+			})
+
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("runAttempts() error = %v, wantErr %v", err, tc.wantErr)
+			}
+			if got := len(runner.attempts); got != tc.wantRuns {
+				t.Fatalf("runs = %d, want %d", got, tc.wantRuns)
+			}
+			if retries != tc.wantRuns-1 {
+				t.Fatalf("retries = %d, want %d", retries, tc.wantRuns-1)
+			}
+			for i, attempt := range runner.attempts {
+				if attempt != i+1 {
+					t.Fatalf("run %d saw attempt %d, want %d", i, attempt, i+1)
+				}
+			}
+		})
+	}
+}
+
+func TestRunAttemptsStopsWhenContextDone(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	runner := &flakyRunner{}
+	if _, err := runAttempts(ctx, runner, contracts.AgentRequest{}, 5, io.Discard, io.Discard, nil, nil); err == nil {
+		t.Fatal("runAttempts() error = nil, want failure")
+	}
+	if got := len(runner.attempts); got != 1 {
+		t.Fatalf("runs = %d, want 1 after cancellation", got)
+	}
+}
+
+// scriptedRunner returns outputs in order and records each request context.
+type scriptedRunner struct {
+	outputs  []string
+	contexts []contracts.RequestContext
+}
+
+func (r *scriptedRunner) Run(_ context.Context, req contracts.AgentRequest, _, _ io.Writer) ([]byte, []byte, int, error) {
+	r.contexts = append(r.contexts, req.Context)
+	return []byte(r.outputs[len(r.contexts)-1]), nil, 0, nil
+}
+
+func TestRunAttemptsRetriesInvalidResponse(t *testing.T) {
+	t.Parallel()
+
+	role := GetRole(RoleAct)
+	runner := &scriptedRunner{outputs: []string{
+		`{"status": "ok", "act_output": {"decision": `,
+		`{"status": "ok", "summary": {"text": "done"}, "progress": {"title": "act", "details": []}, "act_output": {"decision": "close"}}`,
+	}}
+	out, err := runAttempts(context.Background(), runner, contracts.AgentRequest{}, 3, io.Discard, io.Discard, responseAcceptor(role, true), nil)
+	if err != nil {
+		t.Fatalf("runAttempts() error = %v", err)
+	}
+	resp, err := role.MapResponse(out)
+	if err != nil || resp.Act == nil || resp.Act.Decision != "close" {
+		t.Fatalf("MapResponse(final output) = %+v, %v; want the second, valid response", resp, err)
+	}
+
+	if len(runner.contexts) != 2 {
+		t.Fatalf("runs = %d, want 2", len(runner.contexts))
+	}
+	if runner.contexts[0].PreviousResponseError != "" {
+		t.Fatalf("first attempt PreviousResponseError = %q, want empty", runner.contexts[0].PreviousResponseError)
+	}
+	if got := runner.contexts[1].PreviousResponseError; !strings.Contains(got, "unexpected end of JSON input") {
+		t.Fatalf("second attempt PreviousResponseError = %q, want the parse error", got)
+	}
+}
+
+func TestRunAttemptsInvalidResponseWithoutRetry(t *testing.T) {
+	t.Parallel()
+
+	role := GetRole(RoleAct)
+	runner := &scriptedRunner{outputs: []string{`not json`, `not json`}}
+
+	if _, err := runAttempts(context.Background(), runner, contracts.AgentRequest{}, 3, io.Discard, io.Discard, responseAcceptor(role, false), nil); err != nil {
+		t.Fatalf("runAttempts() error = %v, want the output returned for the caller to map", err)
+	}
+	if len(runner.contexts) != 1 {
+		t.Fatalf("runs = %d, want 1 when retry_invalid_response is off", len(runner.contexts))
+	}
+
+	runner = &scriptedRunner{outputs: []string{`not json`, `still not json`}}
+	_, err := runAttempts(context.Background(), runner, contracts.AgentRequest{}, 2, io.Discard, io.Discard, responseAcceptor(role, true), nil)
+	if !errors.Is(err, ErrRetryable) {
+		t.Fatalf("runAttempts() error = %v, want ErrRetryable after the last attempt", err)
+	}
+}
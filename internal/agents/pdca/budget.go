@@ -0,0 +1,55 @@
+package pdca
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/metalagman/norma/internal/config"
+	"github.com/metalagman/norma/internal/task"
+)
+
+// labelMaxIterationsPrefix starts a task label overriding budgets.max_iterations, e.g. norma-max-iterations:5.
+const labelMaxIterationsPrefix = "norma-max-iterations:"
+
+// MaxIterationsCeiling caps per-task max iteration overrides.
+const MaxIterationsCeiling = 20
+
+var notesMaxIterationsPattern = regexp.MustCompile(regexp.QuoteMeta(labelMaxIterationsPrefix) + `\s*(\d+)`)
+
+// TaskBudgetOverride returns base with MaxIterations taken from the task's
+// norma-max-iterations:<n> label, or from the same marker in its notes when no label
+// sets it. Overrides are clamped to MaxIterationsCeiling; invalid values are ignored.
+func TaskBudgetOverride(item task.Task, base config.Budgets) config.Budgets {
+	value, ok := labelMaxIterations(item.Labels)
+	if !ok {
+		if match := notesMaxIterationsPattern.FindStringSubmatch(item.Notes); match != nil {
+			value, ok = parseMaxIterations(match[1])
+		}
+	}
+	if ok {
+		base.MaxIterations = min(value, MaxIterationsCeiling)
+	}
+	return base
+}
+
+func labelMaxIterations(labels []string) (int, bool) {
+	for _, label := range labels {
+		raw, found := strings.CutPrefix(strings.TrimSpace(label), labelMaxIterationsPrefix)
+		if !found {
+			continue
+		}
+		if value, ok := parseMaxIterations(raw); ok {
+			return value, true
+		}
+	}
+	return 0, false
+}
+
+func parseMaxIterations(raw string) (int, bool) {
+	value, err := strconv.Atoi(strings.TrimSpace(raw))
+	if err != nil || value < 1 {
+		return 0, false
+	}
+	return value, true
+}
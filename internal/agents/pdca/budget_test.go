@@ -0,0 +1,42 @@
+package pdca
+
+import (
+	"testing"
+
+	"github.com/metalagman/norma/internal/config"
+	"github.com/metalagman/norma/internal/task"
+)
+
+func TestTaskBudgetOverride(t *testing.T) {
+	t.Parallel()
+
+	base := config.Budgets{MaxIterations: 3, MaxDoSteps: 4}
+	tests := []struct {
+		name string
+		item task.Task
+		want int
+	}{
+		{name: "no override", item: task.Task{Labels: []string{"norma-has-plan"}}, want: 3},
+		{name: "label", item: task.Task{Labels: []string{"norma-has-plan", "norma-max-iterations:5"}}, want: 5},
+		{name: "label lowers budget", item: task.Task{Labels: []string{"norma-max-iterations:1"}}, want: 1},
+		{name: "notes", item: task.Task{Notes: "needs a long migration\nnorma-max-iterations: 7\n"}, want: 7},
+		{name: "label wins over notes", item: task.Task{Labels: []string{"norma-max-iterations:6"}, Notes: "norma-max-iterations:9"}, want: 6},
+		{name: "clamped to ceiling", item: task.Task{Labels: []string{"norma-max-iterations:500"}}, want: MaxIterationsCeiling},
+		{name: "invalid label ignored", item: task.Task{Labels: []string{"norma-max-iterations:lots", "norma-max-iterations:0"}}, want: 3},
+		{name: "invalid label falls back to notes", item: task.Task{Labels: []string{"norma-max-iterations:-2"}, Notes: `{"note":"norma-max-iterations:4"}`}, want: 4},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			got := TaskBudgetOverride(tc.item, base)
+			if got.MaxIterations != tc.want {
+				t.Fatalf("MaxIterations = %d, want %d", got.MaxIterations, tc.want)
+			}
+			if got.MaxDoSteps != base.MaxDoSteps {
+				t.Fatalf("MaxDoSteps = %d, want %d unchanged", got.MaxDoSteps, base.MaxDoSteps)
+			}
+		})
+	}
+}
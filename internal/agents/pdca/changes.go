@@ -0,0 +1,45 @@
+package pdca
+
+import (
+	"context"
+	"path/filepath"
+
+	"github.com/metalagman/norma/internal/db"
+	"github.com/metalagman/norma/internal/fsutil"
+	"github.com/metalagman/norma/internal/git"
+)
+
+// stepChanges is the content of a step's artifacts/changes.json.
+type stepChanges struct {
+	BaseRef string           `json:"base_ref"`
+	Changes []git.FileChange `json:"changes"`
+}
+
+// captureStepChanges lists workspace changes made since baseRef and writes them to artifacts/changes.json.
+func captureStepChanges(ctx context.Context, workspaceDir, stepDir, baseRef string) ([]git.FileChange, error) {
+	changes, err := git.WorkspaceChanges(ctx, workspaceDir, baseRef)
+	if err != nil {
+		return nil, err
+	}
+	if err := fsutil.WriteJSONAtomic(filepath.Join(stepDir, "artifacts", "changes.json"), stepChanges{BaseRef: baseRef, Changes: changes}); err != nil {
+		return nil, err
+	}
+	return changes, nil
+}
+
+// changedPaths returns the paths touched by changes, in order.
+func changedPaths(changes []git.FileChange) []string {
+	paths := make([]string, 0, len(changes))
+	for _, change := range changes {
+		paths = append(paths, change.Path)
+	}
+	return paths
+}
+
+func toStepChanges(changes []git.FileChange) []db.StepChange {
+	out := make([]db.StepChange, 0, len(changes))
+	for _, change := range changes {
+		out = append(out, db.StepChange{Path: change.Path, Status: change.Status, OldPath: change.OldPath})
+	}
+	return out
+}
@@ -0,0 +1,121 @@
+package pdca
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/metalagman/norma/internal/agents/pdca/contracts"
+	"github.com/metalagman/norma/internal/agents/pdca/roles/do"
+	"github.com/metalagman/norma/internal/agents/pdca/roles/plan"
+	"github.com/metalagman/norma/internal/git"
+)
+
+func TestCaptureStepChangesMatchesFilesWrittenByDo(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	workspace := t.TempDir()
+	initTestRepo(t, ctx, workspace)
+	writeTestFile(t, filepath.Join(workspace, "keep.txt"), "keep\n")
+	writeTestFile(t, filepath.Join(workspace, "edit.txt"), "before\n")
+	writeTestFile(t, filepath.Join(workspace, "remove.txt"), "remove\n")
+	runGit(t, ctx, workspace, "add", "-A")
+	runGit(t, ctx, workspace, "commit", "-m", "chore: base")
+	base := strings.TrimSpace(runGit(t, ctx, workspace, "rev-parse", "HEAD"))
+
+	// Simulate a Do step editing, adding, and deleting files.
+	writeTestFile(t, filepath.Join(workspace, "edit.txt"), "after\n")
+	if err := os.MkdirAll(filepath.Join(workspace, "pkg"), 0o700); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	writeTestFile(t, filepath.Join(workspace, "pkg", "new.go"), "package pkg\n")
+	if err := os.Remove(filepath.Join(workspace, "remove.txt")); err != nil {
+		t.Fatalf("remove: %v", err)
+	}
+
+	stepDir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(stepDir, "artifacts"), 0o700); err != nil {
+		t.Fatalf("mkdir artifacts: %v", err)
+	}
+
+	got, err := captureStepChanges(ctx, workspace, stepDir, base)
+	if err != nil {
+		t.Fatalf("captureStepChanges() error = %v", err)
+	}
+	want := []git.FileChange{
+		{Status: "M", Path: "edit.txt"},
+		{Status: "A", Path: "pkg/new.go"},
+		{Status: "D", Path: "remove.txt"},
+	}
+	if !slices.Equal(got, want) {
+		t.Fatalf("captureStepChanges() = %+v, want %+v", got, want)
+	}
+
+	data, err := os.ReadFile(filepath.Join(stepDir, "artifacts", "changes.json"))
+	if err != nil {
+		t.Fatalf("read changes.json: %v", err)
+	}
+	var artifact stepChanges
+	if err := json.Unmarshal(data, &artifact); err != nil {
+		t.Fatalf("parse changes.json: %v", err)
+	}
+	if artifact.BaseRef != base || !slices.Equal(artifact.Changes, want) {
+		t.Fatalf("changes.json = %+v, want base %s and %+v", artifact, base, want)
+	}
+}
+
+func TestCheckInputCarriesDoChangedFiles(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	workspace := t.TempDir()
+	initTestRepo(t, ctx, workspace)
+	writeTestFile(t, filepath.Join(workspace, "edit.txt"), "before\n")
+	runGit(t, ctx, workspace, "add", "-A")
+	runGit(t, ctx, workspace, "commit", "-m", "chore: base")
+	base := strings.TrimSpace(runGit(t, ctx, workspace, "rev-parse", "HEAD"))
+
+	writeTestFile(t, filepath.Join(workspace, "edit.txt"), "after\n")
+	writeTestFile(t, filepath.Join(workspace, "added.txt"), "new\n")
+
+	stepDir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(stepDir, "artifacts"), 0o700); err != nil {
+		t.Fatalf("mkdir artifacts: %v", err)
+	}
+	changes, err := captureStepChanges(ctx, workspace, stepDir, base)
+	if err != nil {
+		t.Fatalf("captureStepChanges() error = %v", err)
+	}
+
+	state := &contracts.TaskState{
+		Plan: &plan.PlanOutput{
+			WorkPlan:           &plan.PlanWorkPlan{TimeboxMinutes: 10},
+			AcceptanceCriteria: &plan.PlanOutputAcceptanceCriteria{},
+		},
+		Do:             &do.DoOutput{Execution: &do.DoExecution{}},
+		DoChangedFiles: changedPaths(changes),
+	}
+	input, err := checkInputFromState(state)
+	if err != nil {
+		t.Fatalf("checkInputFromState() error = %v", err)
+	}
+
+	data, err := json.Marshal(input)
+	if err != nil {
+		t.Fatalf("marshal check input: %v", err)
+	}
+	var decoded struct {
+		ChangedFiles []string `json:"changed_files"`
+	}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("parse check input: %v", err)
+	}
+	if want := []string{"added.txt", "edit.txt"}; !slices.Equal(decoded.ChangedFiles, want) {
+		t.Fatalf("check input changed_files = %v, want %v", decoded.ChangedFiles, want)
+	}
+}
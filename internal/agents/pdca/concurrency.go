@@ -0,0 +1,81 @@
+package pdca
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/metalagman/norma/internal/agents/pdca/contracts"
+)
+
+var (
+	// agentSlots bounds the agent processes running at once across all runs in this process.
+	agentSlots = &slotLimiter{}
+	// worktreeSlots bounds the step worktrees mounted at once across all runs in this process.
+	worktreeSlots = &slotLimiter{}
+)
+
+// slotLimiter is a counting semaphore whose limit is supplied on acquire,
+// so runs configured with different limits share one count.
+type slotLimiter struct {
+	mu      sync.Mutex
+	running int
+	changed chan struct{}
+}
+
+// acquire waits until fewer than limit agents are running or ctx is done.
+func (l *slotLimiter) acquire(ctx context.Context, limit int) error {
+	for {
+		l.mu.Lock()
+		if l.running < limit {
+			l.running++
+			l.mu.Unlock()
+			return nil
+		}
+		if l.changed == nil {
+			l.changed = make(chan struct{})
+		}
+		changed := l.changed
+		l.mu.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-changed:
+		}
+	}
+}
+
+func (l *slotLimiter) release() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.running--
+	if l.changed != nil {
+		close(l.changed)
+		l.changed = nil
+	}
+}
+
+// limitedRunner wraps a Runner and holds a slotLimiter slot while Run is in progress.
+type limitedRunner struct {
+	inner   Runner
+	limiter *slotLimiter
+	limit   int
+}
+
+func (r limitedRunner) Run(ctx context.Context, req contracts.AgentRequest, stdout, stderr io.Writer) ([]byte, []byte, int, error) {
+	if err := r.limiter.acquire(ctx, r.limit); err != nil {
+		return nil, nil, 0, fmt.Errorf("wait for agent slot: %w", err)
+	}
+	defer r.limiter.release()
+	return r.inner.Run(ctx, req, stdout, stderr)
+}
+
+// withConcurrencyLimit returns runner unchanged when limit is not positive.
+func withConcurrencyLimit(runner Runner, limiter *slotLimiter, limit int) Runner {
+	if limit <= 0 {
+		return runner
+	}
+	return limitedRunner{inner: runner, limiter: limiter, limit: limit}
+}
@@ -0,0 +1,87 @@
+package pdca
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/metalagman/norma/internal/agents/pdca/contracts"
+)
+
+// trackingRunner records how many Run calls are in flight at once.
+type trackingRunner struct {
+	inFlight atomic.Int32
+	peak     atomic.Int32
+}
+
+func (r *trackingRunner) Run(context.Context, contracts.AgentRequest, io.Writer, io.Writer) ([]byte, []byte, int, error) {
+	n := r.inFlight.Add(1)
+	defer r.inFlight.Add(-1)
+	for {
+		peak := r.peak.Load()
+		if n <= peak || r.peak.CompareAndSwap(peak, n) {
+			break
+		}
+	}
+	time.Sleep(10 * time.Millisecond)
+	return []byte(`{"status":"ok"}`), nil, 0, nil
+}
+
+func TestWithConcurrencyLimitBoundsInFlightRuns(t *testing.T) {
+	t.Parallel()
+
+	const limit = 2
+	inner := &trackingRunner{}
+	limiter := &slotLimiter{}
+	// Two runs share the limiter, as steps of concurrent runs do.
+	runners := []Runner{
+		withConcurrencyLimit(inner, limiter, limit),
+		withConcurrencyLimit(inner, limiter, limit),
+	}
+
+	var wg sync.WaitGroup
+	for i := range 10 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, _, _, err := runners[i%2].Run(context.Background(), contracts.AgentRequest{}, io.Discard, io.Discard); err != nil {
+				t.Errorf("Run() error = %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if peak := inner.peak.Load(); peak > limit || peak == 0 {
+		t.Fatalf("peak in-flight runs = %d, want 1..%d", peak, limit)
+	}
+}
+
+func TestWithConcurrencyLimitStopsWaitingOnCancel(t *testing.T) {
+	t.Parallel()
+
+	limiter := &slotLimiter{}
+	if err := limiter.acquire(context.Background(), 1); err != nil {
+		t.Fatalf("acquire() error = %v", err)
+	}
+	defer limiter.release()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, _, _, err := withConcurrencyLimit(&trackingRunner{}, limiter, 1).Run(ctx, contracts.AgentRequest{}, io.Discard, io.Discard)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Run() error = %v, want %v", err, context.DeadlineExceeded)
+	}
+}
+
+func TestWithConcurrencyLimitDisabled(t *testing.T) {
+	t.Parallel()
+
+	inner := &trackingRunner{}
+	if got := withConcurrencyLimit(inner, &slotLimiter{}, 0); got != Runner(inner) {
+		t.Fatalf("withConcurrencyLimit(limit=0) = %T, want inner runner", got)
+	}
+}
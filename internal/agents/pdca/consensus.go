@@ -0,0 +1,185 @@
+package pdca
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/metalagman/norma/internal/agents/pdca/contracts"
+	"github.com/metalagman/norma/internal/agents/pdca/roles/check"
+	"github.com/metalagman/norma/internal/config"
+	"github.com/metalagman/norma/internal/fsutil"
+	"github.com/rs/zerolog/log"
+)
+
+// Check consensus policies.
+const (
+	CheckConsensusAll      = "all"
+	CheckConsensusMajority = "majority"
+)
+
+// consensusVoteError is the vote of a consensus agent that produced no verdict.
+const consensusVoteError = "ERROR"
+
+// consensusFileName records the votes of a Check step's consensus in its step directory.
+const consensusFileName = "consensus.json"
+
+// checkVote is one agent's verdict in a Check consensus.
+type checkVote struct {
+	Agent   string `json:"agent"`
+	Verdict string `json:"verdict"`
+	Error   string `json:"error,omitempty"`
+}
+
+// checkConsensusRecord is the content of consensus.json.
+type checkConsensusRecord struct {
+	Policy  string      `json:"policy"`
+	Reached bool        `json:"reached"`
+	Votes   []checkVote `json:"votes"`
+}
+
+// consensusAgents returns the agents configured in check_consensus.agents, failing
+// on names missing from the agents registry.
+func consensusAgents(cfg config.Config) ([]string, error) {
+	names := make([]string, 0, len(cfg.CheckConsensus.Agents))
+	for _, name := range cfg.CheckConsensus.Agents {
+		name = strings.TrimSpace(name)
+		if _, ok := cfg.Agents[name]; !ok {
+			return nil, fmt.Errorf("check consensus agent %q: agent is not configured", name)
+		}
+		names = append(names, name)
+	}
+	return names, nil
+}
+
+// checkConsensusReached reports whether votes accept a PASS under policy:
+// majority needs more than half of the votes to be PASS, all needs every vote.
+func checkConsensusReached(votes []checkVote, policy string) bool {
+	passed := 0
+	for _, vote := range votes {
+		if strings.EqualFold(vote.Verdict, check.VerdictPass) {
+			passed++
+		}
+	}
+	if strings.EqualFold(strings.TrimSpace(policy), CheckConsensusMajority) {
+		return passed*2 > len(votes)
+	}
+	return len(votes) > 0 && passed == len(votes)
+}
+
+// applyCheckConsensus records votes in the Check response and turns its PASS verdict
+// into FAIL when the votes do not reach consensus under policy. It returns whether
+// consensus was reached.
+func applyCheckConsensus(resp *contracts.AgentResponse, votes []checkVote, policy string) bool {
+	reached := checkConsensusReached(votes, policy)
+	cast := make([]string, 0, len(votes))
+	for _, vote := range votes {
+		cast = append(cast, vote.Agent+"="+vote.Verdict)
+	}
+	resp.Progress.Details = append(resp.Progress.Details, "check consensus votes: "+strings.Join(cast, ", "))
+	if reached || resp.Check == nil || resp.Check.Verdict == nil {
+		return reached
+	}
+	resp.Check.Verdict.Status = check.VerdictFail
+	if resp.Check.Verdict.Basis != nil {
+		resp.Check.Verdict.Basis.AllAcceptancePassed = false
+	}
+	resp.Progress.Details = append(resp.Progress.Details,
+		fmt.Sprintf("verdict downgraded to %s: check consensus (%s) not reached", check.VerdictFail, consensusPolicy(policy)))
+	return false
+}
+
+// consensusPolicy returns the effective consensus policy name.
+func consensusPolicy(policy string) string {
+	if strings.EqualFold(strings.TrimSpace(policy), CheckConsensusMajority) {
+		return CheckConsensusMajority
+	}
+	return CheckConsensusAll
+}
+
+// runCheckConsensus asks every consensus agent for a verdict on the Check request
+// req and applies the consensus policy to the Check response resp, which casts the
+// first vote. Each agent runs in stepDir/consensus/<agent> against the Check
+// workspace. Votes are written to consensus.json in stepDir.
+func (a *runtime) runCheckConsensus(ctx context.Context, req contracts.AgentRequest, resp *contracts.AgentResponse, stepDir string, iteration int) error {
+	votes := []checkVote{{Agent: RoleCheck, Verdict: resp.Check.Verdict.Status}}
+	for _, name := range a.consensus {
+		invoke := a.invokeConsensusAgent
+		if a.overrideRunConsensusAgent != nil {
+			invoke = a.overrideRunConsensusAgent
+		}
+		vote := checkVote{Agent: name, Verdict: consensusVoteError}
+		agentResp, err := invoke(ctx, req, filepath.Join(stepDir, "consensus", name), name, iteration)
+		switch {
+		case err != nil:
+			log.Warn().Err(err).Str("agent", name).Msg("check consensus agent failed")
+			vote.Error = err.Error()
+		case agentResp.Check == nil || agentResp.Check.Verdict == nil:
+			vote.Error = fmt.Sprintf("no verdict (status %s)", agentResp.Status)
+		default:
+			vote.Verdict = agentResp.Check.Verdict.Status
+		}
+		votes = append(votes, vote)
+	}
+
+	reached := applyCheckConsensus(resp, votes, a.cfg.CheckConsensus.Policy)
+	record := checkConsensusRecord{Policy: consensusPolicy(a.cfg.CheckConsensus.Policy), Reached: reached, Votes: votes}
+	return fsutil.WriteJSONAtomic(filepath.Join(stepDir, consensusFileName), record)
+}
+
+// invokeConsensusAgent runs the agent name with the Check contract in dir.
+func (a *runtime) invokeConsensusAgent(ctx context.Context, req contracts.AgentRequest, dir, name string, iteration int) (*contracts.AgentResponse, error) {
+	if err := os.MkdirAll(filepath.Join(dir, "logs"), 0o700); err != nil {
+		return nil, err
+	}
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, fmt.Errorf("resolve consensus dir path: %w", err)
+	}
+	req.Paths.RunDir = absDir
+	if err := fsutil.WriteJSONAtomic(filepath.Join(dir, "input.json"), req); err != nil {
+		return nil, err
+	}
+
+	role := a.role(RoleCheck)
+	agentCfg := resolveModel(a.cfg.Agents[name], iteration)
+	runner, err := NewRunner(agentCfg, role,
+		WithShutdownGrace(time.Duration(a.cfg.AgentShutdownGrace)*time.Second),
+		WithSafetyProfile(a.cfg.Safety.Profile),
+		WithSystemPromptPreamble(a.cfg.SystemPromptPreamble),
+		WithSanitizeOutput(a.cfg.SanitizeAgentOutput),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("create runner for check consensus agent %q: %w", name, err)
+	}
+	runner = withConcurrencyLimit(runner, agentSlots, a.cfg.MaxConcurrentAgents)
+
+	stdoutFile, err := os.Create(filepath.Join(dir, "logs", "stdout.txt"))
+	if err != nil {
+		return nil, fmt.Errorf("create stdout log file: %w", err)
+	}
+	defer func() { _ = stdoutFile.Close() }()
+	stderrFile, err := os.Create(filepath.Join(dir, "logs", "stderr.txt"))
+	if err != nil {
+		return nil, fmt.Errorf("create stderr log file: %w", err)
+	}
+	defer func() { _ = stderrFile.Close() }()
+
+	out, err := runAttempts(ctx, runner, req, agentCfg.Attempts(), stdoutFile, stderrFile, responseAcceptor(role, a.cfg.RetryInvalidResponse), func(attempt int, err error) {
+		log.Warn().Err(err).Str("agent", name).Int("attempt", attempt).Msg("check consensus agent failed, retrying")
+	})
+	if err != nil {
+		return nil, fmt.Errorf("run check consensus agent %q: %w", name, err)
+	}
+	resp, err := role.MapResponse(out)
+	if err != nil {
+		return nil, fmt.Errorf("map check consensus response: %w", err)
+	}
+	if err := fsutil.WriteJSONAtomic(filepath.Join(dir, "output.json"), resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
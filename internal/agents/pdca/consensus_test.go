@@ -0,0 +1,142 @@
+package pdca
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/metalagman/norma/internal/agents/pdca/contracts"
+	"github.com/metalagman/norma/internal/agents/pdca/roles/check"
+	"github.com/metalagman/norma/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckConsensusReached(t *testing.T) {
+	t.Parallel()
+
+	votes := func(verdicts ...string) []checkVote {
+		out := make([]checkVote, 0, len(verdicts))
+		for _, verdict := range verdicts {
+			out = append(out, checkVote{Verdict: verdict})
+		}
+		return out
+	}
+	tests := []struct {
+		name   string
+		votes  []checkVote
+		policy string
+		want   bool
+	}{
+		{name: "all agree", votes: votes("PASS", "PASS", "PASS"), want: true},
+		{name: "all with one dissent", votes: votes("PASS", "FAIL", "PASS"), want: false},
+		{name: "majority with one dissent", votes: votes("PASS", "FAIL", "PASS"), policy: CheckConsensusMajority, want: true},
+		{name: "majority tie", votes: votes("PASS", "FAIL"), policy: CheckConsensusMajority, want: false},
+		{name: "majority with error vote", votes: votes("PASS", consensusVoteError, "FAIL"), policy: CheckConsensusMajority, want: false},
+		{name: "no votes", want: false},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, tc.want, checkConsensusReached(tc.votes, tc.policy))
+		})
+	}
+}
+
+func consensusCheckResponse(t *testing.T, verdict string) string {
+	t.Helper()
+	resp := map[string]any{
+		"status":   "ok",
+		"summary":  map[string]any{"text": "checked"},
+		"progress": map[string]any{"title": "check done", "details": []string{}},
+		"check_output": map[string]any{
+			"acceptance_results": []map[string]any{{"ac_id": "AC1", "result": verdict}},
+			"verdict":            map[string]any{"status": verdict, "recommendation": "none", "basis": map[string]any{}},
+		},
+	}
+	data, err := json.Marshal(resp)
+	require.NoError(t, err)
+	return string(data)
+}
+
+func TestRunCheckConsensusWithDisagreeingAgents(t *testing.T) {
+	tests := []struct {
+		name        string
+		policy      string
+		wantVerdict string
+		wantReached bool
+	}{
+		{name: "all rejects dissent", policy: CheckConsensusAll, wantVerdict: check.VerdictFail},
+		{name: "majority accepts dissent", policy: CheckConsensusMajority, wantVerdict: check.VerdictPass, wantReached: true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			input, err := os.ReadFile(filepath.Join("roles", "testdata", "roundtrip", "check.request.json"))
+			require.NoError(t, err)
+			var req contracts.AgentRequest
+			require.NoError(t, json.Unmarshal(input, &req))
+			req.Paths.WorkspaceDir = t.TempDir()
+
+			a := &runtime{
+				cfg: config.Config{
+					Agents: map[string]config.AgentConfig{
+						"strict":  {Type: config.AgentTypeGenericACP, Cmd: helperACPCommand(t, consensusCheckResponse(t, check.VerdictFail))},
+						"lenient": {Type: config.AgentTypeGenericACP, Cmd: helperACPCommand(t, consensusCheckResponse(t, check.VerdictPass))},
+					},
+					CheckConsensus: config.CheckConsensusConfig{Agents: []string{"strict", "lenient"}, Policy: tc.policy},
+				},
+				consensus: []string{"strict", "lenient"},
+			}
+			resp := &contracts.AgentResponse{
+				Status: "ok",
+				Check: &check.CheckOutput{
+					Verdict: &check.CheckVerdict{Status: check.VerdictPass, Basis: &check.CheckVerdictBasis{AllAcceptancePassed: true}},
+				},
+			}
+
+			stepDir := t.TempDir()
+			require.NoError(t, a.runCheckConsensus(context.Background(), req, resp, stepDir, 1))
+			assert.Equal(t, tc.wantVerdict, resp.Check.Verdict.Status)
+
+			data, err := os.ReadFile(filepath.Join(stepDir, consensusFileName))
+			require.NoError(t, err)
+			var record checkConsensusRecord
+			require.NoError(t, json.Unmarshal(data, &record))
+			assert.Equal(t, tc.policy, record.Policy)
+			assert.Equal(t, tc.wantReached, record.Reached)
+			assert.Equal(t, []checkVote{
+				{Agent: RoleCheck, Verdict: check.VerdictPass},
+				{Agent: "strict", Verdict: check.VerdictFail},
+				{Agent: "lenient", Verdict: check.VerdictPass},
+			}, record.Votes)
+
+			_, err = os.Stat(filepath.Join(stepDir, "consensus", "strict", "output.json"))
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestRunCheckConsensusCountsAgentErrors(t *testing.T) {
+	t.Parallel()
+
+	a := &runtime{
+		cfg:       config.Config{CheckConsensus: config.CheckConsensusConfig{Policy: CheckConsensusMajority}},
+		consensus: []string{"broken"},
+		overrideRunConsensusAgent: func(context.Context, contracts.AgentRequest, string, string, int) (*contracts.AgentResponse, error) {
+			return nil, assert.AnError
+		},
+	}
+	resp := &contracts.AgentResponse{Status: "ok", Check: &check.CheckOutput{Verdict: &check.CheckVerdict{Status: check.VerdictPass}}}
+
+	require.NoError(t, a.runCheckConsensus(context.Background(), contracts.AgentRequest{}, resp, t.TempDir(), 1))
+	assert.Equal(t, check.VerdictFail, resp.Check.Verdict.Status)
+}
+
+func TestConsensusAgentsRejectsUnknownAgent(t *testing.T) {
+	t.Parallel()
+
+	_, err := consensusAgents(config.Config{CheckConsensus: config.CheckConsensusConfig{Agents: []string{"missing"}}})
+	require.Error(t, err)
+}
@@ -13,6 +13,7 @@ type Budgets struct {
 	MaxIterations      int `json:"max_iterations"`
 	MaxWallTimeMinutes int `json:"max_wall_time_minutes,omitempty"`
 	MaxFailedChecks    int `json:"max_failed_checks,omitempty"`
+	MaxDoSteps         int `json:"max_do_steps,omitempty"`
 }
 
 // AgentRequest is the normalized request passed to agents.
@@ -45,6 +46,8 @@ type TaskInfo struct {
 	Title              string                     `json:"title"`
 	Description        string                     `json:"description"`
 	AcceptanceCriteria []task.AcceptanceCriterion `json:"acceptance_criteria"`
+	// SeededChecks holds checks derived from verify hints, keyed by acceptance criterion id.
+	SeededChecks map[string][]plan.CriterionCheck `json:"seeded_checks,omitempty"`
 }
 
 // StepInfo identifies the step in the run.
@@ -61,9 +64,12 @@ type RequestPaths struct {
 
 // RequestContext supplies artifacts from previous steps and optional notes.
 type RequestContext struct {
-	Facts   map[string]any `json:"facts"`
-	Links   []string       `json:"links"`
-	Attempt int            `json:"attempt,omitempty"`
+	Facts                 map[string]any `json:"facts"`
+	Links                 []string       `json:"links"`
+	Attempt               int            `json:"attempt,omitempty"`
+	FailureDigest         string         `json:"failure_digest,omitempty"`
+	Constraints           []string       `json:"constraints,omitempty"`
+	PreviousResponseError string         `json:"previous_response_error,omitempty"`
 }
 
 // AgentResponse is the normalized stdout response from agents.
@@ -93,13 +99,37 @@ type StepProgress struct {
 
 // TaskState is stored in task notes to persist step outputs and journal across runs.
 type TaskState struct {
-	Plan    *plan.PlanOutput   `json:"plan,omitempty"`
-	Do      *do.DoOutput       `json:"do,omitempty"`
-	Check   *check.CheckOutput `json:"check,omitempty"`
-	Act     *act.ActOutput     `json:"act,omitempty"`
-	Journal []JournalEntry     `json:"journal,omitempty"`
+	Plan           *plan.PlanOutput   `json:"plan,omitempty"`
+	Do             *do.DoOutput       `json:"do,omitempty"`
+	Check          *check.CheckOutput `json:"check,omitempty"`
+	Act            *act.ActOutput     `json:"act,omitempty"`
+	Journal        []JournalEntry     `json:"journal,omitempty"`
+	DoCommits      []string           `json:"do_commits,omitempty"`
+	DoChangedFiles []string           `json:"do_changed_files,omitempty"`
+	ProcessNotes   []ProcessNote      `json:"process_notes,omitempty"`
 }
 
+// ProcessNoteSeverityHigh marks a process note that needs attention before the task is trusted.
+const ProcessNoteSeverityHigh = "high"
+
+// ProcessNote records an orchestrator observation about how a step ran, as opposed to what it produced.
+type ProcessNote struct {
+	Timestamp string `json:"timestamp"`
+	RunID     string `json:"run_id,omitempty"`
+	StepIndex int    `json:"step_index"`
+	Role      string `json:"role"`
+	Severity  string `json:"severity"`
+	Text      string `json:"text"`
+}
+
+// Journal entry types; regular steps leave the type empty.
+const (
+	// JournalEntrySkipped marks a step reused from an earlier run instead of being run.
+	JournalEntrySkipped = "skipped"
+	// JournalEntryObserver marks the output of an observer agent, which never affects control flow.
+	JournalEntryObserver = "observer"
+)
+
 // JournalEntry records detailed progress for a single step.
 type JournalEntry struct {
 	Timestamp  string   `json:"timestamp"`
@@ -107,8 +137,10 @@ type JournalEntry struct {
 	Iteration  int      `json:"iteration,omitempty"`
 	StepIndex  int      `json:"step_index"`
 	Role       string   `json:"role"`
+	Type       string   `json:"type,omitempty"`
 	Status     string   `json:"status"`
 	StopReason string   `json:"stop_reason"`
+	Reason     string   `json:"reason,omitempty"`
 	Title      string   `json:"title"`
 	Details    []string `json:"details"`
 }
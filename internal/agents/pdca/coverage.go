@@ -0,0 +1,198 @@
+package pdca
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/metalagman/norma/internal/agents/pdca/contracts"
+	"github.com/metalagman/norma/internal/agents/pdca/roles/check"
+	"github.com/metalagman/norma/internal/agents/pdca/roles/plan"
+)
+
+// Dangling AC reference modes for plan validation.
+const (
+	ACRefsModeWarn  = "warn"
+	ACRefsModeError = "error"
+)
+
+// Do step overflow policies for plans exceeding the max_do_steps budget.
+const (
+	DoStepsOverflowTruncate = "truncate"
+	DoStepsOverflowStop     = "stop"
+)
+
+// Policies for an ok plan without Do steps or effective acceptance criteria.
+const (
+	EmptyPlanStop = "stop"
+	EmptyPlanWarn = "warn"
+)
+
+// ACResultSkipped marks an effective acceptance criterion the Check step did not report on.
+const ACResultSkipped = "SKIPPED"
+
+// PlanCoverage describes how a plan's Do steps relate to its effective acceptance criteria.
+type PlanCoverage struct {
+	// Targeted maps effective AC ids to the Do step ids targeting them.
+	Targeted map[string][]string
+	// Uncovered lists effective AC ids no Do step targets, in plan order.
+	Uncovered []string
+	// Dangling lists Do step references to AC ids missing from the effective criteria.
+	Dangling []DanglingACRef
+}
+
+// DanglingACRef is a Do step reference to an unknown acceptance criterion.
+type DanglingACRef struct {
+	StepID string
+	ACID   string
+}
+
+// AnalyzePlanCoverage collects AC references from the plan's Do steps.
+func AnalyzePlanCoverage(out *plan.PlanOutput) PlanCoverage {
+	coverage := PlanCoverage{Targeted: map[string][]string{}}
+	if out == nil {
+		return coverage
+	}
+
+	known := map[string]bool{}
+	order := make([]string, 0)
+	if out.AcceptanceCriteria != nil {
+		for _, ac := range out.AcceptanceCriteria.Effective {
+			if !known[ac.Id] {
+				known[ac.Id] = true
+				order = append(order, ac.Id)
+			}
+		}
+	}
+
+	if out.WorkPlan != nil {
+		for _, step := range out.WorkPlan.DoSteps {
+			for _, acID := range step.TargetsAcIds {
+				if !known[acID] {
+					coverage.Dangling = append(coverage.Dangling, DanglingACRef{StepID: step.Id, ACID: acID})
+					continue
+				}
+				coverage.Targeted[acID] = append(coverage.Targeted[acID], step.Id)
+			}
+		}
+	}
+
+	for _, id := range order {
+		if len(coverage.Targeted[id]) == 0 {
+			coverage.Uncovered = append(coverage.Uncovered, id)
+		}
+	}
+	return coverage
+}
+
+// validatePlanACRefs reports Do step references to unknown acceptance criteria.
+// It returns an error in ACRefsModeError and nil otherwise, along with the dangling refs.
+func validatePlanACRefs(out *plan.PlanOutput, mode string) ([]DanglingACRef, error) {
+	dangling := AnalyzePlanCoverage(out).Dangling
+	if len(dangling) == 0 {
+		return nil, nil
+	}
+	if strings.EqualFold(strings.TrimSpace(mode), ACRefsModeError) {
+		refs := make([]string, 0, len(dangling))
+		for _, ref := range dangling {
+			refs = append(refs, fmt.Sprintf("%s->%s", ref.StepID, ref.ACID))
+		}
+		return dangling, fmt.Errorf("plan references unknown acceptance criteria: %s", strings.Join(refs, ", "))
+	}
+	return dangling, nil
+}
+
+// enforceMaxDoSteps applies the max_do_steps budget to an ok Plan response.
+// The truncate policy keeps the first maxSteps Do steps in plan order; the stop policy
+// turns the response into a replan_required stop. It returns the original Do step
+// count and whether the budget was exceeded.
+func enforceMaxDoSteps(resp *contracts.AgentResponse, maxSteps int, policy string) (int, bool) {
+	if resp == nil || resp.Status != "ok" || resp.Plan == nil || resp.Plan.WorkPlan == nil || maxSteps <= 0 {
+		return 0, false
+	}
+	steps := resp.Plan.WorkPlan.DoSteps
+	if len(steps) <= maxSteps {
+		return len(steps), false
+	}
+
+	if strings.EqualFold(strings.TrimSpace(policy), DoStepsOverflowStop) {
+		resp.Status = "stop"
+		resp.StopReason = "replan_required"
+		resp.Progress.Details = append(resp.Progress.Details,
+			fmt.Sprintf("plan has %d do steps, over max_do_steps %d; replan required", len(steps), maxSteps))
+		return len(steps), true
+	}
+
+	resp.Plan.WorkPlan.DoSteps = steps[:maxSteps:maxSteps]
+	resp.Progress.Details = append(resp.Progress.Details,
+		fmt.Sprintf("plan has %d do steps, truncated to max_do_steps %d", len(steps), maxSteps))
+	return len(steps), true
+}
+
+// enforceNonEmptyPlan checks that an ok Plan response has at least one Do step and one
+// effective acceptance criterion. The stop policy (default) turns an empty plan into a
+// replan_required stop; warn leaves it running. It returns what the plan is missing.
+func enforceNonEmptyPlan(resp *contracts.AgentResponse, policy string) []string {
+	if resp == nil || resp.Status != "ok" {
+		return nil
+	}
+	var missing []string
+	if resp.Plan == nil || resp.Plan.WorkPlan == nil || len(resp.Plan.WorkPlan.DoSteps) == 0 {
+		missing = append(missing, "do_steps")
+	}
+	if resp.Plan == nil || resp.Plan.AcceptanceCriteria == nil || len(resp.Plan.AcceptanceCriteria.Effective) == 0 {
+		missing = append(missing, "effective acceptance criteria")
+	}
+	if len(missing) == 0 || strings.EqualFold(strings.TrimSpace(policy), EmptyPlanWarn) {
+		return missing
+	}
+
+	resp.Status = "stop"
+	resp.StopReason = "replan_required"
+	resp.Progress.Details = append(resp.Progress.Details,
+		fmt.Sprintf("plan has no %s; replan required", strings.Join(missing, " and no ")))
+	return missing
+}
+
+// enforceACCoverage records a SKIPPED result for every effective acceptance criterion
+// the Check response has no result for, in effective order. When requireFull is set,
+// a PASS verdict is replaced by the verdict for the aggregate score with the skipped
+// criteria counted as failing. It returns the skipped AC ids.
+func enforceACCoverage(resp *contracts.AgentResponse, effective []check.CheckEffectiveAcceptanceCriteria, requireFull bool) []string {
+	if resp == nil || resp.Check == nil {
+		return nil
+	}
+	reported := make(map[string]bool, len(resp.Check.AcceptanceResults))
+	for _, result := range resp.Check.AcceptanceResults {
+		reported[result.AcId] = true
+	}
+	var skipped []string
+	for _, ac := range effective {
+		if reported[ac.Id] {
+			continue
+		}
+		reported[ac.Id] = true
+		skipped = append(skipped, ac.Id)
+		resp.Check.AcceptanceResults = append(resp.Check.AcceptanceResults, check.CheckAcceptanceResult{
+			AcId:   ac.Id,
+			Result: ACResultSkipped,
+			Notes:  "no result reported by check",
+		})
+	}
+	if len(skipped) == 0 {
+		return nil
+	}
+	resp.Progress.Details = append(resp.Progress.Details,
+		fmt.Sprintf("check reported no result for acceptance criteria: %s", strings.Join(skipped, ", ")))
+
+	verdict := resp.Check.Verdict
+	if !requireFull || verdict == nil || !strings.EqualFold(strings.TrimSpace(verdict.Status), check.VerdictPass) {
+		return skipped
+	}
+	verdict.Status = check.VerdictForScore(check.AggregateScore(resp.Check.AcceptanceResults))
+	if verdict.Basis != nil {
+		verdict.Basis.AllAcceptancePassed = false
+	}
+	resp.Progress.Details = append(resp.Progress.Details,
+		fmt.Sprintf("verdict downgraded to %s: require_full_ac_coverage is set", verdict.Status))
+	return skipped
+}
@@ -0,0 +1,232 @@
+package pdca
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/metalagman/norma/internal/agents/pdca/contracts"
+	"github.com/metalagman/norma/internal/agents/pdca/roles/check"
+	"github.com/metalagman/norma/internal/agents/pdca/roles/plan"
+)
+
+func coveragePlan(targets ...[]string) *plan.PlanOutput {
+	steps := make([]plan.PlanDoStep, 0, len(targets))
+	for i, ids := range targets {
+		steps = append(steps, plan.PlanDoStep{Id: string(rune('1' + i)), TargetsAcIds: ids})
+	}
+	return &plan.PlanOutput{
+		AcceptanceCriteria: &plan.PlanOutputAcceptanceCriteria{
+			Effective: []plan.EffectiveAcceptanceCriteria{{Id: "AC1"}, {Id: "AC2"}, {Id: "AC3"}},
+		},
+		WorkPlan: &plan.PlanWorkPlan{DoSteps: steps},
+	}
+}
+
+func TestAnalyzePlanCoverage(t *testing.T) {
+	t.Parallel()
+
+	got := AnalyzePlanCoverage(coveragePlan([]string{"AC1"}, []string{"AC1", "AC9"}))
+
+	if !slices.Equal(got.Targeted["AC1"], []string{"1", "2"}) {
+		t.Fatalf("Targeted[AC1] = %v, want [1 2]", got.Targeted["AC1"])
+	}
+	if !slices.Equal(got.Uncovered, []string{"AC2", "AC3"}) {
+		t.Fatalf("Uncovered = %v, want [AC2 AC3]", got.Uncovered)
+	}
+	want := []DanglingACRef{{StepID: "2", ACID: "AC9"}}
+	if !slices.Equal(got.Dangling, want) {
+		t.Fatalf("Dangling = %v, want %v", got.Dangling, want)
+	}
+}
+
+func TestValidatePlanACRefs(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name         string
+		out          *plan.PlanOutput
+		mode         string
+		wantDangling int
+		wantErr      bool
+	}{
+		{name: "valid refs", out: coveragePlan([]string{"AC1", "AC2"}, []string{"AC3"}), mode: ACRefsModeError},
+		{name: "dangling warns by default", out: coveragePlan([]string{"AC1", "AC7"}), wantDangling: 1},
+		{name: "dangling warn mode", out: coveragePlan([]string{"AC7"}), mode: ACRefsModeWarn, wantDangling: 1},
+		{name: "dangling error mode", out: coveragePlan([]string{"AC7"}, []string{"AC8"}), mode: ACRefsModeError, wantDangling: 2, wantErr: true},
+		{name: "nil plan", out: nil, mode: ACRefsModeError},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			dangling, err := validatePlanACRefs(tc.out, tc.mode)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("validatePlanACRefs() error = %v, wantErr %v", err, tc.wantErr)
+			}
+			if len(dangling) != tc.wantDangling {
+				t.Fatalf("len(dangling) = %d, want %d: %v", len(dangling), tc.wantDangling, dangling)
+			}
+		})
+	}
+}
+
+func TestEnforceMaxDoSteps(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name         string
+		steps        int
+		policy       string
+		wantExceeded bool
+		wantStatus   string
+		wantReason   string
+		wantSteps    []string
+	}{
+		{name: "within_cap_truncate", steps: 2, policy: DoStepsOverflowTruncate, wantStatus: "ok", wantSteps: []string{"1", "2"}},
+		{name: "within_cap_stop", steps: 3, policy: DoStepsOverflowStop, wantStatus: "ok", wantSteps: []string{"1", "2", "3"}},
+		{name: "over_cap_truncate", steps: 5, policy: DoStepsOverflowTruncate, wantExceeded: true, wantStatus: "ok", wantSteps: []string{"1", "2", "3"}},
+		{name: "over_cap_default", steps: 4, policy: "", wantExceeded: true, wantStatus: "ok", wantSteps: []string{"1", "2", "3"}},
+		{name: "over_cap_stop", steps: 5, policy: DoStepsOverflowStop, wantExceeded: true, wantStatus: "stop", wantReason: "replan_required", wantSteps: []string{"1", "2", "3", "4", "5"}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			targets := make([][]string, tc.steps)
+			resp := &contracts.AgentResponse{Status: "ok", Plan: coveragePlan(targets...)}
+
+			total, exceeded := enforceMaxDoSteps(resp, 3, tc.policy)
+			if exceeded != tc.wantExceeded {
+				t.Fatalf("exceeded = %v, want %v", exceeded, tc.wantExceeded)
+			}
+			if total != tc.steps {
+				t.Fatalf("total = %d, want %d", total, tc.steps)
+			}
+			if resp.Status != tc.wantStatus || resp.StopReason != tc.wantReason {
+				t.Fatalf("status = %q/%q, want %q/%q", resp.Status, resp.StopReason, tc.wantStatus, tc.wantReason)
+			}
+			ids := make([]string, 0, len(resp.Plan.WorkPlan.DoSteps))
+			for _, step := range resp.Plan.WorkPlan.DoSteps {
+				ids = append(ids, step.Id)
+			}
+			if !slices.Equal(ids, tc.wantSteps) {
+				t.Fatalf("do steps = %v, want %v", ids, tc.wantSteps)
+			}
+			if tc.wantExceeded && len(resp.Progress.Details) == 0 {
+				t.Fatal("progress details empty, want overflow warning")
+			}
+		})
+	}
+}
+
+func TestEnforceMaxDoStepsUnlimited(t *testing.T) {
+	t.Parallel()
+
+	resp := &contracts.AgentResponse{Status: "ok", Plan: coveragePlan(nil, nil, nil, nil)}
+	if _, exceeded := enforceMaxDoSteps(resp, 0, DoStepsOverflowStop); exceeded {
+		t.Fatal("exceeded = true with max_do_steps 0, want unlimited")
+	}
+}
+
+func TestEnforceNonEmptyPlan(t *testing.T) {
+	t.Parallel()
+
+	noCriteria := coveragePlan([]string{"AC1"})
+	noCriteria.AcceptanceCriteria.Effective = nil
+
+	tests := []struct {
+		name        string
+		plan        *plan.PlanOutput
+		policy      string
+		wantMissing []string
+		wantStatus  string
+		wantReason  string
+	}{
+		{name: "complete", plan: coveragePlan([]string{"AC1"}), wantStatus: "ok"},
+		{name: "empty_work_plan_default", plan: coveragePlan(), wantMissing: []string{"do_steps"}, wantStatus: "stop", wantReason: "replan_required"},
+		{name: "empty_work_plan_stop", plan: coveragePlan(), policy: EmptyPlanStop, wantMissing: []string{"do_steps"}, wantStatus: "stop", wantReason: "replan_required"},
+		{name: "empty_work_plan_warn", plan: coveragePlan(), policy: EmptyPlanWarn, wantMissing: []string{"do_steps"}, wantStatus: "ok"},
+		{name: "no_criteria", plan: noCriteria, wantMissing: []string{"effective acceptance criteria"}, wantStatus: "stop", wantReason: "replan_required"},
+		{name: "missing_plan_output", wantMissing: []string{"do_steps", "effective acceptance criteria"}, wantStatus: "stop", wantReason: "replan_required"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			resp := &contracts.AgentResponse{Status: "ok", Plan: tc.plan}
+			missing := enforceNonEmptyPlan(resp, tc.policy)
+			if !slices.Equal(missing, tc.wantMissing) {
+				t.Fatalf("missing = %v, want %v", missing, tc.wantMissing)
+			}
+			if resp.Status != tc.wantStatus || resp.StopReason != tc.wantReason {
+				t.Fatalf("status = %q/%q, want %q/%q", resp.Status, resp.StopReason, tc.wantStatus, tc.wantReason)
+			}
+			if stopped := len(resp.Progress.Details) > 0; stopped != (tc.wantStatus == "stop") {
+				t.Fatalf("progress details = %v, want a detail only when stopped", resp.Progress.Details)
+			}
+		})
+	}
+
+	stopped := &contracts.AgentResponse{Status: "stop", StopReason: "dependency_blocked"}
+	if missing := enforceNonEmptyPlan(stopped, EmptyPlanStop); missing != nil || stopped.StopReason != "dependency_blocked" {
+		t.Fatalf("enforceNonEmptyPlan() changed a stopped plan: missing=%v reason=%q", missing, stopped.StopReason)
+	}
+}
+
+func TestEnforceACCoverage(t *testing.T) {
+	t.Parallel()
+
+	effective := []check.CheckEffectiveAcceptanceCriteria{{Id: "AC1"}, {Id: "AC2"}, {Id: "AC3"}}
+	newResp := func(results ...check.CheckAcceptanceResult) *contracts.AgentResponse {
+		return &contracts.AgentResponse{
+			Status: "ok",
+			Check: &check.CheckOutput{
+				AcceptanceResults: results,
+				Verdict:           &check.CheckVerdict{Status: check.VerdictPass, Basis: &check.CheckVerdictBasis{AllAcceptancePassed: true}},
+			},
+		}
+	}
+	pass := func(id string) check.CheckAcceptanceResult {
+		return check.CheckAcceptanceResult{AcId: id, Result: check.VerdictPass}
+	}
+
+	tests := []struct {
+		name        string
+		resp        *contracts.AgentResponse
+		requireFull bool
+		wantSkipped []string
+		wantVerdict string
+	}{
+		{name: "full coverage", resp: newResp(pass("AC1"), pass("AC2"), pass("AC3")), requireFull: true, wantVerdict: check.VerdictPass},
+		{name: "partial coverage recorded only", resp: newResp(pass("AC1")), wantSkipped: []string{"AC2", "AC3"}, wantVerdict: check.VerdictPass},
+		{name: "partial coverage downgrades pass", resp: newResp(pass("AC1")), requireFull: true, wantSkipped: []string{"AC2", "AC3"}, wantVerdict: check.VerdictPartial},
+		{name: "no results fails", resp: newResp(), requireFull: true, wantSkipped: []string{"AC1", "AC2", "AC3"}, wantVerdict: check.VerdictFail},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			skipped := enforceACCoverage(tc.resp, effective, tc.requireFull)
+			if !slices.Equal(skipped, tc.wantSkipped) {
+				t.Fatalf("enforceACCoverage() skipped = %v, want %v", skipped, tc.wantSkipped)
+			}
+			if got := tc.resp.Check.Verdict.Status; got != tc.wantVerdict {
+				t.Fatalf("verdict = %q, want %q", got, tc.wantVerdict)
+			}
+			if got := len(tc.resp.Check.AcceptanceResults); got != len(effective) {
+				t.Fatalf("acceptance results = %d, want %d", got, len(effective))
+			}
+			for _, result := range tc.resp.Check.AcceptanceResults {
+				if slices.Contains(tc.wantSkipped, result.AcId) && result.Result != ACResultSkipped {
+					t.Fatalf("result for %s = %q, want %q", result.AcId, result.Result, ACResultSkipped)
+				}
+			}
+			if tc.requireFull && len(tc.wantSkipped) > 0 && tc.resp.Check.Verdict.Basis.AllAcceptancePassed {
+				t.Fatal("all_acceptance_passed still set after downgrade")
+			}
+		})
+	}
+}
@@ -0,0 +1,131 @@
+package pdca
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/metalagman/norma/internal/agents/pdca/contracts"
+)
+
+// maxDigestBlockers caps how many recent blockers are listed in a failure digest.
+const maxDigestBlockers = 5
+
+// SummarizeFailures condenses the last Check's failed acceptance criteria, blockers
+// from the journal, and process notes into a compact digest for the planner.
+// It returns an empty string when there is nothing to report.
+func SummarizeFailures(state contracts.TaskState) string {
+	var b strings.Builder
+
+	if state.Check != nil {
+		if state.Check.Verdict != nil && state.Check.Verdict.Status != "" {
+			fmt.Fprintf(&b, "Last check verdict: %s\n", state.Check.Verdict.Status)
+		}
+
+		failed := make([]string, 0, len(state.Check.AcceptanceResults))
+		for _, result := range state.Check.AcceptanceResults {
+			if strings.EqualFold(strings.TrimSpace(result.Result), "PASS") {
+				continue
+			}
+			line := fmt.Sprintf("- %s: %s", result.AcId, result.Result)
+			if result.Score > 0 {
+				line += fmt.Sprintf(" (score %.2f)", result.Score)
+			}
+			if notes := strings.TrimSpace(result.Notes); notes != "" {
+				line += " — " + notes
+			}
+			failed = append(failed, line)
+		}
+		if len(failed) > 0 {
+			b.WriteString("Failed acceptance criteria:\n")
+			b.WriteString(strings.Join(failed, "\n"))
+			b.WriteString("\n")
+		}
+	}
+
+	blockers := make([]string, 0)
+	for _, entry := range state.Journal {
+		if entry.Type == contracts.JournalEntryObserver {
+			continue
+		}
+		if entry.StopReason == "" && entry.Status != "stop" && entry.Status != "error" {
+			continue
+		}
+		line := fmt.Sprintf("- %s step %d: %s", entry.Role, entry.StepIndex, entry.Status)
+		if entry.StopReason != "" {
+			line += " (" + entry.StopReason + ")"
+		}
+		if title := strings.TrimSpace(entry.Title); title != "" {
+			line += " — " + title
+		}
+		blockers = append(blockers, line)
+	}
+	if len(blockers) > maxDigestBlockers {
+		blockers = blockers[len(blockers)-maxDigestBlockers:]
+	}
+	if len(blockers) > 0 {
+		b.WriteString("Blockers:\n")
+		b.WriteString(strings.Join(blockers, "\n"))
+		b.WriteString("\n")
+	}
+
+	notes := processNotes(state)
+	if len(notes) > 0 {
+		b.WriteString("Process notes:\n")
+		for _, note := range notes {
+			b.WriteString("- " + note + "\n")
+		}
+	}
+
+	return strings.TrimSpace(b.String())
+}
+
+// processNotes returns the last Check recommendation and plan match, high-severity
+// process notes, and the details recorded in the most recent check journal entry.
+func processNotes(state contracts.TaskState) []string {
+	var notes []string
+	if state.Check != nil && state.Check.Verdict != nil {
+		if rec := strings.TrimSpace(state.Check.Verdict.Recommendation); rec != "" {
+			notes = append(notes, "recommendation: "+rec)
+		}
+		if basis := state.Check.Verdict.Basis; basis != nil && basis.PlanMatch != "" {
+			notes = append(notes, "plan match: "+basis.PlanMatch)
+		}
+	}
+	for _, note := range state.ProcessNotes {
+		if note.Severity == contracts.ProcessNoteSeverityHigh {
+			notes = append(notes, fmt.Sprintf("%s step %d: %s", note.Role, note.StepIndex, note.Text))
+		}
+	}
+	for i := len(state.Journal) - 1; i >= 0; i-- {
+		entry := state.Journal[i]
+		if entry.Role != RoleCheck {
+			continue
+		}
+		for _, detail := range entry.Details {
+			if detail = strings.TrimSpace(detail); detail != "" {
+				notes = append(notes, detail)
+			}
+		}
+		break
+	}
+	return notes
+}
+
+// recentJournal returns the journal entries recorded within maxAge of now. Entries
+// whose timestamp does not parse are kept; a maxAge that is not positive keeps all.
+func recentJournal(journal []contracts.JournalEntry, maxAge time.Duration, now time.Time) []contracts.JournalEntry {
+	if maxAge <= 0 {
+		return journal
+	}
+	cutoff := now.Add(-maxAge)
+	out := make([]contracts.JournalEntry, 0, len(journal))
+	for _, entry := range journal {
+		ts, err := time.Parse(time.RFC3339, entry.Timestamp)
+		if err == nil && ts.Before(cutoff) {
+			continue
+		}
+		out = append(out, entry)
+	}
+	return out
+}
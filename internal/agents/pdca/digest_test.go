@@ -0,0 +1,96 @@
+package pdca
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/metalagman/norma/internal/agents/pdca/contracts"
+	"github.com/metalagman/norma/internal/agents/pdca/roles/check"
+	"github.com/metalagman/norma/internal/config"
+)
+
+func TestSummarizeFailures(t *testing.T) {
+	t.Parallel()
+
+	state := contracts.TaskState{
+		Check: &check.CheckOutput{
+			AcceptanceResults: []check.CheckAcceptanceResult{
+				{AcId: "AC1", Result: "PASS"},
+				{AcId: "AC2", Result: "FAIL", Notes: "go test ./... exits 1"},
+				{AcId: "AC3", Result: "FAIL", Notes: "two of four endpoints", Score: 0.5},
+			},
+			Verdict: &check.CheckVerdict{
+				Status:         "FAIL",
+				Recommendation: "replan",
+				Basis:          &check.CheckVerdictBasis{PlanMatch: "partial"},
+			},
+		},
+		Journal: []contracts.JournalEntry{
+			{Role: RolePlan, StepIndex: 1, Status: "ok", Title: "planned"},
+			{Role: RoleDo, StepIndex: 2, Status: "stop", StopReason: "dependency_blocked", Title: "missing fixture"},
+			{Role: RoleCheck, StepIndex: 3, Status: "ok", Details: []string{"AC2 test still failing"}},
+		},
+	}
+
+	got := SummarizeFailures(state)
+	for _, want := range []string{
+		"Last check verdict: FAIL",
+		"- AC2: FAIL — go test ./... exits 1",
+		"- AC3: FAIL (score 0.50) — two of four endpoints",
+		"- do step 2: stop (dependency_blocked) — missing fixture",
+		"- recommendation: replan",
+		"- plan match: partial",
+		"- AC2 test still failing",
+	} {
+		if !strings.Contains(got, want) {
+			t.Fatalf("SummarizeFailures() missing %q:\n%s", want, got)
+		}
+	}
+	if strings.Contains(got, "AC1") {
+		t.Fatalf("SummarizeFailures() lists passed AC1:\n%s", got)
+	}
+	if strings.Contains(got, "planned") {
+		t.Fatalf("SummarizeFailures() lists non-blocking journal entry:\n%s", got)
+	}
+}
+
+func TestSummarizeFailuresEmptyState(t *testing.T) {
+	t.Parallel()
+
+	if got := SummarizeFailures(contracts.TaskState{}); got != "" {
+		t.Fatalf("SummarizeFailures(empty) = %q, want empty", got)
+	}
+}
+
+func TestPlanRequestOmitsStaleJournalEntries(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now().UTC()
+	state := &contracts.TaskState{
+		Journal: []contracts.JournalEntry{
+			{Timestamp: now.Add(-21 * 24 * time.Hour).Format(time.RFC3339), Role: RoleDo, StepIndex: 2, Status: "stop", StopReason: "weeks_old", Title: "stale blocker"},
+			{Timestamp: now.Add(-time.Hour).Format(time.RFC3339), Role: RoleDo, StepIndex: 6, Status: "stop", StopReason: "dependency_blocked", Title: "recent blocker"},
+			{Timestamp: "not a time", Role: RoleDo, StepIndex: 7, Status: "error", Title: "undated blocker"},
+		},
+	}
+	cfg := config.Config{Budgets: config.Budgets{JournalMaxAge: int((7 * 24 * time.Hour).Seconds())}}
+	rt := &runtime{cfg: cfg, runInput: AgentInput{RunID: "run-1", TaskID: "task-1", Goal: "goal"}}
+
+	req := rt.baseRequest(1, 1, RolePlan)
+	if err := rt.enrichRequest(&req, RolePlan, state); err != nil {
+		t.Fatalf("enrichRequest() error = %v", err)
+	}
+	digest := req.Context.FailureDigest
+	if strings.Contains(digest, "stale blocker") {
+		t.Fatalf("failure digest includes stale journal entry:\n%s", digest)
+	}
+	for _, want := range []string{"recent blocker", "undated blocker"} {
+		if !strings.Contains(digest, want) {
+			t.Fatalf("failure digest missing %q:\n%s", want, digest)
+		}
+	}
+	if len(state.Journal) != 3 {
+		t.Fatalf("task state journal = %d entries, want 3 kept", len(state.Journal))
+	}
+}
@@ -0,0 +1,56 @@
+package pdca
+
+import (
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/metalagman/norma/internal/fsutil"
+)
+
+// redactedValue replaces secrets in recorded environment values.
+const redactedValue = "[REDACTED]"
+
+// secretNameMarkers flag variables whose whole value is a secret.
+var secretNameMarkers = []string{"KEY", "TOKEN", "SECRET", "PASSWORD", "PASSWD", "CREDENTIAL", "AUTH"}
+
+// secretValuePattern matches well-known API token shapes embedded in values.
+var secretValuePattern = regexp.MustCompile(`\b(sk-[A-Za-z0-9_-]{8,}|gh[pousr]_[A-Za-z0-9]{8,}|github_pat_[A-Za-z0-9_]{8,}|AIza[A-Za-z0-9_-]{8,}|xox[abpr]-[A-Za-z0-9-]{8,})`)
+
+// recordStepEnv writes the allowlisted environment variables a step ran with to env.json
+// in the step directory. Unset variables are omitted and secrets are redacted.
+func recordStepEnv(stepDir string, names []string) error {
+	if len(names) == 0 {
+		return nil
+	}
+	env := make(map[string]string, len(names))
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		if value, ok := os.LookupEnv(name); ok {
+			env[name] = redactEnvValue(name, value)
+		}
+	}
+	return fsutil.WriteJSONAtomic(filepath.Join(stepDir, "env.json"), env)
+}
+
+// redactEnvValue hides the value of secret-named variables, URL passwords, and
+// known token shapes inside other values.
+func redactEnvValue(name, value string) string {
+	upper := strings.ToUpper(name)
+	for _, marker := range secretNameMarkers {
+		if strings.Contains(upper, marker) {
+			return redactedValue
+		}
+	}
+	if u, err := url.Parse(value); err == nil && u.User != nil {
+		if password, hasPassword := u.User.Password(); hasPassword && password != "" {
+			value = strings.Replace(value, ":"+password+"@", ":"+redactedValue+"@", 1)
+		}
+	}
+	return secretValuePattern.ReplaceAllString(value, redactedValue)
+}
@@ -0,0 +1,67 @@
+package pdca
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/metalagman/norma/internal/agents/pdca/roles/check"
+	"github.com/metalagman/norma/internal/fsutil"
+)
+
+// evidenceRef is an acceptance result's log reference resolved on disk.
+type evidenceRef struct {
+	ACID    string `json:"ac_id"`
+	LogRef  string `json:"log_ref"`
+	Path    string `json:"path,omitempty"`
+	Missing bool   `json:"missing,omitempty"`
+}
+
+// resolveEvidenceRefs resolves each result's log_ref against the step directory and,
+// for refs written like "steps/03-check/logs/stdout.txt", the run directory.
+// Refs that do not exist or point outside the run directory are marked missing.
+func resolveEvidenceRefs(stepDir, runDir string, results []check.CheckAcceptanceResult) []evidenceRef {
+	if abs, err := filepath.Abs(runDir); err == nil {
+		runDir = abs
+	}
+	refs := make([]evidenceRef, 0, len(results))
+	for _, result := range results {
+		logRef := strings.TrimSpace(result.LogRef)
+		if logRef == "" {
+			continue
+		}
+		ref := evidenceRef{ACID: result.AcId, LogRef: logRef, Missing: true}
+		for _, base := range []string{stepDir, runDir} {
+			if path, ok := resolveEvidencePath(base, runDir, logRef); ok {
+				ref.Path = path
+				ref.Missing = false
+				break
+			}
+		}
+		refs = append(refs, ref)
+	}
+	return refs
+}
+
+func resolveEvidencePath(base, runDir, logRef string) (string, bool) {
+	path := logRef
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(base, path)
+	}
+	rel, err := filepath.Rel(runDir, path)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", false
+	}
+	if _, err := os.Stat(path); err != nil {
+		return "", false
+	}
+	return path, true
+}
+
+// recordEvidence writes resolved log references to artifacts/evidence.json.
+func recordEvidence(stepDir string, refs []evidenceRef) error {
+	if len(refs) == 0 {
+		return nil
+	}
+	return fsutil.WriteJSONAtomic(filepath.Join(stepDir, "artifacts", "evidence.json"), refs)
+}
@@ -0,0 +1,79 @@
+package pdca
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/metalagman/norma/internal/agents/pdca/roles/check"
+)
+
+func TestResolveEvidenceRefs(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	runDir := filepath.Join(root, "run")
+	stepDir := filepath.Join(runDir, "steps", "003-check")
+	if err := os.MkdirAll(filepath.Join(stepDir, "logs"), 0o700); err != nil {
+		t.Fatalf("mkdir logs: %v", err)
+	}
+	writeTestFile(t, filepath.Join(stepDir, "logs", "ac-1.txt"), "ok\n")
+	writeTestFile(t, filepath.Join(stepDir, "logs", "stdout.txt"), "ok\n")
+	writeTestFile(t, filepath.Join(root, "outside.txt"), "secret\n")
+
+	refs := resolveEvidenceRefs(stepDir, runDir, []check.CheckAcceptanceResult{
+		{AcId: "AC-1", Result: "PASS", LogRef: "logs/ac-1.txt"},
+		{AcId: "AC-2", Result: "PASS", LogRef: "steps/003-check/logs/stdout.txt"},
+		{AcId: "AC-3", Result: "FAIL", LogRef: "logs/missing.txt"},
+		{AcId: "AC-4", Result: "PASS", LogRef: "../../../outside.txt"},
+		{AcId: "AC-5", Result: "PASS"},
+	})
+
+	want := []evidenceRef{
+		{ACID: "AC-1", LogRef: "logs/ac-1.txt", Path: filepath.Join(stepDir, "logs", "ac-1.txt")},
+		{ACID: "AC-2", LogRef: "steps/003-check/logs/stdout.txt", Path: filepath.Join(stepDir, "logs", "stdout.txt")},
+		{ACID: "AC-3", LogRef: "logs/missing.txt", Missing: true},
+		{ACID: "AC-4", LogRef: "../../../outside.txt", Missing: true},
+	}
+	if len(refs) != len(want) {
+		t.Fatalf("refs = %+v, want %+v", refs, want)
+	}
+	for i := range want {
+		if refs[i] != want[i] {
+			t.Fatalf("refs[%d] = %+v, want %+v", i, refs[i], want[i])
+		}
+	}
+}
+
+func TestRecordEvidence(t *testing.T) {
+	t.Parallel()
+
+	stepDir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(stepDir, "artifacts"), 0o700); err != nil {
+		t.Fatalf("mkdir artifacts: %v", err)
+	}
+
+	if err := recordEvidence(stepDir, nil); err != nil {
+		t.Fatalf("recordEvidence(nil) error = %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(stepDir, "artifacts", "evidence.json")); !os.IsNotExist(err) {
+		t.Fatalf("evidence.json written without refs: %v", err)
+	}
+
+	refs := []evidenceRef{{ACID: "AC-1", LogRef: "logs/missing.txt", Missing: true}}
+	if err := recordEvidence(stepDir, refs); err != nil {
+		t.Fatalf("recordEvidence() error = %v", err)
+	}
+	data, err := os.ReadFile(filepath.Join(stepDir, "artifacts", "evidence.json"))
+	if err != nil {
+		t.Fatalf("read evidence.json: %v", err)
+	}
+	var got []evidenceRef
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatalf("unmarshal evidence.json: %v", err)
+	}
+	if len(got) != 1 || got[0] != refs[0] {
+		t.Fatalf("evidence.json = %+v, want %+v", got, refs)
+	}
+}
@@ -0,0 +1,76 @@
+package pdca
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/metalagman/norma/internal/agents/pdca/contracts"
+	"github.com/rs/zerolog/log"
+)
+
+// decisionsFile is the run directory log of control-flow decisions written in explain mode.
+const decisionsFile = "decisions.jsonl"
+
+// Control-flow decision points recorded in explain mode.
+const (
+	decisionSkipStep     = "skip_step"
+	decisionVerdict      = "verdict"
+	decisionAct          = "act_decision"
+	decisionStepStatus   = "step_status"
+	decisionIterationEnd = "iteration_end"
+)
+
+// decisionRecord is one line of decisions.jsonl: the branch taken at a decision
+// point and the inputs it was based on.
+type decisionRecord struct {
+	Timestamp string         `json:"timestamp"`
+	Iteration int            `json:"iteration"`
+	Point     string         `json:"point"`
+	Role      string         `json:"role,omitempty"`
+	Outcome   string         `json:"outcome"`
+	Reason    string         `json:"reason"`
+	Inputs    map[string]any `json:"inputs,omitempty"`
+}
+
+// explain appends rec to decisions.jsonl in the run directory when explain mode is on.
+// Write failures are logged; the decisions log never affects the run.
+func (a *runtime) explain(rec decisionRecord) {
+	if !a.cfg.Explain || a.runInput.RunDir == "" {
+		return
+	}
+	rec.Timestamp = time.Now().UTC().Format(time.RFC3339)
+	if err := appendDecision(filepath.Join(a.runInput.RunDir, decisionsFile), rec); err != nil {
+		log.Warn().Err(err).Str("point", rec.Point).Msg("failed to record decision rationale")
+	}
+}
+
+func appendDecision(path string, rec decisionRecord) error {
+	line, err := json.Marshal(rec)
+	if err != nil {
+		return fmt.Errorf("marshal decision: %w", err)
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return fmt.Errorf("open %s: %w", decisionsFile, err)
+	}
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		_ = f.Close()
+		return fmt.Errorf("write %s: %w", decisionsFile, err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("close %s: %w", decisionsFile, err)
+	}
+	return nil
+}
+
+// verdictInputs summarizes the check output a verdict decision is based on.
+func verdictInputs(resp *contracts.AgentResponse) map[string]any {
+	results := make(map[string]string, len(resp.Check.AcceptanceResults))
+	for _, result := range resp.Check.AcceptanceResults {
+		results[result.AcId] = result.Result
+	}
+	return map[string]any{"status": resp.Status, "acceptance_results": results}
+}
@@ -0,0 +1,127 @@
+package pdca
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/metalagman/norma/internal/adkrunner"
+	"github.com/metalagman/norma/internal/agents/pdca/contracts"
+	"github.com/metalagman/norma/internal/agents/pdca/roles/plan"
+	"github.com/metalagman/norma/internal/config"
+	"github.com/metalagman/norma/internal/task"
+
+	"google.golang.org/adk/agent"
+)
+
+// labelledTracker is a task.Tracker fake holding one task with fixed labels.
+type labelledTracker struct {
+	task.Tracker
+
+	labels []string
+}
+
+func (t *labelledTracker) Task(_ context.Context, id string) (task.Task, error) {
+	return task.Task{ID: id, Labels: t.labels}, nil
+}
+func (t *labelledTracker) UpdateWorkflowState(context.Context, string, string) error { return nil }
+func (t *labelledTracker) SetNotes(context.Context, string, string) error            { return nil }
+
+func TestLoopAgentExplainRecordsDecisions(t *testing.T) {
+	t.Parallel()
+
+	runDir := t.TempDir()
+	verdicts := []string{"FAIL", "PASS"}
+	rt := &runtime{
+		cfg:      config.Config{Explain: true},
+		tracker:  &labelledTracker{labels: []string{"norma-has-plan"}},
+		runInput: AgentInput{RunID: "run-1", TaskID: "norma-1", RunDir: runDir},
+		steps:    slices.Clone(DefaultWorkflowSteps),
+	}
+	rt.overrideRunStep = func(ctx agent.InvocationContext, iteration int, roleName string) (*contracts.AgentResponse, error) {
+		if roleName == RolePlan {
+			return rt.runStep(ctx, iteration, roleName)
+		}
+		resp := workflowStepResponse(roleName, &verdicts)
+		if roleName == RoleAct && iteration == 1 {
+			resp.Act.Decision = "continue"
+		}
+		return resp, nil
+	}
+
+	loopAgent, err := rt.newLoopAgent(context.Background(), 3)
+	if err != nil {
+		t.Fatalf("newLoopAgent() error = %v", err)
+	}
+	if _, _, err := adkrunner.Run(context.Background(), adkrunner.RunInput{
+		Agent: loopAgent,
+		InitialState: map[string]any{
+			"iteration":  1,
+			"task_state": &contracts.TaskState{Plan: &plan.PlanOutput{}},
+		},
+	}); err != nil {
+		t.Fatalf("adkrunner.Run() error = %v", err)
+	}
+
+	records := readDecisions(t, filepath.Join(runDir, decisionsFile))
+	got := make([]string, 0, len(records))
+	for _, rec := range records {
+		got = append(got, fmt.Sprintf("%d:%s:%s", rec.Iteration, rec.Point, rec.Outcome))
+	}
+	want := []string{
+		"1:skip_step:skip",
+		"1:verdict:FAIL",
+		"1:act_decision:continue",
+		"2:skip_step:skip",
+		"2:verdict:PASS",
+		"2:act_decision:close",
+	}
+	if !slices.Equal(got, want) {
+		t.Fatalf("decisions = %v, want %v", got, want)
+	}
+	if records[0].Inputs["skip_label"] != "norma-has-plan" || records[0].Reason == "" {
+		t.Fatalf("skip decision = %+v, want skip label input and reason", records[0])
+	}
+	if records[5].Inputs["verdict"] != "PASS" || records[5].Inputs["requested_decision"] != "close" {
+		t.Fatalf("act decision = %+v, want verdict and requested decision inputs", records[5])
+	}
+}
+
+func TestExplainDisabledWritesNothing(t *testing.T) {
+	t.Parallel()
+
+	runDir := t.TempDir()
+	rt := &runtime{runInput: AgentInput{RunDir: runDir}}
+	rt.explain(decisionRecord{Point: decisionVerdict, Outcome: "PASS"})
+	if _, err := os.Stat(filepath.Join(runDir, decisionsFile)); !os.IsNotExist(err) {
+		t.Fatalf("decisions log stat error = %v, want not exist", err)
+	}
+}
+
+func readDecisions(t *testing.T, path string) []decisionRecord {
+	t.Helper()
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("open decisions log: %v", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	var records []decisionRecord
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var rec decisionRecord
+		if err := json.Unmarshal(scanner.Bytes(), &rec); err != nil {
+			t.Fatalf("decode decision %q: %v", scanner.Text(), err)
+		}
+		records = append(records, rec)
+	}
+	if err := scanner.Err(); err != nil {
+		t.Fatalf("read decisions log: %v", err)
+	}
+	return records
+}
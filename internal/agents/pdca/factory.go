@@ -7,11 +7,13 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 
 	"github.com/metalagman/norma/internal/agents/pdca/contracts"
 	"github.com/metalagman/norma/internal/config"
 	"github.com/metalagman/norma/internal/db"
+	"github.com/metalagman/norma/internal/git"
 	runpkg "github.com/metalagman/norma/internal/run"
 	"github.com/metalagman/norma/internal/task"
 	"github.com/rs/zerolog/log"
@@ -22,9 +24,10 @@ import (
 
 // Factory builds and finalizes PDCA ADK agents.
 type Factory struct {
-	cfg     config.Config
-	store   *db.Store
-	tracker task.Tracker
+	cfg             config.Config
+	store           *db.Store
+	tracker         task.Tracker
+	onStepHeartbeat StepHeartbeatFunc
 }
 
 const actDecisionClose = "close"
@@ -38,6 +41,36 @@ func NewFactory(cfg config.Config, store *db.Store, tracker task.Tracker) *Facto
 	}
 }
 
+// verifyResumeState refuses to resume a task whose Do steps would be skipped when
+// its branch no longer contains the Do commits recorded in the task state.
+func verifyResumeState(ctx context.Context, repoRoot, taskID string, labels []string, state contracts.TaskState) error {
+	if !slices.Contains(labels, labelHasDo) {
+		return nil
+	}
+	branch := task.BranchName(taskID, "")
+	if err := git.VerifyResumeBranch(ctx, repoRoot, branch, state.DoCommits); err != nil {
+		return fmt.Errorf("resume task %s: %w; remove the %s label or reset the task to todo to replan", taskID, err, labelHasDo)
+	}
+	return nil
+}
+
+// perRunTaskState keeps the parts of state a run on a fresh per-run branch can build on.
+// The branch starts from base without earlier Do commits, so the run resumes from the
+// recorded plan and redoes Do, Check and Act.
+func perRunTaskState(state contracts.TaskState) contracts.TaskState {
+	return contracts.TaskState{
+		Plan:         state.Plan,
+		Journal:      state.Journal,
+		ProcessNotes: state.ProcessNotes,
+	}
+}
+
+// OnStepHeartbeat registers fn to receive heartbeats of long-running steps.
+// Heartbeats fire every step_heartbeat_interval seconds; they are always logged.
+func (w *Factory) OnStepHeartbeat(fn StepHeartbeatFunc) {
+	w.onStepHeartbeat = fn
+}
+
 func (w *Factory) Name() string {
 	return "pdca"
 }
@@ -51,6 +84,7 @@ func (w *Factory) Build(ctx context.Context, meta runpkg.RunMeta, task runpkg.Ta
 		RunDir:             meta.RunDir,
 		WorkingDir:         meta.GitRoot,
 		BaseBranch:         meta.BaseBranch,
+		OnStepHeartbeat:    w.onStepHeartbeat,
 	}
 
 	stepsDir := filepath.Join(input.RunDir, "steps")
@@ -70,8 +104,38 @@ func (w *Factory) Build(ctx context.Context, meta runpkg.RunMeta, task runpkg.Ta
 		}
 	}
 
+	if err := runpkg.CheckTaskBranchLayout(ctx, input.WorkingDir, w.cfg.Git, input.TaskID); err != nil {
+		return runpkg.AgentBuild{}, err
+	}
+	if w.cfg.Git.PerRunBranches {
+		state = perRunTaskState(state)
+	} else {
+		if err := runpkg.MigrateLegacyTaskBranch(ctx, input.WorkingDir, input.TaskID); err != nil {
+			return runpkg.AgentBuild{}, err
+		}
+		if err := verifyResumeState(ctx, input.WorkingDir, input.TaskID, taskItem.Labels, state); err != nil {
+			return runpkg.AgentBuild{}, err
+		}
+	}
+
+	cfg := w.cfg
+	cfg.Budgets = TaskBudgetOverride(taskItem, w.cfg.Budgets)
+	if cfg.Budgets.MaxIterations != w.cfg.Budgets.MaxIterations {
+		log.Info().
+			Str("task_id", input.TaskID).
+			Int("max_iterations", cfg.Budgets.MaxIterations).
+			Int("default_max_iterations", w.cfg.Budgets.MaxIterations).
+			Msg("task overrides max iterations")
+	}
+
+	l := runpkg.ContextLogger(ctx, log.Logger).With().Str("component", "pdca").Logger()
+	branchName := runpkg.TaskBranch(cfg.Git, input.TaskID, input.RunID)
+	if err := runSetupCommand(ctx, l, cfg.MaxWorktrees, input.WorkingDir, input.RunDir, branchName, input.BaseBranch, cfg.SetupCommand); err != nil {
+		return runpkg.AgentBuild{}, err
+	}
+
 	// Create the pdca loop agent with plan/do/check/act as direct subagents.
-	la, err := NewLoopAgent(ctx, w.cfg, w.store, w.tracker, input, input.BaseBranch, w.cfg.Budgets.MaxIterations)
+	la, err := NewLoopAgent(ctx, cfg, w.store, w.tracker, input, input.BaseBranch, cfg.Budgets.MaxIterations)
 	if err != nil {
 		return runpkg.AgentBuild{}, fmt.Errorf("create loop agent: %w", err)
 	}
@@ -81,7 +145,6 @@ func (w *Factory) Build(ctx context.Context, meta runpkg.RunMeta, task runpkg.Ta
 		"iteration":  1,
 		"task_state": &state,
 	}
-	l := log.With().Str("component", "pdca").Logger()
 	l.Info().Str("task_id", input.TaskID).Str("run_id", input.RunID).Msg("built ADK loop agent")
 
 	return runpkg.AgentBuild{
@@ -105,11 +168,13 @@ func (w *Factory) Finalize(ctx context.Context, meta runpkg.RunMeta, payload run
 		return runpkg.AgentOutcome{}, fmt.Errorf("final session is required")
 	}
 
-	l := log.With().Str("component", "pdca").Logger()
+	l := runpkg.ContextLogger(ctx, log.Logger).With().Str("component", "pdca").Logger()
 
 	// Persist final task state to tracker from session.
+	var passedCriteria []task.AcceptanceCriterion
 	taskStateVal, err := stateAny(finalSession.State(), "task_state")
 	if err == nil {
+		passedCriteria = passedRequiredCriteria(coerceTaskState(taskStateVal), payload.AcceptanceCriteria)
 		data, err := json.MarshalIndent(taskStateVal, "", "  ")
 		if err == nil {
 			if err := w.tracker.SetNotes(ctx, payload.ID, string(data)); err != nil {
@@ -157,7 +222,9 @@ func (w *Factory) Finalize(ctx context.Context, meta runpkg.RunMeta, payload run
 	}
 
 	res := runpkg.AgentOutcome{
-		Status: status,
+		Status:         status,
+		PassedRequired: len(passedCriteria),
+		PassedCriteria: passedCriteria,
 	}
 	if effectiveVerdict != "" {
 		res.Verdict = &effectiveVerdict
@@ -166,6 +233,28 @@ func (w *Factory) Finalize(ctx context.Context, meta runpkg.RunMeta, payload run
 	return res, nil
 }
 
+// countPassedRequired counts task acceptance criteria with a PASS result in the final check.
+func countPassedRequired(state *contracts.TaskState, required []task.AcceptanceCriterion) int {
+	return len(passedRequiredCriteria(state, required))
+}
+
+// passedRequiredCriteria returns the task acceptance criteria with a PASS result in the final check.
+func passedRequiredCriteria(state *contracts.TaskState, required []task.AcceptanceCriterion) []task.AcceptanceCriterion {
+	if state == nil || state.Check == nil {
+		return nil
+	}
+	var passed []task.AcceptanceCriterion
+	for _, ac := range required {
+		for _, result := range state.Check.AcceptanceResults {
+			if result.AcId == ac.ID && strings.EqualFold(strings.TrimSpace(result.Result), "PASS") {
+				passed = append(passed, ac)
+				break
+			}
+		}
+	}
+	return passed
+}
+
 func parseFinalState(state session.State) (string, string, int, error) {
 	verdict, err := stateString(state, "verdict")
 	if err != nil {
@@ -1,13 +1,18 @@
 package pdca
 
 import (
+	"context"
 	"errors"
 	"iter"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/metalagman/norma/internal/agents/pdca/contracts"
 	"github.com/metalagman/norma/internal/agents/pdca/roles/act"
 	"github.com/metalagman/norma/internal/agents/pdca/roles/check"
+	"github.com/metalagman/norma/internal/git"
+	"github.com/metalagman/norma/internal/task"
 	"google.golang.org/adk/session"
 )
 
@@ -268,3 +273,60 @@ func TestDeriveFinalOutcome(t *testing.T) {
 		})
 	}
 }
+
+func TestCountPassedRequired(t *testing.T) {
+	t.Parallel()
+
+	state := &contracts.TaskState{
+		Check: &check.CheckOutput{
+			AcceptanceResults: []check.CheckAcceptanceResult{
+				{AcId: "AC1", Result: "PASS"},
+				{AcId: "AC1.1", Result: "PASS"},
+				{AcId: "AC2", Result: "FAIL"},
+				{AcId: "AC3", Result: "pass"},
+			},
+		},
+	}
+	required := []task.AcceptanceCriterion{{ID: "AC1"}, {ID: "AC2"}, {ID: "AC3"}, {ID: "AC4"}}
+
+	if got := countPassedRequired(state, required); got != 2 {
+		t.Fatalf("countPassedRequired() = %d, want 2", got)
+	}
+	if got := countPassedRequired(&contracts.TaskState{}, required); got != 0 {
+		t.Fatalf("countPassedRequired(no check) = %d, want 0", got)
+	}
+}
+
+func TestVerifyResumeState(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	repo := t.TempDir()
+	initTestRepo(t, ctx, repo)
+	writeTestFile(t, filepath.Join(repo, "a.txt"), "base\n")
+	runGit(t, ctx, repo, "add", "-A")
+	runGit(t, ctx, repo, "commit", "-m", "chore: base")
+	runGit(t, ctx, repo, "branch", "norma/task/norma-1")
+	runGit(t, ctx, repo, "checkout", "norma/task/norma-1")
+	writeTestFile(t, filepath.Join(repo, "a.txt"), "do\n")
+	runGit(t, ctx, repo, "commit", "-am", "chore: do step 002")
+	doCommit := strings.TrimSpace(runGit(t, ctx, repo, "rev-parse", "HEAD"))
+	state := contracts.TaskState{DoCommits: []string{doCommit}}
+
+	if err := verifyResumeState(ctx, repo, "norma-1", []string{labelHasDo}, state); err != nil {
+		t.Fatalf("verifyResumeState() matching error = %v", err)
+	}
+
+	runGit(t, ctx, repo, "reset", "--hard", "HEAD~1")
+
+	err := verifyResumeState(ctx, repo, "norma-1", []string{labelHasDo}, state)
+	if !errors.Is(err, git.ErrResumeBranchDiverged) {
+		t.Fatalf("verifyResumeState() error = %v, want ErrResumeBranchDiverged", err)
+	}
+	if !strings.Contains(err.Error(), "replan") {
+		t.Fatalf("error = %q, want replan guidance", err)
+	}
+	if err := verifyResumeState(ctx, repo, "norma-1", nil, state); err != nil {
+		t.Fatalf("verifyResumeState() without %s label error = %v", labelHasDo, err)
+	}
+}
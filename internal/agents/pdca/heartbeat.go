@@ -0,0 +1,74 @@
+package pdca
+
+import (
+	"context"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/metalagman/norma/internal/agents/pdca/contracts"
+)
+
+// StepHeartbeat reports that a PDCA step is still running.
+type StepHeartbeat struct {
+	RunID     string
+	TaskID    string
+	Role      string
+	StepIndex int
+	Iteration int
+	Elapsed   time.Duration
+}
+
+// StepHeartbeatFunc receives heartbeats for long-running steps.
+type StepHeartbeatFunc func(StepHeartbeat)
+
+// heartbeatRunner wraps a Runner and calls emit every interval while Run is in progress.
+type heartbeatRunner struct {
+	inner    Runner
+	interval time.Duration
+	emit     func(elapsed time.Duration)
+}
+
+func (r heartbeatRunner) Run(ctx context.Context, req contracts.AgentRequest, stdout, stderr io.Writer) ([]byte, []byte, int, error) {
+	stop := StartHeartbeat(r.interval, r.emit)
+	defer stop()
+	return r.inner.Run(ctx, req, stdout, stderr)
+}
+
+// withHeartbeat returns runner unchanged when interval is not positive.
+func withHeartbeat(runner Runner, interval time.Duration, emit func(elapsed time.Duration)) Runner {
+	if interval <= 0 || emit == nil {
+		return runner
+	}
+	return heartbeatRunner{inner: runner, interval: interval, emit: emit}
+}
+
+// StartHeartbeat calls emit every interval until the returned stop func is called.
+// stop waits for the heartbeat goroutine to exit, so no heartbeat fires after it returns.
+func StartHeartbeat(interval time.Duration, emit func(elapsed time.Duration)) func() {
+	start := time.Now()
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				emit(time.Since(start))
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			close(done)
+			wg.Wait()
+		})
+	}
+}
@@ -0,0 +1,62 @@
+package pdca
+
+import (
+	"context"
+	"io"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/metalagman/norma/internal/agents/pdca/contracts"
+)
+
+type slowRunner struct {
+	delay time.Duration
+}
+
+func (r slowRunner) Run(ctx context.Context, _ contracts.AgentRequest, _, _ io.Writer) ([]byte, []byte, int, error) {
+	select {
+	case <-time.After(r.delay):
+		return []byte(`{"status":"ok"}`), nil, 0, nil
+	case <-ctx.Done():
+		return nil, nil, 1, ctx.Err()
+	}
+}
+
+func TestHeartbeatRunnerFiresWhileStepRuns(t *testing.T) {
+	t.Parallel()
+
+	var beats atomic.Int32
+	var lastElapsed atomic.Int64
+	runner := withHeartbeat(slowRunner{delay: 120 * time.Millisecond}, 20*time.Millisecond, func(elapsed time.Duration) {
+		beats.Add(1)
+		lastElapsed.Store(int64(elapsed))
+	})
+
+	if _, _, _, err := runner.Run(context.Background(), contracts.AgentRequest{}, io.Discard, io.Discard); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	got := beats.Load()
+	if got < 1 {
+		t.Fatalf("heartbeats = %d, want at least 1", got)
+	}
+	if lastElapsed.Load() <= 0 {
+		t.Fatalf("last elapsed = %v, want > 0", time.Duration(lastElapsed.Load()))
+	}
+
+	// The heartbeat goroutine must be stopped once Run returns.
+	time.Sleep(60 * time.Millisecond)
+	if after := beats.Load(); after != got {
+		t.Fatalf("heartbeats after Run returned = %d, want %d", after, got)
+	}
+}
+
+func TestWithHeartbeatDisabled(t *testing.T) {
+	t.Parallel()
+
+	inner := slowRunner{}
+	if got := withHeartbeat(inner, 0, func(time.Duration) {}); got != Runner(inner) {
+		t.Fatalf("withHeartbeat(interval 0) = %T, want inner runner", got)
+	}
+}
@@ -0,0 +1,146 @@
+package pdca
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/metalagman/norma/internal/agents/pdca/roles/plan"
+	"github.com/metalagman/norma/internal/task"
+)
+
+// defaultHintCommandPrefixes lists leading words that mark a verify hint as a runnable command.
+var defaultHintCommandPrefixes = []string{
+	"go", "make", "task", "npm", "npx", "pnpm", "yarn", "node", "cargo", "pytest",
+	"python", "python3", "bash", "sh", "git", "grep", "test", "curl", "docker", "bd", "./",
+}
+
+// ambiguousHintWords are command prefixes that also start English sentences ("make sure",
+// "test that", "go to"). A hint starting with one is a command only when it is wrapped in
+// backticks or prefixed with "$ ", or when the next word passes the word's check.
+var ambiguousHintWords = map[string]func(next string) bool{
+	"go":   isGoSubcommand,
+	"make": isFlagArg,
+	"task": isFlagArg,
+	"test": func(next string) bool { return isFlagArg(next) || next == "!" },
+}
+
+// goSubcommands are the go tool commands a verify hint plausibly runs.
+var goSubcommands = []string{
+	"build", "env", "fmt", "generate", "install", "list", "mod", "run", "test", "tool", "vet", "version", "work",
+}
+
+func isGoSubcommand(next string) bool { return slices.Contains(goSubcommands, next) }
+
+func isFlagArg(next string) bool { return len(next) > 1 && strings.HasPrefix(next, "-") }
+
+// HintsToChecks converts command-like verify hints into deterministic checks.
+// Prose hints are skipped.
+func HintsToChecks(hints []string) []plan.CriterionCheck {
+	return hintsToChecks(hints, defaultHintCommandPrefixes)
+}
+
+func hintsToChecks(hints []string, prefixes []string) []plan.CriterionCheck {
+	if len(prefixes) == 0 {
+		prefixes = defaultHintCommandPrefixes
+	}
+	checks := make([]plan.CriterionCheck, 0, len(hints))
+	for _, hint := range hints {
+		cmd, ok := hintCommand(hint, prefixes)
+		if !ok {
+			continue
+		}
+		checks = append(checks, plan.CriterionCheck{
+			Id:              fmt.Sprintf("HINT-%d", len(checks)+1),
+			Cmd:             cmd,
+			ExpectExitCodes: []int64{0},
+		})
+	}
+	return checks
+}
+
+// hintCommand returns the command encoded in a hint if it looks runnable.
+func hintCommand(hint string, prefixes []string) (string, bool) {
+	cmd := strings.TrimSpace(hint)
+	marked := false
+	if strings.HasPrefix(cmd, "`") && strings.HasSuffix(cmd, "`") && len(cmd) > 1 {
+		cmd = strings.TrimSpace(strings.Trim(cmd, "`"))
+		marked = true
+	}
+	if rest, ok := strings.CutPrefix(cmd, "$ "); ok {
+		cmd = strings.TrimSpace(rest)
+		marked = true
+	}
+	if cmd == "" || strings.ContainsAny(cmd, "\n`") {
+		return "", false
+	}
+
+	fields := strings.Fields(cmd)
+	first := fields[0]
+	if looksLike, ok := ambiguousHintWords[first]; ok && !marked {
+		if len(fields) < 2 || !looksLike(fields[1]) {
+			return "", false
+		}
+	}
+	for _, prefix := range prefixes {
+		prefix = strings.TrimSpace(prefix)
+		if prefix == "" {
+			continue
+		}
+		if first == prefix || (strings.HasSuffix(prefix, "/") && strings.HasPrefix(first, prefix)) {
+			return cmd, true
+		}
+	}
+	return "", false
+}
+
+// baselineHintChecks derives checks from baseline verify hints, keyed by criterion id.
+// They are passed to the Plan agent with the acceptance criteria so it can keep or
+// refine them when it writes the effective criteria.
+func baselineHintChecks(baseline []task.AcceptanceCriterion, prefixes []string) map[string][]plan.CriterionCheck {
+	out := make(map[string][]plan.CriterionCheck)
+	for _, ac := range baseline {
+		seeded := hintsToChecks(ac.VerifyHints, prefixes)
+		if len(seeded) == 0 {
+			continue
+		}
+		for i := range seeded {
+			seeded[i].Id = fmt.Sprintf("CHK-%s-%s", ac.ID, seeded[i].Id)
+		}
+		out[ac.ID] = seeded
+	}
+	if len(out) == 0 {
+		return nil
+	}
+	return out
+}
+
+// seedHintChecks merges checks derived from baseline verify hints into matching effective
+// criteria. The Plan agent already receives them with its acceptance criteria; this pass
+// restores any it dropped, so every seeded command runs exactly once per effective criterion.
+// An effective criterion matches a baseline criterion by id or by refining it.
+// Commands already present on the effective criterion are not duplicated.
+func seedHintChecks(out *plan.PlanOutput, baseline []task.AcceptanceCriterion, prefixes []string) {
+	if out == nil || out.AcceptanceCriteria == nil {
+		return
+	}
+	for _, ac := range baseline {
+		seeded := hintsToChecks(ac.VerifyHints, prefixes)
+		if len(seeded) == 0 {
+			continue
+		}
+		for i := range out.AcceptanceCriteria.Effective {
+			eff := &out.AcceptanceCriteria.Effective[i]
+			if eff.Id != ac.ID && !slices.Contains(eff.Refines, ac.ID) {
+				continue
+			}
+			for _, check := range seeded {
+				if slices.ContainsFunc(eff.Checks, func(c plan.CriterionCheck) bool { return c.Cmd == check.Cmd }) {
+					continue
+				}
+				check.Id = fmt.Sprintf("CHK-%s-%s", eff.Id, check.Id)
+				eff.Checks = append(eff.Checks, check)
+			}
+		}
+	}
+}
@@ -0,0 +1,115 @@
+package pdca
+
+import (
+	"testing"
+
+	"github.com/metalagman/norma/internal/agents/pdca/roles/plan"
+	"github.com/metalagman/norma/internal/task"
+)
+
+func TestHintsToChecksDistinguishesCommandsFromProse(t *testing.T) {
+	t.Parallel()
+
+	hints := []string{
+		"go test ./...",
+		"Run the unit tests and make sure they pass",
+		"`make lint`",
+		"$ npm run build",
+		"Check the README mentions the flag",
+		"./scripts/verify.sh --strict",
+		"",
+	}
+
+	got := HintsToChecks(hints)
+	wantCmds := []string{"go test ./...", "make lint", "npm run build", "./scripts/verify.sh --strict"}
+	if len(got) != len(wantCmds) {
+		t.Fatalf("len(HintsToChecks()) = %d, want %d: %+v", len(got), len(wantCmds), got)
+	}
+	for i, want := range wantCmds {
+		if got[i].Cmd != want {
+			t.Fatalf("check[%d].Cmd = %q, want %q", i, got[i].Cmd, want)
+		}
+		if len(got[i].ExpectExitCodes) != 1 || got[i].ExpectExitCodes[0] != 0 {
+			t.Fatalf("check[%d].ExpectExitCodes = %v, want [0]", i, got[i].ExpectExitCodes)
+		}
+		if got[i].Id == "" {
+			t.Fatalf("check[%d].Id is empty", i)
+		}
+	}
+}
+
+func TestHintsToChecksAmbiguousCommandWords(t *testing.T) {
+	t.Parallel()
+
+	hints := []string{
+		"make sure the build passes",
+		"test that login redirects to the dashboard",
+		"go to settings and enable dark mode",
+		"task list shows the new column",
+		"make",
+		"go vet ./...",
+		"make -C tools lint",
+		"test -f dist/app.js",
+		"task --list",
+		"`make build`",
+		"$ task test",
+	}
+
+	got := HintsToChecks(hints)
+	wantCmds := []string{"go vet ./...", "make -C tools lint", "test -f dist/app.js", "task --list", "make build", "task test"}
+	if len(got) != len(wantCmds) {
+		t.Fatalf("HintsToChecks() = %+v, want %d checks", got, len(wantCmds))
+	}
+	for i, want := range wantCmds {
+		if got[i].Cmd != want {
+			t.Fatalf("check[%d].Cmd = %q, want %q", i, got[i].Cmd, want)
+		}
+	}
+}
+
+func TestHintsToChecksCustomPrefixes(t *testing.T) {
+	t.Parallel()
+
+	got := hintsToChecks([]string{"just test", "go test ./..."}, []string{"just"})
+	if len(got) != 1 || got[0].Cmd != "just test" {
+		t.Fatalf("hintsToChecks() = %+v, want only %q", got, "just test")
+	}
+}
+
+func TestSeedHintChecksAddsChecksToMatchingCriteria(t *testing.T) {
+	t.Parallel()
+
+	out := &plan.PlanOutput{
+		AcceptanceCriteria: &plan.PlanOutputAcceptanceCriteria{
+			Effective: []plan.EffectiveAcceptanceCriteria{
+				{
+					Id:     "AC1",
+					Origin: "baseline",
+					Checks: []plan.CriterionCheck{{Id: "CHK-1", Cmd: "go test ./...", ExpectExitCodes: []int64{0}}},
+				},
+				{Id: "AC1.1", Origin: "extended", Refines: []string{"AC1"}},
+				{Id: "AC2", Origin: "baseline"},
+			},
+		},
+	}
+	baseline := []task.AcceptanceCriterion{
+		{ID: "AC1", VerifyHints: []string{"go test ./...", "go vet ./..."}},
+		{ID: "AC2", VerifyHints: []string{"Ask a reviewer"}},
+	}
+
+	seedHintChecks(out, baseline, nil)
+
+	eff := out.AcceptanceCriteria.Effective
+	if len(eff[0].Checks) != 2 {
+		t.Fatalf("AC1 checks = %+v, want existing check plus go vet", eff[0].Checks)
+	}
+	if eff[0].Checks[1].Cmd != "go vet ./..." || eff[0].Checks[1].Id != "CHK-AC1-HINT-2" {
+		t.Fatalf("AC1 seeded check = %+v", eff[0].Checks[1])
+	}
+	if len(eff[1].Checks) != 2 {
+		t.Fatalf("AC1.1 checks = %+v, want both hint checks", eff[1].Checks)
+	}
+	if len(eff[2].Checks) != 0 {
+		t.Fatalf("AC2 checks = %+v, want none for prose hints", eff[2].Checks)
+	}
+}
@@ -11,4 +11,6 @@ type AgentInput struct {
 	RunDir             string
 	WorkingDir         string
 	BaseBranch         string
+	// OnStepHeartbeat is called for each heartbeat of a long-running step.
+	OnStepHeartbeat StepHeartbeatFunc
 }
@@ -0,0 +1,74 @@
+package pdca
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/metalagman/norma/internal/git"
+	runpkg "github.com/metalagman/norma/internal/run"
+	"github.com/rs/zerolog/log"
+
+	"google.golang.org/adk/agent"
+)
+
+// iterationDiffFile is the step artifact holding the task branch diff of one iteration.
+const iterationDiffFile = "iteration-diff.patch"
+
+// recordIterationDiff records the task branch head at the end of iteration and writes
+// the diff since the previous iteration's head to the artifacts of the iteration's
+// last step. The first iteration diffs from where the branch forked off the base.
+// Failures are logged; the diff is diagnostic and never affects the run.
+func (a *runtime) recordIterationDiff(ctx agent.InvocationContext, iteration int, roleName string) {
+	if a.store == nil || a.runInput.WorkingDir == "" {
+		return
+	}
+	l := runpkg.ContextLogger(ctx, log.Logger).With().Str("component", "pdca").Int("iteration", iteration).Logger()
+	idxVal, _ := ctx.Session().State().Get("current_step_index")
+	index, _ := idxVal.(int)
+	artifactsDir := filepath.Join(a.runInput.RunDir, "steps", fmt.Sprintf("%03d-%s", index, roleName), "artifacts")
+	if err := a.writeIterationDiff(ctx, iteration, artifactsDir); err != nil {
+		l.Warn().Err(err).Msg("failed to capture iteration diff")
+	}
+}
+
+func (a *runtime) writeIterationDiff(ctx context.Context, iteration int, artifactsDir string) error {
+	repoRoot := a.runInput.WorkingDir
+	branch := runpkg.TaskBranch(a.cfg.Git, a.runInput.TaskID, a.runInput.RunID)
+	head, err := git.BranchHead(ctx, repoRoot, branch)
+	if err != nil {
+		return err
+	}
+	if err := a.store.RecordIterationHead(ctx, a.runInput.RunID, iteration, head); err != nil {
+		return err
+	}
+
+	from := ""
+	if iteration > 1 {
+		from, err = a.store.IterationHead(ctx, a.runInput.RunID, iteration-1)
+		if err != nil {
+			return err
+		}
+	}
+	if from == "" {
+		out, err := git.GitRunCmdOutput(ctx, repoRoot, "git", "merge-base", a.baseBranch, branch)
+		if err != nil {
+			return fmt.Errorf("find fork point of %s: %w", branch, err)
+		}
+		from = strings.TrimSpace(out)
+	}
+	patch, err := git.IterationDiff(ctx, repoRoot, branch, from, head)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(artifactsDir, 0o700); err != nil {
+		return fmt.Errorf("create artifacts dir: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(artifactsDir, iterationDiffFile), patch, 0o600); err != nil {
+		return fmt.Errorf("write %s: %w", iterationDiffFile, err)
+	}
+	return nil
+}
@@ -0,0 +1,86 @@
+package pdca
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/metalagman/norma/internal/db"
+	runpkg "github.com/metalagman/norma/internal/run"
+)
+
+func TestWriteIterationDiffCoversOneIteration(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	repo := t.TempDir()
+	initTestRepo(t, ctx, repo)
+	writeTestFile(t, filepath.Join(repo, "a.txt"), "one\n")
+	runGit(t, ctx, repo, "add", "-A")
+	runGit(t, ctx, repo, "commit", "-m", "chore: initial")
+	baseBranch := strings.TrimSpace(runGit(t, ctx, repo, "rev-parse", "--abbrev-ref", "HEAD"))
+
+	sqlDB, err := db.Open(ctx, filepath.Join(t.TempDir(), "norma.db"))
+	if err != nil {
+		t.Fatalf("db.Open() error = %v", err)
+	}
+	t.Cleanup(func() { _ = sqlDB.Close() })
+	store := db.NewStore(sqlDB)
+	if err := store.CreateRun(ctx, "run-1", "norma-1", "goal", t.TempDir(), 1); err != nil {
+		t.Fatalf("CreateRun() error = %v", err)
+	}
+
+	rt := &runtime{
+		store:      store,
+		runInput:   AgentInput{RunID: "run-1", TaskID: "norma-1", WorkingDir: repo},
+		baseBranch: baseBranch,
+	}
+	branch := runpkg.TaskBranch(rt.cfg.Git, "norma-1", "run-1")
+	runGit(t, ctx, repo, "checkout", "-b", branch)
+
+	iterations := []struct {
+		file    string
+		content string
+	}{
+		{file: "a.txt", content: "one\ntwo\n"},
+		{file: "b.txt", content: "three\n"},
+	}
+	artifacts := make([]string, len(iterations))
+	for i, it := range iterations {
+		writeTestFile(t, filepath.Join(repo, it.file), it.content)
+		runGit(t, ctx, repo, "add", "-A")
+		runGit(t, ctx, repo, "commit", "-m", "chore: do step")
+		artifacts[i] = filepath.Join(t.TempDir(), "artifacts")
+		if err := rt.writeIterationDiff(ctx, i+1, artifacts[i]); err != nil {
+			t.Fatalf("writeIterationDiff(%d) error = %v", i+1, err)
+		}
+	}
+
+	first := readPatch(t, artifacts[0])
+	if !strings.Contains(first, "+two") || strings.Contains(first, "b.txt") {
+		t.Fatalf("iteration 1 diff = %q, want only the a.txt change", first)
+	}
+	second := readPatch(t, artifacts[1])
+	if !strings.Contains(second, "+++ b/b.txt") || strings.Contains(second, "a.txt") {
+		t.Fatalf("iteration 2 diff = %q, want only the b.txt change", second)
+	}
+
+	head, err := store.IterationHead(ctx, "run-1", 2)
+	if err != nil {
+		t.Fatalf("IterationHead() error = %v", err)
+	}
+	if want := strings.TrimSpace(runGit(t, ctx, repo, "rev-parse", "HEAD")); head != want {
+		t.Fatalf("IterationHead(2) = %q, want %q", head, want)
+	}
+}
+
+func readPatch(t *testing.T, artifactsDir string) string {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join(artifactsDir, iterationDiffFile))
+	if err != nil {
+		t.Fatalf("read %s: %v", iterationDiffFile, err)
+	}
+	return string(data)
+}
@@ -0,0 +1,189 @@
+package pdca
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/metalagman/norma/internal/agents/pdca/contracts"
+	"github.com/metalagman/norma/internal/config"
+	"github.com/metalagman/norma/internal/db"
+	"github.com/metalagman/norma/internal/fsutil"
+	"github.com/metalagman/norma/internal/logging"
+	runpkg "github.com/metalagman/norma/internal/run"
+	"github.com/rs/zerolog/log"
+
+	"google.golang.org/adk/agent"
+)
+
+// roleObserver is the step role recorded for observer runs.
+const roleObserver = "observer"
+
+// observerAgents returns the agents configured in observers, failing on names
+// missing from the agents registry.
+func observerAgents(cfg config.Config) ([]string, error) {
+	names := make([]string, 0, len(cfg.Observers))
+	for _, name := range cfg.Observers {
+		name = strings.TrimSpace(name)
+		if _, ok := cfg.Agents[name]; !ok {
+			return nil, fmt.Errorf("observer %q: agent is not configured", name)
+		}
+		names = append(names, name)
+	}
+	return names, nil
+}
+
+// runObservers runs every observer after the last workflow step of an iteration.
+// Observer output is journaled; failures are logged and never change control flow.
+func (a *runtime) runObservers(ctx agent.InvocationContext, iteration int) {
+	for _, name := range a.observers {
+		idxVal, _ := ctx.Session().State().Get("current_step_index")
+		index, _ := idxVal.(int)
+		index++
+		if err := ctx.Session().State().Set("current_step_index", index); err != nil {
+			log.Warn().Err(err).Str("observer", name).Msg("failed to set current_step_index for observer")
+			return
+		}
+
+		invoke := a.invokeObserver
+		if a.overrideRunObserver != nil {
+			invoke = a.overrideRunObserver
+		}
+		resp, err := invoke(ctx, iteration, index, name)
+		if err != nil {
+			log.Warn().Err(err).Str("observer", name).Int("iteration", iteration).Msg("observer failed")
+			resp = &contracts.AgentResponse{
+				Status:   "error",
+				Progress: contracts.StepProgress{Details: []string{err.Error()}},
+			}
+		}
+
+		state := a.getTaskState(ctx)
+		applyObserverToTaskState(state, resp, name, a.runInput.RunID, iteration, index, time.Now())
+		if err := a.persistTaskState(ctx, state); err != nil {
+			log.Warn().Err(err).Str("observer", name).Msg("failed to journal observer output")
+		}
+	}
+}
+
+// applyObserverToTaskState journals an observer response without touching step outputs.
+func applyObserverToTaskState(state *contracts.TaskState, resp *contracts.AgentResponse, name, runID string, iteration, index int, now time.Time) {
+	entry := contracts.JournalEntry{
+		Timestamp:  now.UTC().Format(time.RFC3339),
+		RunID:      runID,
+		Iteration:  iteration,
+		StepIndex:  index,
+		Role:       name,
+		Type:       contracts.JournalEntryObserver,
+		Status:     resp.Status,
+		StopReason: resp.StopReason,
+		Title:      resp.Progress.Title,
+		Details:    resp.Progress.Details,
+	}
+	if entry.Title == "" {
+		entry.Title = fmt.Sprintf("%s observer completed", name)
+	}
+	state.Journal = upsertJournalEntry(state.Journal, entry)
+}
+
+// invokeObserver runs the observer agent name with the Check contract against the
+// task branch in its own step directory.
+func (a *runtime) invokeObserver(ctx agent.InvocationContext, iteration, index int, name string) (*contracts.AgentResponse, error) {
+	role := a.role(RoleCheck)
+	req := a.baseRequest(iteration, index, RoleCheck)
+	checkInput, err := checkInputFromState(a.getTaskState(ctx))
+	if err != nil {
+		return nil, err
+	}
+	req.Check = checkInput
+
+	stepDir := filepath.Join(a.runInput.RunDir, "steps", fmt.Sprintf("%03d-%s-%s", index, roleObserver, name))
+	if err := os.MkdirAll(filepath.Join(stepDir, "logs"), 0o700); err != nil {
+		return nil, err
+	}
+	workspaceDir := filepath.Join(stepDir, "workspace")
+	branchName := runpkg.TaskBranch(a.cfg.Git, a.runInput.TaskID, a.runInput.RunID)
+	removeWorktree, err := mountStepWorktree(ctx, worktreeSlots, a.cfg.MaxWorktrees, a.runInput.WorkingDir, workspaceDir, branchName, a.baseBranch)
+	if err != nil {
+		return nil, fmt.Errorf("mount worktree: %w", err)
+	}
+	defer func() {
+		if err := removeWorktree(); err != nil {
+			log.Warn().Err(err).Str("workspace", workspaceDir).Msg("failed to remove observer worktree")
+		}
+	}()
+
+	absStepDir, err := filepath.Abs(stepDir)
+	if err != nil {
+		return nil, fmt.Errorf("resolve step dir path: %w", err)
+	}
+	absWorkspaceDir, err := filepath.Abs(workspaceDir)
+	if err != nil {
+		return nil, fmt.Errorf("resolve workspace dir path: %w", err)
+	}
+	req.Paths = contracts.RequestPaths{WorkspaceDir: absWorkspaceDir, RunDir: absStepDir}
+	if err := fsutil.WriteJSONAtomic(filepath.Join(stepDir, "input.json"), req); err != nil {
+		return nil, err
+	}
+
+	agentCfg := resolveModel(a.cfg.Agents[name], iteration)
+	runner, err := NewRunner(agentCfg, role,
+		WithShutdownGrace(time.Duration(a.cfg.AgentShutdownGrace)*time.Second),
+		WithSafetyProfile(a.cfg.Safety.Profile),
+		WithSystemPromptPreamble(a.cfg.SystemPromptPreamble),
+		WithSanitizeOutput(a.cfg.SanitizeAgentOutput),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("create runner for observer %q: %w", name, err)
+	}
+	runner = withConcurrencyLimit(runner, agentSlots, a.cfg.MaxConcurrentAgents)
+
+	stdoutFile, err := os.OpenFile(filepath.Join(stepDir, "logs", "stdout.txt"), os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("create stdout log file: %w", err)
+	}
+	defer func() { _ = stdoutFile.Close() }()
+	stderrFile, err := os.OpenFile(filepath.Join(stepDir, "logs", "stderr.txt"), os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("create stderr log file: %w", err)
+	}
+	defer func() { _ = stderrFile.Close() }()
+	mirrorStdout, mirrorStderr := mirrorAgentOutput(a.cfg.Logging, logging.DebugEnabled())
+	stdout, stderr := agentOutputWriters(mirrorStdout, mirrorStderr, stdoutFile, stderrFile)
+
+	startTime := time.Now()
+	out, err := runAttempts(ctx, runner, req, agentCfg.Attempts(), stdout, stderr, responseAcceptor(role, a.cfg.RetryInvalidResponse), func(attempt int, err error) {
+		log.Warn().Err(err).Str("observer", name).Int("attempt", attempt).Msg("observer agent failed, retrying")
+	})
+	if err != nil {
+		return nil, fmt.Errorf("run observer %q agent: %w", name, err)
+	}
+	resp, err := role.MapResponse(out)
+	if err != nil {
+		return nil, fmt.Errorf("map observer response: %w", err)
+	}
+	if err := fsutil.WriteJSONAtomic(filepath.Join(stepDir, "output.json"), resp); err != nil {
+		return nil, err
+	}
+
+	if a.store != nil {
+		stepRec := db.StepRecord{
+			RunID:     a.runInput.RunID,
+			StepIndex: index,
+			Role:      roleObserver,
+			Iteration: iteration,
+			Status:    resp.Status,
+			StepDir:   stepDir,
+			StartedAt: startTime.UTC().Format(time.RFC3339),
+			EndedAt:   time.Now().UTC().Format(time.RFC3339),
+			Summary:   resp.Summary.Text,
+		}
+		update := db.Update{CurrentStepIndex: index, Iteration: iteration, Status: "running"}
+		if err := a.store.CommitStep(ctx, stepRec, nil, update); err != nil {
+			return nil, fmt.Errorf("commit observer step %d: %w", index, err)
+		}
+	}
+	return &resp, nil
+}
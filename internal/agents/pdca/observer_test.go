@@ -0,0 +1,64 @@
+package pdca
+
+import (
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/metalagman/norma/internal/agents/pdca/contracts"
+	"github.com/metalagman/norma/internal/agents/pdca/roles/check"
+	"github.com/metalagman/norma/internal/config"
+)
+
+func TestObserverAgents(t *testing.T) {
+	t.Parallel()
+
+	cfg := config.Config{
+		Agents:    map[string]config.AgentConfig{"critic": {Type: "codex_acp"}},
+		Observers: []string{" critic "},
+	}
+	got, err := observerAgents(cfg)
+	if err != nil {
+		t.Fatalf("observerAgents() error = %v", err)
+	}
+	if !slices.Equal(got, []string{"critic"}) {
+		t.Fatalf("observerAgents() = %v, want [critic]", got)
+	}
+
+	cfg.Observers = []string{"missing"}
+	if _, err := observerAgents(cfg); err == nil || !strings.Contains(err.Error(), `"missing"`) {
+		t.Fatalf("observerAgents(unknown) error = %v, want unknown agent error", err)
+	}
+}
+
+func TestApplyObserverToTaskStateKeepsStepOutputs(t *testing.T) {
+	t.Parallel()
+
+	verdict := &check.CheckOutput{Verdict: &check.CheckVerdict{Status: "PASS"}}
+	state := &contracts.TaskState{Check: verdict}
+	resp := &contracts.AgentResponse{
+		Status: "ok",
+		Check:  &check.CheckOutput{Verdict: &check.CheckVerdict{Status: "FAIL"}},
+		Progress: contracts.StepProgress{
+			Details: []string{"handler is missing tests"},
+		},
+	}
+
+	ts := time.Date(2026, time.February, 12, 13, 14, 15, 0, time.UTC)
+	applyObserverToTaskState(state, resp, "critic", "run-1", 2, 9, ts)
+
+	if state.Check != verdict {
+		t.Fatalf("state.Check was replaced by observer output")
+	}
+	if len(state.Journal) != 1 {
+		t.Fatalf("len(state.Journal) = %d, want 1", len(state.Journal))
+	}
+	entry := state.Journal[0]
+	if entry.Type != contracts.JournalEntryObserver || entry.Role != "critic" || entry.StepIndex != 9 {
+		t.Fatalf("journal entry = %+v, want critic observer entry at step 9", entry)
+	}
+	if entry.Title != "critic observer completed" {
+		t.Fatalf("journal title = %q, want default observer title", entry.Title)
+	}
+}
@@ -14,6 +14,9 @@ const (
 	RoleAct   = "act"
 )
 
+// labelHasDo marks tasks whose Do step completed; resumed runs skip Do.
+const labelHasDo = "norma-has-do"
+
 var (
 	roleMap  = make(map[string]contracts.Role)
 	initOnce sync.Once
@@ -0,0 +1,45 @@
+package pdca
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/metalagman/norma/internal/agents/pdca/contracts"
+	"github.com/metalagman/norma/internal/verify"
+)
+
+// Failure policies for a do_post_command that exits nonzero.
+const (
+	DoPostCommandFailureStop = "stop"
+	DoPostCommandFailureWarn = "warn"
+)
+
+// doPostCommandStopReason is the stop reason of a Do step stopped by a failing do_post_command.
+const doPostCommandStopReason = "post_command_failed"
+
+// runDoPostCommand runs command in workspaceDir after a Do step and keeps its output in
+// logs/post_command.txt under stepDir. A nonzero exit adds a blocker to the step progress;
+// the stop policy (default) also turns the response into a stop. It reports whether the command passed.
+func runDoPostCommand(ctx context.Context, workspaceDir, stepDir, command, policy string, resp *contracts.AgentResponse) (bool, error) {
+	exitCode, output, err := verify.RunCommand(ctx, workspaceDir, command)
+	if err != nil {
+		return false, fmt.Errorf("run do post command %q: %w", command, err)
+	}
+	if err := os.WriteFile(filepath.Join(stepDir, "logs", "post_command.txt"), []byte(output), 0o600); err != nil {
+		return false, fmt.Errorf("write do post command log: %w", err)
+	}
+	if exitCode == 0 {
+		return true, nil
+	}
+
+	resp.Progress.Details = append(resp.Progress.Details,
+		fmt.Sprintf("do post command %q exited %d; see logs/post_command.txt", command, exitCode))
+	if !strings.EqualFold(strings.TrimSpace(policy), DoPostCommandFailureWarn) {
+		resp.Status = "stop"
+		resp.StopReason = doPostCommandStopReason
+	}
+	return false, nil
+}
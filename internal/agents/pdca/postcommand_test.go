@@ -0,0 +1,67 @@
+package pdca
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/metalagman/norma/internal/agents/pdca/contracts"
+)
+
+func TestRunDoPostCommand(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		command    string
+		policy     string
+		wantPassed bool
+		wantStatus string
+		wantReason string
+		wantLog    string
+	}{
+		{name: "passing", command: "test -f main.go && echo built", wantPassed: true, wantStatus: "ok", wantLog: "built"},
+		{name: "failing stops", command: "echo broken >&2; exit 2", wantStatus: "stop", wantReason: doPostCommandStopReason, wantLog: "broken"},
+		{name: "failing warns", command: "exit 2", policy: DoPostCommandFailureWarn, wantStatus: "ok"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctx := context.Background()
+			workspaceDir := t.TempDir()
+			initTestRepo(t, ctx, workspaceDir)
+			writeTestFile(t, filepath.Join(workspaceDir, "main.go"), "package main\n")
+			stepDir := t.TempDir()
+			if err := os.MkdirAll(filepath.Join(stepDir, "logs"), 0o700); err != nil {
+				t.Fatalf("create logs dir: %v", err)
+			}
+
+			resp := &contracts.AgentResponse{Status: "ok"}
+			passed, err := runDoPostCommand(ctx, workspaceDir, stepDir, tc.command, tc.policy, resp)
+			if err != nil {
+				t.Fatalf("runDoPostCommand() error = %v", err)
+			}
+			if passed != tc.wantPassed {
+				t.Fatalf("runDoPostCommand() passed = %t, want %t", passed, tc.wantPassed)
+			}
+			if resp.Status != tc.wantStatus || resp.StopReason != tc.wantReason {
+				t.Fatalf("response = (%q, %q), want (%q, %q)", resp.Status, resp.StopReason, tc.wantStatus, tc.wantReason)
+			}
+			if blocked := len(resp.Progress.Details) > 0; blocked == tc.wantPassed {
+				t.Fatalf("progress details = %v, want a blocker only on failure", resp.Progress.Details)
+			}
+
+			logData, err := os.ReadFile(filepath.Join(stepDir, "logs", "post_command.txt"))
+			if err != nil {
+				t.Fatalf("read post command log: %v", err)
+			}
+			if !strings.Contains(string(logData), tc.wantLog) {
+				t.Fatalf("post command log = %q, want it to contain %q", logData, tc.wantLog)
+			}
+		})
+	}
+}
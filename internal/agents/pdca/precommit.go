@@ -0,0 +1,80 @@
+package pdca
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/metalagman/norma/internal/agents/pdca/contracts"
+	"github.com/metalagman/norma/internal/config"
+	"github.com/metalagman/norma/internal/git"
+	"github.com/metalagman/norma/internal/verify"
+)
+
+// preCommitStopReason is the stop reason of a Do step whose changes failed the pre-commit check.
+const preCommitStopReason = "pre_commit_failed"
+
+// preCommitError reports a pre-commit command that exited nonzero; the Do changes stay uncommitted.
+type preCommitError struct {
+	Command  string
+	ExitCode int
+	Output   string
+}
+
+func (e *preCommitError) Error() string {
+	return fmt.Sprintf("pre-commit command %q exited %d", e.Command, e.ExitCode)
+}
+
+// resolvePreCommit returns the command run before Do commits when git.run_pre_commit is set:
+// git.pre_commit_command if configured, else the repository's executable pre-commit hook.
+// It returns an empty command when the check is disabled or no hook is installed.
+func resolvePreCommit(ctx context.Context, cfg config.GitConfig, workspaceDir string) (string, error) {
+	if !cfg.RunPreCommit {
+		return "", nil
+	}
+	if command := strings.TrimSpace(cfg.PreCommitCommand); command != "" {
+		return command, nil
+	}
+
+	out, err := git.GitRunCmdOutput(ctx, workspaceDir, "git", "rev-parse", "--git-path", "hooks/pre-commit")
+	if err != nil {
+		return "", fmt.Errorf("resolve pre-commit hook path: %w", err)
+	}
+	hookPath := strings.TrimSpace(out)
+	if !filepath.IsAbs(hookPath) {
+		hookPath = filepath.Join(workspaceDir, hookPath)
+	}
+	info, err := os.Stat(hookPath)
+	if err != nil || info.IsDir() || info.Mode()&0o111 == 0 {
+		return "", nil
+	}
+	return "'" + strings.ReplaceAll(hookPath, "'", `'\''`) + "'", nil
+}
+
+// runPreCommit runs command in workspaceDir against the staged changes and
+// returns a *preCommitError when it exits nonzero.
+func runPreCommit(ctx context.Context, workspaceDir, command string) error {
+	exitCode, output, err := verify.RunCommand(ctx, workspaceDir, command)
+	if err != nil {
+		return fmt.Errorf("run pre-commit command %q: %w", command, err)
+	}
+	if exitCode != 0 {
+		return &preCommitError{Command: command, ExitCode: exitCode, Output: output}
+	}
+	return nil
+}
+
+// blockPreCommit keeps the failed pre-commit output in logs/pre_commit.txt under stepDir
+// and stops the Do step with a blocker pointing at it.
+func blockPreCommit(stepDir string, failure *preCommitError, resp *contracts.AgentResponse) error {
+	if err := os.WriteFile(filepath.Join(stepDir, "logs", "pre_commit.txt"), []byte(failure.Output), 0o600); err != nil {
+		return fmt.Errorf("write pre-commit log: %w", err)
+	}
+	resp.Progress.Details = append(resp.Progress.Details,
+		fmt.Sprintf("pre-commit command %q exited %d; see logs/pre_commit.txt", failure.Command, failure.ExitCode))
+	resp.Status = "stop"
+	resp.StopReason = preCommitStopReason
+	return nil
+}
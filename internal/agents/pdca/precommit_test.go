@@ -0,0 +1,119 @@
+package pdca
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/metalagman/norma/internal/agents/pdca/contracts"
+	"github.com/metalagman/norma/internal/config"
+)
+
+func TestCommitWorkspaceChangesRunsPreCommit(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name          string
+		preCommit     string
+		wantCommitted bool
+		wantExitCode  int
+	}{
+		{name: "passing", preCommit: "git diff --cached --name-only | grep -q b.txt", wantCommitted: true},
+		{name: "failing", preCommit: "echo lint failed >&2; exit 3", wantExitCode: 3},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctx := context.Background()
+			workingDir := t.TempDir()
+			initTestRepo(t, ctx, workingDir)
+			writeTestFile(t, filepath.Join(workingDir, "a.txt"), "one\n")
+			runGit(t, ctx, workingDir, "add", "a.txt")
+			runGit(t, ctx, workingDir, "commit", "-m", "chore: initial")
+			before := strings.TrimSpace(runGit(t, ctx, workingDir, "rev-parse", "HEAD"))
+			writeTestFile(t, filepath.Join(workingDir, "b.txt"), "new\n")
+
+			committed, err := commitWorkspaceChanges(ctx, workingDir, "run-1", "norma-8sl", 2, tc.preCommit)
+			after := strings.TrimSpace(runGit(t, ctx, workingDir, "rev-parse", "HEAD"))
+			if tc.wantCommitted {
+				if err != nil || !committed {
+					t.Fatalf("commitWorkspaceChanges() = (%t, %v), want (true, nil)", committed, err)
+				}
+				if after == before {
+					t.Fatal("expected a new commit after a passing pre-commit command")
+				}
+				return
+			}
+
+			var failure *preCommitError
+			if !errors.As(err, &failure) {
+				t.Fatalf("commitWorkspaceChanges() error = %v, want *preCommitError", err)
+			}
+			if committed || after != before {
+				t.Fatalf("committed = %t, HEAD %s -> %s; want no commit", committed, before, after)
+			}
+			if failure.ExitCode != tc.wantExitCode || !strings.Contains(failure.Output, "lint failed") {
+				t.Fatalf("failure = %+v, want exit %d with hook output", failure, tc.wantExitCode)
+			}
+
+			stepDir := t.TempDir()
+			if err := os.MkdirAll(filepath.Join(stepDir, "logs"), 0o700); err != nil {
+				t.Fatalf("create logs dir: %v", err)
+			}
+			resp := &contracts.AgentResponse{Status: "ok"}
+			if err := blockPreCommit(stepDir, failure, resp); err != nil {
+				t.Fatalf("blockPreCommit() error = %v", err)
+			}
+			if resp.Status != "stop" || resp.StopReason != preCommitStopReason || len(resp.Progress.Details) != 1 {
+				t.Fatalf("response = %+v, want stop with a pre-commit blocker", resp)
+			}
+			logData, err := os.ReadFile(filepath.Join(stepDir, "logs", "pre_commit.txt"))
+			if err != nil {
+				t.Fatalf("read pre-commit log: %v", err)
+			}
+			if !strings.Contains(string(logData), "lint failed") {
+				t.Fatalf("pre-commit log = %q, want hook output", logData)
+			}
+		})
+	}
+}
+
+func TestResolvePreCommit(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	workingDir := t.TempDir()
+	initTestRepo(t, ctx, workingDir)
+
+	got, err := resolvePreCommit(ctx, config.GitConfig{PreCommitCommand: "make lint"}, workingDir)
+	if err != nil || got != "" {
+		t.Fatalf("resolvePreCommit(disabled) = (%q, %v), want empty", got, err)
+	}
+
+	got, err = resolvePreCommit(ctx, config.GitConfig{RunPreCommit: true}, workingDir)
+	if err != nil || got != "" {
+		t.Fatalf("resolvePreCommit(no hook) = (%q, %v), want empty", got, err)
+	}
+
+	got, err = resolvePreCommit(ctx, config.GitConfig{RunPreCommit: true, PreCommitCommand: " make lint "}, workingDir)
+	if err != nil || got != "make lint" {
+		t.Fatalf("resolvePreCommit(command) = (%q, %v), want %q", got, err, "make lint")
+	}
+
+	hookPath := filepath.Join(workingDir, ".git", "hooks", "pre-commit")
+	if err := os.MkdirAll(filepath.Dir(hookPath), 0o700); err != nil {
+		t.Fatalf("create hooks dir: %v", err)
+	}
+	if err := os.WriteFile(hookPath, []byte("#!/bin/sh\nexit 0\n"), 0o700); err != nil {
+		t.Fatalf("write hook: %v", err)
+	}
+	got, err = resolvePreCommit(ctx, config.GitConfig{RunPreCommit: true}, workingDir)
+	if err != nil || got != "'"+hookPath+"'" {
+		t.Fatalf("resolvePreCommit(hook) = (%q, %v), want quoted %q", got, err, hookPath)
+	}
+}
@@ -0,0 +1,85 @@
+package pdca
+
+import (
+	"bytes"
+	"io"
+)
+
+// reasoningLogName is the step log receiving reasoning lines split from agent output.
+const reasoningLogName = "thinking.txt"
+
+// splitReasoning reports whether line is reasoning output: it starts with marker,
+// ignoring leading whitespace. An empty marker matches nothing.
+func splitReasoning(line []byte, marker string) (isReasoning bool) {
+	if marker == "" {
+		return false
+	}
+	return bytes.HasPrefix(bytes.TrimLeft(line, " \t"), []byte(marker))
+}
+
+// separateReasoning splits text into its regular lines and its reasoning lines.
+func separateReasoning(text []byte, marker string) (output, reasoning []byte) {
+	if marker == "" {
+		return text, nil
+	}
+	for _, line := range bytes.SplitAfter(text, []byte("\n")) {
+		if len(line) == 0 {
+			continue
+		}
+		if splitReasoning(line, marker) {
+			reasoning = append(reasoning, line...)
+			if !bytes.HasSuffix(line, []byte("\n")) {
+				reasoning = append(reasoning, '\n')
+			}
+			continue
+		}
+		output = append(output, line...)
+	}
+	return output, reasoning
+}
+
+// reasoningWriter routes complete lines written to it: reasoning lines to thinking,
+// everything else to out. Flush writes a trailing partial line.
+type reasoningWriter struct {
+	marker   string
+	out      io.Writer
+	thinking io.Writer
+	pending  []byte
+}
+
+func newReasoningWriter(marker string, out, thinking io.Writer) *reasoningWriter {
+	return &reasoningWriter{marker: marker, out: out, thinking: thinking}
+}
+
+func (w *reasoningWriter) Write(p []byte) (int, error) {
+	w.pending = append(w.pending, p...)
+	for {
+		idx := bytes.IndexByte(w.pending, '\n')
+		if idx < 0 {
+			return len(p), nil
+		}
+		if err := w.route(w.pending[:idx+1]); err != nil {
+			return 0, err
+		}
+		w.pending = w.pending[idx+1:]
+	}
+}
+
+// Flush writes any buffered partial line.
+func (w *reasoningWriter) Flush() error {
+	if len(w.pending) == 0 {
+		return nil
+	}
+	err := w.route(w.pending)
+	w.pending = nil
+	return err
+}
+
+func (w *reasoningWriter) route(line []byte) error {
+	dst := w.out
+	if splitReasoning(line, w.marker) {
+		dst = w.thinking
+	}
+	_, err := dst.Write(line)
+	return err
+}
@@ -0,0 +1,42 @@
+package pdca
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSplitReasoning(t *testing.T) {
+	assert.True(t, splitReasoning([]byte("THINK: plan\n"), "THINK:"))
+	assert.True(t, splitReasoning([]byte("\t THINK: plan"), "THINK:"))
+	assert.False(t, splitReasoning([]byte(`{"status":"ok"}`), "THINK:"))
+	assert.False(t, splitReasoning([]byte("THINK: plan"), ""))
+}
+
+func TestSeparateReasoningKeepsJSONParseable(t *testing.T) {
+	text := []byte("THINK: first\n{\"status\":\"ok\",\n\"summary\":\"done\"}\nTHINK: last")
+
+	output, reasoning := separateReasoning(text, "THINK:")
+
+	assert.Equal(t, "THINK: first\nTHINK: last\n", string(reasoning))
+	var decoded map[string]any
+	require.NoError(t, json.Unmarshal(output, &decoded))
+	assert.Equal(t, "ok", decoded["status"])
+}
+
+func TestReasoningWriterRoutesLines(t *testing.T) {
+	var out, thinking bytes.Buffer
+	w := newReasoningWriter("THINK:", &out, &thinking)
+
+	_, err := w.Write([]byte("THINK: a\n{\"status\""))
+	require.NoError(t, err)
+	_, err = w.Write([]byte(":\"ok\"}\nTHINK: b"))
+	require.NoError(t, err)
+	require.NoError(t, w.Flush())
+
+	assert.Equal(t, "{\"status\":\"ok\"}\n", out.String())
+	assert.Equal(t, "THINK: a\nTHINK: b", thinking.String())
+}
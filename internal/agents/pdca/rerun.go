@@ -0,0 +1,155 @@
+package pdca
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/metalagman/norma/internal/agents/pdca/contracts"
+	"github.com/metalagman/norma/internal/fsutil"
+	"github.com/metalagman/norma/internal/git"
+	runpkg "github.com/metalagman/norma/internal/run"
+	"github.com/rs/zerolog/log"
+)
+
+// stepBaseFile records the workspace HEAD a step started from, so the step can be rerun.
+const stepBaseFile = "base_head.txt"
+
+// recordStepBase writes the workspace HEAD to the step directory and returns it.
+func recordStepBase(ctx context.Context, workspaceDir, stepDir string) (string, error) {
+	out, err := git.GitRunCmdOutput(ctx, workspaceDir, "git", "rev-parse", "HEAD")
+	if err != nil {
+		return "", fmt.Errorf("resolve pre-step workspace HEAD: %w", err)
+	}
+	head := strings.TrimSpace(out)
+	if err := os.WriteFile(filepath.Join(stepDir, stepBaseFile), []byte(head+"\n"), 0o600); err != nil {
+		return "", fmt.Errorf("record step base: %w", err)
+	}
+	return head, nil
+}
+
+// RerunStep replays one recorded step of a run: it rebuilds the request from the
+// step's input.json, mounts a detached worktree at the step's recorded base, and
+// runs the step's role again. Results go to steps/<step>/reruns/<timestamp>; the
+// run record, task state, and task branch are left untouched.
+func (w *Factory) RerunStep(ctx context.Context, meta runpkg.RunMeta, stepIndex int) (runpkg.StepRerunResult, error) {
+	stepDir, err := findStepDir(meta.RunDir, stepIndex)
+	if err != nil {
+		return runpkg.StepRerunResult{}, err
+	}
+	var req contracts.AgentRequest
+	if err := readJSONFile(filepath.Join(stepDir, "input.json"), &req); err != nil {
+		return runpkg.StepRerunResult{}, fmt.Errorf("read step input: %w", err)
+	}
+	roleName := req.Step.Name
+	role := GetRole(roleName)
+	if role == nil {
+		return runpkg.StepRerunResult{}, fmt.Errorf("unknown role %q in step %d", roleName, stepIndex)
+	}
+
+	base, err := os.ReadFile(filepath.Join(stepDir, stepBaseFile))
+	if err != nil {
+		return runpkg.StepRerunResult{}, fmt.Errorf("read step base (steps recorded before reruns were supported cannot be rerun): %w", err)
+	}
+
+	rerunDir := filepath.Join(stepDir, "reruns", time.Now().UTC().Format("20060102-150405.000"))
+	for _, dir := range []string{"logs", "artifacts"} {
+		if err := os.MkdirAll(filepath.Join(rerunDir, dir), 0o700); err != nil {
+			return runpkg.StepRerunResult{}, fmt.Errorf("create rerun dir: %w", err)
+		}
+	}
+	absRerunDir, err := filepath.Abs(rerunDir)
+	if err != nil {
+		return runpkg.StepRerunResult{}, fmt.Errorf("resolve rerun dir path: %w", err)
+	}
+	workspaceDir := filepath.Join(absRerunDir, "workspace")
+	if err := git.GitRunCmdErr(ctx, meta.GitRoot, "git", "worktree", "add", "--detach", workspaceDir, strings.TrimSpace(string(base))); err != nil {
+		return runpkg.StepRerunResult{}, fmt.Errorf("mount rerun worktree: %w", err)
+	}
+	defer func() {
+		if err := git.GitRunCmdErr(context.WithoutCancel(ctx), meta.GitRoot, "git", "worktree", "remove", "--force", workspaceDir); err != nil {
+			log.Warn().Err(err).Str("workspace", workspaceDir).Msg("failed to remove rerun worktree")
+		}
+	}()
+
+	req.Paths = contracts.RequestPaths{WorkspaceDir: workspaceDir, RunDir: absRerunDir}
+	if err := fsutil.WriteJSONAtomic(filepath.Join(rerunDir, "input.json"), req); err != nil {
+		return runpkg.StepRerunResult{}, err
+	}
+
+	agentCfg, err := resolvedAgentForRole(w.cfg.Agents, w.cfg.RoleIDs, roleName)
+	if err != nil {
+		return runpkg.StepRerunResult{}, err
+	}
+	agentCfg = resolveModel(agentCfg, req.Run.Iteration)
+	runner, err := NewRunner(agentCfg, role,
+		WithShutdownGrace(time.Duration(w.cfg.AgentShutdownGrace)*time.Second),
+		WithSafetyProfile(w.cfg.Safety.Profile),
+		WithSystemPromptPreamble(w.cfg.SystemPromptPreamble),
+		WithSanitizeOutput(w.cfg.SanitizeAgentOutput),
+	)
+	if err != nil {
+		return runpkg.StepRerunResult{}, fmt.Errorf("create runner for role %q: %w", roleName, err)
+	}
+
+	stdoutFile, err := os.Create(filepath.Join(rerunDir, "logs", "stdout.txt"))
+	if err != nil {
+		return runpkg.StepRerunResult{}, fmt.Errorf("create stdout log file: %w", err)
+	}
+	defer func() { _ = stdoutFile.Close() }()
+	stderrFile, err := os.Create(filepath.Join(rerunDir, "logs", "stderr.txt"))
+	if err != nil {
+		return runpkg.StepRerunResult{}, fmt.Errorf("create stderr log file: %w", err)
+	}
+	defer func() { _ = stderrFile.Close() }()
+
+	maxAttempts := agentCfg.Attempts()
+	out, err := runAttempts(ctx, runner, req, maxAttempts, stdoutFile, stderrFile, responseAcceptor(role, w.cfg.RetryInvalidResponse), func(attempt int, err error) {
+		log.Warn().Err(err).Str("role", roleName).Int("attempt", attempt).Int("max_attempts", maxAttempts).Msg("rerun agent failed, retrying")
+	})
+	if err != nil {
+		return runpkg.StepRerunResult{Dir: rerunDir}, fmt.Errorf("rerun role %q agent: %w", roleName, err)
+	}
+	resp, err := role.MapResponse(out)
+	if err != nil {
+		return runpkg.StepRerunResult{Dir: rerunDir}, fmt.Errorf("map response: %w", err)
+	}
+	if err := fsutil.WriteJSONAtomic(filepath.Join(rerunDir, "output.json"), resp); err != nil {
+		return runpkg.StepRerunResult{Dir: rerunDir}, err
+	}
+
+	return runpkg.StepRerunResult{
+		Dir:     rerunDir,
+		Role:    roleName,
+		Status:  resp.Status,
+		Summary: resp.Summary.Text,
+	}, nil
+}
+
+// findStepDir returns the directory of step stepIndex in runDir.
+func findStepDir(runDir string, stepIndex int) (string, error) {
+	matches, err := filepath.Glob(filepath.Join(runDir, "steps", fmt.Sprintf("%03d-*", stepIndex)))
+	if err != nil {
+		return "", fmt.Errorf("find step %d: %w", stepIndex, err)
+	}
+	if len(matches) != 1 {
+		return "", fmt.Errorf("find step %d in %s: %w", stepIndex, runDir, os.ErrNotExist)
+	}
+	return matches[0], nil
+}
+
+// readJSONFile decodes the JSON file at path into v.
+func readJSONFile(path string, v any) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("decode %s: %w", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,73 @@
+package pdca
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/metalagman/norma/internal/config"
+	runpkg "github.com/metalagman/norma/internal/run"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRerunStepWritesFreshOutputWithoutTouchingStep(t *testing.T) {
+	ctx := context.Background()
+	repoDir := t.TempDir()
+	initTestRepo(t, ctx, repoDir)
+	writeTestFile(t, filepath.Join(repoDir, "a.txt"), "base\n")
+	runGit(t, ctx, repoDir, "add", "a.txt")
+	runGit(t, ctx, repoDir, "commit", "-m", "base")
+	base := runGit(t, ctx, repoDir, "rev-parse", "HEAD")
+
+	runDir := filepath.Join(repoDir, ".norma", "runs", "run-1")
+	stepDir := filepath.Join(runDir, "steps", "004-act")
+	require.NoError(t, os.MkdirAll(stepDir, 0o700))
+	input, err := os.ReadFile(filepath.Join("roles", "testdata", "roundtrip", "act.request.json"))
+	require.NoError(t, err)
+	writeTestFile(t, filepath.Join(stepDir, "input.json"), string(input))
+	writeTestFile(t, filepath.Join(stepDir, stepBaseFile), base)
+	writeTestFile(t, filepath.Join(stepDir, "output.json"), `{"status":"ok","summary":{"text":"original"}}`)
+
+	response, err := os.ReadFile(filepath.Join("roles", "testdata", "roundtrip", "act.response.json"))
+	require.NoError(t, err)
+	var compact bytes.Buffer
+	require.NoError(t, json.Compact(&compact, response))
+
+	factory := NewFactory(config.Config{
+		Agents: map[string]config.AgentConfig{
+			"helper": {Type: config.AgentTypeGenericACP, Cmd: helperACPCommand(t, compact.String())},
+		},
+		RoleIDs: map[string]string{RoleAct: "helper"},
+	}, nil, nil)
+
+	res, err := factory.RerunStep(ctx, runpkg.RunMeta{RunID: "run-1", RunDir: runDir, GitRoot: repoDir}, 4)
+	require.NoError(t, err)
+	assert.Equal(t, RoleAct, res.Role)
+	assert.Equal(t, "ok", res.Status)
+	assert.Equal(t, "replanning", res.Summary)
+	assert.Equal(t, filepath.Join(stepDir, "reruns"), filepath.Dir(res.Dir))
+
+	out, err := os.ReadFile(filepath.Join(res.Dir, "output.json"))
+	require.NoError(t, err)
+	assert.Contains(t, string(out), `"replanning"`)
+
+	original, err := os.ReadFile(filepath.Join(stepDir, "output.json"))
+	require.NoError(t, err)
+	assert.Contains(t, string(original), `"original"`)
+
+	_, err = os.Stat(filepath.Join(res.Dir, "workspace"))
+	assert.True(t, os.IsNotExist(err), "rerun worktree should be removed")
+	assert.NotContains(t, runGit(t, ctx, repoDir, "worktree", "list"), res.Dir)
+}
+
+func TestRerunStepMissingStep(t *testing.T) {
+	t.Parallel()
+
+	factory := NewFactory(config.Config{}, nil, nil)
+	_, err := factory.RerunStep(context.Background(), runpkg.RunMeta{RunDir: t.TempDir()}, 2)
+	require.ErrorIs(t, err, os.ErrNotExist)
+}
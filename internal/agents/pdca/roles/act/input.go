@@ -10,9 +10,10 @@ import (
 
 // ActAcceptanceResult
 type ActAcceptanceResult struct {
-	AcId   string `json:"ac_id"`
-	Notes  string `json:"notes,omitempty"`
-	Result string `json:"result"`
+	AcId   string  `json:"ac_id"`
+	Notes  string  `json:"notes,omitempty"`
+	Result string  `json:"result"`
+	Score  float64 `json:"score,omitempty"`
 }
 
 // ActBudgets
@@ -37,9 +38,10 @@ type ActCheckVerdictBasis struct {
 
 // ActContext
 type ActContext struct {
-	Attempt int64     `json:"attempt,omitempty"`
-	Facts   *ActFacts `json:"facts,omitempty"`
-	Links   []string  `json:"links,omitempty"`
+	Attempt               int64     `json:"attempt,omitempty"`
+	Facts                 *ActFacts `json:"facts,omitempty"`
+	Links                 []string  `json:"links,omitempty"`
+	PreviousResponseError string    `json:"previous_response_error,omitempty"`
 }
 
 // ActFacts
@@ -131,6 +133,17 @@ func (strct *ActAcceptanceResult) MarshalJSON() ([]byte, error) {
 		buf.Write(tmp)
 	}
 	comma = true
+	// Marshal the "score" field
+	if comma {
+		buf.WriteString(",")
+	}
+	buf.WriteString("\"score\": ")
+	if tmp, err := json.Marshal(strct.Score); err != nil {
+		return nil, err
+	} else {
+		buf.Write(tmp)
+	}
+	comma = true
 
 	buf.WriteString("}")
 	rv := buf.Bytes()
@@ -161,6 +174,10 @@ func (strct *ActAcceptanceResult) UnmarshalJSON(b []byte) error {
 				return err
 			}
 			resultReceived = true
+		case "score":
+			if err := json.Unmarshal([]byte(v), &strct.Score); err != nil {
+				return err
+			}
 		}
 	}
 	// check if ac_id (a required property) was received
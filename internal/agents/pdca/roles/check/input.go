@@ -30,9 +30,11 @@ type CheckCheckStep struct {
 
 // CheckContext
 type CheckContext struct {
-	Attempt int64    `json:"attempt,omitempty"`
-	Facts   *Facts   `json:"facts,omitempty"`
-	Links   []string `json:"links,omitempty"`
+	Attempt               int64    `json:"attempt,omitempty"`
+	Constraints           []string `json:"constraints,omitempty"`
+	Facts                 *Facts   `json:"facts,omitempty"`
+	Links                 []string `json:"links,omitempty"`
+	PreviousResponseError string   `json:"previous_response_error,omitempty"`
 }
 
 // CheckDoExecution
@@ -57,6 +59,7 @@ type CheckEffectiveAcceptanceCriteria struct {
 // CheckInput
 type CheckInput struct {
 	AcceptanceCriteriaEffective []CheckEffectiveAcceptanceCriteria `json:"acceptance_criteria_effective"`
+	ChangedFiles                []string                           `json:"changed_files,omitempty"`
 	DoExecution                 *CheckDoExecution                  `json:"do_execution"`
 	WorkPlan                    *CheckWorkPlan                     `json:"work_plan"`
 }
@@ -595,6 +598,17 @@ func (strct *CheckInput) MarshalJSON() ([]byte, error) {
 		buf.Write(tmp)
 	}
 	comma = true
+	// Marshal the "changed_files" field
+	if comma {
+		buf.WriteString(",")
+	}
+	buf.WriteString("\"changed_files\": ")
+	if tmp, err := json.Marshal(strct.ChangedFiles); err != nil {
+		return nil, err
+	} else {
+		buf.Write(tmp)
+	}
+	comma = true
 	// "DoExecution" field is required
 	if strct.DoExecution == nil {
 		return nil, errors.New("do_execution is a required field")
@@ -647,6 +661,10 @@ func (strct *CheckInput) UnmarshalJSON(b []byte) error {
 				return err
 			}
 			acceptance_criteria_effectiveReceived = true
+		case "changed_files":
+			if err := json.Unmarshal([]byte(v), &strct.ChangedFiles); err != nil {
+				return err
+			}
 		case "do_execution":
 			if err := json.Unmarshal([]byte(v), &strct.DoExecution); err != nil {
 				return err
@@ -10,9 +10,11 @@ import (
 
 // CheckAcceptanceResult
 type CheckAcceptanceResult struct {
-	AcId   string `json:"ac_id"`
-	Notes  string `json:"notes,omitempty"`
-	Result string `json:"result"`
+	AcId   string  `json:"ac_id"`
+	LogRef string  `json:"log_ref,omitempty"`
+	Notes  string  `json:"notes,omitempty"`
+	Result string  `json:"result"`
+	Score  float64 `json:"score,omitempty"`
 }
 
 // CheckOutput
@@ -71,6 +73,17 @@ func (strct *CheckAcceptanceResult) MarshalJSON() ([]byte, error) {
 		buf.Write(tmp)
 	}
 	comma = true
+	// Marshal the "log_ref" field
+	if comma {
+		buf.WriteString(",")
+	}
+	buf.WriteString("\"log_ref\": ")
+	if tmp, err := json.Marshal(strct.LogRef); err != nil {
+		return nil, err
+	} else {
+		buf.Write(tmp)
+	}
+	comma = true
 	// Marshal the "notes" field
 	if comma {
 		buf.WriteString(",")
@@ -95,6 +108,17 @@ func (strct *CheckAcceptanceResult) MarshalJSON() ([]byte, error) {
 		buf.Write(tmp)
 	}
 	comma = true
+	// Marshal the "score" field
+	if comma {
+		buf.WriteString(",")
+	}
+	buf.WriteString("\"score\": ")
+	if tmp, err := json.Marshal(strct.Score); err != nil {
+		return nil, err
+	} else {
+		buf.Write(tmp)
+	}
+	comma = true
 
 	buf.WriteString("}")
 	rv := buf.Bytes()
@@ -116,6 +140,10 @@ func (strct *CheckAcceptanceResult) UnmarshalJSON(b []byte) error {
 				return err
 			}
 			ac_idReceived = true
+		case "log_ref":
+			if err := json.Unmarshal([]byte(v), &strct.LogRef); err != nil {
+				return err
+			}
 		case "notes":
 			if err := json.Unmarshal([]byte(v), &strct.Notes); err != nil {
 				return err
@@ -125,6 +153,10 @@ func (strct *CheckAcceptanceResult) UnmarshalJSON(b []byte) error {
 				return err
 			}
 			resultReceived = true
+		case "score":
+			if err := json.Unmarshal([]byte(v), &strct.Score); err != nil {
+				return err
+			}
 		}
 	}
 	// check if ac_id (a required property) was received
@@ -0,0 +1,47 @@
+package check
+
+import "strings"
+
+// Verdict statuses reported by the Check role.
+const (
+	VerdictPass    = "PASS"
+	VerdictFail    = "FAIL"
+	VerdictPartial = "PARTIAL"
+)
+
+// ResultScore returns the credit for a single acceptance result in [0, 1].
+// An explicit score wins; otherwise PASS counts as 1 and anything else as 0.
+func ResultScore(result CheckAcceptanceResult) float64 {
+	if result.Score > 0 {
+		return min(result.Score, 1)
+	}
+	if strings.EqualFold(strings.TrimSpace(result.Result), VerdictPass) {
+		return 1
+	}
+	return 0
+}
+
+// AggregateScore returns the mean credit across results, or 0 when there are none.
+func AggregateScore(results []CheckAcceptanceResult) float64 {
+	if len(results) == 0 {
+		return 0
+	}
+	total := 0.0
+	for _, result := range results {
+		total += ResultScore(result)
+	}
+	return total / float64(len(results))
+}
+
+// VerdictForScore maps an aggregate score to a verdict band:
+// PASS at 1, FAIL at 0 and PARTIAL in between.
+func VerdictForScore(score float64) string {
+	switch {
+	case score >= 1:
+		return VerdictPass
+	case score <= 0:
+		return VerdictFail
+	default:
+		return VerdictPartial
+	}
+}
@@ -0,0 +1,67 @@
+package check
+
+import "testing"
+
+func TestAggregateScore(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		results []CheckAcceptanceResult
+		want    float64
+		verdict string
+	}{
+		{name: "empty", want: 0, verdict: VerdictFail},
+		{
+			name: "all_pass",
+			results: []CheckAcceptanceResult{
+				{AcId: "AC1", Result: "PASS"},
+				{AcId: "AC2", Result: "pass"},
+			},
+			want:    1,
+			verdict: VerdictPass,
+		},
+		{
+			name: "all_fail",
+			results: []CheckAcceptanceResult{
+				{AcId: "AC1", Result: "FAIL"},
+				{AcId: "AC2", Result: "FAIL"},
+			},
+			want:    0,
+			verdict: VerdictFail,
+		},
+		{
+			name: "partial_credit",
+			results: []CheckAcceptanceResult{
+				{AcId: "AC1", Result: "PASS"},
+				{AcId: "AC2", Result: "FAIL", Score: 0.5},
+				{AcId: "AC3", Result: "FAIL"},
+				{AcId: "AC4", Result: "FAIL", Score: 0.5},
+			},
+			want:    0.5,
+			verdict: VerdictPartial,
+		},
+		{
+			name: "score_clamped",
+			results: []CheckAcceptanceResult{
+				{AcId: "AC1", Result: "PASS", Score: 3},
+			},
+			want:    1,
+			verdict: VerdictPass,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			got := AggregateScore(tc.results)
+			if got != tc.want {
+				t.Fatalf("AggregateScore() = %v, want %v", got, tc.want)
+			}
+			if verdict := VerdictForScore(got); verdict != tc.verdict {
+				t.Fatalf("VerdictForScore(%v) = %q, want %q", got, verdict, tc.verdict)
+			}
+		})
+	}
+}
@@ -38,9 +38,11 @@ type DoCheckStep struct {
 
 // DoContext
 type DoContext struct {
-	Attempt int64    `json:"attempt,omitempty"`
-	Facts   *Facts   `json:"facts,omitempty"`
-	Links   []string `json:"links,omitempty"`
+	Attempt               int64    `json:"attempt,omitempty"`
+	Constraints           []string `json:"constraints,omitempty"`
+	Facts                 *Facts   `json:"facts,omitempty"`
+	Links                 []string `json:"links,omitempty"`
+	PreviousResponseError string   `json:"previous_response_error,omitempty"`
 }
 
 // DoDoStep
@@ -0,0 +1,67 @@
+package roles
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/metalagman/norma/internal/agents/pdca/contracts"
+	"github.com/xeipuuv/gojsonschema"
+)
+
+// SchemaOverrideDir holds per-role schema overrides, relative to the repository root.
+const SchemaOverrideDir = ".norma/schemas"
+
+// schemaOverrider is implemented by roles whose schemas can be replaced.
+type schemaOverrider interface {
+	overrideSchemas(inputSchema, outputSchema string)
+}
+
+func (r *baseRole) overrideSchemas(inputSchema, outputSchema string) {
+	if inputSchema != "" {
+		r.inputSchema = inputSchema
+	}
+	if outputSchema != "" {
+		r.outputSchema = outputSchema
+	}
+}
+
+// LoadRoles returns the built-in roles with schemas overridden by
+// <role>.input.json and <role>.output.json files in dir.
+// Missing files keep the compiled-in schema.
+func LoadRoles(dir string) (map[string]contracts.Role, error) {
+	roles := DefaultRoles()
+	for name, role := range roles {
+		overrider, ok := role.(schemaOverrider)
+		if !ok {
+			continue
+		}
+		inputSchema, err := readSchemaOverride(filepath.Join(dir, name+".input.json"))
+		if err != nil {
+			return nil, err
+		}
+		outputSchema, err := readSchemaOverride(filepath.Join(dir, name+".output.json"))
+		if err != nil {
+			return nil, err
+		}
+		overrider.overrideSchemas(inputSchema, outputSchema)
+	}
+	return roles, nil
+}
+
+// readSchemaOverride returns the schema at path, or "" if the file does not exist.
+func readSchemaOverride(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return "", nil
+		}
+		return "", fmt.Errorf("read schema override %s: %w", path, err)
+	}
+	if _, err := gojsonschema.NewSchema(gojsonschema.NewBytesLoader(data)); err != nil {
+		return "", fmt.Errorf("invalid schema override %s: %w", path, err)
+	}
+	return string(data), nil
+}
@@ -0,0 +1,72 @@
+package roles
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/metalagman/norma/internal/agents/pdca/roles/do"
+	"github.com/metalagman/norma/internal/agents/pdca/roles/plan"
+)
+
+func TestLoadRolesUsesSchemaOverride(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	override := `{"type":"object","required":["status"],"properties":{"status":{"type":"string"}}}`
+	if err := os.WriteFile(filepath.Join(dir, "do.output.json"), []byte(override), 0o600); err != nil {
+		t.Fatalf("write override: %v", err)
+	}
+
+	got, err := LoadRoles(dir)
+	if err != nil {
+		t.Fatalf("LoadRoles() error = %v", err)
+	}
+	if schema := got[roleDo].OutputSchema(); schema != override {
+		t.Fatalf("do OutputSchema() = %q, want override", schema)
+	}
+	if schema := got[roleDo].InputSchema(); schema != do.InputSchema {
+		t.Fatal("do InputSchema() changed without an override file")
+	}
+	if schema := got[rolePlan].OutputSchema(); schema != plan.OutputSchema {
+		t.Fatal("plan OutputSchema() changed without an override file")
+	}
+	if schema := DefaultRoles()[roleDo].OutputSchema(); schema != do.OutputSchema {
+		t.Fatal("override leaked into DefaultRoles()")
+	}
+}
+
+func TestLoadRolesMissingDir(t *testing.T) {
+	t.Parallel()
+
+	got, err := LoadRoles(filepath.Join(t.TempDir(), "missing"))
+	if err != nil {
+		t.Fatalf("LoadRoles() error = %v", err)
+	}
+	if schema := got[roleDo].OutputSchema(); schema != do.OutputSchema {
+		t.Fatal("do OutputSchema() differs from the compiled-in schema")
+	}
+}
+
+func TestLoadRolesRejectsInvalidSchema(t *testing.T) {
+	t.Parallel()
+
+	for name, content := range map[string]string{
+		"malformed JSON":     `{"type":`,
+		"invalid JSONSchema": `{"type":"not-a-type"}`,
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			dir := t.TempDir()
+			if err := os.WriteFile(filepath.Join(dir, "check.input.json"), []byte(content), 0o600); err != nil {
+				t.Fatalf("write override: %v", err)
+			}
+			_, err := LoadRoles(dir)
+			if err == nil || !strings.Contains(err.Error(), "check.input.json") {
+				t.Fatalf("LoadRoles() error = %v, want invalid check.input.json", err)
+			}
+		})
+	}
+}
@@ -10,13 +10,15 @@ import (
 
 // PlanAcceptanceCriteria
 type PlanAcceptanceCriteria struct {
-	Id          string   `json:"id"`
-	Text        string   `json:"text"`
-	VerifyHints []string `json:"verify_hints,omitempty"`
+	Checks      []PlanSeededCheck `json:"checks,omitempty"`
+	Id          string            `json:"id"`
+	Text        string            `json:"text"`
+	VerifyHints []string          `json:"verify_hints,omitempty"`
 }
 
 // PlanBudgets
 type PlanBudgets struct {
+	MaxDoSteps         int64 `json:"max_do_steps,omitempty"`
 	MaxFailedChecks    int64 `json:"max_failed_checks,omitempty"`
 	MaxIterations      int64 `json:"max_iterations"`
 	MaxWallTimeMinutes int64 `json:"max_wall_time_minutes,omitempty"`
@@ -24,9 +26,11 @@ type PlanBudgets struct {
 
 // PlanContext
 type PlanContext struct {
-	Attempt int64      `json:"attempt,omitempty"`
-	Facts   *PlanFacts `json:"facts,omitempty"`
-	Links   []string   `json:"links,omitempty"`
+	Attempt               int64      `json:"attempt,omitempty"`
+	Facts                 *PlanFacts `json:"facts,omitempty"`
+	FailureDigest         string     `json:"failure_digest,omitempty"`
+	Links                 []string   `json:"links,omitempty"`
+	PreviousResponseError string     `json:"previous_response_error,omitempty"`
 }
 
 // PlanFacts
@@ -62,6 +66,13 @@ type PlanRun struct {
 	Iteration int64  `json:"iteration"`
 }
 
+// PlanSeededCheck
+type PlanSeededCheck struct {
+	Cmd             string  `json:"cmd"`
+	ExpectExitCodes []int64 `json:"expect_exit_codes"`
+	Id              string  `json:"id"`
+}
+
 // PlanStep
 type PlanStep struct {
 	Index int64  `json:"index"`
@@ -85,6 +96,17 @@ func (strct *PlanAcceptanceCriteria) MarshalJSON() ([]byte, error) {
 	buf := bytes.NewBuffer(make([]byte, 0))
 	buf.WriteString("{")
 	comma := false
+	// Marshal the "checks" field
+	if comma {
+		buf.WriteString(",")
+	}
+	buf.WriteString("\"checks\": ")
+	if tmp, err := json.Marshal(strct.Checks); err != nil {
+		return nil, err
+	} else {
+		buf.Write(tmp)
+	}
+	comma = true
 	// "Id" field is required
 	// only required object types supported for marshal checking (for now)
 	// Marshal the "id" field
@@ -138,6 +160,10 @@ func (strct *PlanAcceptanceCriteria) UnmarshalJSON(b []byte) error {
 	// parse all the defined properties
 	for k, v := range jsonMap {
 		switch k {
+		case "checks":
+			if err := json.Unmarshal([]byte(v), &strct.Checks); err != nil {
+				return err
+			}
 		case "id":
 			if err := json.Unmarshal([]byte(v), &strct.Id); err != nil {
 				return err
@@ -169,6 +195,17 @@ func (strct *PlanBudgets) MarshalJSON() ([]byte, error) {
 	buf := bytes.NewBuffer(make([]byte, 0))
 	buf.WriteString("{")
 	comma := false
+	// Marshal the "max_do_steps" field
+	if comma {
+		buf.WriteString(",")
+	}
+	buf.WriteString("\"max_do_steps\": ")
+	if tmp, err := json.Marshal(strct.MaxDoSteps); err != nil {
+		return nil, err
+	} else {
+		buf.Write(tmp)
+	}
+	comma = true
 	// Marshal the "max_failed_checks" field
 	if comma {
 		buf.WriteString(",")
@@ -219,6 +256,10 @@ func (strct *PlanBudgets) UnmarshalJSON(b []byte) error {
 	// parse all the defined properties
 	for k, v := range jsonMap {
 		switch k {
+		case "max_do_steps":
+			if err := json.Unmarshal([]byte(v), &strct.MaxDoSteps); err != nil {
+				return err
+			}
 		case "max_failed_checks":
 			if err := json.Unmarshal([]byte(v), &strct.MaxFailedChecks); err != nil {
 				return err
@@ -620,6 +661,98 @@ func (strct *PlanRun) UnmarshalJSON(b []byte) error {
 	return nil
 }
 
+func (strct *PlanSeededCheck) MarshalJSON() ([]byte, error) {
+	buf := bytes.NewBuffer(make([]byte, 0))
+	buf.WriteString("{")
+	comma := false
+	// "Cmd" field is required
+	// only required object types supported for marshal checking (for now)
+	// Marshal the "cmd" field
+	if comma {
+		buf.WriteString(",")
+	}
+	buf.WriteString("\"cmd\": ")
+	if tmp, err := json.Marshal(strct.Cmd); err != nil {
+		return nil, err
+	} else {
+		buf.Write(tmp)
+	}
+	comma = true
+	// "ExpectExitCodes" field is required
+	// only required object types supported for marshal checking (for now)
+	// Marshal the "expect_exit_codes" field
+	if comma {
+		buf.WriteString(",")
+	}
+	buf.WriteString("\"expect_exit_codes\": ")
+	if tmp, err := json.Marshal(strct.ExpectExitCodes); err != nil {
+		return nil, err
+	} else {
+		buf.Write(tmp)
+	}
+	comma = true
+	// "Id" field is required
+	// only required object types supported for marshal checking (for now)
+	// Marshal the "id" field
+	if comma {
+		buf.WriteString(",")
+	}
+	buf.WriteString("\"id\": ")
+	if tmp, err := json.Marshal(strct.Id); err != nil {
+		return nil, err
+	} else {
+		buf.Write(tmp)
+	}
+	comma = true
+
+	buf.WriteString("}")
+	rv := buf.Bytes()
+	return rv, nil
+}
+
+func (strct *PlanSeededCheck) UnmarshalJSON(b []byte) error {
+	cmdReceived := false
+	expect_exit_codesReceived := false
+	idReceived := false
+	var jsonMap map[string]json.RawMessage
+	if err := json.Unmarshal(b, &jsonMap); err != nil {
+		return err
+	}
+	// parse all the defined properties
+	for k, v := range jsonMap {
+		switch k {
+		case "cmd":
+			if err := json.Unmarshal([]byte(v), &strct.Cmd); err != nil {
+				return err
+			}
+			cmdReceived = true
+		case "expect_exit_codes":
+			if err := json.Unmarshal([]byte(v), &strct.ExpectExitCodes); err != nil {
+				return err
+			}
+			expect_exit_codesReceived = true
+		case "id":
+			if err := json.Unmarshal([]byte(v), &strct.Id); err != nil {
+				return err
+			}
+			idReceived = true
+		}
+	}
+	// check if cmd (a required property) was received
+	if !cmdReceived {
+		return errors.New("\"cmd\" is required but was not present")
+	}
+	// check if expect_exit_codes (a required property) was received
+	if !expect_exit_codesReceived {
+		return errors.New("\"expect_exit_codes\" is required but was not present")
+	}
+	// check if id (a required property) was received
+	if !idReceived {
+		return errors.New("\"id\" is required but was not present")
+	}
+	return nil
+}
+
 func (strct *PlanStep) MarshalJSON() ([]byte, error) {
 	buf := bytes.NewBuffer(make([]byte, 0))
 	buf.WriteString("{")
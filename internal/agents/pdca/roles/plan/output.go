@@ -13,6 +13,8 @@ type CriterionCheck struct {
 	Cmd             string  `json:"cmd"`
 	ExpectExitCodes []int64 `json:"expect_exit_codes"`
 	Id              string  `json:"id"`
+	Mode            string  `json:"mode,omitempty"`
+	Url             string  `json:"url,omitempty"`
 }
 
 // EffectiveAcceptanceCriteria
@@ -42,6 +44,7 @@ type PlanDoStep struct {
 // PlanOutput
 type PlanOutput struct {
 	AcceptanceCriteria *PlanOutputAcceptanceCriteria `json:"acceptance_criteria"`
+	Constraints        []string                      `json:"constraints,omitempty"`
 	WorkPlan           *PlanWorkPlan                 `json:"work_plan"`
 }
 
@@ -121,6 +124,28 @@ func (strct *CriterionCheck) MarshalJSON() ([]byte, error) {
 		buf.Write(tmp)
 	}
 	comma = true
+	// Marshal the "mode" field
+	if comma {
+		buf.WriteString(",")
+	}
+	buf.WriteString("\"mode\": ")
+	if tmp, err := json.Marshal(strct.Mode); err != nil {
+		return nil, err
+	} else {
+		buf.Write(tmp)
+	}
+	comma = true
+	// Marshal the "url" field
+	if comma {
+		buf.WriteString(",")
+	}
+	buf.WriteString("\"url\": ")
+	if tmp, err := json.Marshal(strct.Url); err != nil {
+		return nil, err
+	} else {
+		buf.Write(tmp)
+	}
+	comma = true
 
 	buf.WriteString("}")
 	rv := buf.Bytes()
@@ -153,6 +178,14 @@ func (strct *CriterionCheck) UnmarshalJSON(b []byte) error {
 				return err
 			}
 			idReceived = true
+		case "mode":
+			if err := json.Unmarshal([]byte(v), &strct.Mode); err != nil {
+				return err
+			}
+		case "url":
+			if err := json.Unmarshal([]byte(v), &strct.Url); err != nil {
+				return err
+			}
 		}
 	}
 	// check if cmd (a required property) was received
@@ -518,6 +551,17 @@ func (strct *PlanOutput) MarshalJSON() ([]byte, error) {
 		buf.Write(tmp)
 	}
 	comma = true
+	// Marshal the "constraints" field
+	if comma {
+		buf.WriteString(",")
+	}
+	buf.WriteString("\"constraints\": ")
+	if tmp, err := json.Marshal(strct.Constraints); err != nil {
+		return nil, err
+	} else {
+		buf.Write(tmp)
+	}
+	comma = true
 	// "WorkPlan" field is required
 	if strct.WorkPlan == nil {
 		return nil, errors.New("work_plan is a required field")
@@ -554,6 +598,10 @@ func (strct *PlanOutput) UnmarshalJSON(b []byte) error {
 				return err
 			}
 			acceptance_criteriaReceived = true
+		case "constraints":
+			if err := json.Unmarshal([]byte(v), &strct.Constraints); err != nil {
+				return err
+			}
 		case "work_plan":
 			if err := json.Unmarshal([]byte(v), &strct.WorkPlan); err != nil {
 				return err
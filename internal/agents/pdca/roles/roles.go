@@ -39,10 +39,15 @@ func (r *planRole) MapRequest(req contracts.AgentRequest) (any, error) {
 		if hints == nil {
 			hints = []string{}
 		}
+		checks := make([]plan.PlanSeededCheck, 0, len(req.Task.SeededChecks[ac.ID]))
+		for _, c := range req.Task.SeededChecks[ac.ID] {
+			checks = append(checks, plan.PlanSeededCheck{Id: c.Id, Cmd: c.Cmd, ExpectExitCodes: c.ExpectExitCodes})
+		}
 		acs = append(acs, plan.PlanAcceptanceCriteria{
 			Id:          ac.ID,
 			Text:        ac.Text,
 			VerifyHints: hints,
+			Checks:      checks,
 		})
 	}
 	links := req.Context.Links
@@ -58,10 +63,13 @@ func (r *planRole) MapRequest(req contracts.AgentRequest) (any, error) {
 			MaxIterations:      int64(req.Budgets.MaxIterations),
 			MaxWallTimeMinutes: int64(req.Budgets.MaxWallTimeMinutes),
 			MaxFailedChecks:    int64(req.Budgets.MaxFailedChecks),
+			MaxDoSteps:         int64(req.Budgets.MaxDoSteps),
 		},
 		Context: &plan.PlanContext{
-			Attempt: int64(req.Context.Attempt),
-			Links:   links,
+			Attempt:               int64(req.Context.Attempt),
+			Links:                 links,
+			FailureDigest:         req.Context.FailureDigest,
+			PreviousResponseError: req.Context.PreviousResponseError,
 		},
 		StopReasonsAllowed: req.StopReasonsAllowed,
 		PlanInput:          req.Plan,
@@ -123,8 +131,10 @@ func (r *doRole) MapRequest(req contracts.AgentRequest) (any, error) {
 			MaxFailedChecks:    int64(req.Budgets.MaxFailedChecks),
 		},
 		Context: &do.DoContext{
-			Attempt: int64(req.Context.Attempt),
-			Links:   links,
+			Attempt:               int64(req.Context.Attempt),
+			PreviousResponseError: req.Context.PreviousResponseError,
+			Links:                 links,
+			Constraints:           req.Context.Constraints,
 		},
 		StopReasonsAllowed: req.StopReasonsAllowed,
 		DoInput:            doInput,
@@ -180,8 +190,10 @@ func (r *checkRole) MapRequest(req contracts.AgentRequest) (any, error) {
 			MaxFailedChecks:    int64(req.Budgets.MaxFailedChecks),
 		},
 		Context: &check.CheckContext{
-			Attempt: int64(req.Context.Attempt),
-			Links:   links,
+			Attempt:               int64(req.Context.Attempt),
+			PreviousResponseError: req.Context.PreviousResponseError,
+			Links:                 links,
+			Constraints:           req.Context.Constraints,
 		},
 		StopReasonsAllowed: req.StopReasonsAllowed,
 		CheckInput:         req.Check,
@@ -234,11 +246,12 @@ func (r *actRole) MapRequest(req contracts.AgentRequest) (any, error) {
 			MaxFailedChecks:    int64(req.Budgets.MaxFailedChecks),
 		},
 		Context: &act.ActContext{
-			Attempt: int64(req.Context.Attempt),
-			Links:   links,
+			Attempt:               int64(req.Context.Attempt),
+			PreviousResponseError: req.Context.PreviousResponseError,
+			Links:                 links,
 		},
 		StopReasonsAllowed: req.StopReasonsAllowed,
-		ActInput:           req.Act,
+		ActInput:           normalizeActInput(req.Act),
 	}, nil
 }
 
@@ -320,3 +333,16 @@ func normalizeDoInput(input *do.DoInput) *do.DoInput {
 
 	return out
 }
+
+// normalizeActInput defaults a missing check verdict basis to its zero value,
+// since Check may omit it but the Act request schema requires it.
+func normalizeActInput(input *act.ActInput) *act.ActInput {
+	if input == nil || input.CheckVerdict == nil || input.CheckVerdict.Basis != nil {
+		return input
+	}
+	verdict := *input.CheckVerdict
+	verdict.Basis = &act.ActCheckVerdictBasis{}
+	out := *input
+	out.CheckVerdict = &verdict
+	return &out
+}
@@ -0,0 +1,83 @@
+// Package rolestest provides golden-file helpers for regression tests of PDCA
+// role request and response mappings.
+package rolestest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/metalagman/norma/internal/agents/pdca/contracts"
+)
+
+// RoundTrip holds the normalized results of mapping one request and one
+// response through a role.
+type RoundTrip struct {
+	Role string `json:"role"`
+	// Request is the role-specific request produced by MapRequest.
+	Request json.RawMessage `json:"request"`
+	// Response is the contracts.AgentResponse produced by MapResponse.
+	Response json.RawMessage `json:"response"`
+}
+
+// CaptureRoleRoundTrip maps inputJSON, a contracts.AgentRequest, with
+// role.MapRequest and outputJSON, the agent's raw response, with role.MapResponse.
+func CaptureRoleRoundTrip(role contracts.Role, inputJSON, outputJSON []byte) (RoundTrip, error) {
+	var req contracts.AgentRequest
+	if err := json.Unmarshal(inputJSON, &req); err != nil {
+		return RoundTrip{}, fmt.Errorf("decode agent request: %w", err)
+	}
+	mapped, err := role.MapRequest(req)
+	if err != nil {
+		return RoundTrip{}, fmt.Errorf("map request: %w", err)
+	}
+	reqJSON, err := json.Marshal(mapped)
+	if err != nil {
+		return RoundTrip{}, fmt.Errorf("encode mapped request: %w", err)
+	}
+
+	resp, err := role.MapResponse(outputJSON)
+	if err != nil {
+		return RoundTrip{}, fmt.Errorf("map response: %w", err)
+	}
+	respJSON, err := json.Marshal(resp)
+	if err != nil {
+		return RoundTrip{}, fmt.Errorf("encode mapped response: %w", err)
+	}
+
+	return RoundTrip{Role: role.Name(), Request: reqJSON, Response: respJSON}, nil
+}
+
+// Golden returns rt as indented JSON with a trailing newline, the golden file format.
+func (rt RoundTrip) Golden() ([]byte, error) {
+	data, err := json.MarshalIndent(rt, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("encode round trip: %w", err)
+	}
+	return append(data, '\n'), nil
+}
+
+// AssertGolden compares got with the golden file at path. With update set it
+// rewrites the file instead.
+func AssertGolden(t testing.TB, path string, got []byte, update bool) {
+	t.Helper()
+	if update {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("create golden dir: %v", err)
+		}
+		if err := os.WriteFile(path, got, 0o600); err != nil {
+			t.Fatalf("write golden file: %v", err)
+		}
+		return
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read golden file (run with -update to create it): %v", err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("%s mismatch (run with -update to accept):\n--- got\n%s\n--- want\n%s", path, got, want)
+	}
+}
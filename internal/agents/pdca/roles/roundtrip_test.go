@@ -0,0 +1,38 @@
+package roles
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/metalagman/norma/internal/agents/pdca/roles/rolestest"
+)
+
+var updateGolden = flag.Bool("update", false, "rewrite role round-trip golden files")
+
+func TestRoleRoundTripGolden(t *testing.T) {
+	for name, role := range DefaultRoles() {
+		t.Run(name, func(t *testing.T) {
+			dir := filepath.Join("testdata", "roundtrip")
+			input, err := os.ReadFile(filepath.Join(dir, name+".request.json"))
+			if err != nil {
+				t.Fatalf("read request fixture: %v", err)
+			}
+			output, err := os.ReadFile(filepath.Join(dir, name+".response.json"))
+			if err != nil {
+				t.Fatalf("read response fixture: %v", err)
+			}
+
+			rt, err := rolestest.CaptureRoleRoundTrip(role, input, output)
+			if err != nil {
+				t.Fatalf("CaptureRoleRoundTrip() error = %v", err)
+			}
+			got, err := rt.Golden()
+			if err != nil {
+				t.Fatalf("Golden() error = %v", err)
+			}
+			rolestest.AssertGolden(t, filepath.Join(dir, name+".golden.json"), got, *updateGolden)
+		})
+	}
+}
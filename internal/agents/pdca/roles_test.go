@@ -2,10 +2,16 @@ package pdca
 
 import (
 	"encoding/json"
+	"reflect"
+	"slices"
 	"testing"
 
 	"github.com/metalagman/norma/internal/agents/pdca/contracts"
+	"github.com/metalagman/norma/internal/agents/pdca/roles/act"
+	"github.com/metalagman/norma/internal/agents/pdca/roles/check"
 	"github.com/metalagman/norma/internal/agents/pdca/roles/do"
+	"github.com/metalagman/norma/internal/agents/pdca/roles/plan"
+	"github.com/metalagman/norma/internal/config"
 	"github.com/metalagman/norma/internal/task"
 )
 
@@ -101,3 +107,182 @@ func TestDoRoleMapRequestRefinesDefaultsToEmptySlice(t *testing.T) {
 		t.Fatalf("len(refines) = %d, want 0", len(refines))
 	}
 }
+
+func TestPlanRoleMapRequestIncludesMaxDoSteps(t *testing.T) {
+	role := GetRole(RolePlan)
+	if role == nil {
+		t.Fatal("GetRole(RolePlan) returned nil")
+	}
+
+	mapped, err := role.MapRequest(contracts.AgentRequest{
+		Run:     contracts.RunInfo{ID: "run-1", Iteration: 1},
+		Task:    contracts.TaskInfo{ID: "task-1", Title: "title", Description: "desc"},
+		Step:    contracts.StepInfo{Index: 1, Name: RolePlan},
+		Budgets: contracts.Budgets{MaxIterations: 1, MaxDoSteps: 4},
+	})
+	if err != nil {
+		t.Fatalf("role.MapRequest() error = %v", err)
+	}
+
+	planReq, ok := mapped.(*plan.PlanRequest)
+	if !ok {
+		t.Fatalf("mapped type = %T, want *plan.PlanRequest", mapped)
+	}
+	if planReq.Budgets.MaxDoSteps != 4 {
+		t.Fatalf("Budgets.MaxDoSteps = %d, want 4", planReq.Budgets.MaxDoSteps)
+	}
+}
+
+func TestCheckRoleMapResponseCarriesScore(t *testing.T) {
+	role := GetRole(RoleCheck)
+	if role == nil {
+		t.Fatal("GetRole(RoleCheck) returned nil")
+	}
+
+	out := []byte(`{
+		"status": "ok",
+		"summary": {"text": "checked"},
+		"progress": {"title": "checked", "details": []},
+		"check_output": {
+			"acceptance_results": [
+				{"ac_id": "AC-1", "result": "PASS"},
+				{"ac_id": "AC-2", "result": "FAIL", "score": 0.25, "notes": "one of four cases"}
+			],
+			"verdict": {"status": "PARTIAL", "recommendation": "replan", "basis": {}}
+		}
+	}`)
+	resp, err := role.MapResponse(out)
+	if err != nil {
+		t.Fatalf("role.MapResponse() error = %v", err)
+	}
+	if resp.Check == nil || len(resp.Check.AcceptanceResults) != 2 {
+		t.Fatalf("resp.Check = %+v, want two acceptance results", resp.Check)
+	}
+	if got := resp.Check.AcceptanceResults[1].Score; got != 0.25 {
+		t.Fatalf("AC-2 score = %v, want 0.25", got)
+	}
+
+	actResults := checkAcceptanceResultsToAct(resp.Check.AcceptanceResults)
+	if got := actResults[1].Score; got != 0.25 {
+		t.Fatalf("act AC-2 score = %v, want 0.25", got)
+	}
+}
+
+func TestActRoleMapRequestDefaultsNilVerdictBasis(t *testing.T) {
+	role := GetRole(RoleAct)
+	if role == nil {
+		t.Fatal("GetRole(RoleAct) returned nil")
+	}
+
+	verdict := checkVerdictToAct(&check.CheckVerdict{Status: "FAIL", Recommendation: "replan"})
+	req := contracts.AgentRequest{
+		Run:  contracts.RunInfo{ID: "run-1", Iteration: 1},
+		Task: contracts.TaskInfo{ID: "task-1", Title: "title", Description: "desc"},
+		Step: contracts.StepInfo{Index: 4, Name: RoleAct},
+		Act:  &act.ActInput{CheckVerdict: verdict},
+	}
+
+	mapped, err := role.MapRequest(req)
+	if err != nil {
+		t.Fatalf("role.MapRequest() error = %v", err)
+	}
+	actReq, ok := mapped.(*act.ActRequest)
+	if !ok {
+		t.Fatalf("mapped type = %T, want *act.ActRequest", mapped)
+	}
+	basis := actReq.ActInput.CheckVerdict.Basis
+	if basis == nil {
+		t.Fatal("CheckVerdict.Basis = nil, want zero value")
+	}
+	if basis.AllAcceptancePassed || basis.PlanMatch != "" {
+		t.Fatalf("CheckVerdict.Basis = %+v, want zero value", basis)
+	}
+	if verdict.Basis != nil {
+		t.Fatal("MapRequest mutated the caller's verdict")
+	}
+	if _, err := json.Marshal(actReq); err != nil {
+		t.Fatalf("marshal act request: %v", err)
+	}
+}
+
+func TestPlanConstraintsReachDoAndCheckRequests(t *testing.T) {
+	rt := &runtime{runInput: AgentInput{RunID: "run-1", TaskID: "task-1", Goal: "goal"}}
+	state := &contracts.TaskState{
+		Plan: &plan.PlanOutput{
+			AcceptanceCriteria: &plan.PlanOutputAcceptanceCriteria{
+				Effective: []plan.EffectiveAcceptanceCriteria{{Id: "AC-1", Origin: "baseline", Text: "ok"}},
+			},
+			Constraints: []string{"do not touch go.mod", "  ", "keep the public API stable"},
+			WorkPlan:    &plan.PlanWorkPlan{TimeboxMinutes: 10},
+		},
+		Do: &do.DoOutput{Execution: &do.DoExecution{ExecutedStepIds: []string{}, SkippedStepIds: []string{}}},
+	}
+	want := []string{"do not touch go.mod", "keep the public API stable"}
+
+	for _, roleName := range []string{RoleDo, RoleCheck} {
+		t.Run(roleName, func(t *testing.T) {
+			req := rt.baseRequest(1, 2, roleName)
+			if err := rt.enrichRequest(&req, roleName, state); err != nil {
+				t.Fatalf("enrichRequest() error = %v", err)
+			}
+			mapped, err := GetRole(roleName).MapRequest(req)
+			if err != nil {
+				t.Fatalf("role.MapRequest() error = %v", err)
+			}
+
+			var got []string
+			switch typed := mapped.(type) {
+			case *do.DoRequest:
+				got = typed.Context.Constraints
+			case *check.CheckRequest:
+				got = typed.Context.Constraints
+			default:
+				t.Fatalf("mapped request type = %T", mapped)
+			}
+			if !slices.Equal(got, want) {
+				t.Fatalf("context.constraints = %q, want %q", got, want)
+			}
+		})
+	}
+
+	req := rt.baseRequest(1, 4, RoleAct)
+	state.Check = &check.CheckOutput{Verdict: &check.CheckVerdict{Status: "PASS"}}
+	if err := rt.enrichRequest(&req, RoleAct, state); err != nil {
+		t.Fatalf("enrichRequest(act) error = %v", err)
+	}
+	if req.Context.Constraints != nil {
+		t.Fatalf("act context.constraints = %q, want none", req.Context.Constraints)
+	}
+}
+
+func TestPlanRequestCarriesSeededHintChecks(t *testing.T) {
+	cfg := config.Config{}
+	cfg.VerifyHints.SeedChecks = true
+	rt := &runtime{cfg: cfg, runInput: AgentInput{
+		RunID:  "run-1",
+		TaskID: "task-1",
+		Goal:   "goal",
+		AcceptanceCriteria: []task.AcceptanceCriterion{
+			{ID: "AC1", Text: "tests pass", VerifyHints: []string{"go test ./...", "Ask a reviewer"}},
+			{ID: "AC2", Text: "docs", VerifyHints: []string{"Read the README"}},
+		},
+	}}
+
+	req := rt.baseRequest(1, 1, RolePlan)
+	mapped, err := GetRole(RolePlan).MapRequest(req)
+	if err != nil {
+		t.Fatalf("role.MapRequest() error = %v", err)
+	}
+	acs := mapped.(*plan.PlanRequest).Task.AcceptanceCriteria
+	want := []plan.PlanSeededCheck{{Id: "CHK-AC1-HINT-1", Cmd: "go test ./...", ExpectExitCodes: []int64{0}}}
+	if len(acs) != 2 || !reflect.DeepEqual(acs[0].Checks, want) {
+		t.Fatalf("AC1 checks = %+v, want %+v", acs, want)
+	}
+	if len(acs[1].Checks) != 0 {
+		t.Fatalf("AC2 checks = %+v, want none for prose hints", acs[1].Checks)
+	}
+
+	if req := rt.baseRequest(1, 2, RoleDo); req.Task.SeededChecks != nil {
+		t.Fatalf("do request seeded checks = %+v, want none", req.Task.SeededChecks)
+	}
+}
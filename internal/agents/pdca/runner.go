@@ -2,17 +2,24 @@
 package pdca
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"os"
+	"path/filepath"
 	"strings"
+	"time"
 
 	acp "github.com/coder/acp-go-sdk"
+	"github.com/metalagman/norma/internal/adk/agentconfig"
 	"github.com/metalagman/norma/internal/adk/agentfactory"
 	"github.com/metalagman/norma/internal/adk/structured"
 	"github.com/metalagman/norma/internal/agents/pdca/contracts"
 	"github.com/metalagman/norma/internal/config"
+	runpkg "github.com/metalagman/norma/internal/run"
 	"github.com/rs/zerolog/log"
 
 	"google.golang.org/adk/agent"
@@ -26,21 +33,82 @@ type Runner interface {
 	Run(ctx context.Context, req contracts.AgentRequest, stdout, stderr io.Writer) (outBytes, errBytes []byte, exitCode int, err error)
 }
 
+// ErrStepTimeout reports an agent that did not finish within its configured timeout.
+var ErrStepTimeout = errors.New("agent step timed out")
+
+// RunnerOption configures a Runner.
+type RunnerOption func(*adkRunner)
+
+// WithShutdownGrace gives the agent process grace after SIGTERM before it is killed.
+func WithShutdownGrace(grace time.Duration) RunnerOption {
+	return func(r *adkRunner) {
+		r.shutdownGrace = grace
+	}
+}
+
+// WithSafetyProfile answers ACP permission requests as the safety profile requires:
+// interactive approves them, ci and locked reject them.
+func WithSafetyProfile(profile string) RunnerOption {
+	return func(r *adkRunner) {
+		r.rejectPermissions = !agentconfig.AutoApprovesPermissions(profile)
+	}
+}
+
+// WithSystemPromptPreamble prepends preamble to the role system instruction.
+func WithSystemPromptPreamble(preamble string) RunnerOption {
+	return func(r *adkRunner) {
+		r.preamble = preamble
+	}
+}
+
+// WithSanitizeOutput replaces invalid UTF-8 in the agent output before it is parsed.
+func WithSanitizeOutput(sanitize bool) RunnerOption {
+	return func(r *adkRunner) {
+		r.sanitizeOutput = sanitize
+	}
+}
+
 // NewRunner constructs a runner for the given agent config and role.
-func NewRunner(cfg config.AgentConfig, role contracts.Role) (Runner, error) {
-	return &adkRunner{
+func NewRunner(cfg config.AgentConfig, role contracts.Role, opts ...RunnerOption) (Runner, error) {
+	r := &adkRunner{
 		cfg:  cfg,
 		role: role,
-	}, nil
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r, nil
 }
 
 type adkRunner struct {
-	cfg  config.AgentConfig
-	role contracts.Role
+	cfg           config.AgentConfig
+	role          contracts.Role
+	shutdownGrace time.Duration
+	preamble      string
+	// rejectPermissions rejects ACP permission requests instead of approving them.
+	rejectPermissions bool
+	// sanitizeOutput replaces invalid UTF-8 in the agent output before parsing.
+	sanitizeOutput bool
 }
 
+// Run runs the agent for req. With a configured timeout the agent is stopped once it
+// elapses, and Run returns ErrStepTimeout with exit code -1.
 func (r *adkRunner) Run(ctx context.Context, req contracts.AgentRequest, stdout, stderr io.Writer) ([]byte, []byte, int, error) {
-	l := log.With().Str("role", r.role.Name()).Logger()
+	timeout := time.Duration(r.cfg.Timeout) * time.Second
+	if timeout <= 0 {
+		return r.run(ctx, req, stdout, stderr)
+	}
+	ctx, cancel := context.WithTimeoutCause(ctx, timeout, ErrStepTimeout)
+	defer cancel()
+	out, errOut, exitCode, err := r.run(ctx, req, stdout, stderr)
+	if err != nil && errors.Is(context.Cause(ctx), ErrStepTimeout) {
+		return nil, nil, -1, fmt.Errorf("%s agent: %w after %s", r.role.Name(), ErrStepTimeout, timeout)
+	}
+	return out, errOut, exitCode, err
+}
+
+func (r *adkRunner) run(ctx context.Context, req contracts.AgentRequest, stdout, stderr io.Writer) ([]byte, []byte, int, error) {
+	l := runpkg.ContextLogger(ctx, log.Logger).With().Str("role", r.role.Name()).Logger()
 
 	// 1. Map request to JSON input for the role.
 	input, err := r.role.MapRequest(req)
@@ -51,12 +119,16 @@ func (r *adkRunner) Run(ctx context.Context, req contracts.AgentRequest, stdout,
 	if err != nil {
 		return nil, nil, 0, fmt.Errorf("marshal input JSON: %w", err)
 	}
+	if err := writeMappedRequest(req.Paths.RunDir, inputJSON); err != nil {
+		return nil, nil, 0, err
+	}
 
 	// 2. Resolve system instruction (role-specific prompt).
 	systemInstruction, err := r.role.Prompt(req)
 	if err != nil {
 		return nil, nil, 0, fmt.Errorf("generate role prompt: %w", err)
 	}
+	systemInstruction = prependPreamble(r.preamble, systemInstruction)
 
 	// 3. Resolve working directory.
 	workingDirectory := strings.TrimSpace(req.Paths.WorkspaceDir)
@@ -64,6 +136,24 @@ func (r *adkRunner) Run(ctx context.Context, req contracts.AgentRequest, stdout,
 		workingDirectory = strings.TrimSpace(req.Paths.RunDir)
 	}
 
+	// Route reasoning lines to logs/thinking.txt when the agent marks them.
+	var thinkingLog io.Writer
+	if marker := r.cfg.ReasoningMarker; marker != "" {
+		logsDir := filepath.Join(req.Paths.RunDir, "logs")
+		if err := os.MkdirAll(logsDir, 0o700); err != nil {
+			return nil, nil, 0, fmt.Errorf("create logs dir: %w", err)
+		}
+		thinkingFile, err := os.OpenFile(filepath.Join(logsDir, reasoningLogName), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+		if err != nil {
+			return nil, nil, 0, fmt.Errorf("open reasoning log: %w", err)
+		}
+		defer func() { _ = thinkingFile.Close() }()
+		reasoningOut := newReasoningWriter(marker, stdout, thinkingFile)
+		defer func() { _ = reasoningOut.Flush() }()
+		stdout = reasoningOut
+		thinkingLog = thinkingFile
+	}
+
 	// 4. Create ephemeral inner agent via factory.
 	factory := agentfactory.NewFactory(map[string]config.AgentConfig{
 		r.role.Name(): r.cfg,
@@ -73,9 +163,14 @@ func (r *adkRunner) Run(ctx context.Context, req contracts.AgentRequest, stdout,
 		Description:       "Norma " + req.Step.Name + " agent",
 		SystemInstruction: systemInstruction,
 		WorkingDirectory:  workingDirectory,
+		Env:               runpkg.CorrelationEnv(ctx),
 		Stdout:            stdout,
 		Stderr:            stderr,
 		PermissionHandler: defaultACPPermissionHandler,
+		ShutdownGrace:     r.shutdownGrace,
+	}
+	if r.rejectPermissions {
+		creationReq.PermissionHandler = rejectACPPermissionHandler
 	}
 
 	inner, err := factory.CreateAgent(ctx, r.role.Name(), creationReq)
@@ -91,10 +186,26 @@ func (r *adkRunner) Run(ctx context.Context, req contracts.AgentRequest, stdout,
 	}
 
 	// 5. Wrap with structured I/O agent.
-	a, err := structured.NewAgent(inner,
+	structuredOpts := []structured.Option{
 		structured.WithInputSchema(r.role.InputSchema()),
 		structured.WithOutputSchema(r.role.OutputSchema()),
-	)
+	}
+	marker := r.cfg.ReasoningMarker
+	structuredOpts = append(structuredOpts, structured.WithOutputFilter(func(text string) string {
+		output := sanitizeAgentOutput([]byte(text), r.sanitizeOutput)
+		if marker != "" {
+			output, _ = separateReasoning(output, marker)
+		}
+		return string(output)
+	}))
+	responseFile := ""
+	var staleResponse responseFileStamp
+	if r.cfg.ResponseMode == agentconfig.ResponseModeFile {
+		responseFile = filepath.Join(req.Paths.RunDir, agentconfig.ResponseFileName)
+		structuredOpts = append(structuredOpts, structured.WithOutputFile(responseFile))
+		staleResponse = stampResponseFile(responseFile)
+	}
+	a, err := structured.NewAgent(inner, structuredOpts...)
 	if err != nil {
 		return nil, nil, 1, fmt.Errorf("failed to create structured wrapper: %w", err)
 	}
@@ -138,14 +249,36 @@ func (r *adkRunner) Run(ctx context.Context, req contracts.AgentRequest, stdout,
 		}
 	}
 
-	if len(lastOutBytes) == 0 {
-		return nil, nil, 0, fmt.Errorf("no output from agent")
+	lastOutBytes = sanitizeAgentOutput(lastOutBytes, r.sanitizeOutput)
+	if thinkingLog != nil {
+		var reasoning []byte
+		lastOutBytes, reasoning = separateReasoning(lastOutBytes, r.cfg.ReasoningMarker)
+		if _, err := thinkingLog.Write(reasoning); err != nil {
+			return nil, nil, 0, fmt.Errorf("write reasoning log: %w", err)
+		}
 	}
 
 	// 7. Extract and map final response.
-	extracted, ok := ExtractJSON(lastOutBytes)
-	if !ok {
-		extracted = lastOutBytes
+	var extracted []byte
+	if responseFile != "" {
+		extracted, err = readResponseFile(responseFile, staleResponse)
+		extracted = sanitizeAgentOutput(extracted, r.sanitizeOutput)
+		if errors.Is(err, errStaleResponseFile) && len(lastOutBytes) > 0 {
+			l.Warn().Int("attempt", req.Context.Attempt).Str("path", responseFile).Msg("response file left by a prior attempt, using stdout response")
+			extracted = extractStdoutResponse(lastOutBytes)
+		} else if err != nil {
+			return nil, nil, 0, err
+		} else if conflict := r.responseConflict(extracted, lastOutBytes); conflict != "" {
+			if r.cfg.ResponseConflict == agentconfig.ResponseConflictError {
+				return nil, nil, 0, fmt.Errorf("response file %s disagrees with stdout response: %s", responseFile, conflict)
+			}
+			l.Warn().Str("path", responseFile).Str("conflict", conflict).Msg("response file disagrees with stdout response, using response file")
+		}
+	} else {
+		if len(lastOutBytes) == 0 {
+			return nil, nil, 0, fmt.Errorf("no output from agent")
+		}
+		extracted = extractStdoutResponse(lastOutBytes)
 	}
 
 	// Validate that it actually matches the role response (mapped via role.MapResponse).
@@ -163,6 +296,121 @@ func (r *adkRunner) Run(ctx context.Context, req contracts.AgentRequest, stdout,
 	return normalized, nil, 0, nil
 }
 
+// responseConflict describes how a response in the agent's final text output disagrees
+// with the response file on status or stop reason. It returns "" when the text output
+// holds no parseable response or both agree.
+func (r *adkRunner) responseConflict(fileOut, stdoutOut []byte) string {
+	stdoutJSON, ok := ExtractJSON(stdoutOut)
+	if !ok {
+		return ""
+	}
+	stdoutResp, err := r.role.MapResponse(stdoutJSON)
+	if err != nil {
+		return ""
+	}
+	fileResp, err := r.role.MapResponse(fileOut)
+	if err != nil {
+		return ""
+	}
+	var diffs []string
+	if fileResp.Status != stdoutResp.Status {
+		diffs = append(diffs, fmt.Sprintf("status %q in file, %q on stdout", fileResp.Status, stdoutResp.Status))
+	}
+	if fileResp.StopReason != stdoutResp.StopReason {
+		diffs = append(diffs, fmt.Sprintf("stop_reason %q in file, %q on stdout", fileResp.StopReason, stdoutResp.StopReason))
+	}
+	return strings.Join(diffs, "; ")
+}
+
+// prependPreamble places a configured preamble before the built-in role instructions.
+// The structured output contract is added to the user prompt by the wrapper, so the
+// preamble cannot replace it.
+func prependPreamble(preamble, instruction string) string {
+	preamble = strings.TrimSpace(preamble)
+	if preamble == "" {
+		return instruction
+	}
+	return preamble + "\n\n" + instruction
+}
+
+// mappedRequestFileName is the sidecar holding the role-specific request sent to the agent.
+const mappedRequestFileName = "input.mapped.json"
+
+// writeMappedRequest saves the role-specific request next to input.json so mapping bugs
+// can be debugged from the step directory. It is a no-op without a run directory.
+func writeMappedRequest(runDir string, inputJSON []byte) error {
+	if strings.TrimSpace(runDir) == "" {
+		return nil
+	}
+	var buf bytes.Buffer
+	if err := json.Indent(&buf, inputJSON, "", "  "); err != nil {
+		return fmt.Errorf("indent %s: %w", mappedRequestFileName, err)
+	}
+	if err := os.WriteFile(filepath.Join(runDir, mappedRequestFileName), buf.Bytes(), 0o600); err != nil {
+		return fmt.Errorf("write %s: %w", mappedRequestFileName, err)
+	}
+	return nil
+}
+
+// errStaleResponseFile reports a response file that was not rewritten by the current attempt.
+var errStaleResponseFile = errors.New("response file was not written by this attempt")
+
+// responseFileStamp records a response file present before an attempt starts,
+// so a file left by a prior attempt is not mistaken for the current response.
+type responseFileStamp struct {
+	modTime time.Time
+	data    []byte
+}
+
+func stampResponseFile(path string) responseFileStamp {
+	info, err := os.Stat(path)
+	if err != nil {
+		return responseFileStamp{}
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return responseFileStamp{}
+	}
+	return responseFileStamp{modTime: info.ModTime(), data: data}
+}
+
+// matches reports whether the file still holds what it held before the attempt.
+func (s responseFileStamp) matches(info os.FileInfo, data []byte) bool {
+	return s.data != nil && info.ModTime().Equal(s.modTime) && bytes.Equal(data, s.data)
+}
+
+// readResponseFile reads the response an agent wrote in file response mode.
+// A file unchanged since stale was taken yields errStaleResponseFile.
+func readResponseFile(path string, stale responseFileStamp) ([]byte, error) {
+	info, err := os.Stat(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("response_mode is file but agent did not write %s", path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read response file: %w", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read response file: %w", err)
+	}
+	if stale.matches(info, data) {
+		return nil, fmt.Errorf("%s: %w", path, errStaleResponseFile)
+	}
+	if len(bytes.TrimSpace(data)) == 0 {
+		return nil, fmt.Errorf("response file %s is empty", path)
+	}
+	return data, nil
+}
+
+// extractStdoutResponse returns the JSON object in an agent's final text output,
+// or the whole output when it holds none.
+func extractStdoutResponse(out []byte) []byte {
+	if extracted, ok := ExtractJSON(out); ok {
+		return extracted
+	}
+	return out
+}
+
 func toPascal(s string) string {
 	s = strings.TrimSpace(s)
 	if s == "" {
@@ -210,3 +458,15 @@ func defaultACPPermissionHandler(_ context.Context, req acp.RequestPermissionReq
 	}
 	return acp.RequestPermissionResponse{Outcome: acp.NewRequestPermissionOutcomeCancelled()}, nil
 }
+
+// rejectACPPermissionHandler rejects every permission request, cancelling those without a reject option.
+func rejectACPPermissionHandler(_ context.Context, req acp.RequestPermissionRequest) (acp.RequestPermissionResponse, error) {
+	for _, option := range req.Options {
+		if option.Kind == acp.PermissionOptionKindRejectOnce || option.Kind == acp.PermissionOptionKindRejectAlways {
+			return acp.RequestPermissionResponse{
+				Outcome: acp.NewRequestPermissionOutcomeSelected(option.OptionId),
+			}, nil
+		}
+	}
+	return acp.RequestPermissionResponse{Outcome: acp.NewRequestPermissionOutcomeCancelled()}, nil
+}
@@ -8,12 +8,21 @@ import (
 	"errors"
 	"io"
 	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
 	acp "github.com/coder/acp-go-sdk"
+	"github.com/metalagman/norma/internal/adk/agentconfig"
 	"github.com/metalagman/norma/internal/agents/pdca/contracts"
+	"github.com/metalagman/norma/internal/agents/pdca/roles"
+	"github.com/metalagman/norma/internal/agents/pdca/roles/act"
+	"github.com/metalagman/norma/internal/agents/pdca/roles/check"
+	"github.com/metalagman/norma/internal/agents/pdca/roles/do"
 	"github.com/metalagman/norma/internal/agents/pdca/roles/plan"
 	"github.com/metalagman/norma/internal/config"
+	runpkg "github.com/metalagman/norma/internal/run"
 	"github.com/metalagman/norma/internal/task"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -158,6 +167,341 @@ func TestAinvokeRunner_RunReturnsErrorWhenResponseMappingFails(t *testing.T) {
 	assert.Contains(t, err.Error(), "map failed")
 }
 
+func TestAinvokeRunner_RunReadsResponseFileInFileMode(t *testing.T) {
+	runDir := t.TempDir()
+	response := `{"status":"ok","summary":{"text":"from file"},"progress":{"title":"done","details":[]}}`
+	cfg := config.AgentConfig{
+		Type:         config.AgentTypeGenericACP,
+		Cmd:          helperACPFileCommand(t, "wrote response file", filepath.Join(runDir, agentconfig.ResponseFileName), response),
+		ResponseMode: agentconfig.ResponseModeFile,
+	}
+
+	runner, err := NewRunner(cfg, &dummyRole{})
+	require.NoError(t, err)
+
+	out, _, exitCode, err := runner.Run(context.Background(), fileModeRequest(t, runDir), io.Discard, io.Discard)
+	require.NoError(t, err)
+	assert.Equal(t, 0, exitCode)
+
+	var resp contracts.AgentResponse
+	require.NoError(t, json.Unmarshal(out, &resp))
+	assert.Equal(t, "ok", resp.Status)
+	assert.Equal(t, "from file", resp.Summary.Text)
+}
+
+func TestAinvokeRunner_RunPassesCorrelationIDToAgent(t *testing.T) {
+	runDir := t.TempDir()
+	envFile := filepath.Join(t.TempDir(), "env.txt")
+	cmd := helperACPCommand(t, `{"status":"ok","summary":{"text":"success"},"progress":{"title":"done","details":[]}}`)
+	cfg := config.AgentConfig{
+		Type: config.AgentTypeGenericACP,
+		Cmd:  append([]string{cmd[0], "GO_HELPER_ENV_FILE=" + envFile}, cmd[1:]...),
+	}
+
+	runner, err := NewRunner(cfg, &dummyRole{})
+	require.NoError(t, err)
+
+	ctx := runpkg.WithCorrelationID(context.Background(), "cid-123")
+	_, _, _, err = runner.Run(ctx, fileModeRequest(t, runDir), io.Discard, io.Discard)
+	require.NoError(t, err)
+
+	got, err := os.ReadFile(envFile)
+	require.NoError(t, err)
+	assert.Equal(t, "cid-123", string(got))
+}
+
+func TestAinvokeRunner_RunStopsHungAgentAfterTimeout(t *testing.T) {
+	cmd := helperACPCommand(t, `{"status":"ok","summary":{"text":"never"},"progress":{"title":"done","details":[]}}`)
+	cfg := config.AgentConfig{
+		Type:    config.AgentTypeGenericACP,
+		Cmd:     append([]string{cmd[0], "GO_HELPER_HANG=1"}, cmd[1:]...),
+		Timeout: 1,
+	}
+
+	runner, err := NewRunner(cfg, &dummyRole{})
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	start := time.Now()
+	out, _, exitCode, err := runner.Run(ctx, fileModeRequest(t, t.TempDir()), io.Discard, io.Discard)
+	require.ErrorIs(t, err, ErrStepTimeout)
+	assert.Equal(t, -1, exitCode)
+	assert.Nil(t, out)
+	assert.Less(t, time.Since(start), 20*time.Second)
+	require.NoError(t, ctx.Err(), "parent context must not be cancelled by the step timeout")
+}
+
+func TestAgentRunErrorClassifiesMissingBinary(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		cmd      []string
+		wantKind runpkg.FailureKind
+	}{
+		{name: "absolute path", cmd: []string{filepath.Join(t.TempDir(), "no-such-agent")}, wantKind: runpkg.FailureInfrastructure},
+		{name: "not on PATH", cmd: []string{"norma-no-such-agent-binary"}, wantKind: runpkg.FailureInfrastructure},
+		{name: "invalid response", cmd: helperACPCommand(t, "not json"), wantKind: runpkg.FailureAgentError},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			runner, err := NewRunner(config.AgentConfig{Type: config.AgentTypeGenericACP, Cmd: tc.cmd}, &dummyRole{})
+			require.NoError(t, err)
+
+			_, err = runAttempts(context.Background(), runner, fileModeRequest(t, t.TempDir()), 1, io.Discard, io.Discard, nil, nil)
+			require.Error(t, err)
+			err = agentRunError(RoleDo, err)
+			assert.Equal(t, tc.wantKind, runpkg.FailureKindOf(err, runpkg.FailureAgentError), "error: %v", err)
+		})
+	}
+}
+
+func TestAinvokeRunner_RunParsesBOMPrefixedStdout(t *testing.T) {
+	cfg := config.AgentConfig{
+		Type: config.AgentTypeGenericACP,
+		Cmd:  helperACPCommand(t, "\ufeff"+`{"status":"ok","summary":{"text":"bom"},"progress":{"title":"done","details":[]}}`),
+	}
+
+	runner, err := NewRunner(cfg, &dummyRole{})
+	require.NoError(t, err)
+
+	out, _, _, err := runner.Run(context.Background(), fileModeRequest(t, t.TempDir()), io.Discard, io.Discard)
+	require.NoError(t, err)
+
+	var resp contracts.AgentResponse
+	require.NoError(t, json.Unmarshal(out, &resp))
+	assert.Equal(t, "bom", resp.Summary.Text)
+}
+
+func TestAinvokeRunner_RunSanitizesResponseFile(t *testing.T) {
+	runDir := t.TempDir()
+	response := "\xef\xbb\xbf" + `{"status":"ok","summary":{"text":"caf` + "\xe9" + `"},"progress":{"title":"done","details":[]}}`
+	cfg := config.AgentConfig{
+		Type:         config.AgentTypeGenericACP,
+		Cmd:          helperACPFileCommand(t, "wrote response file", filepath.Join(runDir, agentconfig.ResponseFileName), response),
+		ResponseMode: agentconfig.ResponseModeFile,
+	}
+
+	runner, err := NewRunner(cfg, &dummyRole{}, WithSanitizeOutput(true))
+	require.NoError(t, err)
+
+	out, _, _, err := runner.Run(context.Background(), fileModeRequest(t, runDir), io.Discard, io.Discard)
+	require.NoError(t, err)
+
+	var resp contracts.AgentResponse
+	require.NoError(t, json.Unmarshal(out, &resp))
+	assert.Equal(t, "caf\uFFFD", resp.Summary.Text)
+}
+
+func TestAinvokeRunner_RunFailsWhenResponseFileMissing(t *testing.T) {
+	runDir := t.TempDir()
+	cfg := config.AgentConfig{
+		Type:         config.AgentTypeGenericACP,
+		Cmd:          helperACPCommand(t, `{"status":"ok","summary":{"text":"stdout"},"progress":{"title":"done","details":[]}}`),
+		ResponseMode: agentconfig.ResponseModeFile,
+	}
+
+	runner, err := NewRunner(cfg, &dummyRole{})
+	require.NoError(t, err)
+
+	_, _, _, err = runner.Run(context.Background(), fileModeRequest(t, runDir), io.Discard, io.Discard)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "did not write")
+	assert.Contains(t, err.Error(), filepath.Join(runDir, agentconfig.ResponseFileName))
+}
+
+func TestAinvokeRunner_RunPrefersStdoutOverStaleResponseFile(t *testing.T) {
+	runDir := t.TempDir()
+	cfg := config.AgentConfig{
+		Type:         config.AgentTypeGenericACP,
+		Cmd:          helperACPCommand(t, `{"status":"ok","summary":{"text":"attempt 2"},"progress":{"title":"done","details":[]}}`),
+		ResponseMode: agentconfig.ResponseModeFile,
+	}
+	// A prior attempt left its response behind; this attempt only answers on stdout.
+	stale := `{"status":"error","summary":{"text":"attempt 1"},"progress":{"title":"failed","details":[]}}`
+	require.NoError(t, os.WriteFile(filepath.Join(runDir, agentconfig.ResponseFileName), []byte(stale), 0o600))
+
+	runner, err := NewRunner(cfg, &dummyRole{})
+	require.NoError(t, err)
+
+	req := fileModeRequest(t, runDir)
+	req.Context.Attempt = 2
+	out, _, _, err := runner.Run(context.Background(), req, io.Discard, io.Discard)
+	require.NoError(t, err)
+
+	var resp contracts.AgentResponse
+	require.NoError(t, json.Unmarshal(out, &resp))
+	assert.Equal(t, "ok", resp.Status)
+	assert.Equal(t, "attempt 2", resp.Summary.Text)
+}
+
+func TestAinvokeRunner_RunReadsRewrittenResponseFile(t *testing.T) {
+	runDir := t.TempDir()
+	responseFile := filepath.Join(runDir, agentconfig.ResponseFileName)
+	stale := `{"status":"error","summary":{"text":"attempt 1"},"progress":{"title":"failed","details":[]}}`
+	require.NoError(t, os.WriteFile(responseFile, []byte(stale), 0o600))
+
+	response := `{"status":"ok","summary":{"text":"attempt 2 file"},"progress":{"title":"done","details":[]}}`
+	cfg := config.AgentConfig{
+		Type:         config.AgentTypeGenericACP,
+		Cmd:          helperACPFileCommand(t, "wrote response file", responseFile, response),
+		ResponseMode: agentconfig.ResponseModeFile,
+	}
+	runner, err := NewRunner(cfg, &dummyRole{})
+	require.NoError(t, err)
+
+	out, _, _, err := runner.Run(context.Background(), fileModeRequest(t, runDir), io.Discard, io.Discard)
+	require.NoError(t, err)
+
+	var resp contracts.AgentResponse
+	require.NoError(t, json.Unmarshal(out, &resp))
+	assert.Equal(t, "attempt 2 file", resp.Summary.Text)
+}
+
+func TestAinvokeRunner_RunChecksStdoutAgainstResponseFile(t *testing.T) {
+	fileResponse := `{"status":"ok","summary":{"text":"from file"},"progress":{"title":"done","details":[]}}`
+	tests := []struct {
+		name     string
+		stdout   string
+		policy   string
+		wantErr  string
+		wantText string
+	}{
+		{
+			name:     "agreeing",
+			stdout:   `{"status":"ok","summary":{"text":"from stdout"},"progress":{"title":"done","details":[]}}`,
+			policy:   agentconfig.ResponseConflictError,
+			wantText: "from file",
+		},
+		{
+			name:     "disagreeing warns",
+			stdout:   `{"status":"stop","stop_reason":"budget_exceeded","summary":{"text":"from stdout"},"progress":{"title":"done","details":[]}}`,
+			wantText: "from file",
+		},
+		{
+			name:    "disagreeing errors",
+			stdout:  `{"status":"stop","stop_reason":"budget_exceeded","summary":{"text":"from stdout"},"progress":{"title":"done","details":[]}}`,
+			policy:  agentconfig.ResponseConflictError,
+			wantErr: `status "ok" in file, "stop" on stdout; stop_reason "" in file, "budget_exceeded" on stdout`,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			runDir := t.TempDir()
+			cfg := config.AgentConfig{
+				Type:             config.AgentTypeGenericACP,
+				Cmd:              helperACPFileCommand(t, tc.stdout, filepath.Join(runDir, agentconfig.ResponseFileName), fileResponse),
+				ResponseMode:     agentconfig.ResponseModeFile,
+				ResponseConflict: tc.policy,
+			}
+			runner, err := NewRunner(cfg, &dummyRole{})
+			require.NoError(t, err)
+
+			out, _, _, err := runner.Run(context.Background(), fileModeRequest(t, runDir), io.Discard, io.Discard)
+			if tc.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tc.wantErr)
+				return
+			}
+			require.NoError(t, err)
+
+			var resp contracts.AgentResponse
+			require.NoError(t, json.Unmarshal(out, &resp))
+			assert.Equal(t, "ok", resp.Status)
+			assert.Equal(t, tc.wantText, resp.Summary.Text)
+		})
+	}
+}
+
+func TestAinvokeRunner_RunWritesMappedRequestSidecar(t *testing.T) {
+	inputs := map[string]func(*contracts.AgentRequest){
+		RolePlan: func(req *contracts.AgentRequest) {
+			req.Plan = &plan.PlanInput{Task: &plan.PlanTaskID{Id: "task-1"}}
+		},
+		RoleDo: func(req *contracts.AgentRequest) {
+			req.Do = &do.DoInput{WorkPlan: &do.DoWorkPlan{TimeboxMinutes: 10}}
+		},
+		RoleCheck: func(req *contracts.AgentRequest) {
+			req.Check = &check.CheckInput{
+				WorkPlan:     &check.CheckWorkPlan{TimeboxMinutes: 10},
+				DoExecution:  &check.CheckDoExecution{},
+				ChangedFiles: []string{"main.go"},
+			}
+		},
+		RoleAct: func(req *contracts.AgentRequest) {
+			req.Act = &act.ActInput{CheckVerdict: &act.ActCheckVerdict{Status: "FAIL", Basis: &act.ActCheckVerdictBasis{}}}
+		},
+	}
+
+	for roleName, setInput := range inputs {
+		t.Run(roleName, func(t *testing.T) {
+			runDir := t.TempDir()
+			role := roles.DefaultRoles()[roleName]
+			runner, err := NewRunner(config.AgentConfig{Type: config.AgentTypeGenericACP, Cmd: helperACPCommand(t, "not a response")}, role)
+			require.NoError(t, err)
+
+			req := fileModeRequest(t, runDir)
+			req.Step.Name = roleName
+			setInput(&req)
+			// The helper reply is not a valid response; only the sidecar matters here.
+			_, _, _, _ = runner.Run(context.Background(), req, io.Discard, io.Discard)
+
+			got, err := os.ReadFile(filepath.Join(runDir, mappedRequestFileName))
+			require.NoError(t, err)
+			mapped, err := role.MapRequest(req)
+			require.NoError(t, err)
+			want, err := json.Marshal(mapped)
+			require.NoError(t, err)
+			assert.JSONEq(t, string(want), string(got))
+		})
+	}
+}
+
+func TestAinvokeRunner_RunSeparatesReasoningOutput(t *testing.T) {
+	runDir := t.TempDir()
+	response := "THINK: weighing options\n" +
+		`{"status":"ok","summary":{"text":"reasoned"},"progress":{"title":"done","details":[]}}` +
+		"\n  THINK: done"
+	cfg := config.AgentConfig{
+		Type:            config.AgentTypeGenericACP,
+		Cmd:             helperACPCommand(t, response),
+		ReasoningMarker: "THINK:",
+	}
+	runner, err := NewRunner(cfg, &dummyRole{})
+	require.NoError(t, err)
+
+	out, _, _, err := runner.Run(context.Background(), fileModeRequest(t, runDir), io.Discard, io.Discard)
+	require.NoError(t, err)
+
+	var resp contracts.AgentResponse
+	require.NoError(t, json.Unmarshal(out, &resp))
+	assert.Equal(t, "reasoned", resp.Summary.Text)
+
+	thinking, err := os.ReadFile(filepath.Join(runDir, "logs", "thinking.txt"))
+	require.NoError(t, err)
+	assert.Equal(t, "THINK: weighing options\n  THINK: done\n", string(thinking))
+}
+
+func fileModeRequest(t *testing.T, runDir string) contracts.AgentRequest {
+	t.Helper()
+	return contracts.AgentRequest{
+		Run:  contracts.RunInfo{ID: "run-1", Iteration: 1},
+		Task: contracts.TaskInfo{ID: "task-1", Title: "title", Description: "desc", AcceptanceCriteria: []task.AcceptanceCriterion{{ID: "AC1", Text: "text"}}},
+		Step: contracts.StepInfo{Index: 1, Name: "plan"},
+		Paths: contracts.RequestPaths{
+			WorkspaceDir: t.TempDir(),
+			RunDir:       runDir,
+		},
+		Budgets:            contracts.Budgets{MaxIterations: 1},
+		StopReasonsAllowed: []string{"budget_exceeded"},
+	}
+}
+
 func helperACPCommand(t *testing.T, response string) []string {
 	t.Helper()
 	return []string{
@@ -170,6 +514,13 @@ func helperACPCommand(t *testing.T, response string) []string {
 	}
 }
 
+// helperACPFileCommand is helperACPCommand for an agent that also writes fileResponse to responseFile.
+func helperACPFileCommand(t *testing.T, response, responseFile, fileResponse string) []string {
+	t.Helper()
+	cmd := helperACPCommand(t, response)
+	return append([]string{cmd[0], "GO_HELPER_RESPONSE_FILE=" + responseFile, "GO_HELPER_FILE_RESPONSE=" + fileResponse}, cmd[1:]...)
+}
+
 func TestAgentACPHelperProcess(t *testing.T) {
 	if os.Getenv("GO_WANT_AGENT_ACP_HELPER") != "1" {
 		return
@@ -206,6 +557,21 @@ func TestAgentACPHelperProcess(t *testing.T) {
 				},
 			})
 		case acp.AgentMethodSessionPrompt:
+			if os.Getenv("GO_HELPER_HANG") == "1" {
+				select {}
+			}
+			if stderr := os.Getenv("GO_HELPER_STDERR"); stderr != "" {
+				_, _ = os.Stderr.WriteString(stderr + "\n")
+			}
+			if promptFile := os.Getenv("GO_HELPER_PROMPT_FILE"); promptFile != "" {
+				_ = os.WriteFile(promptFile, req.Params, 0o600)
+			}
+			if envFile := os.Getenv("GO_HELPER_ENV_FILE"); envFile != "" {
+				_ = os.WriteFile(envFile, []byte(os.Getenv(runpkg.CorrelationEnvVar)), 0o600)
+			}
+			if responseFile := os.Getenv("GO_HELPER_RESPONSE_FILE"); responseFile != "" {
+				_ = os.WriteFile(responseFile, []byte(os.Getenv("GO_HELPER_FILE_RESPONSE")), 0o600)
+			}
 			// Send response
 			_ = encoder.Encode(map[string]any{
 				"jsonrpc": "2.0",
@@ -233,3 +599,44 @@ func TestAgentACPHelperProcess(t *testing.T) {
 	}
 	os.Exit(0)
 }
+
+func TestPrependPreamblePrecedesRoleInstructions(t *testing.T) {
+	planRole := roles.DefaultRoles()[RolePlan]
+	instruction, err := planRole.Prompt(fileModeRequest(t, t.TempDir()))
+	require.NoError(t, err)
+
+	preamble := "Never touch files under infra/."
+	got := prependPreamble("  "+preamble+"\n", instruction)
+
+	assert.True(t, strings.HasPrefix(got, preamble+"\n\n"), "preamble must come first, got %q", got[:min(len(got), 80)])
+	assert.True(t, strings.HasSuffix(got, instruction), "built-in instructions must follow the preamble unchanged")
+	assert.Equal(t, instruction, prependPreamble("  ", instruction))
+}
+
+func TestAinvokeRunner_RunSendsPreambleBeforeRoleInstructions(t *testing.T) {
+	promptFile := filepath.Join(t.TempDir(), "prompt.json")
+	cmd := helperACPCommand(t, `{"status":"ok","summary":{"text":"success"},"progress":{"title":"done","details":[]}}`)
+	cmd = append([]string{cmd[0], "GO_HELPER_PROMPT_FILE=" + promptFile}, cmd[1:]...)
+	cfg := config.AgentConfig{Type: config.AgentTypeGenericACP, Cmd: cmd}
+
+	preamble := "Never touch files under infra/."
+	runner, err := NewRunner(cfg, roles.DefaultRoles()[RolePlan], WithSystemPromptPreamble(preamble))
+	require.NoError(t, err)
+
+	req := fileModeRequest(t, t.TempDir())
+	req.Plan = &plan.PlanInput{Task: &plan.PlanTaskID{Id: "task-1"}}
+	// The helper reply is not a valid plan output; only the prompt it received matters here.
+	_, _, _, _ = runner.Run(context.Background(), req, io.Discard, io.Discard)
+
+	raw, err := os.ReadFile(promptFile)
+	require.NoError(t, err)
+	var params acp.PromptRequest
+	require.NoError(t, json.Unmarshal(raw, &params))
+	require.NotEmpty(t, params.Prompt)
+	require.NotNil(t, params.Prompt[0].Text)
+	prompt := params.Prompt[0].Text.Text
+
+	assert.True(t, strings.HasPrefix(prompt, preamble), "prompt must start with the preamble")
+	assert.Contains(t, prompt, "Return only output JSON text.")
+	assert.Greater(t, strings.Index(prompt, "Return only output JSON text."), strings.Index(prompt, preamble))
+}
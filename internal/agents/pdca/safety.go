@@ -0,0 +1,84 @@
+package pdca
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/metalagman/norma/internal/agents/pdca/contracts"
+)
+
+// suspiciousOutputStopReason is the stop reason of a step stopped by safety.stop_on_match.
+const suspiciousOutputStopReason = "suspicious_output"
+
+// maxMatchedLine caps how much of a matching line is kept in a process note.
+const maxMatchedLine = 200
+
+// outputMatch is an output line that matched a pattern, e.g. a safety.suspicious_patterns entry.
+type outputMatch struct {
+	Pattern string
+	Line    string
+}
+
+// compileSuspiciousPatterns compiles safety.suspicious_patterns, failing on the first invalid expression.
+func compileSuspiciousPatterns(patterns []string) ([]*regexp.Regexp, error) {
+	compiled := make([]*regexp.Regexp, 0, len(patterns))
+	for _, pattern := range patterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("safety suspicious pattern %q: %w", pattern, err)
+		}
+		compiled = append(compiled, re)
+	}
+	return compiled, nil
+}
+
+// scanOutputLines returns the first matching line of output for each pattern.
+func scanOutputLines(output []byte, patterns []*regexp.Regexp) []outputMatch {
+	if len(patterns) == 0 {
+		return nil
+	}
+	var matches []outputMatch
+	matched := make([]bool, len(patterns))
+	scanner := bufio.NewScanner(bytes.NewReader(output))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		for i, re := range patterns {
+			if matched[i] || !re.MatchString(line) {
+				continue
+			}
+			matched[i] = true
+			line = strings.TrimSpace(line)
+			if len(line) > maxMatchedLine {
+				line = line[:maxMatchedLine]
+			}
+			matches = append(matches, outputMatch{Pattern: re.String(), Line: line})
+		}
+	}
+	return matches
+}
+
+// applySuspiciousMatches records a high-severity process note per match and adds
+// them to the step progress. With stop set, it turns the response into a stop.
+func applySuspiciousMatches(state *contracts.TaskState, resp *contracts.AgentResponse, matches []outputMatch, stop bool, role, runID string, index int, now time.Time) {
+	for _, m := range matches {
+		text := fmt.Sprintf("suspicious agent output matched %q: %s", m.Pattern, m.Line)
+		state.ProcessNotes = append(state.ProcessNotes, contracts.ProcessNote{
+			Timestamp: now.UTC().Format(time.RFC3339),
+			RunID:     runID,
+			StepIndex: index,
+			Role:      role,
+			Severity:  contracts.ProcessNoteSeverityHigh,
+			Text:      text,
+		})
+		resp.Progress.Details = append(resp.Progress.Details, text)
+	}
+	if stop && len(matches) > 0 {
+		resp.Status = "stop"
+		resp.StopReason = suspiciousOutputStopReason
+	}
+}
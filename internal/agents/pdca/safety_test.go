@@ -0,0 +1,73 @@
+package pdca
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/metalagman/norma/internal/agents/pdca/contracts"
+)
+
+func TestScanSuspiciousOutput(t *testing.T) {
+	t.Parallel()
+
+	patterns, err := compileSuspiciousPatterns([]string{`(?i)i can(no|')t help with`, `rm -rf /`})
+	if err != nil {
+		t.Fatalf("compileSuspiciousPatterns() error = %v", err)
+	}
+
+	clean := []byte("running go test ./...\nok  \texample.com/pkg\n")
+	if got := scanOutputLines(clean, patterns); len(got) != 0 {
+		t.Fatalf("scanOutputLines(clean) = %+v, want no matches", got)
+	}
+
+	derailed := []byte("step 1\n  I can't help with that request.\nrm -rf / --no-preserve-root\nI cannot help with this either\n")
+	got := scanOutputLines(derailed, patterns)
+	if len(got) != 2 {
+		t.Fatalf("scanOutputLines(derailed) = %+v, want one match per pattern", got)
+	}
+	if got[0].Line != "I can't help with that request." {
+		t.Fatalf("first match line = %q, want the trimmed refusal line", got[0].Line)
+	}
+	if got[1].Pattern != "rm -rf /" {
+		t.Fatalf("second match pattern = %q, want %q", got[1].Pattern, "rm -rf /")
+	}
+}
+
+func TestCompileSuspiciousPatternsRejectsInvalid(t *testing.T) {
+	t.Parallel()
+
+	if _, err := compileSuspiciousPatterns([]string{"("}); err == nil || !strings.Contains(err.Error(), `"("`) {
+		t.Fatalf("compileSuspiciousPatterns(invalid) error = %v, want pattern error", err)
+	}
+}
+
+func TestApplySuspiciousMatches(t *testing.T) {
+	t.Parallel()
+
+	matches := []outputMatch{{Pattern: "rm -rf /", Line: "rm -rf /"}}
+	ts := time.Date(2026, time.February, 12, 13, 14, 15, 0, time.UTC)
+
+	for _, stop := range []bool{false, true} {
+		state := &contracts.TaskState{}
+		resp := &contracts.AgentResponse{Status: "ok"}
+		applySuspiciousMatches(state, resp, matches, stop, RoleDo, "run-1", 2, ts)
+
+		if len(state.ProcessNotes) != 1 || state.ProcessNotes[0].Severity != contracts.ProcessNoteSeverityHigh {
+			t.Fatalf("stop=%t: process notes = %+v, want one high-severity note", stop, state.ProcessNotes)
+		}
+		if len(resp.Progress.Details) != 1 {
+			t.Fatalf("stop=%t: progress details = %v, want the match recorded", stop, resp.Progress.Details)
+		}
+		wantStatus, wantReason := "ok", ""
+		if stop {
+			wantStatus, wantReason = "stop", suspiciousOutputStopReason
+		}
+		if resp.Status != wantStatus || resp.StopReason != wantReason {
+			t.Fatalf("stop=%t: response = (%q, %q), want (%q, %q)", stop, resp.Status, resp.StopReason, wantStatus, wantReason)
+		}
+		if digest := SummarizeFailures(*state); !strings.Contains(digest, "do step 2: suspicious agent output") {
+			t.Fatalf("stop=%t: failure digest = %q, want the process note", stop, digest)
+		}
+	}
+}
@@ -0,0 +1,20 @@
+package pdca
+
+import (
+	"bytes"
+	"unicode/utf8"
+)
+
+// utf8BOM is the byte order mark some agents put before their output.
+var utf8BOM = []byte{0xEF, 0xBB, 0xBF}
+
+// sanitizeAgentOutput prepares agent output for JSON parsing. A leading UTF-8 byte
+// order mark is always stripped; with replaceInvalid set, invalid UTF-8 sequences
+// are replaced by U+FFFD.
+func sanitizeAgentOutput(out []byte, replaceInvalid bool) []byte {
+	out = bytes.TrimPrefix(out, utf8BOM)
+	if replaceInvalid && !utf8.Valid(out) {
+		out = bytes.ToValidUTF8(out, []byte(string(utf8.RuneError)))
+	}
+	return out
+}
@@ -0,0 +1,35 @@
+package pdca
+
+import (
+	"testing"
+	"unicode/utf8"
+)
+
+func TestSanitizeAgentOutput(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name           string
+		in             string
+		replaceInvalid bool
+		want           string
+	}{
+		{name: "clean output unchanged", in: `{"a":"b"}`, replaceInvalid: true, want: `{"a":"b"}`},
+		{name: "leading bom stripped", in: "\xef\xbb\xbf{\"a\":\"b\"}", want: `{"a":"b"}`},
+		{name: "invalid utf8 kept without replacement", in: "{\"a\":\"b\xff\"}", want: "{\"a\":\"b\xff\"}"},
+		{name: "invalid utf8 replaced", in: "\xef\xbb\xbf{\"a\":\"b\xff\xfe\"}", replaceInvalid: true, want: "{\"a\":\"b�\"}"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			got := string(sanitizeAgentOutput([]byte(tc.in), tc.replaceInvalid))
+			if got != tc.want {
+				t.Fatalf("sanitizeAgentOutput(%q) = %q, want %q", tc.in, got, tc.want)
+			}
+			if tc.replaceInvalid && !utf8.ValidString(got) {
+				t.Fatalf("sanitizeAgentOutput(%q) = %q is not valid UTF-8", tc.in, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,219 @@
+package pdca
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"math"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/metalagman/norma/internal/agents/pdca/contracts"
+	"github.com/metalagman/norma/internal/git"
+)
+
+// secretsStopReason is the stop reason of a Do step whose changes were reverted by safety.block_secrets.
+const secretsStopReason = "secrets_detected"
+
+// DefaultSecretPatterns match well-known credential formats in added diff lines.
+var DefaultSecretPatterns = []string{
+	`AKIA[0-9A-Z]{16}`,
+	`gh[pousr]_[A-Za-z0-9]{36,}`,
+	`github_pat_[A-Za-z0-9_]{40,}`,
+	`xox[abprs]-[A-Za-z0-9-]{10,}`,
+	`\bsk-(proj-)?[A-Za-z0-9]{20,}`,
+	`AIza[0-9A-Za-z_-]{35}`,
+	`-----BEGIN [A-Z ]*PRIVATE KEY-----`,
+	`(?i)(api[_-]?key|secret|token|password|passwd)["']?\s*[:=]\s*["'][^"'\s]{12,}["']`,
+}
+
+// highEntropyToken finds candidate tokens for the entropy heuristic.
+var highEntropyToken = regexp.MustCompile(`[A-Za-z0-9+/_=-]{32,}`)
+
+const (
+	// highEntropyPattern is the SecretHit pattern of a token flagged by its entropy.
+	highEntropyPattern = "high-entropy string"
+	// minSecretEntropy is the Shannon entropy in bits per character above which a token looks random.
+	minSecretEntropy = 4.5
+)
+
+// lockFiles hold generated checksums that would trip the entropy heuristic.
+var lockFiles = map[string]bool{
+	"go.sum":            true,
+	"package-lock.json": true,
+	"yarn.lock":         true,
+	"pnpm-lock.yaml":    true,
+	"Cargo.lock":        true,
+	"poetry.lock":       true,
+	"composer.lock":     true,
+	"Gemfile.lock":      true,
+}
+
+// SecretHit is an added diff line that looks like it holds a secret.
+type SecretHit struct {
+	File string
+	// Line is the line number in the new version of File.
+	Line    int
+	Pattern string
+	// Text is the line with the matched secret redacted.
+	Text string
+}
+
+// ScanDiffForSecrets reports the added lines of a unified diff that match one of the
+// patterns (regular expressions; invalid ones are ignored) or hold a high-entropy
+// token. Lock files are exempt from the entropy check.
+func ScanDiffForSecrets(diff []byte, patterns []string) []SecretHit {
+	compiled := make([]*regexp.Regexp, 0, len(patterns))
+	for _, pattern := range patterns {
+		if re, err := regexp.Compile(pattern); err == nil {
+			compiled = append(compiled, re)
+		}
+	}
+
+	var hits []SecretHit
+	file := ""
+	line := 0
+	scanner := bufio.NewScanner(bytes.NewReader(diff))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		text := scanner.Text()
+		switch {
+		case strings.HasPrefix(text, "+++ "):
+			file = strings.TrimPrefix(strings.TrimPrefix(text, "+++ "), "b/")
+		case strings.HasPrefix(text, "@@ "):
+			line = hunkNewStart(text)
+		case strings.HasPrefix(text, "+"):
+			added := text[1:]
+			if hit, ok := scanSecretLine(added, compiled, !lockFiles[path.Base(file)]); ok {
+				hit.File = file
+				hit.Line = line
+				hits = append(hits, hit)
+			}
+			line++
+		case strings.HasPrefix(text, " "):
+			line++
+		}
+	}
+	return hits
+}
+
+// scanSecretLine checks one added line against patterns and, with entropy set, the entropy heuristic.
+func scanSecretLine(text string, patterns []*regexp.Regexp, entropy bool) (SecretHit, bool) {
+	for _, re := range patterns {
+		if loc := re.FindStringIndex(text); loc != nil {
+			return SecretHit{Pattern: re.String(), Text: redactSecret(text, loc)}, true
+		}
+	}
+	if !entropy {
+		return SecretHit{}, false
+	}
+	for _, loc := range highEntropyToken.FindAllStringIndex(text, -1) {
+		if shannonEntropy(text[loc[0]:loc[1]]) >= minSecretEntropy {
+			return SecretHit{Pattern: highEntropyPattern, Text: redactSecret(text, loc)}, true
+		}
+	}
+	return SecretHit{}, false
+}
+
+// hunkNewStart returns the first new-file line number of a "@@ -a,b +c,d @@" hunk header.
+func hunkNewStart(header string) int {
+	fields := strings.Fields(header)
+	if len(fields) < 3 || !strings.HasPrefix(fields[2], "+") {
+		return 0
+	}
+	start, _, _ := strings.Cut(strings.TrimPrefix(fields[2], "+"), ",")
+	n, err := strconv.Atoi(start)
+	if err != nil {
+		return 0
+	}
+	return n
+}
+
+// redactSecret keeps the first four characters of the match at loc and masks the rest,
+// so reports point at the secret without repeating it.
+func redactSecret(text string, loc []int) string {
+	match := text[loc[0]:loc[1]]
+	keep := min(4, len(match))
+	text = text[:loc[0]] + match[:keep] + strings.Repeat("*", len(match)-keep) + text[loc[1]:]
+	text = strings.TrimSpace(text)
+	if len(text) > maxMatchedLine {
+		text = text[:maxMatchedLine]
+	}
+	return text
+}
+
+// shannonEntropy returns the Shannon entropy of s in bits per character.
+func shannonEntropy(s string) float64 {
+	counts := make(map[rune]int)
+	for _, r := range s {
+		counts[r]++
+	}
+	n := float64(len(s))
+	var entropy float64
+	for _, c := range counts {
+		p := float64(c) / n
+		entropy -= p * math.Log2(p)
+	}
+	return entropy
+}
+
+// secretPatterns returns the built-in secret patterns followed by safety.secret_patterns.
+func secretPatterns(extra []string) []string {
+	return append(append([]string(nil), DefaultSecretPatterns...), extra...)
+}
+
+// compileSecretPatterns validates safety.secret_patterns, failing on the first invalid expression.
+func compileSecretPatterns(patterns []string) error {
+	for _, pattern := range patterns {
+		if _, err := regexp.Compile(pattern); err != nil {
+			return fmt.Errorf("safety secret pattern %q: %w", pattern, err)
+		}
+	}
+	return nil
+}
+
+// revertSecrets stages the workspace changes and scans them for secrets. When any are
+// found it reverts the workspace to HEAD, so nothing is committed, and returns the hits.
+func revertSecrets(ctx context.Context, workspaceDir string, patterns []string) ([]SecretHit, error) {
+	if err := git.GitRunCmdErr(ctx, workspaceDir, "git", "add", "-A"); err != nil {
+		return nil, fmt.Errorf("stage workspace changes: %w", err)
+	}
+	diff, err := git.GitRunCmdOutput(ctx, workspaceDir, "git", "diff", "--cached", "--no-color", "--no-ext-diff")
+	if err != nil {
+		return nil, fmt.Errorf("read staged diff: %w", err)
+	}
+	hits := ScanDiffForSecrets([]byte(diff), patterns)
+	if len(hits) == 0 {
+		return nil, nil
+	}
+	if err := git.GitRunCmdErr(ctx, workspaceDir, "git", "reset", "--hard", "-q", "HEAD"); err != nil {
+		return nil, fmt.Errorf("revert workspace changes: %w", err)
+	}
+	if err := git.GitRunCmdErr(ctx, workspaceDir, "git", "clean", "-fdq"); err != nil {
+		return nil, fmt.Errorf("remove untracked workspace files: %w", err)
+	}
+	return hits, nil
+}
+
+// blockSecrets keeps a report of hits in logs/secrets.txt under stepDir and stops the
+// Do step with a blocker per offending line.
+func blockSecrets(stepDir string, hits []SecretHit, resp *contracts.AgentResponse) error {
+	var report strings.Builder
+	for _, hit := range hits {
+		line := fmt.Sprintf("%s:%d: %s", hit.File, hit.Line, hit.Pattern)
+		fmt.Fprintf(&report, "%s\n\t%s\n", line, hit.Text)
+		resp.Progress.Details = append(resp.Progress.Details, "possible secret reverted at "+line)
+	}
+	if err := os.WriteFile(filepath.Join(stepDir, "logs", "secrets.txt"), []byte(report.String()), 0o600); err != nil {
+		return fmt.Errorf("write secrets report: %w", err)
+	}
+	resp.Progress.Details = append(resp.Progress.Details, "Do changes were reverted; see logs/secrets.txt")
+	resp.Status = "stop"
+	resp.StopReason = secretsStopReason
+	return nil
+}
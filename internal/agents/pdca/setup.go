@@ -0,0 +1,52 @@
+package pdca
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/metalagman/norma/internal/verify"
+	"github.com/rs/zerolog"
+)
+
+// setupDir is the run subdirectory holding the setup command workspace and log.
+const setupDir = "setup"
+
+// runSetupCommand runs command once in a worktree of the task branch mounted under
+// runDir/setup/workspace and keeps its output in runDir/setup/output.txt.
+// A nonzero exit fails the run before the first Plan step.
+func runSetupCommand(ctx context.Context, l zerolog.Logger, limit int, repoRoot, runDir, branchName, baseBranch, command string) error {
+	if strings.TrimSpace(command) == "" {
+		return nil
+	}
+	dir := filepath.Join(runDir, setupDir)
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return fmt.Errorf("create setup dir: %w", err)
+	}
+	workspaceDir := filepath.Join(dir, "workspace")
+	removeWorktree, err := mountStepWorktree(ctx, worktreeSlots, limit, repoRoot, workspaceDir, branchName, baseBranch)
+	if err != nil {
+		return fmt.Errorf("mount setup worktree: %w", err)
+	}
+	defer func() {
+		if err := removeWorktree(); err != nil {
+			l.Warn().Err(err).Str("workspace", workspaceDir).Msg("failed to remove setup worktree")
+		}
+	}()
+
+	l.Info().Str("command", command).Msg("running setup command")
+	exitCode, output, err := verify.RunCommand(ctx, workspaceDir, command)
+	if err != nil {
+		return fmt.Errorf("run setup command %q: %w", command, err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "output.txt"), []byte(output), 0o600); err != nil {
+		return fmt.Errorf("write setup command log: %w", err)
+	}
+	l.Info().Str("command", command).Int("exit_code", exitCode).Str("output", output).Msg("setup command finished")
+	if exitCode != 0 {
+		return fmt.Errorf("setup command %q exited %d; see %s", command, exitCode, filepath.Join(dir, "output.txt"))
+	}
+	return nil
+}
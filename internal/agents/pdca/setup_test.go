@@ -0,0 +1,58 @@
+package pdca
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/rs/zerolog"
+)
+
+func TestRunSetupCommand(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		command string
+		wantErr bool
+		wantLog string
+	}{
+		{name: "passing", command: "test -f go.mod && echo deps ready", wantLog: "deps ready"},
+		{name: "failing", command: "echo cannot download >&2; exit 3", wantErr: true, wantLog: "cannot download"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctx := context.Background()
+			repo := t.TempDir()
+			initTestRepo(t, ctx, repo)
+			writeTestFile(t, filepath.Join(repo, "go.mod"), "module example.com/app\n")
+			runGit(t, ctx, repo, "add", ".")
+			runGit(t, ctx, repo, "commit", "-m", "init")
+			runDir := filepath.Join(repo, ".norma", "runs", "run-1")
+
+			err := runSetupCommand(ctx, zerolog.Nop(), 0, repo, runDir, "norma/task/setup-"+tc.name, "", tc.command)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("runSetupCommand() error = %v, want error %t", err, tc.wantErr)
+			}
+			if tc.wantErr && !strings.Contains(err.Error(), "exited 3") {
+				t.Fatalf("runSetupCommand() error = %v, want exit code in message", err)
+			}
+
+			logData, err := os.ReadFile(filepath.Join(runDir, setupDir, "output.txt"))
+			if err != nil {
+				t.Fatalf("read setup log: %v", err)
+			}
+			if !strings.Contains(string(logData), tc.wantLog) {
+				t.Fatalf("setup log = %q, want it to contain %q", logData, tc.wantLog)
+			}
+			if _, err := os.Stat(filepath.Join(runDir, setupDir, "workspace")); !os.IsNotExist(err) {
+				t.Fatalf("setup workspace still present: %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,39 @@
+package pdca
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+
+	"github.com/metalagman/norma/internal/agents/pdca/contracts"
+)
+
+// scanStderrWarnings returns the first line of the step's logs/stderr.txt matching
+// each of patterns, the agent's stderr_warning_patterns.
+func scanStderrWarnings(stepDir string, patterns []string) ([]outputMatch, error) {
+	if len(patterns) == 0 {
+		return nil, nil
+	}
+	compiled := make([]*regexp.Regexp, 0, len(patterns))
+	for _, pattern := range patterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("stderr warning pattern %q: %w", pattern, err)
+		}
+		compiled = append(compiled, re)
+	}
+	stderr, err := os.ReadFile(filepath.Join(stepDir, "logs", "stderr.txt"))
+	if err != nil {
+		return nil, fmt.Errorf("read stderr log for warning scan: %w", err)
+	}
+	return scanOutputLines(stderr, compiled), nil
+}
+
+// applyStderrWarnings adds a progress detail per stderr warning, so it reaches the journal.
+// The step status is left unchanged.
+func applyStderrWarnings(resp *contracts.AgentResponse, warnings []outputMatch) {
+	for _, w := range warnings {
+		resp.Progress.Details = append(resp.Progress.Details, "agent stderr warning: "+w.Line)
+	}
+}
@@ -0,0 +1,57 @@
+package pdca
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/metalagman/norma/internal/agents/pdca/contracts"
+	"github.com/metalagman/norma/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStderrWarningsRecordedOnSuccess(t *testing.T) {
+	stepDir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(stepDir, "logs"), 0o700))
+	stderrLog, err := os.Create(filepath.Join(stepDir, "logs", "stderr.txt"))
+	require.NoError(t, err)
+	defer func() { _ = stderrLog.Close() }()
+
+	cmd := helperACPCommand(t, `{"status":"ok","summary":{"text":"success"},"progress":{"title":"done","details":[]}}`)
+	cmd = append([]string{cmd[0], "GO_HELPER_STDERR=WARN: model gpt-x is deprecated"}, cmd[1:]...)
+	cfg := config.AgentConfig{
+		Type:                  config.AgentTypeGenericACP,
+		Cmd:                   cmd,
+		StderrWarningPatterns: []string{`^WARN:`, `^ERROR:`},
+	}
+	runner, err := NewRunner(cfg, &dummyRole{})
+	require.NoError(t, err)
+
+	out, _, exitCode, err := runner.Run(context.Background(), fileModeRequest(t, stepDir), io.Discard, stderrLog)
+	require.NoError(t, err)
+	require.Equal(t, 0, exitCode)
+	resp, err := (&dummyRole{}).MapResponse(out)
+	require.NoError(t, err)
+
+	warnings, err := scanStderrWarnings(stepDir, cfg.StderrWarningPatterns)
+	require.NoError(t, err)
+	require.Len(t, warnings, 1)
+	assert.Equal(t, "^WARN:", warnings[0].Pattern)
+
+	applyStderrWarnings(&resp, warnings)
+	assert.Equal(t, "ok", resp.Status)
+	state := &contracts.TaskState{}
+	applyAgentResponseToTaskState(state, &resp, RoleDo, "run-1", 1, 2, time.Now())
+	require.Len(t, state.Journal, 1)
+	assert.Equal(t, []string{"agent stderr warning: WARN: model gpt-x is deprecated"}, state.Journal[0].Details)
+}
+
+func TestScanStderrWarningsWithoutPatterns(t *testing.T) {
+	warnings, err := scanStderrWarnings(t.TempDir(), nil)
+	require.NoError(t, err)
+	assert.Empty(t, warnings)
+}
@@ -0,0 +1,105 @@
+package pdca
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/metalagman/norma/internal/agents/pdca/contracts"
+	"github.com/metalagman/norma/internal/agents/pdca/roles/check"
+	"github.com/metalagman/norma/internal/agents/pdca/roles/plan"
+	"github.com/metalagman/norma/internal/config"
+	"github.com/metalagman/norma/internal/fsutil"
+	"github.com/metalagman/norma/internal/verify"
+)
+
+// verifyFileName is the Check step file holding the results of the deterministic checks.
+const verifyFileName = "verify.json"
+
+// verifiableChecks returns the effective acceptance criteria reduced to the checks
+// the deterministic verifier runs under cfg: command checks with verify_checks and
+// webhook checks with allow_webhook_checks. Criteria without such checks are dropped.
+func verifiableChecks(cfg config.Config, effective []plan.EffectiveAcceptanceCriteria) []plan.EffectiveAcceptanceCriteria {
+	var out []plan.EffectiveAcceptanceCriteria
+	for _, ac := range effective {
+		var checks []plan.CriterionCheck
+		for _, chk := range ac.Checks {
+			switch chk.Mode {
+			case "", verify.ModeCommand:
+				if cfg.VerifyChecks {
+					checks = append(checks, chk)
+				}
+			case verify.ModeWebhook:
+				if cfg.AllowWebhookChecks {
+					checks = append(checks, chk)
+				}
+			}
+		}
+		if len(checks) > 0 {
+			ac.Checks = checks
+			out = append(out, ac)
+		}
+	}
+	return out
+}
+
+// runDeterministicChecks runs the plan's acceptance checks selected by verifiableChecks
+// in the Check workspace, up to cfg.CheckParallelism at once, with the verifier options
+// of cfg, and keeps their results in verify.json under stepDir. A criterion whose
+// checks fail is reported as FAIL whatever the Check agent said, and a PASS verdict is
+// replaced by the verdict for the resulting score. It returns the ids of the failed criteria.
+func runDeterministicChecks(ctx context.Context, cfg config.Config, workspaceDir, stepDir string, effective []plan.EffectiveAcceptanceCriteria, resp *contracts.AgentResponse) ([]string, error) {
+	criteria := verifiableChecks(cfg, effective)
+	if len(criteria) == 0 || resp == nil || resp.Check == nil {
+		return nil, nil
+	}
+	results := verify.RunAcceptanceChecks(ctx, workspaceDir, criteria, cfg.CheckParallelism, verify.OptionsFromConfig(cfg)...)
+	if err := fsutil.WriteJSONAtomic(filepath.Join(stepDir, verifyFileName), results); err != nil {
+		return nil, fmt.Errorf("write deterministic check results: %w", err)
+	}
+
+	var failed []string
+	for _, result := range results {
+		if result.Result == check.VerdictPass {
+			continue
+		}
+		failed = append(failed, result.AcId)
+		failAcceptanceResult(resp.Check, result)
+	}
+	if len(failed) == 0 {
+		return nil, nil
+	}
+	resp.Progress.Details = append(resp.Progress.Details,
+		fmt.Sprintf("deterministic checks failed for acceptance criteria: %s; see %s", strings.Join(failed, ", "), verifyFileName))
+
+	verdict := resp.Check.Verdict
+	if verdict == nil || !strings.EqualFold(strings.TrimSpace(verdict.Status), check.VerdictPass) {
+		return failed, nil
+	}
+	verdict.Status = check.VerdictForScore(check.AggregateScore(resp.Check.AcceptanceResults))
+	if verdict.Basis != nil {
+		verdict.Basis.AllAcceptancePassed = false
+	}
+	resp.Progress.Details = append(resp.Progress.Details,
+		fmt.Sprintf("verdict downgraded to %s: deterministic checks failed", verdict.Status))
+	return failed, nil
+}
+
+// failAcceptanceResult marks the result for failed.AcId as FAIL, adding the check notes,
+// or appends failed when the Check agent reported no result for the criterion.
+func failAcceptanceResult(out *check.CheckOutput, failed check.CheckAcceptanceResult) {
+	for i := range out.AcceptanceResults {
+		result := &out.AcceptanceResults[i]
+		if result.AcId != failed.AcId {
+			continue
+		}
+		result.Result = check.VerdictFail
+		result.Score = 0
+		if failed.Notes != "" {
+			result.Notes = strings.TrimSpace(result.Notes + "\n" + failed.Notes)
+		}
+		return
+	}
+	out.AcceptanceResults = append(out.AcceptanceResults, failed)
+}
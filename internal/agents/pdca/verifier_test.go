@@ -0,0 +1,142 @@
+package pdca
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/metalagman/norma/internal/agents/pdca/contracts"
+	"github.com/metalagman/norma/internal/agents/pdca/roles/check"
+	"github.com/metalagman/norma/internal/agents/pdca/roles/plan"
+	"github.com/metalagman/norma/internal/config"
+	"github.com/metalagman/norma/internal/verify"
+)
+
+func verifierCriteria() []plan.EffectiveAcceptanceCriteria {
+	return []plan.EffectiveAcceptanceCriteria{
+		{Id: "AC1", Checks: []plan.CriterionCheck{{Id: "C1", Cmd: "test -f go.mod"}}},
+		{Id: "AC2", Checks: []plan.CriterionCheck{{Id: "C2", Cmd: "test -f missing.txt"}}},
+		{Id: "AC3"},
+	}
+}
+
+func passingCheckResponse() *contracts.AgentResponse {
+	return &contracts.AgentResponse{
+		Status: "ok",
+		Check: &check.CheckOutput{
+			Verdict: &check.CheckVerdict{Status: check.VerdictPass, Basis: &check.CheckVerdictBasis{AllAcceptancePassed: true}},
+			AcceptanceResults: []check.CheckAcceptanceResult{
+				{AcId: "AC1", Result: check.VerdictPass},
+				{AcId: "AC2", Result: check.VerdictPass, Score: 1},
+				{AcId: "AC3", Result: check.VerdictPass},
+			},
+		},
+	}
+}
+
+func TestRunDeterministicChecks(t *testing.T) {
+	t.Parallel()
+
+	workspace := t.TempDir()
+	writeTestFile(t, filepath.Join(workspace, "go.mod"), "module example.com/app\n")
+	stepDir := t.TempDir()
+	resp := passingCheckResponse()
+	cfg := config.Config{VerifyChecks: true, CheckParallelism: 2}
+
+	failed, err := runDeterministicChecks(context.Background(), cfg, workspace, stepDir, verifierCriteria(), resp)
+	if err != nil {
+		t.Fatalf("runDeterministicChecks() error = %v", err)
+	}
+	if !slices.Equal(failed, []string{"AC2"}) {
+		t.Fatalf("failed = %v, want [AC2]", failed)
+	}
+	results := resp.Check.AcceptanceResults
+	if results[0].Result != check.VerdictPass || results[1].Result != check.VerdictFail || results[1].Score != 0 || results[2].Result != check.VerdictPass {
+		t.Fatalf("acceptance results = %+v, want AC2 failed only", results)
+	}
+	if !strings.Contains(results[1].Notes, "C2") {
+		t.Fatalf("AC2 notes = %q, want the failed check", results[1].Notes)
+	}
+	if resp.Check.Verdict.Status != check.VerdictPartial || resp.Check.Verdict.Basis.AllAcceptancePassed {
+		t.Fatalf("verdict = %+v, want PARTIAL", resp.Check.Verdict)
+	}
+
+	data, err := os.ReadFile(filepath.Join(stepDir, verifyFileName))
+	if err != nil {
+		t.Fatalf("read %s: %v", verifyFileName, err)
+	}
+	var recorded []check.CheckAcceptanceResult
+	if err := json.Unmarshal(data, &recorded); err != nil {
+		t.Fatalf("parse %s: %v", verifyFileName, err)
+	}
+	if len(recorded) != 2 || recorded[0].AcId != "AC1" || recorded[1].AcId != "AC2" {
+		t.Fatalf("%s = %+v, want results for AC1 and AC2", verifyFileName, recorded)
+	}
+}
+
+func TestRunDeterministicChecksDisabled(t *testing.T) {
+	t.Parallel()
+
+	stepDir := t.TempDir()
+	resp := passingCheckResponse()
+
+	failed, err := runDeterministicChecks(context.Background(), config.Config{}, t.TempDir(), stepDir, verifierCriteria(), resp)
+	if err != nil || failed != nil {
+		t.Fatalf("runDeterministicChecks() = %v, %v, want no checks run", failed, err)
+	}
+	if resp.Check.Verdict.Status != check.VerdictPass {
+		t.Fatalf("verdict = %s, want PASS untouched", resp.Check.Verdict.Status)
+	}
+	if _, err := os.Stat(filepath.Join(stepDir, verifyFileName)); !os.IsNotExist(err) {
+		t.Fatalf("%s written with verify_checks off: %v", verifyFileName, err)
+	}
+}
+
+func TestRunDeterministicChecksWebhook(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(`{"pass": false, "notes": "deploy check red"}`))
+	}))
+	defer srv.Close()
+
+	criteria := []plan.EffectiveAcceptanceCriteria{
+		{Id: "AC1", Checks: []plan.CriterionCheck{{Id: "C1", Cmd: "false"}}},
+		{Id: "AC2", Checks: []plan.CriterionCheck{{Id: "C2", Mode: verify.ModeWebhook, Url: srv.URL}}},
+	}
+	resp := passingCheckResponse()
+	cfg := config.Config{AllowWebhookChecks: true}
+
+	failed, err := runDeterministicChecks(context.Background(), cfg, t.TempDir(), t.TempDir(), criteria, resp)
+	if err != nil {
+		t.Fatalf("runDeterministicChecks() error = %v", err)
+	}
+	// Command checks stay off without verify_checks, so only the webhook check runs.
+	if !slices.Equal(failed, []string{"AC2"}) {
+		t.Fatalf("failed = %v, want [AC2]", failed)
+	}
+	if !strings.Contains(resp.Check.AcceptanceResults[1].Notes, "deploy check red") {
+		t.Fatalf("AC2 notes = %q, want the webhook notes", resp.Check.AcceptanceResults[1].Notes)
+	}
+}
+
+func TestPlanEffectiveToDoDropsWebhookChecks(t *testing.T) {
+	t.Parallel()
+
+	got := planEffectiveToDo([]plan.EffectiveAcceptanceCriteria{{
+		Id: "AC1",
+		Checks: []plan.CriterionCheck{
+			{Id: "C1", Cmd: "go test ./..."},
+			{Id: "C2", Mode: verify.ModeWebhook, Url: "https://ci.example.com/verify"},
+		},
+	}})
+	if len(got) != 1 || len(got[0].Checks) != 1 || got[0].Checks[0].Id != "C1" {
+		t.Fatalf("planEffectiveToDo() = %+v, want only the command check", got)
+	}
+}
@@ -0,0 +1,45 @@
+package pdca
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/metalagman/norma/internal/config"
+)
+
+// DefaultWorkflowSteps is the role sequence of an iteration when workflow.steps is unset.
+var DefaultWorkflowSteps = []string{RolePlan, RoleDo, RoleCheck, RoleAct}
+
+// workflowSteps returns the role sequence configured in workflow.steps,
+// failing on roles that are not registered.
+func workflowSteps(cfg config.WorkflowConfig) ([]string, error) {
+	if len(cfg.Steps) == 0 {
+		return slices.Clone(DefaultWorkflowSteps), nil
+	}
+	steps := make([]string, 0, len(cfg.Steps))
+	for _, step := range cfg.Steps {
+		roleName := strings.ToLower(strings.TrimSpace(step))
+		if GetRole(roleName) == nil {
+			return nil, fmt.Errorf("workflow step %q: role is not registered", step)
+		}
+		steps = append(steps, roleName)
+	}
+	return steps, nil
+}
+
+// subAgentNames returns unique subagent names for steps; a role that repeats
+// gets its position among the repeats appended, e.g. Check, Check2.
+func subAgentNames(steps []string) []string {
+	names := make([]string, 0, len(steps))
+	seen := make(map[string]int, len(steps))
+	for _, roleName := range steps {
+		seen[roleName]++
+		name := pascalRoleName(roleName)
+		if n := seen[roleName]; n > 1 {
+			name = fmt.Sprintf("%s%d", name, n)
+		}
+		names = append(names, name)
+	}
+	return names
+}
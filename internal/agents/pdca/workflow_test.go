@@ -0,0 +1,254 @@
+package pdca
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/metalagman/norma/internal/adkrunner"
+	"github.com/metalagman/norma/internal/agents/pdca/contracts"
+	"github.com/metalagman/norma/internal/agents/pdca/roles/act"
+	"github.com/metalagman/norma/internal/agents/pdca/roles/check"
+	"github.com/metalagman/norma/internal/agents/pdca/roles/do"
+	"github.com/metalagman/norma/internal/agents/pdca/roles/plan"
+	"github.com/metalagman/norma/internal/config"
+	"github.com/metalagman/norma/internal/task"
+
+	"google.golang.org/adk/agent"
+	"google.golang.org/adk/session"
+)
+
+func TestWorkflowSteps(t *testing.T) {
+	t.Parallel()
+
+	got, err := workflowSteps(config.WorkflowConfig{})
+	if err != nil {
+		t.Fatalf("workflowSteps(default) error = %v", err)
+	}
+	if !slices.Equal(got, DefaultWorkflowSteps) {
+		t.Fatalf("workflowSteps(default) = %v, want %v", got, DefaultWorkflowSteps)
+	}
+
+	got, err = workflowSteps(config.WorkflowConfig{Steps: []string{"Plan", " do ", "check", "check"}})
+	if err != nil {
+		t.Fatalf("workflowSteps(custom) error = %v", err)
+	}
+	if want := []string{RolePlan, RoleDo, RoleCheck, RoleCheck}; !slices.Equal(got, want) {
+		t.Fatalf("workflowSteps(custom) = %v, want %v", got, want)
+	}
+	if want := []string{"Plan", "Do", "Check", "Check2"}; !slices.Equal(subAgentNames(got), want) {
+		t.Fatalf("subAgentNames() = %v, want %v", subAgentNames(got), want)
+	}
+
+	if _, err := workflowSteps(config.WorkflowConfig{Steps: []string{"plan", "review"}}); err == nil || !strings.Contains(err.Error(), `"review"`) {
+		t.Fatalf("workflowSteps(unknown role) error = %v, want unregistered role error", err)
+	}
+}
+
+func TestLoopAgentRunsWorkflowStepsInOrder(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		steps    []string
+		verdicts []string
+		want     []string
+	}{
+		{
+			name:     "default",
+			verdicts: []string{"PASS"},
+			want:     []string{"1:plan", "1:do", "1:check", "1:act"},
+		},
+		{
+			name:     "without_act",
+			steps:    []string{"plan", "do", "check"},
+			verdicts: []string{"FAIL", "PASS"},
+			want:     []string{"1:plan", "1:do", "1:check", "2:plan", "2:do", "2:check"},
+		},
+		{
+			name:     "doubled_check",
+			steps:    []string{"plan", "do", "check", "check", "act"},
+			verdicts: []string{"FAIL", "PASS"},
+			want:     []string{"1:plan", "1:do", "1:check", "1:check", "1:act"},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			steps, err := workflowSteps(config.WorkflowConfig{Steps: tc.steps})
+			if err != nil {
+				t.Fatalf("workflowSteps() error = %v", err)
+			}
+			var ran []string
+			rt := &runtime{steps: steps}
+			rt.overrideRunStep = func(_ agent.InvocationContext, iteration int, roleName string) (*contracts.AgentResponse, error) {
+				ran = append(ran, fmt.Sprintf("%d:%s", iteration, roleName))
+				return workflowStepResponse(roleName, &tc.verdicts), nil
+			}
+
+			loopAgent, err := rt.newLoopAgent(context.Background(), 3)
+			if err != nil {
+				t.Fatalf("newLoopAgent() error = %v", err)
+			}
+			if _, _, err := adkrunner.Run(context.Background(), adkrunner.RunInput{
+				Agent:        loopAgent,
+				InitialState: map[string]any{"iteration": 1},
+			}); err != nil {
+				t.Fatalf("adkrunner.Run() error = %v", err)
+			}
+
+			if !slices.Equal(ran, tc.want) {
+				t.Fatalf("steps ran = %v, want %v", ran, tc.want)
+			}
+		})
+	}
+}
+
+// workflowStepResponse returns an ok response for roleName. Check steps consume
+// verdicts in order; Act closes once the last verdict passed.
+func workflowStepResponse(roleName string, verdicts *[]string) *contracts.AgentResponse {
+	resp := &contracts.AgentResponse{Status: "ok"}
+	switch roleName {
+	case RolePlan:
+		resp.Plan = &plan.PlanOutput{}
+	case RoleDo:
+		resp.Do = &do.DoOutput{}
+	case RoleCheck:
+		status := (*verdicts)[0]
+		if len(*verdicts) > 1 {
+			*verdicts = (*verdicts)[1:]
+		}
+		resp.Check = &check.CheckOutput{Verdict: &check.CheckVerdict{Status: status}}
+	case RoleAct:
+		resp.Act = &act.ActOutput{Decision: "close"}
+	}
+	return resp
+}
+
+func TestLoopAgentObserversDoNotAffectControlFlow(t *testing.T) {
+	t.Parallel()
+
+	var ran []string
+	verdicts := []string{"FAIL", "PASS"}
+	rt := &runtime{steps: slices.Clone(DefaultWorkflowSteps), observers: []string{"critic"}}
+	rt.overrideRunStep = func(_ agent.InvocationContext, iteration int, roleName string) (*contracts.AgentResponse, error) {
+		ran = append(ran, fmt.Sprintf("%d:%s", iteration, roleName))
+		resp := workflowStepResponse(roleName, &verdicts)
+		if roleName == RoleAct && iteration == 1 {
+			resp.Act.Decision = "continue"
+		}
+		return resp, nil
+	}
+	// Step state lives on the invocation session, so keep it to inspect the journal afterwards.
+	var sess session.Session
+	rt.overrideRunObserver = func(ctx agent.InvocationContext, iteration, _ int, name string) (*contracts.AgentResponse, error) {
+		sess = ctx.Session()
+		ran = append(ran, fmt.Sprintf("%d:%s", iteration, name))
+		if iteration == 1 {
+			return &contracts.AgentResponse{Status: "stop", StopReason: "budget_exceeded"}, nil
+		}
+		return nil, fmt.Errorf("observer crashed")
+	}
+
+	loopAgent, err := rt.newLoopAgent(context.Background(), 3)
+	if err != nil {
+		t.Fatalf("newLoopAgent() error = %v", err)
+	}
+	_, _, err = adkrunner.Run(context.Background(), adkrunner.RunInput{
+		Agent:        loopAgent,
+		InitialState: map[string]any{"iteration": 1},
+	})
+	if err != nil {
+		t.Fatalf("adkrunner.Run() error = %v", err)
+	}
+
+	want := []string{"1:plan", "1:do", "1:check", "1:act", "1:critic", "2:plan", "2:do", "2:check", "2:act", "2:critic"}
+	if !slices.Equal(ran, want) {
+		t.Fatalf("steps ran = %v, want %v", ran, want)
+	}
+
+	stateVal, err := sess.State().Get("task_state")
+	if err != nil {
+		t.Fatalf("get task_state: %v", err)
+	}
+	state := coerceTaskState(stateVal)
+	var observed []contracts.JournalEntry
+	for _, entry := range state.Journal {
+		if entry.Type == contracts.JournalEntryObserver {
+			observed = append(observed, entry)
+		}
+	}
+	if len(observed) != 2 {
+		t.Fatalf("observer journal entries = %+v, want 2", observed)
+	}
+	if observed[0].Role != "critic" || observed[0].Status != "stop" || observed[0].Iteration != 1 {
+		t.Fatalf("first observer entry = %+v, want critic stop in iteration 1", observed[0])
+	}
+	if observed[1].Status != "error" || !slices.Contains(observed[1].Details, "observer crashed") {
+		t.Fatalf("second observer entry = %+v, want recorded failure", observed[1])
+	}
+	if digest := SummarizeFailures(*state); strings.Contains(digest, "critic") {
+		t.Fatalf("failure digest = %q, want observers left out", digest)
+	}
+}
+
+func TestLoopAgentDowngradesEarlyClose(t *testing.T) {
+	t.Parallel()
+
+	criteria := []task.AcceptanceCriterion{{ID: "AC1", Text: "works"}}
+	verified := &contracts.TaskState{Check: &check.CheckOutput{
+		Verdict:           &check.CheckVerdict{Status: "PASS"},
+		AcceptanceResults: []check.CheckAcceptanceResult{{AcId: "AC1", Result: "PASS"}},
+	}}
+	tests := []struct {
+		name          string
+		minIterations int
+		taskState     *contracts.TaskState
+		wantLastAct   string
+	}{
+		{name: "disabled", wantLastAct: "1:act"},
+		{name: "unverified_pass_downgraded", minIterations: 3, wantLastAct: "3:act"},
+		{name: "verified_pass_closes", minIterations: 3, taskState: verified, wantLastAct: "1:act"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			var ran []string
+			verdicts := []string{"PASS"}
+			rt := &runtime{
+				cfg:      config.Config{MinIterationsBeforeClose: tc.minIterations},
+				runInput: AgentInput{AcceptanceCriteria: criteria},
+				steps:    slices.Clone(DefaultWorkflowSteps),
+			}
+			rt.overrideRunStep = func(_ agent.InvocationContext, iteration int, roleName string) (*contracts.AgentResponse, error) {
+				ran = append(ran, fmt.Sprintf("%d:%s", iteration, roleName))
+				return workflowStepResponse(roleName, &verdicts), nil
+			}
+
+			loopAgent, err := rt.newLoopAgent(context.Background(), 5)
+			if err != nil {
+				t.Fatalf("newLoopAgent() error = %v", err)
+			}
+			initial := map[string]any{"iteration": 1}
+			if tc.taskState != nil {
+				initial["task_state"] = tc.taskState
+			}
+			if _, _, err := adkrunner.Run(context.Background(), adkrunner.RunInput{
+				Agent:        loopAgent,
+				InitialState: initial,
+			}); err != nil {
+				t.Fatalf("adkrunner.Run() error = %v", err)
+			}
+
+			if got := ran[len(ran)-1]; got != tc.wantLastAct {
+				t.Fatalf("last step = %s, want %s (ran %v)", got, tc.wantLastAct, ran)
+			}
+		})
+	}
+}
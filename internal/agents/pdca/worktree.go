@@ -0,0 +1,29 @@
+package pdca
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/metalagman/norma/internal/git"
+)
+
+// mountStepWorktree mounts a step worktree once limiter has a free slot; a limit
+// that is not positive mounts right away. The returned remove func removes the
+// worktree and frees the slot, even when removal fails.
+func mountStepWorktree(ctx context.Context, limiter *slotLimiter, limit int, repoRoot, workspaceDir, branchName, baseBranch string) (func() error, error) {
+	release := func() {}
+	if limit > 0 {
+		if err := limiter.acquire(ctx, limit); err != nil {
+			return nil, fmt.Errorf("wait for worktree slot: %w", err)
+		}
+		release = limiter.release
+	}
+	if _, err := git.MountWorktree(ctx, repoRoot, workspaceDir, branchName, baseBranch); err != nil {
+		release()
+		return nil, err
+	}
+	return func() error {
+		defer release()
+		return git.RemoveWorktree(ctx, repoRoot, workspaceDir)
+	}, nil
+}
@@ -0,0 +1,67 @@
+package pdca
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestMountStepWorktreeBlocksAtCap(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	repoRoot := t.TempDir()
+	initTestRepo(t, ctx, repoRoot)
+	runGit(t, ctx, repoRoot, "checkout", "-b", "master")
+	writeTestFile(t, filepath.Join(repoRoot, ".gitignore"), ".norma/\n")
+	runGit(t, ctx, repoRoot, "add", "-A")
+	runGit(t, ctx, repoRoot, "commit", "-m", "chore: initial")
+
+	workspace := func(runID string) string {
+		dir := filepath.Join(repoRoot, ".norma", "runs", runID, "steps", "001-plan")
+		if err := os.MkdirAll(dir, 0o700); err != nil {
+			t.Fatalf("mkdir step dir: %v", err)
+		}
+		return filepath.Join(dir, "workspace")
+	}
+
+	limiter := &slotLimiter{}
+	removeFirst, err := mountStepWorktree(ctx, limiter, 1, repoRoot, workspace("run-1"), "norma/task/norma-w1", "master")
+	if err != nil {
+		t.Fatalf("mountStepWorktree(first) error = %v", err)
+	}
+
+	type mountResult struct {
+		remove func() error
+		err    error
+	}
+	second := make(chan mountResult, 1)
+	go func() {
+		remove, err := mountStepWorktree(ctx, limiter, 1, repoRoot, workspace("run-2"), "norma/task/norma-w2", "master")
+		second <- mountResult{remove: remove, err: err}
+	}()
+
+	select {
+	case res := <-second:
+		t.Fatalf("second mount finished while the first worktree was mounted (err = %v)", res.err)
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	if err := removeFirst(); err != nil {
+		t.Fatalf("remove first worktree: %v", err)
+	}
+
+	select {
+	case res := <-second:
+		if res.err != nil {
+			t.Fatalf("mountStepWorktree(second) error = %v", res.err)
+		}
+		if err := res.remove(); err != nil {
+			t.Fatalf("remove second worktree: %v", err)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("second mount still blocked after the first worktree was removed")
+	}
+}
@@ -35,6 +35,9 @@ Planning Rules:
   - verify (concrete commands/checks to prove it works)
 - Keep scope pragmatic. Prefer 2-6 features and 1-6 tasks per feature.
 - Keep titles concise and action-oriented.
+- Before creating a feature or task, list the children of its parent (bd list --parent <id>) and reuse a child with the same title instead of creating a duplicate.
+- Duplicates are scoped to one parent: the same title under a different epic or feature is a distinct issue.
+- Create siblings in a deterministic order: features sorted by title, tasks within a feature in dependency order with ties sorted by title. Planning the same request again must yield the same titles in the same order, so existing issues are matched by title and reused.
 `
 
 func plannerInstruction() string {
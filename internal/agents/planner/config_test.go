@@ -22,6 +22,8 @@ func TestPlannerInstruction_ContainsNormaPlannerPolicy(t *testing.T) {
 		"You are Norma's planning agent.",
 		"Use the 'bd' CLI",
 		"Never claim a 'human' tool exists.",
+		"Duplicates are scoped to one parent",
+		"Create siblings in a deterministic order",
 	} {
 		if !strings.Contains(got, mustContain) {
 			t.Fatalf("plannerInstruction() missing %q: %q", mustContain, got)
@@ -0,0 +1,117 @@
+package planner
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/metalagman/norma/internal/task"
+)
+
+// Decomposition is an epic broken down into features and their tasks.
+type Decomposition struct {
+	EpicID   string
+	Features []FeatureSpec
+}
+
+// FeatureSpec describes a feature of a decomposition.
+type FeatureSpec struct {
+	Title       string
+	Description string
+	Tasks       []TaskSpec
+}
+
+// TaskSpec describes a task of a decomposition feature.
+type TaskSpec struct {
+	Title    string
+	Goal     string
+	Criteria []task.AcceptanceCriterion
+}
+
+// MaterializedFeature reports the issue backing a feature and its tasks.
+type MaterializedFeature struct {
+	ID      string
+	Reused  bool
+	TaskIDs []string
+}
+
+// DecompositionTracker creates the features and tasks of a decomposition.
+type DecompositionTracker interface {
+	Children(ctx context.Context, parentID string) ([]task.Task, error)
+	AddFeatureDetailed(ctx context.Context, epicID, title, description string) (string, error)
+	AddTaskDetailed(ctx context.Context, parentID, title, goal string, criteria []task.AcceptanceCriterion, runID *string) (string, error)
+}
+
+var _ DecompositionTracker = (*task.BeadsTracker)(nil)
+
+// epicLocks serialises MaterializeDecomposition per epic.
+var epicLocks sync.Map
+
+// MaterializeDecomposition creates the features and tasks of d under its epic.
+// A feature or task is a duplicate only if its parent already has a child with the
+// same title, so epics may share feature titles; duplicates are reused, not created.
+// Calls for the same epic are serialised and calls for different epics run in parallel.
+func MaterializeDecomposition(ctx context.Context, tracker DecompositionTracker, d Decomposition) ([]MaterializedFeature, error) {
+	epicID := strings.TrimSpace(d.EpicID)
+	if epicID == "" {
+		return nil, fmt.Errorf("epic id is required")
+	}
+	mu, _ := epicLocks.LoadOrStore(epicID, &sync.Mutex{})
+	mu.(*sync.Mutex).Lock()
+	defer mu.(*sync.Mutex).Unlock()
+
+	out := make([]MaterializedFeature, 0, len(d.Features))
+	features, err := childTitles(ctx, tracker, epicID)
+	if err != nil {
+		return nil, err
+	}
+	for _, spec := range d.Features {
+		feature := MaterializedFeature{}
+		feature.ID, feature.Reused = features[titleKey(spec.Title)]
+		if !feature.Reused {
+			feature.ID, err = tracker.AddFeatureDetailed(ctx, epicID, spec.Title, spec.Description)
+			if err != nil {
+				return nil, fmt.Errorf("create feature %q: %w", spec.Title, err)
+			}
+			features[titleKey(spec.Title)] = feature.ID
+		}
+
+		tasks, err := childTitles(ctx, tracker, feature.ID)
+		if err != nil {
+			return nil, err
+		}
+		for _, t := range spec.Tasks {
+			id, ok := tasks[titleKey(t.Title)]
+			if !ok {
+				id, err = tracker.AddTaskDetailed(ctx, feature.ID, t.Title, t.Goal, t.Criteria, nil)
+				if err != nil {
+					return nil, fmt.Errorf("create task %q: %w", t.Title, err)
+				}
+				tasks[titleKey(t.Title)] = id
+			}
+			feature.TaskIDs = append(feature.TaskIDs, id)
+		}
+		out = append(out, feature)
+	}
+	return out, nil
+}
+
+// childTitles maps the normalised titles of parentID's children to their ids.
+func childTitles(ctx context.Context, tracker DecompositionTracker, parentID string) (map[string]string, error) {
+	children, err := tracker.Children(ctx, parentID)
+	if err != nil {
+		return nil, fmt.Errorf("list children of %s: %w", parentID, err)
+	}
+	out := make(map[string]string, len(children))
+	for _, child := range children {
+		if _, ok := out[titleKey(child.Title)]; !ok {
+			out[titleKey(child.Title)] = child.ID
+		}
+	}
+	return out, nil
+}
+
+func titleKey(title string) string {
+	return strings.ToLower(strings.Join(strings.Fields(title), " "))
+}
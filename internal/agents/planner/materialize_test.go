@@ -0,0 +1,146 @@
+package planner
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/metalagman/norma/internal/task"
+)
+
+type memTracker struct {
+	mu     sync.Mutex
+	issues []task.Task
+}
+
+func (m *memTracker) Children(_ context.Context, parentID string) ([]task.Task, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var out []task.Task
+	for _, issue := range m.issues {
+		if issue.ParentID == parentID {
+			out = append(out, issue)
+		}
+	}
+	return out, nil
+}
+
+func (m *memTracker) AddFeatureDetailed(_ context.Context, epicID, title, _ string) (string, error) {
+	return m.add(epicID, "feature", title), nil
+}
+
+func (m *memTracker) AddTaskDetailed(_ context.Context, parentID, title, _ string, _ []task.AcceptanceCriterion, _ *string) (string, error) {
+	return m.add(parentID, "task", title), nil
+}
+
+func (m *memTracker) add(parentID, typ, title string) string {
+	// Widen the window between listing children and creating one.
+	time.Sleep(time.Millisecond)
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	id := fmt.Sprintf("norma-%d", len(m.issues)+1)
+	m.issues = append(m.issues, task.Task{ID: id, Type: typ, ParentID: parentID, Title: title})
+	return id
+}
+
+func (m *memTracker) count(parentID, title string) int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	n := 0
+	for _, issue := range m.issues {
+		if issue.ParentID == parentID && issue.Title == title {
+			n++
+		}
+	}
+	return n
+}
+
+func sharedTitlesDecomposition(epicID string) Decomposition {
+	return Decomposition{
+		EpicID: epicID,
+		Features: []FeatureSpec{
+			{Title: "API", Tasks: []TaskSpec{{Title: "Add endpoint"}, {Title: "Add tests"}}},
+			{Title: "Docs", Tasks: []TaskSpec{{Title: "Write guide"}}},
+		},
+	}
+}
+
+func TestMaterializeDecompositionScopesDedupToParent(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	tracker := &memTracker{}
+
+	first, err := MaterializeDecomposition(ctx, tracker, sharedTitlesDecomposition("epic-1"))
+	if err != nil {
+		t.Fatalf("MaterializeDecomposition(epic-1) error = %v", err)
+	}
+	second, err := MaterializeDecomposition(ctx, tracker, sharedTitlesDecomposition("epic-2"))
+	if err != nil {
+		t.Fatalf("MaterializeDecomposition(epic-2) error = %v", err)
+	}
+	for i := range first {
+		if second[i].Reused {
+			t.Fatalf("epic-2 feature %d reused %s from epic-1", i, second[i].ID)
+		}
+		if first[i].ID == second[i].ID {
+			t.Fatalf("feature %d shares id %s across epics", i, first[i].ID)
+		}
+	}
+	if got := tracker.count(second[0].ID, "Add endpoint"); got != 1 {
+		t.Fatalf("epic-2 API tasks titled Add endpoint = %d, want 1", got)
+	}
+
+	again, err := MaterializeDecomposition(ctx, tracker, sharedTitlesDecomposition("epic-1"))
+	if err != nil {
+		t.Fatalf("MaterializeDecomposition(epic-1) again error = %v", err)
+	}
+	for i := range first {
+		if !again[i].Reused || again[i].ID != first[i].ID {
+			t.Fatalf("feature %d = %+v, want reused %s", i, again[i], first[i].ID)
+		}
+		if fmt.Sprint(again[i].TaskIDs) != fmt.Sprint(first[i].TaskIDs) {
+			t.Fatalf("feature %d tasks = %v, want reused %v", i, again[i].TaskIDs, first[i].TaskIDs)
+		}
+	}
+}
+
+func TestMaterializeDecompositionConcurrentEpics(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	tracker := &memTracker{}
+	epics := []string{"epic-a", "epic-a", "epic-b", "epic-b", "epic-c"}
+
+	var wg sync.WaitGroup
+	errs := make([]error, len(epics))
+	for i, epicID := range epics {
+		wg.Go(func() {
+			_, errs[i] = MaterializeDecomposition(ctx, tracker, sharedTitlesDecomposition(epicID))
+		})
+	}
+	wg.Wait()
+
+	for i, err := range errs {
+		if err != nil {
+			t.Fatalf("MaterializeDecomposition(%s) error = %v", epics[i], err)
+		}
+	}
+	for _, epicID := range []string{"epic-a", "epic-b", "epic-c"} {
+		for _, title := range []string{"API", "Docs"} {
+			if got := tracker.count(epicID, title); got != 1 {
+				t.Fatalf("%s features titled %s = %d, want 1", epicID, title, got)
+			}
+		}
+	}
+}
+
+func TestMaterializeDecompositionRequiresEpic(t *testing.T) {
+	t.Parallel()
+
+	if _, err := MaterializeDecomposition(context.Background(), &memTracker{}, Decomposition{}); err == nil {
+		t.Fatal("MaterializeDecomposition() without epic error = nil, want error")
+	}
+}
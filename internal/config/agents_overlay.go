@@ -0,0 +1,66 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"maps"
+	"os"
+
+	"github.com/go-viper/mapstructure/v2"
+	"gopkg.in/yaml.v3"
+)
+
+// AgentsOverlayPath is the repo-local agents file, relative to the repository root,
+// whose entries replace same-named agents of the loaded config.
+const AgentsOverlayPath = ".norma/agents.yaml"
+
+// MergeAgentConfigs returns base with overlay layered on top. An overlay agent replaces
+// the base agent of the same name as a whole; agents present in only one map are kept.
+// Neither input is modified.
+func MergeAgentConfigs(base, overlay map[string]AgentConfig) map[string]AgentConfig {
+	merged := make(map[string]AgentConfig, len(base)+len(overlay))
+	maps.Copy(merged, base)
+	maps.Copy(merged, overlay)
+	return merged
+}
+
+// LoadAgentsOverlay reads agent configs keyed by agent name from the YAML file at path.
+// Environment variables are expanded as in the main config. A missing file yields no agents.
+func LoadAgentsOverlay(path string) (map[string]AgentConfig, error) {
+	raw, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read agents overlay: %w", err)
+	}
+
+	expanded, err := ExpandEnv(string(raw))
+	if err != nil {
+		return nil, fmt.Errorf("expand env vars in agents overlay: %w", err)
+	}
+	var settings map[string]any
+	if err := yaml.Unmarshal([]byte(expanded), &settings); err != nil {
+		return nil, fmt.Errorf("parse agents overlay yaml: %w", err)
+	}
+
+	var agents map[string]AgentConfig
+	decoder, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{
+		Result:      &agents,
+		TagName:     "mapstructure",
+		ErrorUnused: true,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("create agents overlay decoder: %w", err)
+	}
+	if err := decoder.Decode(settings); err != nil {
+		return nil, fmt.Errorf("decode agents overlay: %w", err)
+	}
+	for name, agentCfg := range agents {
+		if err := agentCfg.Validate(); err != nil {
+			return nil, fmt.Errorf("agents overlay %q: %w", name, err)
+		}
+	}
+	return agents, nil
+}
@@ -0,0 +1,79 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestMergeAgentConfigs(t *testing.T) {
+	t.Parallel()
+
+	base := map[string]AgentConfig{
+		"codex":  {Type: AgentTypeCodexACP, Model: "gpt-5-codex"},
+		"gemini": {Type: AgentTypeGeminiACP},
+	}
+	overlay := map[string]AgentConfig{
+		"codex":   {Type: AgentTypeCodexACP, Model: "gpt-5-mini", MaxAttempts: 2},
+		"copilot": {Type: AgentTypeCopilotACP},
+	}
+
+	merged := MergeAgentConfigs(base, overlay)
+
+	if len(merged) != 3 {
+		t.Fatalf("len(merged) = %d, want 3: %v", len(merged), merged)
+	}
+	if got := merged["codex"]; got.Model != "gpt-5-mini" || got.MaxAttempts != 2 {
+		t.Fatalf("merged codex = %+v, want the overlay entry", got)
+	}
+	if got := merged["gemini"]; got.Type != AgentTypeGeminiACP {
+		t.Fatalf("merged gemini = %+v, want the base entry kept", got)
+	}
+	if got := merged["copilot"]; got.Type != AgentTypeCopilotACP {
+		t.Fatalf("merged copilot = %+v, want the overlay-only entry added", got)
+	}
+	if base["codex"].Model != "gpt-5-codex" {
+		t.Fatalf("base codex model = %q, want base left unmodified", base["codex"].Model)
+	}
+	if got := MergeAgentConfigs(base, nil); len(got) != 2 {
+		t.Fatalf("MergeAgentConfigs(base, nil) = %v, want base agents", got)
+	}
+}
+
+func TestLoadAgentsOverlay(t *testing.T) {
+	t.Setenv("NORMA_TEST_OVERLAY_MODEL", "gpt-5-mini")
+	dir := t.TempDir()
+
+	agents, err := LoadAgentsOverlay(filepath.Join(dir, "missing.yaml"))
+	if err != nil || agents != nil {
+		t.Fatalf("LoadAgentsOverlay(missing) = %v, %v; want nil, nil", agents, err)
+	}
+
+	path := filepath.Join(dir, "agents.yaml")
+	writeOverlay(t, path, "codex:\n  type: codex_acp\n  model: ${NORMA_TEST_OVERLAY_MODEL}\n  max_attempts: 2\n")
+	agents, err = LoadAgentsOverlay(path)
+	if err != nil {
+		t.Fatalf("LoadAgentsOverlay() error = %v", err)
+	}
+	if got := agents["codex"]; got.Type != AgentTypeCodexACP || got.Model != "gpt-5-mini" || got.MaxAttempts != 2 {
+		t.Fatalf("LoadAgentsOverlay() codex = %+v", got)
+	}
+
+	writeOverlay(t, path, "codex:\n  type: codex_acp\n  modle: typo\n")
+	if _, err := LoadAgentsOverlay(path); err == nil || !strings.Contains(err.Error(), "modle") {
+		t.Fatalf("LoadAgentsOverlay(unknown key) error = %v, want decode error naming the key", err)
+	}
+
+	writeOverlay(t, path, "broken:\n  model: gpt-5\n")
+	if _, err := LoadAgentsOverlay(path); err == nil || !strings.Contains(err.Error(), `"broken"`) {
+		t.Fatalf("LoadAgentsOverlay(invalid agent) error = %v, want validation error naming the agent", err)
+	}
+}
+
+func writeOverlay(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("write overlay: %v", err)
+	}
+}
@@ -10,12 +10,48 @@ import (
 
 // Config is the root configuration.
 type Config struct {
-	Agents    map[string]agentconfig.Config `json:"agents,omitempty"   mapstructure:"agents"`
-	Profiles  map[string]ProfileConfig      `json:"profiles,omitempty" mapstructure:"profiles"`
-	Profile   string                        `json:"profile,omitempty"  mapstructure:"profile"`
-	RoleIDs   map[string]string             `json:"-"                  mapstructure:"-"`
-	Budgets   Budgets                       `json:"budgets"            mapstructure:"budgets"`
-	Retention RetentionPolicy               `json:"retention"          mapstructure:"retention"`
+	Agents                    map[string]agentconfig.Config `json:"agents,omitempty"                      mapstructure:"agents"`
+	Profiles                  map[string]ProfileConfig      `json:"profiles,omitempty"                    mapstructure:"profiles"`
+	Profile                   string                        `json:"profile,omitempty"                     mapstructure:"profile"`
+	RoleIDs                   map[string]string             `json:"-"                                     mapstructure:"-"`
+	Budgets                   Budgets                       `json:"budgets"                               mapstructure:"budgets"`
+	Retention                 RetentionPolicy               `json:"retention"                             mapstructure:"retention"`
+	VerifyHints               VerifyHintsPolicy             `json:"verify_hints,omitempty"                mapstructure:"verify_hints"`
+	ApplyOnPartial            PartialApplyPolicy            `json:"apply_on_partial,omitempty"            mapstructure:"apply_on_partial"`
+	CheckParallelism          int                           `json:"check_parallelism,omitempty"           mapstructure:"check_parallelism"`
+	VerifyChecks              bool                          `json:"verify_checks,omitempty"               mapstructure:"verify_checks"`
+	Git                       GitConfig                     `json:"git,omitempty"                         mapstructure:"git"`
+	PlanValidation            PlanValidationPolicy          `json:"plan_validation,omitempty"             mapstructure:"plan_validation"`
+	RequireAcceptanceCriteria bool                          `json:"require_acceptance_criteria,omitempty" mapstructure:"require_acceptance_criteria"`
+	AgentShutdownGrace        int                           `json:"agent_shutdown_grace,omitempty"        mapstructure:"agent_shutdown_grace"`
+	StepHeartbeatInterval     int                           `json:"step_heartbeat_interval,omitempty"     mapstructure:"step_heartbeat_interval"`
+	Beads                     BeadsConfig                   `json:"beads,omitempty"                       mapstructure:"beads"`
+	SystemPromptPreamble      string                        `json:"system_prompt_preamble,omitempty"      mapstructure:"system_prompt_preamble"`
+	MaxRunsPerTask            int                           `json:"max_runs_per_task,omitempty"           mapstructure:"max_runs_per_task"`
+	CheckOnPartialDo          bool                          `json:"check_on_partial_do,omitempty"         mapstructure:"check_on_partial_do"`
+	RecordEnv                 []string                      `json:"record_env,omitempty"                  mapstructure:"record_env"`
+	AutoCloseParents          bool                          `json:"auto_close_parents,omitempty"          mapstructure:"auto_close_parents"`
+	Workflow                  WorkflowConfig                `json:"workflow,omitempty"                    mapstructure:"workflow"`
+	MaxConcurrentAgents       int                           `json:"max_concurrent_agents,omitempty"       mapstructure:"max_concurrent_agents"`
+	MaxWorktrees              int                           `json:"max_worktrees,omitempty"               mapstructure:"max_worktrees"`
+	SetupCommand              string                        `json:"setup_command,omitempty"               mapstructure:"setup_command"`
+	DoPostCommand             string                        `json:"do_post_command,omitempty"             mapstructure:"do_post_command"`
+	DoPostCommandFailure      string                        `json:"do_post_command_failure,omitempty"     mapstructure:"do_post_command_failure"`
+	Observers                 []string                      `json:"observers,omitempty"                   mapstructure:"observers"`
+	Safety                    SafetyConfig                  `json:"safety,omitempty"                      mapstructure:"safety"`
+	Changelog                 ChangelogConfig               `json:"changelog,omitempty"                   mapstructure:"changelog"`
+	RetryInvalidResponse      bool                          `json:"retry_invalid_response,omitempty"      mapstructure:"retry_invalid_response"`
+	Logging                   LoggingConfig                 `json:"logging,omitempty"                     mapstructure:"logging"`
+	MinIterationsBeforeClose  int                           `json:"min_iterations_before_close,omitempty" mapstructure:"min_iterations_before_close"`
+	AllowWebhookChecks        bool                          `json:"allow_webhook_checks,omitempty"        mapstructure:"allow_webhook_checks"`
+	Explain                   bool                          `json:"explain,omitempty"                     mapstructure:"explain"`
+	Loop                      LoopConfig                    `json:"loop,omitempty"                        mapstructure:"loop"`
+	RequireApprovalToApply    bool                          `json:"require_approval_to_apply,omitempty"   mapstructure:"require_approval_to_apply"`
+	ApprovalTimeout           int                           `json:"approval_timeout,omitempty"            mapstructure:"approval_timeout"`
+	RequireFullACCoverage     bool                          `json:"require_full_ac_coverage,omitempty"    mapstructure:"require_full_ac_coverage"`
+	SanitizeAgentOutput       bool                          `json:"sanitize_agent_output,omitempty"       mapstructure:"sanitize_agent_output"`
+	ACChangePolicy            string                        `json:"ac_change_policy,omitempty"            mapstructure:"ac_change_policy"`
+	CheckConsensus            CheckConsensusConfig          `json:"check_consensus,omitempty"             mapstructure:"check_consensus"`
 }
 
 // AgentConfig describes how to run an agent.
@@ -37,7 +73,16 @@ type PDCAAgentRefs struct {
 
 // Budgets defines run limits.
 type Budgets struct {
-	MaxIterations int `json:"max_iterations" mapstructure:"max_iterations"`
+	MaxIterations int `json:"max_iterations"         mapstructure:"max_iterations"`
+	MaxDoSteps    int `json:"max_do_steps,omitempty" mapstructure:"max_do_steps"`
+	// MaxChangedFiles caps the files a task branch may change before it is applied. Zero is unlimited.
+	MaxChangedFiles int `json:"max_changed_files,omitempty" mapstructure:"max_changed_files"`
+	// MaxPatchKB caps the task branch diff size in KiB before it is applied. Zero is unlimited.
+	MaxPatchKB int `json:"max_patch_kb,omitempty" mapstructure:"max_patch_kb"`
+	// PatchOverflow is stop (default) or warn when a task branch exceeds a patch budget.
+	PatchOverflow string `json:"patch_overflow,omitempty" mapstructure:"patch_overflow"`
+	// JournalMaxAge drops journal entries older than this many seconds from the request context. Zero keeps every entry.
+	JournalMaxAge int `json:"journal_max_age,omitempty" mapstructure:"journal_max_age"`
 }
 
 // RetentionPolicy defines how many old runs to keep.
@@ -46,6 +91,126 @@ type RetentionPolicy struct {
 	KeepDays int `json:"keep_days,omitempty" mapstructure:"keep_days"`
 }
 
+// VerifyHintsPolicy controls seeding of command-like verify hints into effective acceptance checks.
+type VerifyHintsPolicy struct {
+	SeedChecks      bool     `json:"seed_checks,omitempty"      mapstructure:"seed_checks"`
+	CommandPrefixes []string `json:"command_prefixes,omitempty" mapstructure:"command_prefixes"`
+}
+
+// PartialApplyPolicy controls applying workspace changes when Check returns PARTIAL.
+type PartialApplyPolicy struct {
+	Enabled           bool `json:"enabled,omitempty"             mapstructure:"enabled"`
+	MinPassedRequired int  `json:"min_passed_required,omitempty" mapstructure:"min_passed_required"`
+}
+
+// GitConfig controls how task branches are applied to the base branch.
+type GitConfig struct {
+	// MergeStrategy is one of squash (default), merge, or ff-only.
+	MergeStrategy string `json:"merge_strategy,omitempty" mapstructure:"merge_strategy"`
+	// AllowedApplyBranches limits the base branches task changes may be applied to.
+	// Empty allows any branch.
+	AllowedApplyBranches []string `json:"allowed_apply_branches,omitempty" mapstructure:"allowed_apply_branches"`
+	// OnBaseMoved is proceed (default), abort, or rebase when the base branch
+	// received commits while a run was in progress.
+	OnBaseMoved string `json:"on_base_moved,omitempty" mapstructure:"on_base_moved"`
+	// PerRunBranches gives every run its own task branch, norma/task/<id>/<run-id>,
+	// so the same task can run more than once at a time.
+	PerRunBranches bool `json:"per_run_branches,omitempty" mapstructure:"per_run_branches"`
+	// CommitTrailers appends Norma-Run-Id, Norma-Task-Id, and Norma-Step-Index trailers to apply commits.
+	CommitTrailers bool `json:"commit_trailers,omitempty" mapstructure:"commit_trailers"`
+	// ExtraTrailers are static trailers appended to apply commits, keyed by trailer name.
+	ExtraTrailers map[string]string `json:"extra_trailers,omitempty" mapstructure:"extra_trailers"`
+	// RunPreCommit checks staged Do step changes before they are committed, failing the Do step on a nonzero exit.
+	RunPreCommit bool `json:"run_pre_commit,omitempty" mapstructure:"run_pre_commit"`
+	// PreCommitCommand is the shell command run for RunPreCommit. Empty runs the repository's pre-commit hook.
+	PreCommitCommand string `json:"pre_commit_command,omitempty" mapstructure:"pre_commit_command"`
+	// StashPolicy handles local changes in the working tree before an apply:
+	// auto (default) stashes them, refuse fails the apply, ignore-untracked stashes tracked changes only.
+	StashPolicy string `json:"stash_policy,omitempty" mapstructure:"stash_policy"`
+	// PushOnPass names the remote the task branch of a passed run is pushed to before it is applied.
+	// Empty disables pushing.
+	PushOnPass string `json:"push_on_pass,omitempty" mapstructure:"push_on_pass"`
+	// PushFailure is warn (default) to log a failed push and apply anyway, or error to fail the run.
+	PushFailure string `json:"push_failure,omitempty" mapstructure:"push_failure"`
+}
+
+// ChangelogConfig controls the changelog fragment written when a run's changes are applied.
+type ChangelogConfig struct {
+	// Path is the changelog file, relative to the repository root. Empty disables fragments.
+	Path string `json:"path,omitempty" mapstructure:"path"`
+	// Template is a text/template rendered with Goal, TaskID, RunID, and Criteria (the acceptance criteria met).
+	// Empty uses the built-in template.
+	Template string `json:"template,omitempty" mapstructure:"template"`
+}
+
+// WorkflowConfig controls the role sequence of a PDCA iteration.
+type WorkflowConfig struct {
+	// Steps lists the roles run in order each iteration. Empty runs plan, do, check, act.
+	Steps []string `json:"steps,omitempty" mapstructure:"steps"`
+}
+
+// LoggingConfig controls how agent output is surfaced beyond the step log files.
+type LoggingConfig struct {
+	// MirrorStdout copies agent stdout to the console even without debug logging.
+	MirrorStdout bool `json:"mirror_stdout,omitempty" mapstructure:"mirror_stdout"`
+	// MirrorStderr copies agent stderr to the console even without debug logging.
+	MirrorStderr bool `json:"mirror_stderr,omitempty" mapstructure:"mirror_stderr"`
+}
+
+// LoopConfig controls task selection in "norma loop".
+type LoopConfig struct {
+	// QuarantineAfter skips and labels a task once it has this many failed runs. Zero disables quarantine.
+	QuarantineAfter int `json:"quarantine_after,omitempty" mapstructure:"quarantine_after"`
+	// TaskAllowlist restricts selection to these task IDs. Empty allows every task.
+	TaskAllowlist []string `json:"task_allowlist,omitempty" mapstructure:"task_allowlist"`
+	// TrackerRetries is how many consecutive transient tracker failures the selector backs off
+	// and retries before failing the loop. Zero fails on the first error.
+	TrackerRetries int `json:"tracker_retries,omitempty" mapstructure:"tracker_retries"`
+	// Prefetch selects the next task while the current iteration runs instead of after it finishes.
+	Prefetch bool `json:"prefetch,omitempty" mapstructure:"prefetch"`
+}
+
+// CheckConsensusConfig makes a PASS verdict of the Check step subject to a vote of further Check agents.
+type CheckConsensusConfig struct {
+	// Agents run the Check contract after a Check step that returned PASS. Empty disables consensus.
+	Agents []string `json:"agents,omitempty" mapstructure:"agents"`
+	// Policy is all (default), requiring every vote to be PASS, or majority.
+	Policy string `json:"policy,omitempty" mapstructure:"policy"`
+}
+
+// SafetyConfig controls heuristic scans of agent output for signs the agent was derailed.
+type SafetyConfig struct {
+	// SuspiciousPatterns are regular expressions matched against each line of agent stdout.
+	SuspiciousPatterns []string `json:"suspicious_patterns,omitempty" mapstructure:"suspicious_patterns"`
+	// StopOnMatch stops the run when a step's output matches a suspicious pattern.
+	StopOnMatch bool `json:"stop_on_match,omitempty" mapstructure:"stop_on_match"`
+	// BlockSecrets scans Do changes for secrets before they are committed and reverts them on a hit.
+	BlockSecrets bool `json:"block_secrets,omitempty" mapstructure:"block_secrets"`
+	// SecretPatterns are regular expressions matched against added lines in addition to the built-in secret patterns.
+	SecretPatterns []string `json:"secret_patterns,omitempty" mapstructure:"secret_patterns"`
+	// Profile is interactive (default), ci, or locked. It selects the default flags of provider
+	// aliases and whether ACP permission requests are auto-approved.
+	Profile string `json:"profile,omitempty" mapstructure:"profile"`
+}
+
+// PlanValidationPolicy controls post-Plan validation.
+type PlanValidationPolicy struct {
+	// DanglingACRefs is warn (default) or error for Do steps targeting unknown AC ids.
+	DanglingACRefs string `json:"dangling_ac_refs,omitempty"  mapstructure:"dangling_ac_refs"`
+	// DoStepsOverflow is truncate (default) or stop for plans exceeding budgets.max_do_steps.
+	DoStepsOverflow string `json:"do_steps_overflow,omitempty" mapstructure:"do_steps_overflow"`
+	// EmptyPlan is stop (default) or warn for ok plans without Do steps or effective acceptance criteria.
+	EmptyPlan string `json:"empty_plan,omitempty"        mapstructure:"empty_plan"`
+}
+
+// BeadsConfig customizes the beads task tracker.
+type BeadsConfig struct {
+	// StatusMap overrides the beads status used for norma statuses (todo, doing, done, failed, stopped, planning, checking, acting).
+	StatusMap map[string]string `json:"status_map,omitempty"      mapstructure:"status_map"`
+	// CustomStatuses lists custom beads statuses that StatusMap may target.
+	CustomStatuses []string `json:"custom_statuses,omitempty" mapstructure:"custom_statuses"`
+}
+
 const defaultProfile = "default"
 
 // Supported agent types.
@@ -116,6 +281,28 @@ func (c Config) ResolveAgentIDs(profile string) (string, map[string]string, erro
 	return selected, resolved, nil
 }
 
+// EffectiveModels returns the starting model for each role, keyed by role name.
+// Each role resolves its model independently from the agent its profile references,
+// so Plan and Check can run a cheaper or stronger model than Do. Escalation models
+// apply from the first iteration. An empty value means the agent's default model.
+// Roles come from RoleIDs, or from the configured profile when RoleIDs is unset.
+func (c Config) EffectiveModels() map[string]string {
+	roleIDs := c.RoleIDs
+	if len(roleIDs) == 0 {
+		_, resolved, err := c.ResolveAgentIDs(c.Profile)
+		if err != nil {
+			return map[string]string{}
+		}
+		roleIDs = resolved
+	}
+
+	models := make(map[string]string, len(roleIDs))
+	for role, agentID := range roleIDs {
+		models[role] = c.Agents[agentID].ModelForIteration(1)
+	}
+	return models
+}
+
 // ResolveProfile returns the profile configuration for the given profile name.
 func (c Config) ResolveProfile(profile string) (string, ProfileConfig, error) {
 	return c.resolveProfile(profile)
@@ -126,3 +126,61 @@ func TestIsPlannerSupportedType(t *testing.T) {
 		})
 	}
 }
+
+func TestEffectiveModelsResolvesEachRoleIndependently(t *testing.T) {
+	t.Parallel()
+
+	cfg := Config{
+		Agents: map[string]AgentConfig{
+			"planner_strong": {Type: "codex_acp", Model: "gpt-5"},
+			"doer_cheap":     {Type: "codex_acp", Model: "gpt-5-mini", EscalationModels: []string{"gpt-5-mini", "gpt-5"}},
+			"checker":        {Type: "gemini_acp", Model: "gemini-2.5-pro"},
+			"default_model":  {Type: "gemini_acp"},
+		},
+		Profiles: map[string]ProfileConfig{
+			"default": {
+				PDCA: PDCAAgentRefs{
+					Plan:  "planner_strong",
+					Do:    "doer_cheap",
+					Check: "checker",
+					Act:   "default_model",
+				},
+			},
+		},
+	}
+
+	want := map[string]string{
+		"plan":  "gpt-5",
+		"do":    "gpt-5-mini",
+		"check": "gemini-2.5-pro",
+		"act":   "",
+	}
+
+	got := cfg.EffectiveModels()
+	if len(got) != len(want) {
+		t.Fatalf("EffectiveModels() = %v, want %v", got, want)
+	}
+	for role, model := range want {
+		if got[role] != model {
+			t.Fatalf("EffectiveModels()[%q] = %q, want %q", role, got[role], model)
+		}
+	}
+
+	_, roleIDs, err := cfg.ResolveAgentIDs("")
+	if err != nil {
+		t.Fatalf("ResolveAgentIDs returned error: %v", err)
+	}
+	cfg.RoleIDs = roleIDs
+	cfg.RoleIDs["check"] = "planner_strong"
+	if got := cfg.EffectiveModels()["check"]; got != "gpt-5" {
+		t.Fatalf("EffectiveModels()[check] with RoleIDs override = %q, want gpt-5", got)
+	}
+}
+
+func TestEffectiveModelsUnresolvableProfile(t *testing.T) {
+	t.Parallel()
+
+	if got := (Config{}).EffectiveModels(); len(got) != 0 {
+		t.Fatalf("EffectiveModels() = %v, want empty", got)
+	}
+}
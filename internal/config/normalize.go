@@ -6,9 +6,10 @@ import (
 	"github.com/metalagman/norma/internal/adk/agentconfig"
 )
 
-// NormalizeAgentAliases canonicalizes alias agent types in config to generic runtimes.
+// NormalizeAgentAliases canonicalizes alias agent types in config to generic runtimes
+// using the default flags of the configured safety profile.
 func NormalizeAgentAliases(cfg Config, executablePath string) (Config, error) {
-	normalizedAgents, err := agentconfig.NormalizeACPConfigs(cfg.Agents, executablePath)
+	normalizedAgents, err := agentconfig.NormalizeACPConfigs(cfg.Agents, executablePath, cfg.Safety.Profile)
 	if err != nil {
 		return Config{}, fmt.Errorf("normalize agent aliases: %w", err)
 	}
@@ -0,0 +1,14 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE runs ADD COLUMN failure_kind TEXT NULL;
+
+INSERT OR IGNORE INTO schema_migrations(version, applied_at)
+VALUES(3, datetime('now'));
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE runs DROP COLUMN failure_kind;
+
+DELETE FROM schema_migrations WHERE version = 3;
+-- +goose StatementEnd
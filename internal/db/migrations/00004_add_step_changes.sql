@@ -0,0 +1,22 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS step_changes (
+    run_id TEXT NOT NULL,
+    step_index INTEGER NOT NULL,
+    path TEXT NOT NULL,
+    status TEXT NOT NULL,
+    old_path TEXT NULL,
+    PRIMARY KEY (run_id, step_index, path),
+    FOREIGN KEY (run_id, step_index) REFERENCES steps(run_id, step_index) ON DELETE CASCADE
+);
+
+INSERT OR IGNORE INTO schema_migrations(version, applied_at)
+VALUES(4, datetime('now'));
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS step_changes;
+
+DELETE FROM schema_migrations WHERE version = 4;
+-- +goose StatementEnd
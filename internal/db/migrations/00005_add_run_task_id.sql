@@ -0,0 +1,18 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE runs ADD COLUMN task_id TEXT NULL;
+
+CREATE INDEX IF NOT EXISTS idx_runs_task_id ON runs(task_id);
+
+INSERT OR IGNORE INTO schema_migrations(version, applied_at)
+VALUES(5, datetime('now'));
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_runs_task_id;
+
+ALTER TABLE runs DROP COLUMN task_id;
+
+DELETE FROM schema_migrations WHERE version = 5;
+-- +goose StatementEnd
@@ -0,0 +1,19 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS loop_state (
+    id INTEGER PRIMARY KEY CHECK (id = 1),
+    iteration INTEGER NOT NULL,
+    selected_task_id TEXT NULL,
+    updated_at TEXT NOT NULL
+);
+
+INSERT OR IGNORE INTO schema_migrations(version, applied_at)
+VALUES(6, datetime('now'));
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS loop_state;
+
+DELETE FROM schema_migrations WHERE version = 6;
+-- +goose StatementEnd
@@ -0,0 +1,20 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS iteration_heads (
+    run_id TEXT NOT NULL REFERENCES runs(run_id) ON DELETE CASCADE,
+    iteration INTEGER NOT NULL,
+    head TEXT NOT NULL,
+    recorded_at TEXT NOT NULL,
+    PRIMARY KEY (run_id, iteration)
+);
+
+INSERT OR IGNORE INTO schema_migrations(version, applied_at)
+VALUES(7, datetime('now'));
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS iteration_heads;
+
+DELETE FROM schema_migrations WHERE version = 7;
+-- +goose StatementEnd
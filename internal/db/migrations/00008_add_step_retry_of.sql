@@ -0,0 +1,14 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE steps ADD COLUMN retry_of INTEGER NULL;
+
+INSERT OR IGNORE INTO schema_migrations(version, applied_at)
+VALUES(8, datetime('now'));
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE steps DROP COLUMN retry_of;
+
+DELETE FROM schema_migrations WHERE version = 8;
+-- +goose StatementEnd
@@ -0,0 +1,14 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE runs ADD COLUMN correlation_id TEXT NULL;
+
+INSERT OR IGNORE INTO schema_migrations(version, applied_at)
+VALUES(9, datetime('now'));
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE runs DROP COLUMN correlation_id;
+
+DELETE FROM schema_migrations WHERE version = 9;
+-- +goose StatementEnd
@@ -0,0 +1,14 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE runs ADD COLUMN ac_snapshot TEXT NULL;
+
+INSERT OR IGNORE INTO schema_migrations(version, applied_at)
+VALUES(10, datetime('now'));
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE runs DROP COLUMN ac_snapshot;
+
+DELETE FROM schema_migrations WHERE version = 10;
+-- +goose StatementEnd
@@ -0,0 +1,174 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+)
+
+// OutputDiff compares the agent outputs of two runs, typically of the same task
+// under different models.
+type OutputDiff struct {
+	RunA        string
+	RunB        string
+	VerdictA    string
+	VerdictB    string
+	IterationsA int
+	IterationsB int
+	// CheckVerdicts pairs the Check verdicts of both runs by iteration.
+	CheckVerdicts []CheckVerdictDelta
+	// ACDeltas lists acceptance criteria whose last Check result differs between the runs.
+	ACDeltas []ACResultDelta
+}
+
+// VerdictChanged reports whether the runs ended with different verdicts.
+func (d OutputDiff) VerdictChanged() bool {
+	return d.VerdictA != d.VerdictB
+}
+
+// CheckVerdictDelta is the Check verdict of each run in one iteration.
+// An empty verdict means the run had no Check output in that iteration.
+type CheckVerdictDelta struct {
+	Iteration int
+	VerdictA  string
+	VerdictB  string
+}
+
+// ACResultDelta is the result of one acceptance criterion in each run.
+// An empty result means the run's last Check did not report the criterion.
+type ACResultDelta struct {
+	ACID    string
+	ResultA string
+	ResultB string
+}
+
+// checkOutputFile is the subset of a step output.json read by DiffRunOutputs.
+type checkOutputFile struct {
+	CheckOutput *struct {
+		Verdict *struct {
+			Status string `json:"status"`
+		} `json:"verdict"`
+		AcceptanceResults []struct {
+			AcID   string `json:"ac_id"`
+			Result string `json:"result"`
+		} `json:"acceptance_results"`
+	} `json:"check_output"`
+}
+
+// runCheckOutputs is what DiffRunOutputs loads of one run.
+type runCheckOutputs struct {
+	verdict    string
+	iterations int
+	// verdicts maps iteration to the verdict of its last Check step.
+	verdicts map[int]string
+	// results holds the acceptance results of the last Check step of the run.
+	results map[string]string
+}
+
+// DiffRunOutputs loads the per-step outputs of runA and runB and reports how their
+// verdicts, iteration counts and acceptance criteria results differ.
+func DiffRunOutputs(ctx context.Context, store *Store, runA, runB string) (OutputDiff, error) {
+	a, err := store.loadCheckOutputs(ctx, runA)
+	if err != nil {
+		return OutputDiff{}, err
+	}
+	b, err := store.loadCheckOutputs(ctx, runB)
+	if err != nil {
+		return OutputDiff{}, err
+	}
+
+	diff := OutputDiff{
+		RunA:        runA,
+		RunB:        runB,
+		VerdictA:    a.verdict,
+		VerdictB:    b.verdict,
+		IterationsA: a.iterations,
+		IterationsB: b.iterations,
+	}
+	for _, it := range sortedKeys(a.verdicts, b.verdicts) {
+		diff.CheckVerdicts = append(diff.CheckVerdicts, CheckVerdictDelta{Iteration: it, VerdictA: a.verdicts[it], VerdictB: b.verdicts[it]})
+	}
+	for _, id := range sortedKeys(a.results, b.results) {
+		if a.results[id] != b.results[id] {
+			diff.ACDeltas = append(diff.ACDeltas, ACResultDelta{ACID: id, ResultA: a.results[id], ResultB: b.results[id]})
+		}
+	}
+	return diff, nil
+}
+
+func (s *Store) loadCheckOutputs(ctx context.Context, runID string) (runCheckOutputs, error) {
+	out := runCheckOutputs{verdicts: map[int]string{}, results: map[string]string{}}
+	var verdict sql.NullString
+	row := s.db.QueryRowContext(ctx, `SELECT iteration, verdict FROM runs WHERE run_id=?`, runID)
+	if err := row.Scan(&out.iterations, &verdict); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return out, fmt.Errorf("run %s not found", runID)
+		}
+		return out, fmt.Errorf("read run %s: %w", runID, err)
+	}
+	out.verdict = verdict.String
+
+	rows, err := s.db.QueryContext(ctx, `SELECT step_index, iteration, step_dir FROM steps
+		WHERE run_id=? AND role='check' ORDER BY step_index`, runID)
+	if err != nil {
+		return out, fmt.Errorf("query check steps of run %s: %w", runID, err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	for rows.Next() {
+		var stepIndex, iteration int
+		var stepDir string
+		if err := rows.Scan(&stepIndex, &iteration, &stepDir); err != nil {
+			return out, fmt.Errorf("scan check step: %w", err)
+		}
+		if stepDir == "" {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(stepDir, "output.json"))
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return out, fmt.Errorf("read output of step %d of run %s: %w", stepIndex, runID, err)
+		}
+		var file checkOutputFile
+		if err := json.Unmarshal(data, &file); err != nil {
+			return out, fmt.Errorf("parse output of step %d of run %s: %w", stepIndex, runID, err)
+		}
+		if file.CheckOutput == nil {
+			continue
+		}
+		if file.CheckOutput.Verdict != nil {
+			out.verdicts[iteration] = file.CheckOutput.Verdict.Status
+		}
+		clear(out.results)
+		for _, res := range file.CheckOutput.AcceptanceResults {
+			out.results[res.AcID] = res.Result
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return out, fmt.Errorf("read check steps of run %s: %w", runID, err)
+	}
+	return out, nil
+}
+
+// sortedKeys returns the union of the keys of a and b in ascending order.
+func sortedKeys[K int | string](a, b map[K]string) []K {
+	seen := make(map[K]bool, len(a)+len(b))
+	var keys []K
+	for _, m := range []map[K]string{a, b} {
+		for k := range m {
+			if !seen[k] {
+				seen[k] = true
+				keys = append(keys, k)
+			}
+		}
+	}
+	slices.Sort(keys)
+	return keys
+}
@@ -0,0 +1,82 @@
+package db
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestDiffRunOutputs(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	dir := t.TempDir()
+	sqlDB, err := Open(ctx, filepath.Join(dir, "norma.db"))
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	t.Cleanup(func() { _ = sqlDB.Close() })
+	store := NewStore(sqlDB)
+
+	seedRun := func(runID, verdict string, checks []string) {
+		t.Helper()
+		if err := store.CreateRun(ctx, runID, "norma-a1", "goal", filepath.Join(dir, runID), 1); err != nil {
+			t.Fatalf("CreateRun(%s) error = %v", runID, err)
+		}
+		for i, output := range checks {
+			stepDir := filepath.Join(dir, runID, "steps", "check", string(rune('a'+i)))
+			if err := os.MkdirAll(stepDir, 0o700); err != nil {
+				t.Fatalf("create step dir: %v", err)
+			}
+			if err := os.WriteFile(filepath.Join(stepDir, "output.json"), []byte(output), 0o600); err != nil {
+				t.Fatalf("write output.json: %v", err)
+			}
+			step := StepRecord{RunID: runID, StepIndex: i + 1, Role: "check", Iteration: i + 1, Status: "ok", StepDir: stepDir, StartedAt: "2026-01-01T10:00:00Z", EndedAt: "2026-01-01T10:00:01Z"}
+			if err := store.CommitStep(ctx, step, nil, Update{CurrentStepIndex: i + 1, Iteration: i + 1, Status: "running"}); err != nil {
+				t.Fatalf("CommitStep() error = %v", err)
+			}
+		}
+		v := verdict
+		if err := store.UpdateRun(ctx, runID, Update{CurrentStepIndex: len(checks), Iteration: len(checks), Status: "done", Verdict: &v}, nil); err != nil {
+			t.Fatalf("UpdateRun() error = %v", err)
+		}
+	}
+	seedRun("run-a", "FAIL", []string{
+		`{"status":"ok","check_output":{"verdict":{"status":"FAIL"},"acceptance_results":[{"ac_id":"AC1","result":"PASS"},{"ac_id":"AC2","result":"FAIL"}]}}`,
+	})
+	seedRun("run-b", "PASS", []string{
+		`{"status":"ok","check_output":{"verdict":{"status":"FAIL"},"acceptance_results":[{"ac_id":"AC1","result":"FAIL"},{"ac_id":"AC2","result":"FAIL"}]}}`,
+		`{"status":"ok","check_output":{"verdict":{"status":"PASS"},"acceptance_results":[{"ac_id":"AC1","result":"PASS"},{"ac_id":"AC2","result":"PASS"},{"ac_id":"AC3","result":"PASS"}]}}`,
+	})
+
+	diff, err := DiffRunOutputs(ctx, store, "run-a", "run-b")
+	if err != nil {
+		t.Fatalf("DiffRunOutputs() error = %v", err)
+	}
+	if !diff.VerdictChanged() || diff.VerdictA != "FAIL" || diff.VerdictB != "PASS" {
+		t.Fatalf("verdicts = (%q, %q), want (FAIL, PASS)", diff.VerdictA, diff.VerdictB)
+	}
+	if diff.IterationsA != 1 || diff.IterationsB != 2 {
+		t.Fatalf("iterations = (%d, %d), want (1, 2)", diff.IterationsA, diff.IterationsB)
+	}
+	wantChecks := []CheckVerdictDelta{
+		{Iteration: 1, VerdictA: "FAIL", VerdictB: "FAIL"},
+		{Iteration: 2, VerdictB: "PASS"},
+	}
+	if !slices.Equal(diff.CheckVerdicts, wantChecks) {
+		t.Fatalf("CheckVerdicts = %+v, want %+v", diff.CheckVerdicts, wantChecks)
+	}
+	wantAC := []ACResultDelta{
+		{ACID: "AC2", ResultA: "FAIL", ResultB: "PASS"},
+		{ACID: "AC3", ResultB: "PASS"},
+	}
+	if !slices.Equal(diff.ACDeltas, wantAC) {
+		t.Fatalf("ACDeltas = %+v, want %+v", diff.ACDeltas, wantAC)
+	}
+
+	if _, err := DiffRunOutputs(ctx, store, "run-a", "run-missing"); err == nil {
+		t.Fatal("DiffRunOutputs() error = nil for unknown run")
+	}
+}
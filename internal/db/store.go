@@ -4,10 +4,18 @@ package db
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"time"
 )
 
+// ErrRunNotFound reports a run id the store does not know.
+var ErrRunNotFound = errors.New("run not found")
+
+// ErrRunRunning reports a change refused because the run has not finished.
+var ErrRunRunning = errors.New("run is still running")
+
 // Store provides persistence for runs and steps.
 type Store struct {
 	db *sql.DB
@@ -23,8 +31,8 @@ func (s *Store) DB() *sql.DB {
 	return s.db
 }
 
-// CreateRun inserts the run record and a run_started event.
-func (s *Store) CreateRun(ctx context.Context, runID, goal, runDir string, iteration int) error {
+// CreateRun inserts the run record for taskID and a run_started event.
+func (s *Store) CreateRun(ctx context.Context, runID, taskID, goal, runDir string, iteration int) error {
 	createdAt := time.Now().UTC().Format(time.RFC3339)
 	tx, err := s.db.BeginTx(ctx, &sql.TxOptions{})
 	if err != nil {
@@ -32,9 +40,9 @@ func (s *Store) CreateRun(ctx context.Context, runID, goal, runDir string, itera
 	}
 	defer func() { _ = tx.Rollback() }()
 
-	if _, err := tx.ExecContext(ctx, `INSERT INTO runs(run_id, created_at, goal, status, iteration, current_step_index, verdict, run_dir)
-		VALUES(?, ?, ?, ?, ?, ?, NULL, ?)`,
-		runID, createdAt, goal, "running", iteration, 0, runDir); err != nil {
+	if _, err := tx.ExecContext(ctx, `INSERT INTO runs(run_id, task_id, created_at, goal, status, iteration, current_step_index, verdict, run_dir)
+		VALUES(?, ?, ?, ?, ?, ?, ?, NULL, ?)`,
+		runID, nullableString(taskID), createdAt, goal, "running", iteration, 0, runDir); err != nil {
 		return fmt.Errorf("insert run: %w", err)
 	}
 	if err := s.insertEvent(ctx, tx, runID, "run_started", "run started", ""); err != nil {
@@ -59,6 +67,10 @@ type StepRecord struct {
 	Summary   string
 }
 
+// StepRetryWindow is how soon after the previous step ended a step with the same
+// role, status, and summary must start to be recorded as its retry.
+const StepRetryWindow = 5 * time.Minute
+
 // Update contains updates for a run record.
 type Update struct {
 	CurrentStepIndex int
@@ -97,7 +109,26 @@ func (s *Store) UpdateRun(ctx context.Context, runID string, update Update, even
 	return nil
 }
 
+// AddEvent appends an event to the timeline of a run.
+func (s *Store) AddEvent(ctx context.Context, runID string, event Event) error {
+	tx, err := s.db.BeginTx(ctx, &sql.TxOptions{})
+	if err != nil {
+		return fmt.Errorf("begin add event: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if err := s.insertEvent(ctx, tx, runID, event.Type, event.Message, event.DataJSON); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit add event: %w", err)
+	}
+	return nil
+}
+
 // CommitStep inserts the step record, events, and updates the run in one transaction.
+// A step repeating the previous step of the run (same role, status, and summary within
+// StepRetryWindow) is linked to it through retry_of.
 func (s *Store) CommitStep(ctx context.Context, step StepRecord, events []Event, update Update) error {
 	tx, err := s.db.BeginTx(ctx, &sql.TxOptions{})
 	if err != nil {
@@ -105,9 +136,13 @@ func (s *Store) CommitStep(ctx context.Context, step StepRecord, events []Event,
 	}
 	defer func() { _ = tx.Rollback() }()
 
-	if _, err := tx.ExecContext(ctx, `INSERT INTO steps(run_id, step_index, role, iteration, status, step_dir, started_at, ended_at, summary)
-		VALUES(?, ?, ?, ?, ?, ?, ?, ?, ?)`,
-		step.RunID, step.StepIndex, step.Role, step.Iteration, step.Status, step.StepDir, step.StartedAt, step.EndedAt, step.Summary); err != nil {
+	retryOf, err := s.retriedStep(ctx, tx, step)
+	if err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, `INSERT INTO steps(run_id, step_index, role, iteration, status, step_dir, started_at, ended_at, summary, retry_of)
+		VALUES(?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		step.RunID, step.StepIndex, step.Role, step.Iteration, step.Status, step.StepDir, step.StartedAt, step.EndedAt, step.Summary, nullableInt(retryOf)); err != nil {
 		return fmt.Errorf("insert step: %w", err)
 	}
 	for _, ev := range events {
@@ -125,6 +160,49 @@ func (s *Store) CommitStep(ctx context.Context, step StepRecord, events []Event,
 	return nil
 }
 
+// retriedStep returns the logical step that step retries, or 0 when it is a new step.
+// Retries of a retry point at the original step.
+func (s *Store) retriedStep(ctx context.Context, tx *sql.Tx, step StepRecord) (int, error) {
+	row := tx.QueryRowContext(ctx, `SELECT step_index, role, status, summary, ended_at, retry_of FROM steps
+		WHERE run_id=? AND step_index < ? ORDER BY step_index DESC LIMIT 1`, step.RunID, step.StepIndex)
+	var (
+		prevIndex                      int
+		role, status, summary, endedAt sql.NullString
+		prevRetryOf                    sql.NullInt64
+	)
+	if err := row.Scan(&prevIndex, &role, &status, &summary, &endedAt, &prevRetryOf); err != nil {
+		if err == sql.ErrNoRows {
+			return 0, nil
+		}
+		return 0, fmt.Errorf("read previous step: %w", err)
+	}
+	if role.String != step.Role || status.String != step.Status || summary.String != step.Summary {
+		return 0, nil
+	}
+	ended, err := time.Parse(time.RFC3339, endedAt.String)
+	if err != nil {
+		return 0, nil
+	}
+	started, err := time.Parse(time.RFC3339, step.StartedAt)
+	if err != nil || started.Sub(ended) > StepRetryWindow {
+		return 0, nil
+	}
+	if prevRetryOf.Valid {
+		return int(prevRetryOf.Int64), nil
+	}
+	return prevIndex, nil
+}
+
+// StepRetryOf returns the step index that the step retries, or 0 if it is not a retry.
+func (s *Store) StepRetryOf(ctx context.Context, runID string, stepIndex int) (int, error) {
+	row := s.db.QueryRowContext(ctx, `SELECT retry_of FROM steps WHERE run_id=? AND step_index=?`, runID, stepIndex)
+	var retryOf sql.NullInt64
+	if err := row.Scan(&retryOf); err != nil {
+		return 0, fmt.Errorf("read step retry_of: %w", err)
+	}
+	return int(retryOf.Int64), nil
+}
+
 func (s *Store) insertEvent(ctx context.Context, tx *sql.Tx, runID, typ, message, dataJSON string) error {
 	seq, err := s.nextSeq(ctx, tx, runID)
 	if err != nil {
@@ -154,6 +232,13 @@ func nullableString(value string) any {
 	return value
 }
 
+func nullableInt(value int) any {
+	if value == 0 {
+		return nil
+	}
+	return value
+}
+
 func nullableStringPtr(value *string) any {
 	if value == nil {
 		return nil
@@ -161,6 +246,156 @@ func nullableStringPtr(value *string) any {
 	return *value
 }
 
+// MarkRunFailed marks a run as failed with a failure kind and records a run_failed event.
+func (s *Store) MarkRunFailed(ctx context.Context, runID, failureKind, message string) error {
+	tx, err := s.db.BeginTx(ctx, &sql.TxOptions{})
+	if err != nil {
+		return fmt.Errorf("begin mark run failed: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if err := s.insertEvent(ctx, tx, runID, "run_failed", message, ""); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, `UPDATE runs SET status=?, failure_kind=? WHERE run_id=?`,
+		"failed", nullableString(failureKind), runID); err != nil {
+		return fmt.Errorf("update run failure: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit mark run failed: %w", err)
+	}
+	return nil
+}
+
+// SetRunStatus sets the status of a run and records a run_status event with message.
+func (s *Store) SetRunStatus(ctx context.Context, runID, status, message string) error {
+	tx, err := s.db.BeginTx(ctx, &sql.TxOptions{})
+	if err != nil {
+		return fmt.Errorf("begin set run status: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if err := s.insertEvent(ctx, tx, runID, "run_status", fmt.Sprintf("%s: %s", status, message), ""); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, `UPDATE runs SET status=? WHERE run_id=?`, status, runID); err != nil {
+		return fmt.Errorf("update run status: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit set run status: %w", err)
+	}
+	return nil
+}
+
+// SetVerdict overrides the verdict of a finished run and records a verdict_override
+// event with the previous verdict and reason. It returns ErrRunNotFound for an
+// unknown run and ErrRunRunning for a run that is still running.
+func (s *Store) SetVerdict(ctx context.Context, runID, verdict, reason string) error {
+	tx, err := s.db.BeginTx(ctx, &sql.TxOptions{})
+	if err != nil {
+		return fmt.Errorf("begin set verdict: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	var status string
+	var previous sql.NullString
+	if err := tx.QueryRowContext(ctx, `SELECT status, verdict FROM runs WHERE run_id=?`, runID).Scan(&status, &previous); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("set verdict of run %s: %w", runID, ErrRunNotFound)
+		}
+		return fmt.Errorf("read run for verdict: %w", err)
+	}
+	if status == "running" {
+		return fmt.Errorf("set verdict of run %s: %w", runID, ErrRunRunning)
+	}
+
+	data, err := json.Marshal(map[string]string{"verdict": verdict, "previous_verdict": previous.String, "reason": reason})
+	if err != nil {
+		return fmt.Errorf("marshal verdict override: %w", err)
+	}
+	message := fmt.Sprintf("verdict set to %s", verdict)
+	if reason != "" {
+		message += ": " + reason
+	}
+	if err := s.insertEvent(ctx, tx, runID, "verdict_override", message, string(data)); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, `UPDATE runs SET verdict=? WHERE run_id=?`, verdict, runID); err != nil {
+		return fmt.Errorf("update run verdict: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit set verdict: %w", err)
+	}
+	return nil
+}
+
+// GetRunFailureKind returns the failure kind for a run id, or empty if unset or missing.
+func (s *Store) GetRunFailureKind(ctx context.Context, runID string) (string, error) {
+	row := s.db.QueryRowContext(ctx, `SELECT failure_kind FROM runs WHERE run_id=?`, runID)
+	var kind sql.NullString
+	if err := row.Scan(&kind); err != nil {
+		if err == sql.ErrNoRows {
+			return "", nil
+		}
+		return "", fmt.Errorf("read run failure kind: %w", err)
+	}
+	return kind.String, nil
+}
+
+// StepChange is a file changed by a step.
+type StepChange struct {
+	Path    string
+	Status  string
+	OldPath string
+}
+
+// RecordStepChanges replaces the changed files recorded for a committed step.
+func (s *Store) RecordStepChanges(ctx context.Context, runID string, stepIndex int, changes []StepChange) error {
+	tx, err := s.db.BeginTx(ctx, &sql.TxOptions{})
+	if err != nil {
+		return fmt.Errorf("begin record step changes: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM step_changes WHERE run_id=? AND step_index=?`, runID, stepIndex); err != nil {
+		return fmt.Errorf("clear step changes: %w", err)
+	}
+	for _, change := range changes {
+		if _, err := tx.ExecContext(ctx, `INSERT INTO step_changes(run_id, step_index, path, status, old_path) VALUES(?, ?, ?, ?, ?)`,
+			runID, stepIndex, change.Path, change.Status, nullableString(change.OldPath)); err != nil {
+			return fmt.Errorf("insert step change: %w", err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit step changes: %w", err)
+	}
+	return nil
+}
+
+// ListStepChanges returns the changed files recorded for a step ordered by path.
+func (s *Store) ListStepChanges(ctx context.Context, runID string, stepIndex int) ([]StepChange, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT path, status, old_path FROM step_changes WHERE run_id=? AND step_index=? ORDER BY path`, runID, stepIndex)
+	if err != nil {
+		return nil, fmt.Errorf("query step changes: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	changes := make([]StepChange, 0)
+	for rows.Next() {
+		var change StepChange
+		var oldPath sql.NullString
+		if err := rows.Scan(&change.Path, &change.Status, &oldPath); err != nil {
+			return nil, fmt.Errorf("scan step change: %w", err)
+		}
+		change.OldPath = oldPath.String
+		changes = append(changes, change)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate step changes: %w", err)
+	}
+	return changes, nil
+}
+
 // GetRunStatus returns the status for a run id, or empty if missing.
 func (s *Store) GetRunStatus(ctx context.Context, runID string) (string, error) {
 	row := s.db.QueryRowContext(ctx, `SELECT status FROM runs WHERE run_id=?`, runID)
@@ -173,3 +408,270 @@ func (s *Store) GetRunStatus(ctx context.Context, runID string) (string, error)
 	}
 	return status, nil
 }
+
+// RunRecord is a row of the runs table.
+type RunRecord struct {
+	RunID            string
+	TaskID           string
+	CreatedAt        string
+	Goal             string
+	Status           string
+	Iteration        int
+	CurrentStepIndex int
+	Verdict          string
+	RunDir           string
+	CorrelationID    string
+}
+
+// LastPassedRun returns the newest run of taskID with verdict PASS, or nil if there is none.
+func (s *Store) LastPassedRun(ctx context.Context, taskID string) (*RunRecord, error) {
+	row := s.db.QueryRowContext(ctx, `SELECT run_id, task_id, created_at, goal, status, iteration, current_step_index, verdict, run_dir, COALESCE(correlation_id, '')
+		FROM runs WHERE task_id=? AND verdict=? ORDER BY created_at DESC, rowid DESC LIMIT 1`, taskID, "PASS")
+	var rec RunRecord
+	if err := row.Scan(&rec.RunID, &rec.TaskID, &rec.CreatedAt, &rec.Goal, &rec.Status, &rec.Iteration, &rec.CurrentStepIndex, &rec.Verdict, &rec.RunDir, &rec.CorrelationID); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("read last passed run: %w", err)
+	}
+	return &rec, nil
+}
+
+// GetRun returns the run runID, or nil if there is none.
+func (s *Store) GetRun(ctx context.Context, runID string) (*RunRecord, error) {
+	row := s.db.QueryRowContext(ctx, `SELECT run_id, COALESCE(task_id, ''), created_at, goal, status, iteration, current_step_index, COALESCE(verdict, ''), run_dir, COALESCE(correlation_id, '')
+		FROM runs WHERE run_id=?`, runID)
+	var rec RunRecord
+	if err := row.Scan(&rec.RunID, &rec.TaskID, &rec.CreatedAt, &rec.Goal, &rec.Status, &rec.Iteration, &rec.CurrentStepIndex, &rec.Verdict, &rec.RunDir, &rec.CorrelationID); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("read run: %w", err)
+	}
+	return &rec, nil
+}
+
+// SetRunCorrelationID records the correlation ID shared by a run's logs and agent processes.
+func (s *Store) SetRunCorrelationID(ctx context.Context, runID, correlationID string) error {
+	if _, err := s.db.ExecContext(ctx, `UPDATE runs SET correlation_id=? WHERE run_id=?`, nullableString(correlationID), runID); err != nil {
+		return fmt.Errorf("set run correlation id: %w", err)
+	}
+	return nil
+}
+
+// RunCorrelationID returns the correlation ID recorded for runID, or "" if none was recorded.
+func (s *Store) RunCorrelationID(ctx context.Context, runID string) (string, error) {
+	row := s.db.QueryRowContext(ctx, `SELECT COALESCE(correlation_id, '') FROM runs WHERE run_id=?`, runID)
+	var id string
+	if err := row.Scan(&id); err != nil {
+		return "", fmt.Errorf("read run correlation id: %w", err)
+	}
+	return id, nil
+}
+
+// SetRunACSnapshot records the acceptance criteria, as JSON, that a run started with.
+func (s *Store) SetRunACSnapshot(ctx context.Context, runID, snapshotJSON string) error {
+	if _, err := s.db.ExecContext(ctx, `UPDATE runs SET ac_snapshot=? WHERE run_id=?`, nullableString(snapshotJSON), runID); err != nil {
+		return fmt.Errorf("set run acceptance criteria snapshot: %w", err)
+	}
+	return nil
+}
+
+// RunACSnapshot returns the acceptance criteria snapshot of runID, or "" if none was recorded.
+func (s *Store) RunACSnapshot(ctx context.Context, runID string) (string, error) {
+	row := s.db.QueryRowContext(ctx, `SELECT COALESCE(ac_snapshot, '') FROM runs WHERE run_id=?`, runID)
+	var snapshot string
+	if err := row.Scan(&snapshot); err != nil {
+		return "", fmt.Errorf("read run acceptance criteria snapshot: %w", err)
+	}
+	return snapshot, nil
+}
+
+// RunCountForTask returns how many runs were recorded for taskID.
+func (s *Store) RunCountForTask(ctx context.Context, taskID string) (int, error) {
+	row := s.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM runs WHERE task_id=?`, taskID)
+	var count int
+	if err := row.Scan(&count); err != nil {
+		return 0, fmt.Errorf("count task runs: %w", err)
+	}
+	return count, nil
+}
+
+// FailedRunCountForTask returns how many runs of taskID ended with status failed.
+func (s *Store) FailedRunCountForTask(ctx context.Context, taskID string) (int, error) {
+	row := s.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM runs WHERE task_id=? AND status=?`, taskID, "failed")
+	var count int
+	if err := row.Scan(&count); err != nil {
+		return 0, fmt.Errorf("count failed task runs: %w", err)
+	}
+	return count, nil
+}
+
+// StopRunningRunsForTask marks the running runs of taskID as stopped, records a
+// run_stopped event for each, and returns their ids.
+func (s *Store) StopRunningRunsForTask(ctx context.Context, taskID, message string) ([]string, error) {
+	tx, err := s.db.BeginTx(ctx, &sql.TxOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("begin stop task runs: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	rows, err := tx.QueryContext(ctx, `SELECT run_id FROM runs WHERE task_id=? AND status=? ORDER BY created_at`, taskID, "running")
+	if err != nil {
+		return nil, fmt.Errorf("query running task runs: %w", err)
+	}
+	var runIDs []string
+	for rows.Next() {
+		var runID string
+		if err := rows.Scan(&runID); err != nil {
+			_ = rows.Close()
+			return nil, fmt.Errorf("scan running task run: %w", err)
+		}
+		runIDs = append(runIDs, runID)
+	}
+	if err := rows.Err(); err != nil {
+		_ = rows.Close()
+		return nil, fmt.Errorf("iterate running task runs: %w", err)
+	}
+	_ = rows.Close()
+
+	for _, runID := range runIDs {
+		if err := s.insertEvent(ctx, tx, runID, "run_stopped", message, ""); err != nil {
+			return nil, err
+		}
+		if _, err := tx.ExecContext(ctx, `UPDATE runs SET status=? WHERE run_id=?`, "stopped", runID); err != nil {
+			return nil, fmt.Errorf("update run status: %w", err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("commit stop task runs: %w", err)
+	}
+	return runIDs, nil
+}
+
+// RecordIterationHead stores the task branch head at the end of iteration, replacing any earlier record.
+func (s *Store) RecordIterationHead(ctx context.Context, runID string, iteration int, head string) error {
+	recordedAt := time.Now().UTC().Format(time.RFC3339)
+	if _, err := s.db.ExecContext(ctx, `INSERT INTO iteration_heads(run_id, iteration, head, recorded_at) VALUES(?, ?, ?, ?)
+		ON CONFLICT(run_id, iteration) DO UPDATE SET head=excluded.head, recorded_at=excluded.recorded_at`,
+		runID, iteration, head, recordedAt); err != nil {
+		return fmt.Errorf("record iteration head: %w", err)
+	}
+	return nil
+}
+
+// IterationHead returns the task branch head recorded for iteration, or empty if none was recorded.
+func (s *Store) IterationHead(ctx context.Context, runID string, iteration int) (string, error) {
+	row := s.db.QueryRowContext(ctx, `SELECT head FROM iteration_heads WHERE run_id=? AND iteration=?`, runID, iteration)
+	var head string
+	if err := row.Scan(&head); err != nil {
+		if err == sql.ErrNoRows {
+			return "", nil
+		}
+		return "", fmt.Errorf("read iteration head: %w", err)
+	}
+	return head, nil
+}
+
+// LoopState is the position of "norma loop" in the backlog.
+type LoopState struct {
+	Iteration      int
+	SelectedTaskID string
+	UpdatedAt      string
+}
+
+// SaveLoopState replaces the persisted loop state.
+func (s *Store) SaveLoopState(ctx context.Context, state LoopState) error {
+	updatedAt := time.Now().UTC().Format(time.RFC3339)
+	if _, err := s.db.ExecContext(ctx, `INSERT INTO loop_state(id, iteration, selected_task_id, updated_at) VALUES(1, ?, ?, ?)
+		ON CONFLICT(id) DO UPDATE SET iteration=excluded.iteration, selected_task_id=excluded.selected_task_id, updated_at=excluded.updated_at`,
+		state.Iteration, nullableString(state.SelectedTaskID), updatedAt); err != nil {
+		return fmt.Errorf("save loop state: %w", err)
+	}
+	return nil
+}
+
+// LoadLoopState returns the persisted loop state, or a zero LoopState if none was saved.
+func (s *Store) LoadLoopState(ctx context.Context) (LoopState, error) {
+	row := s.db.QueryRowContext(ctx, `SELECT iteration, selected_task_id, updated_at FROM loop_state WHERE id=1`)
+	var state LoopState
+	var selected sql.NullString
+	if err := row.Scan(&state.Iteration, &selected, &state.UpdatedAt); err != nil {
+		if err == sql.ErrNoRows {
+			return LoopState{}, nil
+		}
+		return LoopState{}, fmt.Errorf("read loop state: %w", err)
+	}
+	state.SelectedTaskID = selected.String
+	return state, nil
+}
+
+// RunStats aggregates run and step outcomes over a time range.
+type RunStats struct {
+	// RunsByStatus counts runs per status.
+	RunsByStatus map[string]int
+	// AvgIterationsToPass is the mean iteration of runs that ended with a PASS verdict.
+	AvgIterationsToPass float64
+	// AvgStepDuration is the mean duration of finished steps per role.
+	AvgStepDuration map[string]time.Duration
+}
+
+// Statistics computes RunStats for runs created at or after since.
+// An empty range yields empty maps and a zero average.
+func (s *Store) Statistics(ctx context.Context, since time.Time) (RunStats, error) {
+	from := since.UTC().Format(time.RFC3339)
+	stats := RunStats{
+		RunsByStatus:    map[string]int{},
+		AvgStepDuration: map[string]time.Duration{},
+	}
+
+	rows, err := s.db.QueryContext(ctx, `SELECT status, COUNT(*) FROM runs WHERE created_at >= ? GROUP BY status`, from)
+	if err != nil {
+		return RunStats{}, fmt.Errorf("query run status counts: %w", err)
+	}
+	for rows.Next() {
+		var status string
+		var count int
+		if err := rows.Scan(&status, &count); err != nil {
+			_ = rows.Close()
+			return RunStats{}, fmt.Errorf("scan run status count: %w", err)
+		}
+		stats.RunsByStatus[status] = count
+	}
+	if err := rows.Err(); err != nil {
+		_ = rows.Close()
+		return RunStats{}, fmt.Errorf("iterate run status counts: %w", err)
+	}
+	_ = rows.Close()
+
+	row := s.db.QueryRowContext(ctx, `SELECT AVG(iteration) FROM runs WHERE created_at >= ? AND verdict=?`, from, "PASS")
+	var avgIterations sql.NullFloat64
+	if err := row.Scan(&avgIterations); err != nil {
+		return RunStats{}, fmt.Errorf("read average iterations to pass: %w", err)
+	}
+	stats.AvgIterationsToPass = avgIterations.Float64
+
+	rows, err = s.db.QueryContext(ctx, `SELECT s.role, AVG((julianday(s.ended_at) - julianday(s.started_at)) * 86400.0)
+		FROM steps s JOIN runs r ON r.run_id = s.run_id
+		WHERE r.created_at >= ?
+		GROUP BY s.role`, from)
+	if err != nil {
+		return RunStats{}, fmt.Errorf("query step durations: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+	for rows.Next() {
+		var role string
+		var seconds sql.NullFloat64
+		if err := rows.Scan(&role, &seconds); err != nil {
+			return RunStats{}, fmt.Errorf("scan step duration: %w", err)
+		}
+		if !seconds.Valid {
+			continue
+		}
+		stats.AvgStepDuration[role] = time.Duration(seconds.Float64 * float64(time.Second)).Round(time.Millisecond)
+	}
+	if err := rows.Err(); err != nil {
+		return RunStats{}, fmt.Errorf("iterate step durations: %w", err)
+	}
+	return stats, nil
+}
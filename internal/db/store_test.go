@@ -0,0 +1,484 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"maps"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestStoreRecordStepChanges(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	sqlDB, err := Open(ctx, filepath.Join(t.TempDir(), "norma.db"))
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	t.Cleanup(func() { _ = sqlDB.Close() })
+	store := NewStore(sqlDB)
+
+	if err := store.CreateRun(ctx, "run-1", "norma-a1", "goal", t.TempDir(), 1); err != nil {
+		t.Fatalf("CreateRun() error = %v", err)
+	}
+	step := StepRecord{RunID: "run-1", StepIndex: 2, Role: "do", Iteration: 1, Status: "ok", StepDir: "steps/002-do", StartedAt: "2026-01-01T00:00:00Z"}
+	if err := store.CommitStep(ctx, step, nil, Update{CurrentStepIndex: 2, Iteration: 1, Status: "running"}); err != nil {
+		t.Fatalf("CommitStep() error = %v", err)
+	}
+
+	changes := []StepChange{
+		{Path: "b.go", Status: "M"},
+		{Path: "a.go", Status: "R", OldPath: "old.go"},
+	}
+	if err := store.RecordStepChanges(ctx, "run-1", 2, changes); err != nil {
+		t.Fatalf("RecordStepChanges() error = %v", err)
+	}
+
+	got, err := store.ListStepChanges(ctx, "run-1", 2)
+	if err != nil {
+		t.Fatalf("ListStepChanges() error = %v", err)
+	}
+	want := []StepChange{changes[1], changes[0]}
+	if !slices.Equal(got, want) {
+		t.Fatalf("ListStepChanges() = %+v, want %+v", got, want)
+	}
+}
+
+func TestStoreRunCountForTask(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	sqlDB, err := Open(ctx, filepath.Join(t.TempDir(), "norma.db"))
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	t.Cleanup(func() { _ = sqlDB.Close() })
+	store := NewStore(sqlDB)
+
+	runs := []struct{ runID, taskID string }{
+		{runID: "run-1", taskID: "norma-a1"},
+		{runID: "run-2", taskID: "norma-a1"},
+		{runID: "run-3", taskID: "norma-a1"},
+		{runID: "run-4", taskID: "norma-b2"},
+		{runID: "run-5"},
+	}
+	for _, run := range runs {
+		if err := store.CreateRun(ctx, run.runID, run.taskID, "goal", t.TempDir(), 1); err != nil {
+			t.Fatalf("CreateRun(%s) error = %v", run.runID, err)
+		}
+	}
+
+	for taskID, want := range map[string]int{"norma-a1": 3, "norma-b2": 1, "norma-c3": 0} {
+		got, err := store.RunCountForTask(ctx, taskID)
+		if err != nil {
+			t.Fatalf("RunCountForTask(%s) error = %v", taskID, err)
+		}
+		if got != want {
+			t.Fatalf("RunCountForTask(%s) = %d, want %d", taskID, got, want)
+		}
+	}
+
+	for _, runID := range []string{"run-1", "run-2"} {
+		if err := store.MarkRunFailed(ctx, runID, "agent_error", "failed"); err != nil {
+			t.Fatalf("MarkRunFailed(%s) error = %v", runID, err)
+		}
+	}
+	for taskID, want := range map[string]int{"norma-a1": 2, "norma-b2": 0} {
+		got, err := store.FailedRunCountForTask(ctx, taskID)
+		if err != nil {
+			t.Fatalf("FailedRunCountForTask(%s) error = %v", taskID, err)
+		}
+		if got != want {
+			t.Fatalf("FailedRunCountForTask(%s) = %d, want %d", taskID, got, want)
+		}
+	}
+}
+
+func TestStoreStopRunningRunsForTask(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	sqlDB, err := Open(ctx, filepath.Join(t.TempDir(), "norma.db"))
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	t.Cleanup(func() { _ = sqlDB.Close() })
+	store := NewStore(sqlDB)
+
+	for _, run := range []struct{ runID, taskID string }{
+		{runID: "run-1", taskID: "norma-a1"},
+		{runID: "run-2", taskID: "norma-a1"},
+		{runID: "run-3", taskID: "norma-b2"},
+	} {
+		if err := store.CreateRun(ctx, run.runID, run.taskID, "goal", t.TempDir(), 1); err != nil {
+			t.Fatalf("CreateRun(%s) error = %v", run.runID, err)
+		}
+	}
+	if err := store.MarkRunFailed(ctx, "run-1", "agent_error", "boom"); err != nil {
+		t.Fatalf("MarkRunFailed() error = %v", err)
+	}
+
+	stopped, err := store.StopRunningRunsForTask(ctx, "norma-a1", "task cleaned up")
+	if err != nil {
+		t.Fatalf("StopRunningRunsForTask() error = %v", err)
+	}
+	if want := []string{"run-2"}; !slices.Equal(stopped, want) {
+		t.Fatalf("StopRunningRunsForTask() = %v, want %v", stopped, want)
+	}
+	for runID, want := range map[string]string{"run-1": "failed", "run-2": "stopped", "run-3": "running"} {
+		got, err := store.GetRunStatus(ctx, runID)
+		if err != nil {
+			t.Fatalf("GetRunStatus(%s) error = %v", runID, err)
+		}
+		if got != want {
+			t.Fatalf("GetRunStatus(%s) = %q, want %q", runID, got, want)
+		}
+	}
+}
+
+func TestStoreSaveLoadLoopState(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	sqlDB, err := Open(ctx, filepath.Join(t.TempDir(), "norma.db"))
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	t.Cleanup(func() { _ = sqlDB.Close() })
+	store := NewStore(sqlDB)
+
+	got, err := store.LoadLoopState(ctx)
+	if err != nil {
+		t.Fatalf("LoadLoopState() error = %v", err)
+	}
+	if got != (LoopState{}) {
+		t.Fatalf("LoadLoopState() before save = %+v, want zero", got)
+	}
+
+	for _, want := range []LoopState{
+		{Iteration: 4, SelectedTaskID: "norma-a1"},
+		{Iteration: 5},
+	} {
+		if err := store.SaveLoopState(ctx, want); err != nil {
+			t.Fatalf("SaveLoopState(%+v) error = %v", want, err)
+		}
+		got, err := store.LoadLoopState(ctx)
+		if err != nil {
+			t.Fatalf("LoadLoopState() error = %v", err)
+		}
+		if got.Iteration != want.Iteration || got.SelectedTaskID != want.SelectedTaskID || got.UpdatedAt == "" {
+			t.Fatalf("LoadLoopState() = %+v, want %+v", got, want)
+		}
+	}
+}
+
+func TestStoreStatistics(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	sqlDB, err := Open(ctx, filepath.Join(t.TempDir(), "norma.db"))
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	t.Cleanup(func() { _ = sqlDB.Close() })
+	store := NewStore(sqlDB)
+
+	since := time.Now().Add(-time.Hour)
+	empty, err := store.Statistics(ctx, since)
+	if err != nil {
+		t.Fatalf("Statistics() on empty db error = %v", err)
+	}
+	if len(empty.RunsByStatus) != 0 || empty.AvgIterationsToPass != 0 || len(empty.AvgStepDuration) != 0 {
+		t.Fatalf("Statistics() on empty db = %+v, want zero", empty)
+	}
+
+	pass := "PASS"
+	fail := "FAIL"
+	runs := []struct {
+		runID     string
+		iteration int
+		status    string
+		verdict   *string
+	}{
+		{runID: "run-1", iteration: 1, status: "passed", verdict: &pass},
+		{runID: "run-2", iteration: 3, status: "passed", verdict: &pass},
+		{runID: "run-3", iteration: 2, status: "failed", verdict: &fail},
+		{runID: "run-4", iteration: 1, status: "running"},
+		{runID: "run-old", iteration: 9, status: "passed", verdict: &pass},
+	}
+	for _, run := range runs {
+		if err := store.CreateRun(ctx, run.runID, "", "goal", t.TempDir(), 1); err != nil {
+			t.Fatalf("CreateRun(%s) error = %v", run.runID, err)
+		}
+		update := Update{Iteration: run.iteration, Status: run.status, Verdict: run.verdict}
+		if err := store.UpdateRun(ctx, run.runID, update, nil); err != nil {
+			t.Fatalf("UpdateRun(%s) error = %v", run.runID, err)
+		}
+	}
+	if _, err := sqlDB.ExecContext(ctx, `UPDATE runs SET created_at=? WHERE run_id=?`, "2020-01-01T00:00:00Z", "run-old"); err != nil {
+		t.Fatalf("backdate run: %v", err)
+	}
+
+	steps := []StepRecord{
+		{RunID: "run-1", StepIndex: 1, Role: "plan", StartedAt: "2026-01-01T00:00:00Z", EndedAt: "2026-01-01T00:00:10Z"},
+		{RunID: "run-2", StepIndex: 1, Role: "plan", StartedAt: "2026-01-01T00:00:00Z", EndedAt: "2026-01-01T00:00:30Z"},
+		{RunID: "run-2", StepIndex: 2, Role: "do", StartedAt: "2026-01-01T00:00:00Z", EndedAt: "2026-01-01T00:01:00Z"},
+		{RunID: "run-4", StepIndex: 1, Role: "do", StartedAt: "2026-01-01T00:00:00Z"},
+		{RunID: "run-old", StepIndex: 1, Role: "plan", StartedAt: "2020-01-01T00:00:00Z", EndedAt: "2020-01-01T01:00:00Z"},
+	}
+	for _, step := range steps {
+		step.Iteration = 1
+		step.Status = "ok"
+		step.StepDir = "steps/" + step.Role
+		if err := store.CommitStep(ctx, step, nil, Update{CurrentStepIndex: step.StepIndex, Iteration: 1, Status: "running"}); err != nil {
+			t.Fatalf("CommitStep(%s/%d) error = %v", step.RunID, step.StepIndex, err)
+		}
+	}
+	for _, run := range runs {
+		update := Update{CurrentStepIndex: 2, Iteration: run.iteration, Status: run.status, Verdict: run.verdict}
+		if err := store.UpdateRun(ctx, run.runID, update, nil); err != nil {
+			t.Fatalf("UpdateRun(%s) error = %v", run.runID, err)
+		}
+	}
+
+	got, err := store.Statistics(ctx, since)
+	if err != nil {
+		t.Fatalf("Statistics() error = %v", err)
+	}
+	wantStatus := map[string]int{"passed": 2, "failed": 1, "running": 1}
+	if !maps.Equal(got.RunsByStatus, wantStatus) {
+		t.Fatalf("RunsByStatus = %v, want %v", got.RunsByStatus, wantStatus)
+	}
+	if got.AvgIterationsToPass != 2 {
+		t.Fatalf("AvgIterationsToPass = %v, want 2", got.AvgIterationsToPass)
+	}
+	wantDurations := map[string]time.Duration{"plan": 20 * time.Second, "do": time.Minute}
+	if !maps.Equal(got.AvgStepDuration, wantDurations) {
+		t.Fatalf("AvgStepDuration = %v, want %v", got.AvgStepDuration, wantDurations)
+	}
+}
+
+func TestStoreCommitStepLinksRetries(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	sqlDB, err := Open(ctx, filepath.Join(t.TempDir(), "norma.db"))
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	t.Cleanup(func() { _ = sqlDB.Close() })
+	store := NewStore(sqlDB)
+
+	if err := store.CreateRun(ctx, "run-1", "norma-a1", "goal", t.TempDir(), 1); err != nil {
+		t.Fatalf("CreateRun() error = %v", err)
+	}
+
+	steps := []StepRecord{
+		{StepIndex: 1, Role: "plan", Status: "ok", Summary: "planned", StartedAt: "2026-01-01T00:00:00Z", EndedAt: "2026-01-01T00:01:00Z"},
+		{StepIndex: 2, Role: "do", Status: "error", Summary: "agent crashed", StartedAt: "2026-01-01T00:01:00Z", EndedAt: "2026-01-01T00:02:00Z"},
+		{StepIndex: 3, Role: "do", Status: "error", Summary: "agent crashed", StartedAt: "2026-01-01T00:02:30Z", EndedAt: "2026-01-01T00:03:00Z"},
+		{StepIndex: 4, Role: "do", Status: "error", Summary: "agent crashed", StartedAt: "2026-01-01T00:03:10Z", EndedAt: "2026-01-01T00:04:00Z"},
+		{StepIndex: 5, Role: "do", Status: "error", Summary: "agent crashed", StartedAt: "2026-01-01T01:00:00Z", EndedAt: "2026-01-01T01:01:00Z"},
+		{StepIndex: 6, Role: "do", Status: "ok", Summary: "agent crashed", StartedAt: "2026-01-01T01:01:00Z", EndedAt: "2026-01-01T01:02:00Z"},
+	}
+	for _, step := range steps {
+		step.RunID = "run-1"
+		step.Iteration = 1
+		step.StepDir = "steps/" + step.Role
+		if err := store.CommitStep(ctx, step, nil, Update{CurrentStepIndex: step.StepIndex, Iteration: 1, Status: "running"}); err != nil {
+			t.Fatalf("CommitStep(%d) error = %v", step.StepIndex, err)
+		}
+	}
+
+	// Step 3 retries step 2, step 4 retries the same logical step, step 5 falls
+	// outside the retry window and step 6 has a different status.
+	want := map[int]int{1: 0, 2: 0, 3: 2, 4: 2, 5: 0, 6: 0}
+	for stepIndex, wantRetryOf := range want {
+		got, err := store.StepRetryOf(ctx, "run-1", stepIndex)
+		if err != nil {
+			t.Fatalf("StepRetryOf(%d) error = %v", stepIndex, err)
+		}
+		if got != wantRetryOf {
+			t.Fatalf("StepRetryOf(%d) = %d, want %d", stepIndex, got, wantRetryOf)
+		}
+	}
+}
+
+func TestStoreLastPassedRun(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	sqlDB, err := Open(ctx, filepath.Join(t.TempDir(), "norma.db"))
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	t.Cleanup(func() { _ = sqlDB.Close() })
+	store := NewStore(sqlDB)
+
+	if got, err := store.LastPassedRun(ctx, "norma-a1"); err != nil || got != nil {
+		t.Fatalf("LastPassedRun() on empty db = %+v, %v, want nil, nil", got, err)
+	}
+
+	pass := "PASS"
+	fail := "FAIL"
+	runs := []struct {
+		runID     string
+		taskID    string
+		createdAt string
+		status    string
+		verdict   *string
+	}{
+		{runID: "run-1", taskID: "norma-a1", createdAt: "2026-01-01T00:00:00Z", status: "passed", verdict: &pass},
+		{runID: "run-2", taskID: "norma-a1", createdAt: "2026-01-02T00:00:00Z", status: "passed", verdict: &pass},
+		{runID: "run-3", taskID: "norma-a1", createdAt: "2026-01-03T00:00:00Z", status: "failed", verdict: &fail},
+		{runID: "run-4", taskID: "norma-a1", createdAt: "2026-01-04T00:00:00Z", status: "running"},
+		{runID: "run-5", taskID: "norma-b2", createdAt: "2026-01-05T00:00:00Z", status: "passed", verdict: &pass},
+		{runID: "run-6", taskID: "norma-c3", createdAt: "2026-01-06T00:00:00Z", status: "failed", verdict: &fail},
+	}
+	for _, run := range runs {
+		if err := store.CreateRun(ctx, run.runID, run.taskID, "goal", "runs/"+run.runID, 1); err != nil {
+			t.Fatalf("CreateRun(%s) error = %v", run.runID, err)
+		}
+		if err := store.UpdateRun(ctx, run.runID, Update{CurrentStepIndex: 4, Iteration: 2, Status: run.status, Verdict: run.verdict}, nil); err != nil {
+			t.Fatalf("UpdateRun(%s) error = %v", run.runID, err)
+		}
+		if _, err := sqlDB.ExecContext(ctx, `UPDATE runs SET created_at=? WHERE run_id=?`, run.createdAt, run.runID); err != nil {
+			t.Fatalf("set created_at(%s): %v", run.runID, err)
+		}
+	}
+
+	got, err := store.LastPassedRun(ctx, "norma-a1")
+	if err != nil {
+		t.Fatalf("LastPassedRun() error = %v", err)
+	}
+	want := RunRecord{RunID: "run-2", TaskID: "norma-a1", CreatedAt: "2026-01-02T00:00:00Z", Goal: "goal", Status: "passed", Iteration: 2, CurrentStepIndex: 4, Verdict: "PASS", RunDir: "runs/run-2"}
+	if got == nil || *got != want {
+		t.Fatalf("LastPassedRun() = %+v, want %+v", got, want)
+	}
+
+	if got, err := store.LastPassedRun(ctx, "norma-c3"); err != nil || got != nil {
+		t.Fatalf("LastPassedRun(norma-c3) = %+v, %v, want nil, nil", got, err)
+	}
+}
+
+func TestStoreRunCorrelationID(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	sqlDB, err := Open(ctx, filepath.Join(t.TempDir(), "norma.db"))
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	t.Cleanup(func() { _ = sqlDB.Close() })
+	store := NewStore(sqlDB)
+
+	if err := store.CreateRun(ctx, "run-1", "norma-a1", "goal", "runs/run-1", 1); err != nil {
+		t.Fatalf("CreateRun() error = %v", err)
+	}
+	if got, err := store.RunCorrelationID(ctx, "run-1"); err != nil || got != "" {
+		t.Fatalf("RunCorrelationID() before set = %q, %v, want empty", got, err)
+	}
+	if err := store.SetRunCorrelationID(ctx, "run-1", "cid-1"); err != nil {
+		t.Fatalf("SetRunCorrelationID() error = %v", err)
+	}
+	if got, err := store.RunCorrelationID(ctx, "run-1"); err != nil || got != "cid-1" {
+		t.Fatalf("RunCorrelationID() = %q, %v, want cid-1", got, err)
+	}
+
+	pass := "PASS"
+	if err := store.UpdateRun(ctx, "run-1", Update{Status: "passed", Verdict: &pass}, nil); err != nil {
+		t.Fatalf("UpdateRun() error = %v", err)
+	}
+	rec, err := store.LastPassedRun(ctx, "norma-a1")
+	if err != nil || rec == nil || rec.CorrelationID != "cid-1" {
+		t.Fatalf("LastPassedRun() = %+v, %v, want correlation id cid-1", rec, err)
+	}
+
+	if _, err := store.RunCorrelationID(ctx, "missing"); err == nil {
+		t.Fatal("RunCorrelationID(missing) error = nil, want error")
+	}
+}
+
+func TestStoreRunACSnapshot(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	sqlDB, err := Open(ctx, filepath.Join(t.TempDir(), "norma.db"))
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	t.Cleanup(func() { _ = sqlDB.Close() })
+	store := NewStore(sqlDB)
+
+	if err := store.CreateRun(ctx, "run-1", "norma-a1", "goal", "runs/run-1", 1); err != nil {
+		t.Fatalf("CreateRun() error = %v", err)
+	}
+	if got, err := store.RunACSnapshot(ctx, "run-1"); err != nil || got != "" {
+		t.Fatalf("RunACSnapshot() before set = %q, %v, want empty", got, err)
+	}
+	snapshot := `[{"id":"AC1","text":"prints hello"}]`
+	if err := store.SetRunACSnapshot(ctx, "run-1", snapshot); err != nil {
+		t.Fatalf("SetRunACSnapshot() error = %v", err)
+	}
+	if got, err := store.RunACSnapshot(ctx, "run-1"); err != nil || got != snapshot {
+		t.Fatalf("RunACSnapshot() = %q, %v, want %q", got, err, snapshot)
+	}
+}
+
+func TestStoreSetVerdict(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	sqlDB, err := Open(ctx, filepath.Join(t.TempDir(), "norma.db"))
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	t.Cleanup(func() { _ = sqlDB.Close() })
+	store := NewStore(sqlDB)
+
+	if err := store.CreateRun(ctx, "run-1", "norma-a1", "goal", "runs/run-1", 1); err != nil {
+		t.Fatalf("CreateRun() error = %v", err)
+	}
+	if err := store.SetVerdict(ctx, "run-1", "PASS", "verified by hand"); !errors.Is(err, ErrRunRunning) {
+		t.Fatalf("SetVerdict() on running run error = %v, want %v", err, ErrRunRunning)
+	}
+	if err := store.SetVerdict(ctx, "run-missing", "PASS", ""); !errors.Is(err, ErrRunNotFound) {
+		t.Fatalf("SetVerdict() on unknown run error = %v, want %v", err, ErrRunNotFound)
+	}
+
+	failed := "FAIL"
+	if err := store.UpdateRun(ctx, "run-1", Update{Iteration: 2, Status: "failed", Verdict: &failed}, nil); err != nil {
+		t.Fatalf("UpdateRun() error = %v", err)
+	}
+	if err := store.SetVerdict(ctx, "run-1", "PASS", "verified by hand"); err != nil {
+		t.Fatalf("SetVerdict() error = %v", err)
+	}
+
+	rec, err := store.GetRun(ctx, "run-1")
+	if err != nil {
+		t.Fatalf("GetRun() error = %v", err)
+	}
+	if rec == nil || rec.Verdict != "PASS" || rec.Status != "failed" {
+		t.Fatalf("GetRun() = %+v, want verdict PASS with status unchanged", rec)
+	}
+
+	var typ, message, data string
+	row := sqlDB.QueryRowContext(ctx, `SELECT type, message, data_json FROM events WHERE run_id=? ORDER BY seq DESC LIMIT 1`, "run-1")
+	if err := row.Scan(&typ, &message, &data); err != nil {
+		t.Fatalf("read verdict event: %v", err)
+	}
+	if typ != "verdict_override" || message != "verdict set to PASS: verified by hand" {
+		t.Fatalf("event = (%q, %q), want verdict_override with reason", typ, message)
+	}
+	var payload map[string]string
+	if err := json.Unmarshal([]byte(data), &payload); err != nil {
+		t.Fatalf("parse event data: %v", err)
+	}
+	if payload["previous_verdict"] != "FAIL" || payload["verdict"] != "PASS" {
+		t.Fatalf("event data = %v, want FAIL -> PASS", payload)
+	}
+}
@@ -0,0 +1,104 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+)
+
+// SpanRecord is the wall-clock span of one step of a run.
+type SpanRecord struct {
+	RunID     string
+	StepIndex int
+	Role      string
+	Iteration int
+	Status    string
+	Start     time.Time
+	// End equals Start for steps that have not ended.
+	End time.Time
+}
+
+// Duration returns how long the step ran.
+func (s SpanRecord) Duration() time.Duration {
+	return s.End.Sub(s.Start)
+}
+
+// TimingProfile returns the spans of every step of runID in step order.
+func (s *Store) TimingProfile(ctx context.Context, runID string) ([]SpanRecord, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT step_index, role, iteration, status, started_at, ended_at
+		FROM steps WHERE run_id=? ORDER BY step_index`, runID)
+	if err != nil {
+		return nil, fmt.Errorf("query step timings: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var spans []SpanRecord
+	for rows.Next() {
+		span := SpanRecord{RunID: runID}
+		var startedAt string
+		var endedAt sql.NullString
+		if err := rows.Scan(&span.StepIndex, &span.Role, &span.Iteration, &span.Status, &startedAt, &endedAt); err != nil {
+			return nil, fmt.Errorf("scan step timing: %w", err)
+		}
+		span.Start, err = time.Parse(time.RFC3339, startedAt)
+		if err != nil {
+			return nil, fmt.Errorf("parse start of step %d: %w", span.StepIndex, err)
+		}
+		span.End = span.Start
+		if endedAt.Valid && endedAt.String != "" {
+			span.End, err = time.Parse(time.RFC3339, endedAt.String)
+			if err != nil {
+				return nil, fmt.Errorf("parse end of step %d: %w", span.StepIndex, err)
+			}
+		}
+		spans = append(spans, span)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("read step timings: %w", err)
+	}
+	return spans, nil
+}
+
+// chromeTraceEvent is a complete ("X") event of the Chrome Trace Event Format.
+type chromeTraceEvent struct {
+	Name string         `json:"name"`
+	Cat  string         `json:"cat"`
+	Ph   string         `json:"ph"`
+	Ts   int64          `json:"ts"`
+	Dur  int64          `json:"dur"`
+	Pid  int            `json:"pid"`
+	Tid  int            `json:"tid"`
+	Args map[string]any `json:"args"`
+}
+
+// WriteChromeTrace writes spans as Chrome Tracing JSON, loadable in chrome://tracing
+// or Perfetto. Each step is a complete event on the thread of its iteration, with
+// timestamps in microseconds since the Unix epoch.
+func WriteChromeTrace(w io.Writer, spans []SpanRecord) error {
+	events := make([]chromeTraceEvent, 0, len(spans))
+	for _, span := range spans {
+		events = append(events, chromeTraceEvent{
+			Name: span.Role,
+			Cat:  "step",
+			Ph:   "X",
+			Ts:   span.Start.UnixMicro(),
+			Dur:  span.Duration().Microseconds(),
+			Pid:  1,
+			Tid:  span.Iteration,
+			Args: map[string]any{
+				"run_id":     span.RunID,
+				"step_index": span.StepIndex,
+				"status":     span.Status,
+			},
+		})
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(map[string]any{"traceEvents": events, "displayTimeUnit": "ms"}); err != nil {
+		return fmt.Errorf("write chrome trace: %w", err)
+	}
+	return nil
+}
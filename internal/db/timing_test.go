@@ -0,0 +1,99 @@
+package db
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestTimingProfileChromeTrace(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	sqlDB, err := Open(ctx, filepath.Join(t.TempDir(), "norma.db"))
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	t.Cleanup(func() { _ = sqlDB.Close() })
+	store := NewStore(sqlDB)
+
+	if err := store.CreateRun(ctx, "run-1", "norma-a1", "goal", "runs/run-1", 1); err != nil {
+		t.Fatalf("CreateRun() error = %v", err)
+	}
+	steps := []StepRecord{
+		{RunID: "run-1", StepIndex: 1, Role: "plan", Iteration: 1, Status: "ok", StepDir: "steps/001-plan", StartedAt: "2026-01-01T10:00:00Z", EndedAt: "2026-01-01T10:00:30Z"},
+		{RunID: "run-1", StepIndex: 2, Role: "do", Iteration: 1, Status: "ok", StepDir: "steps/002-do", StartedAt: "2026-01-01T10:00:30Z", EndedAt: "2026-01-01T10:05:00Z"},
+		{RunID: "run-1", StepIndex: 3, Role: "check", Iteration: 2, Status: "ok", StepDir: "steps/003-check", StartedAt: "2026-01-01T10:05:00Z", EndedAt: "2026-01-01T10:05:02Z"},
+	}
+	for _, step := range steps {
+		if err := store.CommitStep(ctx, step, nil, Update{CurrentStepIndex: step.StepIndex, Iteration: step.Iteration, Status: "running"}); err != nil {
+			t.Fatalf("CommitStep(%d) error = %v", step.StepIndex, err)
+		}
+	}
+
+	spans, err := store.TimingProfile(ctx, "run-1")
+	if err != nil {
+		t.Fatalf("TimingProfile() error = %v", err)
+	}
+	wantDurations := []time.Duration{30 * time.Second, 270 * time.Second, 2 * time.Second}
+	if len(spans) != len(wantDurations) {
+		t.Fatalf("TimingProfile() = %d spans, want %d", len(spans), len(wantDurations))
+	}
+	for i, span := range spans {
+		if span.Duration() != wantDurations[i] {
+			t.Fatalf("span %d duration = %s, want %s", i, span.Duration(), wantDurations[i])
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := WriteChromeTrace(&buf, spans); err != nil {
+		t.Fatalf("WriteChromeTrace() error = %v", err)
+	}
+	var trace struct {
+		TraceEvents []struct {
+			Name string `json:"name"`
+			Ph   string `json:"ph"`
+			Ts   int64  `json:"ts"`
+			Dur  int64  `json:"dur"`
+			Tid  int    `json:"tid"`
+		} `json:"traceEvents"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &trace); err != nil {
+		t.Fatalf("trace is not valid JSON: %v\n%s", err, buf.String())
+	}
+	if len(trace.TraceEvents) != len(steps) {
+		t.Fatalf("trace has %d events, want %d", len(trace.TraceEvents), len(steps))
+	}
+	start := time.Date(2026, 1, 1, 10, 0, 0, 0, time.UTC).UnixMicro()
+	first := trace.TraceEvents[0]
+	if first.Name != "plan" || first.Ph != "X" || first.Ts != start || first.Dur != 30_000_000 || first.Tid != 1 {
+		t.Fatalf("first event = %+v", first)
+	}
+	for i, event := range trace.TraceEvents {
+		if event.Dur != wantDurations[i].Microseconds() {
+			t.Fatalf("event %d dur = %d, want %d", i, event.Dur, wantDurations[i].Microseconds())
+		}
+	}
+	if trace.TraceEvents[2].Tid != 2 {
+		t.Fatalf("check event tid = %d, want iteration 2", trace.TraceEvents[2].Tid)
+	}
+}
+
+func TestTimingProfileUnknownRun(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	sqlDB, err := Open(ctx, filepath.Join(t.TempDir(), "norma.db"))
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	t.Cleanup(func() { _ = sqlDB.Close() })
+
+	spans, err := NewStore(sqlDB).TimingProfile(ctx, "missing")
+	if err != nil || len(spans) != 0 {
+		t.Fatalf("TimingProfile(missing) = %v, %v, want no spans", spans, err)
+	}
+}
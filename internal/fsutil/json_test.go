@@ -0,0 +1,103 @@
+package fsutil
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestWriteJSONAtomicFailureKeepsPreviousFile(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "input.json")
+	if err := WriteJSONAtomic(path, map[string]string{"step": "1"}); err != nil {
+		t.Fatalf("WriteJSONAtomic() error = %v", err)
+	}
+	before, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read input.json: %v", err)
+	}
+
+	if err := WriteJSONAtomic(path, map[string]any{"bad": make(chan int)}); err == nil {
+		t.Fatal("WriteJSONAtomic(unmarshalable) error = nil, want error")
+	}
+	// A rename onto a directory fails after the temp file was written.
+	blocked := filepath.Join(dir, "blocked")
+	if err := os.Mkdir(blocked, 0o700); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	if err := WriteJSONAtomic(blocked, map[string]string{"step": "2"}); err == nil {
+		t.Fatal("WriteJSONAtomic(directory) error = nil, want error")
+	}
+
+	after, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read input.json: %v", err)
+	}
+	if string(after) != string(before) {
+		t.Fatalf("input.json = %s, want unchanged %s", after, before)
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("read dir: %v", err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("dir entries = %v, want no leftover temp files", entries)
+	}
+}
+
+func TestWriteJSONAtomicReadersNeverSeePartialFile(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "output.json")
+	payloads := []map[string]string{
+		{"status": "ok", "text": strings.Repeat("a", 64<<10)},
+		{"status": "stop", "text": strings.Repeat("b", 128<<10)},
+	}
+	if err := WriteJSONAtomic(path, payloads[0]); err != nil {
+		t.Fatalf("WriteJSONAtomic() error = %v", err)
+	}
+
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		defer close(done)
+		for i := range 200 {
+			if err := WriteJSONAtomic(path, payloads[i%2]); err != nil {
+				t.Errorf("WriteJSONAtomic() error = %v", err)
+				return
+			}
+		}
+	}()
+
+	for reader := 0; reader < 4; reader++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-done:
+					return
+				default:
+				}
+				data, err := os.ReadFile(path)
+				if err != nil {
+					t.Errorf("read output.json: %v", err)
+					return
+				}
+				var got map[string]string
+				if err := json.Unmarshal(data, &got); err != nil {
+					t.Errorf("reader saw partial output.json (%d bytes): %v", len(data), err)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
@@ -0,0 +1,34 @@
+package git
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// ApplyBranchNotAllowedError reports a base branch that is not in the apply allowlist.
+type ApplyBranchNotAllowedError struct {
+	Branch  string
+	Allowed []string
+}
+
+func (e *ApplyBranchNotAllowedError) Error() string {
+	return fmt.Sprintf("refusing to apply changes to branch %q: allowed branches are %s", e.Branch, strings.Join(e.Allowed, ", "))
+}
+
+// CheckApplyBranch verifies that the branch checked out in repoRoot may receive
+// task changes. An empty allowed list permits every branch.
+func CheckApplyBranch(ctx context.Context, repoRoot string, allowed []string) error {
+	if len(allowed) == 0 {
+		return nil
+	}
+	branch, err := CurrentBranch(ctx, repoRoot)
+	if err != nil {
+		return err
+	}
+	if slices.Contains(allowed, branch) {
+		return nil
+	}
+	return &ApplyBranchNotAllowedError{Branch: branch, Allowed: slices.Clone(allowed)}
+}
@@ -0,0 +1,30 @@
+package git
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestCheckApplyBranch(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	repo := newTaskRepo(t, ctx)
+
+	if err := CheckApplyBranch(ctx, repo, nil); err != nil {
+		t.Fatalf("CheckApplyBranch(no allowlist) error = %v", err)
+	}
+	if err := CheckApplyBranch(ctx, repo, []string{"main", "master"}); err != nil {
+		t.Fatalf("CheckApplyBranch(allowed) error = %v", err)
+	}
+
+	err := CheckApplyBranch(ctx, repo, []string{"main"})
+	var notAllowed *ApplyBranchNotAllowedError
+	if !errors.As(err, &notAllowed) {
+		t.Fatalf("CheckApplyBranch(disallowed) error = %v, want ApplyBranchNotAllowedError", err)
+	}
+	if notAllowed.Branch != "master" {
+		t.Fatalf("Branch = %q, want master", notAllowed.Branch)
+	}
+}
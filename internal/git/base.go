@@ -0,0 +1,69 @@
+package git
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Policies for a base branch that received commits while a run was in progress.
+const (
+	// OnBaseMovedProceed applies the task branch as is (default).
+	OnBaseMovedProceed = "proceed"
+	// OnBaseMovedAbort refuses to apply the task branch.
+	OnBaseMovedAbort = "abort"
+	// OnBaseMovedRebase rebases the task branch onto the new base before applying it.
+	OnBaseMovedRebase = "rebase"
+)
+
+// ErrBaseMoved reports a base branch whose HEAD changed since the run started.
+var ErrBaseMoved = errors.New("base branch moved during run")
+
+// NormalizeOnBaseMoved returns the policy to use for a configured value.
+// An empty value selects OnBaseMovedProceed.
+func NormalizeOnBaseMoved(policy string) (string, error) {
+	switch p := strings.ToLower(strings.TrimSpace(policy)); p {
+	case "":
+		return OnBaseMovedProceed, nil
+	case OnBaseMovedProceed, OnBaseMovedAbort, OnBaseMovedRebase:
+		return p, nil
+	default:
+		return "", fmt.Errorf("unsupported on_base_moved policy %q", policy)
+	}
+}
+
+// BaseHeadMoved reports whether branch no longer points at recordedHash.
+func BaseHeadMoved(ctx context.Context, repoRoot, branch, recordedHash string) (bool, error) {
+	out, err := GitRunCmdOutput(ctx, repoRoot, "git", "rev-parse", "--verify", branch+"^{commit}")
+	if err != nil {
+		return false, fmt.Errorf("resolve %s HEAD: %w", branch, err)
+	}
+	return strings.TrimSpace(out) != strings.TrimSpace(recordedHash), nil
+}
+
+// RebaseBranch rebases branch onto onto in a temporary worktree, leaving the
+// checked out branch in repoRoot untouched. A conflicting rebase is aborted.
+func RebaseBranch(ctx context.Context, repoRoot, branch, onto string) (err error) {
+	tmpDir, err := os.MkdirTemp("", "norma-rebase-*")
+	if err != nil {
+		return fmt.Errorf("create rebase worktree dir: %w", err)
+	}
+	defer func() { _ = os.RemoveAll(tmpDir) }()
+
+	if err := GitRunCmdErr(ctx, repoRoot, "git", "worktree", "add", tmpDir, branch); err != nil {
+		return fmt.Errorf("add rebase worktree for %s: %w", branch, err)
+	}
+	defer func() {
+		if rmErr := GitRunCmdErr(ctx, repoRoot, "git", "worktree", "remove", "--force", tmpDir); rmErr != nil && err == nil {
+			err = fmt.Errorf("remove rebase worktree: %w", rmErr)
+		}
+	}()
+
+	if err := GitRunCmdErr(ctx, tmpDir, "git", "rebase", onto); err != nil {
+		_ = GitRunCmdErr(ctx, tmpDir, "git", "rebase", "--abort")
+		return fmt.Errorf("rebase %s onto %s: %w", branch, onto, err)
+	}
+	return nil
+}
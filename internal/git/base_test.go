@@ -0,0 +1,46 @@
+package git
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestBaseHeadMoved(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	repo := newTaskRepo(t, ctx)
+	head := strings.TrimSpace(runTestGit(t, ctx, repo, "rev-parse", "HEAD"))
+
+	moved, err := BaseHeadMoved(ctx, repo, "master", head)
+	if err != nil || moved {
+		t.Fatalf("BaseHeadMoved() = %v, %v; want false, nil", moved, err)
+	}
+
+	runTestGit(t, ctx, repo, "commit", "--allow-empty", "-m", "chore: developer change")
+	moved, err = BaseHeadMoved(ctx, repo, "master", head)
+	if err != nil || !moved {
+		t.Fatalf("BaseHeadMoved() = %v, %v; want true, nil", moved, err)
+	}
+}
+
+func TestRebaseBranchKeepsCheckedOutBranch(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	repo := newTaskRepo(t, ctx)
+	runTestGit(t, ctx, repo, "commit", "--allow-empty", "-m", "chore: developer change")
+	baseHead := strings.TrimSpace(runTestGit(t, ctx, repo, "rev-parse", "HEAD"))
+
+	if err := RebaseBranch(ctx, repo, "norma/task/norma-1", "master"); err != nil {
+		t.Fatalf("RebaseBranch() error = %v", err)
+	}
+	if branch := strings.TrimSpace(runTestGit(t, ctx, repo, "rev-parse", "--abbrev-ref", "HEAD")); branch != "master" {
+		t.Fatalf("checked out branch = %q, want master", branch)
+	}
+	runTestGit(t, ctx, repo, "merge-base", "--is-ancestor", baseHead, "norma/task/norma-1")
+	if worktrees := runTestGit(t, ctx, repo, "worktree", "list"); strings.Count(worktrees, "\n") != 1 {
+		t.Fatalf("worktrees left behind:\n%s", worktrees)
+	}
+}
@@ -0,0 +1,43 @@
+package git
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// FileChange is one entry of `git diff --name-status`.
+type FileChange struct {
+	Status  string `json:"status"`
+	Path    string `json:"path"`
+	OldPath string `json:"old_path,omitempty"`
+}
+
+// WorkspaceChanges stages all changes in dir and lists them relative to baseRef.
+// Untracked files are included because they are staged first.
+func WorkspaceChanges(ctx context.Context, dir, baseRef string) ([]FileChange, error) {
+	if err := GitRunCmdErr(ctx, dir, "git", "add", "-A"); err != nil {
+		return nil, fmt.Errorf("stage workspace changes: %w", err)
+	}
+	out, err := GitRunCmdOutput(ctx, dir, "git", "diff", "--cached", "--name-status", "-M", baseRef)
+	if err != nil {
+		return nil, fmt.Errorf("diff workspace against %s: %w", baseRef, err)
+	}
+	return parseNameStatus(out), nil
+}
+
+func parseNameStatus(out string) []FileChange {
+	changes := make([]FileChange, 0)
+	for _, line := range strings.Split(out, "\n") {
+		fields := strings.Split(strings.TrimRight(line, "\r"), "\t")
+		if len(fields) < 2 || fields[0] == "" {
+			continue
+		}
+		change := FileChange{Status: fields[0][:1], Path: fields[len(fields)-1]}
+		if len(fields) == 3 {
+			change.OldPath = fields[1]
+		}
+		changes = append(changes, change)
+	}
+	return changes
+}
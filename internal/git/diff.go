@@ -0,0 +1,42 @@
+package git
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// BranchHead returns the commit branch points at.
+func BranchHead(ctx context.Context, repoRoot, branch string) (string, error) {
+	out, err := GitRunCmdOutput(ctx, repoRoot, "git", "rev-parse", "--verify", branch+"^{commit}")
+	if err != nil {
+		return "", fmt.Errorf("resolve %s HEAD: %w", branch, err)
+	}
+	return strings.TrimSpace(out), nil
+}
+
+// IterationDiff returns the patch from fromHash to toHash on branch.
+// An empty toHash diffs up to the branch tip. Both commits must be reachable from branch.
+func IterationDiff(ctx context.Context, repoRoot, branch, fromHash, toHash string) ([]byte, error) {
+	if toHash == "" {
+		toHash = branch
+	}
+	for _, hash := range []string{fromHash, toHash} {
+		if err := GitRunCmdErr(ctx, repoRoot, "git", "merge-base", "--is-ancestor", hash, branch); err != nil {
+			return nil, fmt.Errorf("%s is not on branch %s: %w", shortCommit(hash), branch, err)
+		}
+	}
+
+	// Read stdout alone so git warnings on stderr cannot end up in the patch.
+	cmd := exec.CommandContext(ctx, "git", "diff", "--binary", fromHash, toHash)
+	cmd.Dir = repoRoot
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("git diff %s..%s: %v: %s", shortCommit(fromHash), shortCommit(toHash), err, strings.TrimSpace(stderr.String()))
+	}
+	return out, nil
+}
@@ -0,0 +1,57 @@
+package git
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestIterationDiff(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	repo := newTaskRepo(t, ctx)
+	branch := "norma/task/norma-1"
+	base := strings.TrimSpace(runTestGit(t, ctx, repo, "merge-base", "master", branch))
+	first := strings.TrimSpace(runTestGit(t, ctx, repo, "rev-parse", branch+"~1"))
+	second, err := BranchHead(ctx, repo, branch)
+	if err != nil {
+		t.Fatalf("BranchHead() error = %v", err)
+	}
+
+	patch, err := IterationDiff(ctx, repo, branch, first, second)
+	if err != nil {
+		t.Fatalf("IterationDiff() error = %v", err)
+	}
+	diff := string(patch)
+	if !strings.Contains(diff, "+++ b/c.txt") || !strings.Contains(diff, "+three") {
+		t.Fatalf("second iteration diff = %q, want c.txt added", diff)
+	}
+	if strings.Contains(diff, "a.txt") {
+		t.Fatalf("second iteration diff = %q, want first iteration change left out", diff)
+	}
+
+	tipPatch, err := IterationDiff(ctx, repo, branch, base, "")
+	if err != nil {
+		t.Fatalf("IterationDiff() to tip error = %v", err)
+	}
+	if tip := string(tipPatch); !strings.Contains(tip, "+two") || !strings.Contains(tip, "+three") {
+		t.Fatalf("diff to tip = %q, want both iterations", tip)
+	}
+}
+
+func TestIterationDiffRejectsCommitOffBranch(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	repo := newTaskRepo(t, ctx)
+	writeTestFile(t, filepath.Join(repo, "d.txt"), "elsewhere\n")
+	runTestGit(t, ctx, repo, "add", "-A")
+	runTestGit(t, ctx, repo, "commit", "-m", "chore: on master")
+	other := strings.TrimSpace(runTestGit(t, ctx, repo, "rev-parse", "HEAD"))
+
+	if _, err := IterationDiff(ctx, repo, "norma/task/norma-1", other, ""); err == nil || !strings.Contains(err.Error(), "is not on branch") {
+		t.Fatalf("IterationDiff() error = %v, want commit not on branch", err)
+	}
+}
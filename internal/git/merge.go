@@ -0,0 +1,91 @@
+package git
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// Merge strategies used when applying a task branch to the base branch.
+const (
+	// MergeStrategySquash squashes the task branch into a single commit (default).
+	MergeStrategySquash = "squash"
+	// MergeStrategyMerge creates a merge commit that preserves the task branch history.
+	MergeStrategyMerge = "merge"
+	// MergeStrategyFFOnly fast-forwards the base branch and fails if that is not possible.
+	MergeStrategyFFOnly = "ff-only"
+)
+
+// NormalizeMergeStrategy returns the strategy to use for a configured value.
+// An empty value selects MergeStrategySquash.
+func NormalizeMergeStrategy(strategy string) (string, error) {
+	switch s := strings.ToLower(strings.TrimSpace(strategy)); s {
+	case "":
+		return MergeStrategySquash, nil
+	case MergeStrategySquash, MergeStrategyMerge, MergeStrategyFFOnly:
+		return s, nil
+	default:
+		return "", fmt.Errorf("unsupported merge strategy %q", strategy)
+	}
+}
+
+// MergeBranch merges branch into the branch checked out in repoRoot using strategy.
+// commitMsg is used for squash and merge commits and ignored for fast-forwards.
+// On failure the checked out branch is reset to its previous HEAD.
+// It reports whether HEAD moved.
+func MergeBranch(ctx context.Context, repoRoot, branch, strategy, commitMsg string) (bool, error) {
+	strategy, err := NormalizeMergeStrategy(strategy)
+	if err != nil {
+		return false, err
+	}
+
+	beforeHash, err := GitRunCmdOutput(ctx, repoRoot, "git", "rev-parse", "HEAD")
+	if err != nil {
+		return false, fmt.Errorf("resolve HEAD: %w", err)
+	}
+	beforeHash = strings.TrimSpace(beforeHash)
+
+	rollback := func(step string, err error) (bool, error) {
+		if strategy == MergeStrategyMerge {
+			_ = GitRunCmdErr(ctx, repoRoot, "git", "merge", "--abort")
+		}
+		if resetErr := GitRunCmdErr(ctx, repoRoot, "git", "reset", "--hard", beforeHash); resetErr != nil {
+			return false, fmt.Errorf("%s: %w (rollback failed: %w)", step, err, resetErr)
+		}
+		return false, fmt.Errorf("%s: %w", step, err)
+	}
+
+	switch strategy {
+	case MergeStrategyMerge:
+		if err := GitRunCmdErr(ctx, repoRoot, "git", "merge", "--no-ff", "-m", commitMsg, branch); err != nil {
+			return rollback("git merge --no-ff", err)
+		}
+	case MergeStrategyFFOnly:
+		if err := GitRunCmdErr(ctx, repoRoot, "git", "merge", "--ff-only", branch); err != nil {
+			return rollback("git merge --ff-only", err)
+		}
+	default:
+		if err := GitRunCmdErr(ctx, repoRoot, "git", "merge", "--squash", branch); err != nil {
+			return rollback("git merge --squash", err)
+		}
+		if err := GitRunCmdErr(ctx, repoRoot, "git", "add", "-A"); err != nil {
+			return rollback("git add -A", err)
+		}
+		status, err := GitRunCmdOutput(ctx, repoRoot, "git", "status", "--porcelain")
+		if err != nil {
+			return rollback("git status", err)
+		}
+		if strings.TrimSpace(status) == "" {
+			return false, nil
+		}
+		if err := GitRunCmdErr(ctx, repoRoot, "git", "commit", "-m", commitMsg); err != nil {
+			return rollback("git commit", err)
+		}
+	}
+
+	afterHash, err := GitRunCmdOutput(ctx, repoRoot, "git", "rev-parse", "HEAD")
+	if err != nil {
+		return rollback("resolve HEAD", err)
+	}
+	return strings.TrimSpace(afterHash) != beforeHash, nil
+}
@@ -0,0 +1,164 @@
+package git
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestMergeBranchHistoryShape(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		strategy     string
+		wantParents  int
+		wantTipHead  bool
+		wantAncestor bool
+	}{
+		{strategy: "", wantParents: 1},
+		{strategy: MergeStrategySquash, wantParents: 1},
+		{strategy: MergeStrategyMerge, wantParents: 2, wantAncestor: true},
+		{strategy: MergeStrategyFFOnly, wantParents: 1, wantTipHead: true, wantAncestor: true},
+	}
+
+	for _, tc := range tests {
+		t.Run("strategy_"+tc.strategy, func(t *testing.T) {
+			t.Parallel()
+
+			ctx := context.Background()
+			repo := newTaskRepo(t, ctx)
+			tip := strings.TrimSpace(runTestGit(t, ctx, repo, "rev-parse", "norma/task/norma-1"))
+
+			moved, err := MergeBranch(ctx, repo, "norma/task/norma-1", tc.strategy, "feat: apply")
+			if err != nil {
+				t.Fatalf("MergeBranch() error = %v", err)
+			}
+			if !moved {
+				t.Fatal("MergeBranch() moved = false, want true")
+			}
+
+			parents := strings.Fields(runTestGit(t, ctx, repo, "rev-list", "--parents", "-n", "1", "HEAD"))
+			if got := len(parents) - 1; got != tc.wantParents {
+				t.Fatalf("HEAD parents = %d, want %d", got, tc.wantParents)
+			}
+			head := strings.TrimSpace(runTestGit(t, ctx, repo, "rev-parse", "HEAD"))
+			if (head == tip) != tc.wantTipHead {
+				t.Fatalf("HEAD == branch tip is %v, want %v", head == tip, tc.wantTipHead)
+			}
+			isAncestor := exec.CommandContext(ctx, "git", "-C", repo, "merge-base", "--is-ancestor", tip, "HEAD").Run() == nil
+			if isAncestor != tc.wantAncestor {
+				t.Fatalf("branch tip is ancestor of HEAD = %v, want %v", isAncestor, tc.wantAncestor)
+			}
+			if got := readTestFile(t, filepath.Join(repo, "a.txt")); got != "one\ntwo\n" {
+				t.Fatalf("a.txt = %q, want branch content", got)
+			}
+		})
+	}
+}
+
+func TestMergeBranchFFOnlyRollsBackOnDivergence(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	repo := newTaskRepo(t, ctx)
+	writeTestFile(t, filepath.Join(repo, "b.txt"), "base change\n")
+	runTestGit(t, ctx, repo, "add", "-A")
+	runTestGit(t, ctx, repo, "commit", "-m", "chore: diverge")
+	before := strings.TrimSpace(runTestGit(t, ctx, repo, "rev-parse", "HEAD"))
+
+	moved, err := MergeBranch(ctx, repo, "norma/task/norma-1", MergeStrategyFFOnly, "")
+	if err == nil {
+		t.Fatal("MergeBranch() error = nil, want ff-only failure")
+	}
+	if moved {
+		t.Fatal("MergeBranch() moved = true, want false")
+	}
+	if after := strings.TrimSpace(runTestGit(t, ctx, repo, "rev-parse", "HEAD")); after != before {
+		t.Fatalf("HEAD = %s, want rollback to %s", after, before)
+	}
+	if status := strings.TrimSpace(runTestGit(t, ctx, repo, "status", "--porcelain")); status != "" {
+		t.Fatalf("working tree not clean after rollback:\n%s", status)
+	}
+}
+
+func TestMergeBranchMergeRollsBackOnConflict(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	repo := newTaskRepo(t, ctx)
+	writeTestFile(t, filepath.Join(repo, "a.txt"), "conflict\n")
+	runTestGit(t, ctx, repo, "commit", "-am", "chore: conflicting change")
+	before := strings.TrimSpace(runTestGit(t, ctx, repo, "rev-parse", "HEAD"))
+
+	if _, err := MergeBranch(ctx, repo, "norma/task/norma-1", MergeStrategyMerge, "feat: apply"); err == nil {
+		t.Fatal("MergeBranch() error = nil, want conflict")
+	}
+	if after := strings.TrimSpace(runTestGit(t, ctx, repo, "rev-parse", "HEAD")); after != before {
+		t.Fatalf("HEAD = %s, want rollback to %s", after, before)
+	}
+	if status := strings.TrimSpace(runTestGit(t, ctx, repo, "status", "--porcelain")); status != "" {
+		t.Fatalf("working tree not clean after rollback:\n%s", status)
+	}
+}
+
+func TestNormalizeMergeStrategy(t *testing.T) {
+	t.Parallel()
+
+	if got, err := NormalizeMergeStrategy(" FF-Only "); err != nil || got != MergeStrategyFFOnly {
+		t.Fatalf("NormalizeMergeStrategy(ff-only) = %q, %v", got, err)
+	}
+	if _, err := NormalizeMergeStrategy("rebase"); err == nil {
+		t.Fatal("NormalizeMergeStrategy(rebase) error = nil, want error")
+	}
+}
+
+// newTaskRepo creates a repo on master with a task branch holding two commits.
+func newTaskRepo(t *testing.T, ctx context.Context) string {
+	t.Helper()
+	repo := t.TempDir()
+	runTestGit(t, ctx, repo, "init", "-b", "master")
+	runTestGit(t, ctx, repo, "config", "user.email", "norma@example.com")
+	runTestGit(t, ctx, repo, "config", "user.name", "Norma Test")
+	writeTestFile(t, filepath.Join(repo, "a.txt"), "one\n")
+	runTestGit(t, ctx, repo, "add", "-A")
+	runTestGit(t, ctx, repo, "commit", "-m", "chore: initial")
+
+	runTestGit(t, ctx, repo, "checkout", "-b", "norma/task/norma-1")
+	writeTestFile(t, filepath.Join(repo, "a.txt"), "one\ntwo\n")
+	runTestGit(t, ctx, repo, "commit", "-am", "chore: do step 1")
+	writeTestFile(t, filepath.Join(repo, "c.txt"), "three\n")
+	runTestGit(t, ctx, repo, "add", "-A")
+	runTestGit(t, ctx, repo, "commit", "-m", "chore: do step 2")
+	runTestGit(t, ctx, repo, "checkout", "master")
+	return repo
+}
+
+func runTestGit(t *testing.T, ctx context.Context, dir string, args ...string) string {
+	t.Helper()
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = dir
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("git %s: %v\n%s", strings.Join(args, " "), err, out)
+	}
+	return string(out)
+}
+
+func writeTestFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("write %s: %v", path, err)
+	}
+}
+
+func readTestFile(t *testing.T, path string) string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read %s: %v", path, err)
+	}
+	return string(data)
+}
@@ -0,0 +1,15 @@
+package git
+
+import (
+	"context"
+	"fmt"
+)
+
+// PushBranch pushes branch from repoRoot to the same branch name on remote.
+func PushBranch(ctx context.Context, repoRoot, remote, branch string) error {
+	ref := "refs/heads/" + branch
+	if err := GitRunCmdErr(ctx, repoRoot, "git", "push", remote, ref+":"+ref); err != nil {
+		return fmt.Errorf("push %s to %s: %w", branch, remote, err)
+	}
+	return nil
+}
@@ -0,0 +1,46 @@
+package git
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrResumeBranchDiverged reports a task branch that no longer contains the
+// Do step commits recorded by earlier runs.
+var ErrResumeBranchDiverged = errors.New("task branch diverged from recorded do step commits")
+
+// VerifyResumeBranch checks that branch still contains every commit in expectedCommits.
+// It returns an error wrapping ErrResumeBranchDiverged if the branch is gone or any
+// commit is no longer reachable from it, e.g. after a reset or force-push.
+func VerifyResumeBranch(ctx context.Context, repoRoot, branch string, expectedCommits []string) error {
+	if len(expectedCommits) == 0 {
+		return nil
+	}
+
+	if _, err := GitRunCmdOutput(ctx, repoRoot, "git", "rev-parse", "--verify", "--quiet", branch+"^{commit}"); err != nil {
+		return fmt.Errorf("%w: branch %s not found", ErrResumeBranchDiverged, branch)
+	}
+
+	missing := make([]string, 0)
+	for _, commit := range expectedCommits {
+		if err := GitRunCmdErr(ctx, repoRoot, "git", "merge-base", "--is-ancestor", commit, branch); err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			missing = append(missing, shortCommit(commit))
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("%w: %s is missing %s", ErrResumeBranchDiverged, branch, strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+func shortCommit(commit string) string {
+	if len(commit) > 12 {
+		return commit[:12]
+	}
+	return commit
+}
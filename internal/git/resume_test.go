@@ -0,0 +1,62 @@
+package git
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestVerifyResumeBranch(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	repo := newTaskRepo(t, ctx)
+	branch := "norma/task/norma-1"
+	commits := strings.Fields(runTestGit(t, ctx, repo, "rev-list", "master.."+branch))
+
+	if err := VerifyResumeBranch(ctx, repo, branch, commits); err != nil {
+		t.Fatalf("VerifyResumeBranch() matching error = %v", err)
+	}
+	if err := VerifyResumeBranch(ctx, repo, branch, nil); err != nil {
+		t.Fatalf("VerifyResumeBranch() without commits error = %v", err)
+	}
+}
+
+func TestVerifyResumeBranchDiverged(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	repo := newTaskRepo(t, ctx)
+	branch := "norma/task/norma-1"
+	commits := strings.Fields(runTestGit(t, ctx, repo, "rev-list", "master.."+branch))
+
+	// Rewrite the branch: drop both Do commits and add an unrelated one.
+	runTestGit(t, ctx, repo, "checkout", "-B", branch, "master")
+	writeTestFile(t, filepath.Join(repo, "d.txt"), "rewritten\n")
+	runTestGit(t, ctx, repo, "add", "-A")
+	runTestGit(t, ctx, repo, "commit", "-m", "chore: rewritten")
+	runTestGit(t, ctx, repo, "checkout", "master")
+
+	err := VerifyResumeBranch(ctx, repo, branch, commits)
+	if !errors.Is(err, ErrResumeBranchDiverged) {
+		t.Fatalf("VerifyResumeBranch() error = %v, want ErrResumeBranchDiverged", err)
+	}
+	if !strings.Contains(err.Error(), commits[0][:12]) {
+		t.Fatalf("error = %q, want missing commit %s", err, commits[0][:12])
+	}
+}
+
+func TestVerifyResumeBranchMissingBranch(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	repo := newTaskRepo(t, ctx)
+	commits := strings.Fields(runTestGit(t, ctx, repo, "rev-list", "master..norma/task/norma-1"))
+	runTestGit(t, ctx, repo, "branch", "-D", "norma/task/norma-1")
+
+	if err := VerifyResumeBranch(ctx, repo, "norma/task/norma-1", commits); !errors.Is(err, ErrResumeBranchDiverged) {
+		t.Fatalf("VerifyResumeBranch() error = %v, want ErrResumeBranchDiverged", err)
+	}
+}
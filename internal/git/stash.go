@@ -0,0 +1,131 @@
+package git
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+const (
+	// StashPolicyAuto stashes all local changes, including untracked files, before an apply.
+	StashPolicyAuto = "auto"
+	// StashPolicyRefuse refuses to apply onto a working tree with local changes.
+	StashPolicyRefuse = "refuse"
+	// StashPolicyIgnoreUntracked stashes tracked changes only and leaves untracked files in place.
+	StashPolicyIgnoreUntracked = "ignore-untracked"
+)
+
+// ErrDirtyWorkingTree reports local changes that block an apply under StashPolicyRefuse.
+var ErrDirtyWorkingTree = errors.New("working tree has local changes")
+
+// NormalizeStashPolicy returns the policy to use for a configured value.
+// An empty value selects StashPolicyAuto.
+func NormalizeStashPolicy(policy string) (string, error) {
+	switch p := strings.ToLower(strings.TrimSpace(policy)); p {
+	case "":
+		return StashPolicyAuto, nil
+	case StashPolicyAuto, StashPolicyRefuse, StashPolicyIgnoreUntracked:
+		return p, nil
+	default:
+		return "", fmt.Errorf("unsupported stash_policy %q", policy)
+	}
+}
+
+// StashLocalChanges clears the local changes in repoRoot ahead of an apply
+// according to policy. It returns the stash commit to restore with StashPop,
+// or empty when nothing was stashed.
+func StashLocalChanges(ctx context.Context, repoRoot, policy, message string) (string, error) {
+	policy, err := NormalizeStashPolicy(policy)
+	if err != nil {
+		return "", err
+	}
+	args := []string{"status", "--porcelain"}
+	if policy == StashPolicyIgnoreUntracked {
+		args = append(args, "--untracked-files=no")
+	}
+	dirty, err := GitRunCmdOutput(ctx, repoRoot, "git", args...)
+	if err != nil {
+		return "", fmt.Errorf("git status: %w", err)
+	}
+	dirty = strings.TrimSpace(dirty)
+	if dirty == "" {
+		return "", nil
+	}
+
+	switch policy {
+	case StashPolicyRefuse:
+		return "", fmt.Errorf("%w (stash_policy is refuse); commit, stash, or remove them and apply again:\n%s", ErrDirtyWorkingTree, dirty)
+	case StashPolicyIgnoreUntracked:
+		return stashPush(ctx, repoRoot, message, false)
+	default:
+		return stashPush(ctx, repoRoot, message, true)
+	}
+}
+
+// StashConflictError reports local changes that could not be restored after an
+// apply because popping their stash conflicted. Git keeps the stash entry, so the
+// changes can be recovered from Ref once the working tree is resolved.
+type StashConflictError struct {
+	Ref    string
+	Commit string
+	Err    error
+}
+
+func (e *StashConflictError) Error() string {
+	return fmt.Sprintf("restore stashed local changes: git stash pop conflicted; the changes are kept in %s (%s), resolve the working tree and run `git stash apply %s`: %v",
+		e.Ref, shortCommit(e.Commit), e.Ref, e.Err)
+}
+
+func (e *StashConflictError) Unwrap() error { return e.Err }
+
+// StashPush stashes the local changes in repoRoot, including untracked files,
+// and returns the stash commit.
+func StashPush(ctx context.Context, repoRoot, message string) (string, error) {
+	return stashPush(ctx, repoRoot, message, true)
+}
+
+func stashPush(ctx context.Context, repoRoot, message string, includeUntracked bool) (string, error) {
+	args := []string{"stash", "push", "-m", message}
+	if includeUntracked {
+		args = append(args, "-u")
+	}
+	if err := GitRunCmdErr(ctx, repoRoot, "git", args...); err != nil {
+		return "", fmt.Errorf("git stash push: %w", err)
+	}
+	out, err := GitRunCmdOutput(ctx, repoRoot, "git", "rev-parse", "stash@{0}")
+	if err != nil {
+		return "", fmt.Errorf("resolve stash: %w", err)
+	}
+	return strings.TrimSpace(out), nil
+}
+
+// StashPop restores the stash created by StashPush, popping the entry that points at
+// commit even if other stashes were pushed since. When the pop fails and the stash
+// entry is still present, it returns a *StashConflictError naming the entry.
+func StashPop(ctx context.Context, repoRoot, commit string) error {
+	ref := stashRef(ctx, repoRoot, commit)
+	if ref == "" {
+		return fmt.Errorf("git stash pop: no stash entry for %s", shortCommit(commit))
+	}
+	popErr := GitRunCmdErr(ctx, repoRoot, "git", "stash", "pop", ref)
+	if popErr == nil {
+		return nil
+	}
+	if ref := stashRef(ctx, repoRoot, commit); ref != "" {
+		return &StashConflictError{Ref: ref, Commit: commit, Err: popErr}
+	}
+	return fmt.Errorf("git stash pop: %w", popErr)
+}
+
+// stashRef returns the stash@{n} entry pointing at commit, or empty if there is none.
+func stashRef(ctx context.Context, repoRoot, commit string) string {
+	out := GitRunCmd(ctx, repoRoot, "git", "stash", "list", "--format=%gd %H")
+	for _, line := range strings.Split(out, "\n") {
+		ref, hash, ok := strings.Cut(strings.TrimSpace(line), " ")
+		if ok && hash == commit {
+			return ref
+		}
+	}
+	return ""
+}
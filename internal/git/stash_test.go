@@ -0,0 +1,160 @@
+package git
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestStashPopConflictKeepsStash(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	repo := newTaskRepo(t, ctx)
+	writeTestFile(t, filepath.Join(repo, "a.txt"), "one\nlocal\n")
+
+	stash, err := StashPush(ctx, repo, "norma pre-apply run-1")
+	if err != nil {
+		t.Fatalf("StashPush() error = %v", err)
+	}
+	if _, err := MergeBranch(ctx, repo, "norma/task/norma-1", MergeStrategySquash, "feat: apply"); err != nil {
+		t.Fatalf("MergeBranch() error = %v", err)
+	}
+
+	err = StashPop(ctx, repo, stash)
+	var conflict *StashConflictError
+	if !errors.As(err, &conflict) {
+		t.Fatalf("StashPop() error = %v, want *StashConflictError", err)
+	}
+	if conflict.Ref != "stash@{0}" || conflict.Commit != stash {
+		t.Fatalf("StashConflictError = %+v, want stash@{0} at %s", conflict, stash)
+	}
+	if !strings.Contains(err.Error(), "git stash apply stash@{0}") {
+		t.Fatalf("error %q does not explain how to recover the stash", err)
+	}
+	if list := runTestGit(t, ctx, repo, "stash", "list"); !strings.Contains(list, "norma pre-apply run-1") {
+		t.Fatalf("stash list = %q, want the conflicting stash kept", list)
+	}
+}
+
+func TestStashPopRestoresChanges(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	repo := newTaskRepo(t, ctx)
+	writeTestFile(t, filepath.Join(repo, "local.txt"), "local\n")
+
+	stash, err := StashPush(ctx, repo, "norma pre-apply run-1")
+	if err != nil {
+		t.Fatalf("StashPush() error = %v", err)
+	}
+	if err := StashPop(ctx, repo, stash); err != nil {
+		t.Fatalf("StashPop() error = %v", err)
+	}
+	if list := strings.TrimSpace(runTestGit(t, ctx, repo, "stash", "list")); list != "" {
+		t.Fatalf("stash list = %q, want empty", list)
+	}
+}
+
+func TestStashPopRestoresRecordedStashAfterLaterPush(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	repo := newTaskRepo(t, ctx)
+	writeTestFile(t, filepath.Join(repo, "local.txt"), "local\n")
+
+	stash, err := StashPush(ctx, repo, "norma pre-apply run-1")
+	if err != nil {
+		t.Fatalf("StashPush() error = %v", err)
+	}
+	writeTestFile(t, filepath.Join(repo, "other.txt"), "other\n")
+	runTestGit(t, ctx, repo, "stash", "push", "-u", "-m", "someone else")
+
+	if err := StashPop(ctx, repo, stash); err != nil {
+		t.Fatalf("StashPop() error = %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(repo, "local.txt")); err != nil {
+		t.Fatalf("local.txt not restored: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(repo, "other.txt")); !os.IsNotExist(err) {
+		t.Fatalf("other.txt restored from the later stash: %v", err)
+	}
+	list := strings.TrimSpace(runTestGit(t, ctx, repo, "stash", "list"))
+	if !strings.Contains(list, "someone else") || strings.Contains(list, "norma pre-apply run-1") {
+		t.Fatalf("stash list = %q, want only the later stash left", list)
+	}
+}
+
+func TestStashPopMissingStash(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	repo := newTaskRepo(t, ctx)
+	if err := StashPop(ctx, repo, "0123456789abcdef0123456789abcdef01234567"); err == nil {
+		t.Fatal("StashPop() error = nil, want error for a missing stash")
+	}
+}
+
+func TestStashLocalChangesPolicies(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		policy        string
+		wantErr       error
+		wantStash     bool
+		wantUntracked bool
+		wantModified  bool
+	}{
+		{policy: "", wantStash: true},
+		{policy: StashPolicyAuto, wantStash: true},
+		{policy: StashPolicyRefuse, wantErr: ErrDirtyWorkingTree, wantUntracked: true, wantModified: true},
+		{policy: StashPolicyIgnoreUntracked, wantStash: true, wantUntracked: true},
+	}
+	for _, tc := range tests {
+		t.Run("policy="+tc.policy, func(t *testing.T) {
+			t.Parallel()
+
+			ctx := context.Background()
+			repo := newTaskRepo(t, ctx)
+			writeTestFile(t, filepath.Join(repo, "a.txt"), "one\nlocal\n")
+			writeTestFile(t, filepath.Join(repo, "scratch.txt"), "scratch\n")
+
+			stash, err := StashLocalChanges(ctx, repo, tc.policy, "norma pre-apply run-1")
+			if !errors.Is(err, tc.wantErr) {
+				t.Fatalf("StashLocalChanges() error = %v, want %v", err, tc.wantErr)
+			}
+			if tc.wantErr != nil && !strings.Contains(err.Error(), "a.txt") {
+				t.Fatalf("error %q does not list the changed paths", err)
+			}
+			if got := stash != ""; got != tc.wantStash {
+				t.Fatalf("stash = %q, want stashed %v", stash, tc.wantStash)
+			}
+			status := runTestGit(t, ctx, repo, "status", "--porcelain")
+			if got := strings.Contains(status, "?? scratch.txt"); got != tc.wantUntracked {
+				t.Fatalf("status = %q, want untracked scratch.txt %v", status, tc.wantUntracked)
+			}
+			if got := strings.Contains(status, " M a.txt"); got != tc.wantModified {
+				t.Fatalf("status = %q, want modified a.txt %v", status, tc.wantModified)
+			}
+		})
+	}
+}
+
+func TestStashLocalChangesCleanTree(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	repo := newTaskRepo(t, ctx)
+	for _, policy := range []string{StashPolicyAuto, StashPolicyRefuse, StashPolicyIgnoreUntracked} {
+		stash, err := StashLocalChanges(ctx, repo, policy, "norma pre-apply run-1")
+		if err != nil || stash != "" {
+			t.Fatalf("StashLocalChanges(%s) = %q, %v, want no stash", policy, stash, err)
+		}
+	}
+	if _, err := StashLocalChanges(ctx, repo, "drop", "norma pre-apply run-1"); err == nil {
+		t.Fatal("StashLocalChanges(drop) error = nil, want unsupported policy")
+	}
+}
@@ -3,6 +3,7 @@ package git
 import (
 	"context"
 	"fmt"
+	"path/filepath"
 	"strings"
 
 	"github.com/rs/zerolog/log"
@@ -49,9 +50,19 @@ func MountWorktree(ctx context.Context, repoRoot, workspaceDir, branchName, base
 }
 
 func ForceCleanupStaleWorktree(ctx context.Context, repoRoot, branchName string) {
+	for _, worktree := range BranchWorktrees(ctx, repoRoot, branchName) {
+		log.Warn().Str("branch", branchName).Str("stale_worktree", worktree).Msg("found stale worktree, forcing removal")
+		// Try to remove the worktree
+		_ = GitRunCmdErr(ctx, repoRoot, "git", "worktree", "remove", "--force", worktree)
+	}
+}
+
+// BranchWorktrees returns the paths of worktrees that have branchName checked out.
+func BranchWorktrees(ctx context.Context, repoRoot, branchName string) []string {
 	out := GitRunCmd(ctx, repoRoot, "git", "worktree", "list", "--porcelain")
 	lines := strings.Split(out, "\n")
 	var currentWorktree string
+	var worktrees []string
 	for _, line := range lines {
 		line = strings.TrimSpace(line)
 		if line == "" {
@@ -62,15 +73,52 @@ func ForceCleanupStaleWorktree(ctx context.Context, repoRoot, branchName string)
 		} else if strings.HasPrefix(line, "branch ") {
 			branch := strings.TrimPrefix(line, "branch refs/heads/")
 			if branch == branchName {
-				log.Warn().Str("branch", branchName).Str("stale_worktree", currentWorktree).Msg("found stale worktree, forcing removal")
-				// Try to remove the worktree
-				_ = GitRunCmdErr(ctx, repoRoot, "git", "worktree", "remove", "--force", currentWorktree)
+				worktrees = append(worktrees, currentWorktree)
 			}
 		}
 	}
+	return worktrees
+}
+
+// UnsafeWorktreePathError is returned when a worktree path to remove lies outside
+// the run workspaces under .norma/runs.
+type UnsafeWorktreePathError struct {
+	Path string
+	Root string
+}
+
+func (e *UnsafeWorktreePathError) Error() string {
+	return fmt.Sprintf("refusing to remove worktree %s: not a workspace under %s", e.Path, e.Root)
+}
+
+// CheckWorkspacePath verifies that workspaceDir resolves to a "workspace" directory
+// under repoRoot/.norma/runs, the only place norma mounts step worktrees.
+func CheckWorkspacePath(repoRoot, workspaceDir string) error {
+	root := filepath.Join(resolvePath(repoRoot), ".norma", "runs")
+	path := resolvePath(workspaceDir)
+	rel, err := filepath.Rel(root, path)
+	if err != nil || rel == "." || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) || filepath.Base(path) != "workspace" {
+		return &UnsafeWorktreePathError{Path: path, Root: root}
+	}
+	return nil
+}
+
+// resolvePath returns the absolute path with symlinks resolved when it exists.
+func resolvePath(path string) string {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return filepath.Clean(path)
+	}
+	if resolved, err := filepath.EvalSymlinks(abs); err == nil {
+		return resolved
+	}
+	return abs
 }
 
 func RemoveWorktree(ctx context.Context, repoRoot, workspaceDir string) error {
+	if err := CheckWorkspacePath(repoRoot, workspaceDir); err != nil {
+		return err
+	}
 	// Remove worktree only, keep the branch for restartable progress
 	err := GitRunCmdErr(ctx, repoRoot, "git", "worktree", "remove", "--force", workspaceDir)
 	if err != nil {
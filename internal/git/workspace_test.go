@@ -0,0 +1,68 @@
+package git
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCheckWorkspacePath(t *testing.T) {
+	t.Parallel()
+
+	repo := t.TempDir()
+	tests := []struct {
+		name string
+		path string
+		ok   bool
+	}{
+		{name: "step_workspace", path: filepath.Join(repo, ".norma", "runs", "run-1", "steps", "002-do", "workspace"), ok: true},
+		{name: "run_workspace", path: filepath.Join(repo, ".norma", "runs", "run-1", "workspace"), ok: true},
+		{name: "repo_root", path: repo},
+		{name: "norma_dir", path: filepath.Join(repo, ".norma")},
+		{name: "runs_dir", path: filepath.Join(repo, ".norma", "runs")},
+		{name: "not_a_workspace", path: filepath.Join(repo, ".norma", "runs", "run-1", "steps")},
+		{name: "escapes_runs", path: filepath.Join(repo, ".norma", "runs", "..", "..", "workspace")},
+		{name: "outside_repo", path: filepath.Join(t.TempDir(), "workspace")},
+	}
+
+	for _, tc := range tests {
+		err := CheckWorkspacePath(repo, tc.path)
+		if tc.ok && err != nil {
+			t.Fatalf("%s: CheckWorkspacePath() error = %v, want nil", tc.name, err)
+		}
+		if !tc.ok {
+			var unsafe *UnsafeWorktreePathError
+			if !errors.As(err, &unsafe) {
+				t.Fatalf("%s: CheckWorkspacePath() error = %v, want UnsafeWorktreePathError", tc.name, err)
+			}
+		}
+	}
+}
+
+func TestRemoveWorktreeRefusesPathOutsideNorma(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	repo := newTaskRepo(t, ctx)
+
+	var unsafe *UnsafeWorktreePathError
+	if err := RemoveWorktree(ctx, repo, repo); !errors.As(err, &unsafe) {
+		t.Fatalf("RemoveWorktree(repo root) error = %v, want UnsafeWorktreePathError", err)
+	}
+	if _, err := os.Stat(filepath.Join(repo, "a.txt")); err != nil {
+		t.Fatalf("repo root touched by refused removal: %v", err)
+	}
+
+	workspace := filepath.Join(repo, ".norma", "runs", "run-1", "steps", "001-do", "workspace")
+	if _, err := MountWorktree(ctx, repo, workspace, "norma/task/norma-1", "master"); err != nil {
+		t.Fatalf("MountWorktree() error = %v", err)
+	}
+	if err := RemoveWorktree(ctx, repo, workspace); err != nil {
+		t.Fatalf("RemoveWorktree(workspace) error = %v", err)
+	}
+	if _, err := os.Stat(workspace); !os.IsNotExist(err) {
+		t.Fatalf("workspace still exists after removal: %v", err)
+	}
+}
@@ -31,7 +31,7 @@ func TestRunInsertsMissingStepRecords(t *testing.T) {
 	t.Cleanup(func() { _ = db.Close() })
 
 	store := dbpkg.NewStore(db)
-	if err := store.CreateRun(ctx, runID, "goal", runDir, 1); err != nil {
+	if err := store.CreateRun(ctx, runID, "", "goal", runDir, 1); err != nil {
 		t.Fatalf("create run: %v", err)
 	}
 
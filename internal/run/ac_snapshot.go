@@ -0,0 +1,91 @@
+package run
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/metalagman/norma/internal/db"
+	"github.com/metalagman/norma/internal/task"
+)
+
+// Policies for acceptance criteria that change while a run is in progress.
+const (
+	ACChangeIgnore = "ignore"
+	ACChangeDetect = "detect"
+	ACChangeStop   = "stop"
+)
+
+// ErrACChanged reports that a task's acceptance criteria changed while its run was in progress.
+var ErrACChanged = errors.New("acceptance criteria changed during run; replan_required")
+
+// ACSnapshotStore records and reads the acceptance criteria a run started with.
+type ACSnapshotStore interface {
+	RunEventRecorder
+	SetRunACSnapshot(ctx context.Context, runID, snapshotJSON string) error
+	RunACSnapshot(ctx context.Context, runID string) (string, error)
+}
+
+// SnapshotAcceptanceCriteria records ac on the run as the criteria it is judged against.
+func SnapshotAcceptanceCriteria(ctx context.Context, store ACSnapshotStore, runID string, ac []task.AcceptanceCriterion) error {
+	data, err := json.Marshal(ac)
+	if err != nil {
+		return fmt.Errorf("marshal acceptance criteria snapshot: %w", err)
+	}
+	return store.SetRunACSnapshot(ctx, runID, string(data))
+}
+
+// CheckACChange compares the acceptance criteria snapshot of runID with the current
+// criteria of taskID. On divergence it records an ac_changed event and, under
+// ACChangeStop, returns ErrACChanged. Other policies than detect and stop skip the check.
+func CheckACChange(ctx context.Context, store ACSnapshotStore, tracker task.Tracker, policy, runID, taskID string) error {
+	policy = strings.ToLower(strings.TrimSpace(policy))
+	if policy != ACChangeDetect && policy != ACChangeStop {
+		return nil
+	}
+
+	snapshot, err := store.RunACSnapshot(ctx, runID)
+	if err != nil {
+		return err
+	}
+	if snapshot == "" {
+		return nil
+	}
+	item, err := tracker.Task(ctx, taskID)
+	if err != nil {
+		return fmt.Errorf("read task %s acceptance criteria: %w", taskID, err)
+	}
+	current, err := EnsureAcceptanceCriteria(item.Criteria, item.Goal, false)
+	if err != nil {
+		return err
+	}
+	currentJSON, err := json.Marshal(current)
+	if err != nil {
+		return fmt.Errorf("marshal acceptance criteria: %w", err)
+	}
+	if bytes.Equal(currentJSON, []byte(snapshot)) {
+		return nil
+	}
+
+	data, err := json.Marshal(map[string]json.RawMessage{
+		"snapshot": json.RawMessage(snapshot),
+		"current":  currentJSON,
+	})
+	if err != nil {
+		return fmt.Errorf("marshal acceptance criteria change: %w", err)
+	}
+	event := db.Event{Type: "ac_changed", Message: "task acceptance criteria changed during the run; verdict is against the snapshot", DataJSON: string(data)}
+	if policy == ACChangeStop {
+		event.Message = "task acceptance criteria changed during the run; stopping with replan_required"
+	}
+	if err := store.AddEvent(ctx, runID, event); err != nil {
+		return err
+	}
+	if policy == ACChangeStop {
+		return ErrACChanged
+	}
+	return nil
+}
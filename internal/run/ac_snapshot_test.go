@@ -0,0 +1,98 @@
+package run
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/metalagman/norma/internal/task"
+)
+
+type snapshotStore struct {
+	recordingEventStore
+	snapshot string
+}
+
+func (s *snapshotStore) SetRunACSnapshot(_ context.Context, _ string, snapshotJSON string) error {
+	s.snapshot = snapshotJSON
+	return nil
+}
+
+func (s *snapshotStore) RunACSnapshot(context.Context, string) (string, error) {
+	return s.snapshot, nil
+}
+
+type criteriaTracker struct {
+	task.Tracker
+	item task.Task
+}
+
+func (t *criteriaTracker) Task(context.Context, string) (task.Task, error) {
+	return t.item, nil
+}
+
+func TestCheckACChange(t *testing.T) {
+	t.Parallel()
+
+	original := []task.AcceptanceCriterion{{ID: "AC1", Text: "prints hello"}}
+	changed := []task.AcceptanceCriterion{{ID: "AC1", Text: "prints hello"}, {ID: "AC2", Text: "prints bye"}}
+
+	tests := []struct {
+		name       string
+		policy     string
+		current    []task.AcceptanceCriterion
+		wantErr    error
+		wantEvents int
+	}{
+		{name: "detect unchanged", policy: ACChangeDetect, current: original},
+		{name: "detect changed mid-run", policy: ACChangeDetect, current: changed, wantEvents: 1},
+		{name: "stop changed mid-run", policy: ACChangeStop, current: changed, wantErr: ErrACChanged, wantEvents: 1},
+		{name: "ignore changed mid-run", policy: ACChangeIgnore, current: changed},
+		{name: "default ignores", current: changed},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctx := context.Background()
+			store := &snapshotStore{}
+			if err := SnapshotAcceptanceCriteria(ctx, store, "run-1", original); err != nil {
+				t.Fatalf("SnapshotAcceptanceCriteria() error = %v", err)
+			}
+			tracker := &criteriaTracker{item: task.Task{ID: "norma-a1", Goal: "greet", Criteria: tc.current}}
+
+			err := CheckACChange(ctx, store, tracker, tc.policy, "run-1", "norma-a1")
+			if !errors.Is(err, tc.wantErr) {
+				t.Fatalf("CheckACChange() error = %v, want %v", err, tc.wantErr)
+			}
+			if len(store.events) != tc.wantEvents {
+				t.Fatalf("events = %+v, want %d", store.events, tc.wantEvents)
+			}
+			if tc.wantEvents > 0 && store.events[0].Type != "ac_changed" {
+				t.Fatalf("event type = %q, want ac_changed", store.events[0].Type)
+			}
+		})
+	}
+}
+
+func TestCheckACChangeImplicitGoalCriterion(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	implicit, err := EnsureAcceptanceCriteria(nil, "greet", false)
+	if err != nil {
+		t.Fatalf("EnsureAcceptanceCriteria() error = %v", err)
+	}
+	store := &snapshotStore{}
+	if err := SnapshotAcceptanceCriteria(ctx, store, "run-1", implicit); err != nil {
+		t.Fatalf("SnapshotAcceptanceCriteria() error = %v", err)
+	}
+
+	tracker := &criteriaTracker{item: task.Task{ID: "norma-a1", Goal: "greet"}}
+	if err := CheckACChange(ctx, store, tracker, ACChangeStop, "run-1", "norma-a1"); err != nil {
+		t.Fatalf("CheckACChange() for a task still without criteria error = %v", err)
+	}
+	if len(store.events) != 0 {
+		t.Fatalf("events = %+v, want none", store.events)
+	}
+}
@@ -37,6 +37,10 @@ type AgentBuild struct {
 type AgentOutcome struct {
 	Status  string
 	Verdict *string
+	// PassedRequired counts task acceptance criteria that passed in the final check.
+	PassedRequired int
+	// PassedCriteria lists the task acceptance criteria that passed in the final check.
+	PassedCriteria []task.AcceptanceCriterion
 }
 
 // AgentFactory builds and finalizes ADK agents for task runs.
@@ -0,0 +1,159 @@
+package run
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/metalagman/norma/internal/db"
+	"github.com/metalagman/norma/internal/task"
+	"github.com/rs/zerolog/log"
+)
+
+// StatusAwaitingApproval is the status of a passed run waiting for its approval
+// sentinel before its changes are applied.
+const StatusAwaitingApproval = "awaiting_approval"
+
+// approvalPollInterval is how often AwaitApproval checks for the sentinel.
+const approvalPollInterval = time.Second
+
+// ErrApprovalTimeout is returned when a run was not approved within the configured timeout.
+var ErrApprovalTimeout = errors.New("approval timed out")
+
+// ErrNotAwaitingApproval is returned when approving a run that is not waiting for approval,
+// for example one whose approval already timed out.
+var ErrNotAwaitingApproval = errors.New("run is not awaiting approval")
+
+// RunStatusSetter records a run status change.
+type RunStatusSetter interface {
+	SetRunStatus(ctx context.Context, runID, status, message string) error
+}
+
+// RunStatusGetter reads the status of a run.
+type RunStatusGetter interface {
+	GetRunStatus(ctx context.Context, runID string) (string, error)
+}
+
+// ApprovalStore records the status changes and the outcome event of an approval wait.
+type ApprovalStore interface {
+	RunStatusSetter
+	RunEventRecorder
+}
+
+// ApprovalPath returns the sentinel file approving the changes of runID.
+func ApprovalPath(normaDir, runID string) string {
+	return filepath.Join(normaDir, "approve", runID)
+}
+
+// Approve writes the sentinel that lets a run awaiting approval apply its changes.
+func Approve(normaDir, runID string) error {
+	if runID == "" || runID == "." || runID == ".." || filepath.Base(runID) != runID {
+		return fmt.Errorf("invalid run id %q", runID)
+	}
+	path := ApprovalPath(normaDir, runID)
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return fmt.Errorf("create approval dir: %w", err)
+	}
+	if err := os.WriteFile(path, []byte(time.Now().UTC().Format(time.RFC3339)+"\n"), 0o600); err != nil {
+		return fmt.Errorf("write approval: %w", err)
+	}
+	return nil
+}
+
+// ApproveRun approves runID like Approve after checking in store that the run is
+// still awaiting approval, so approving a run that timed out or finished fails
+// with ErrNotAwaitingApproval instead of leaving a sentinel nobody waits for.
+func ApproveRun(ctx context.Context, store RunStatusGetter, normaDir, runID string) error {
+	status, err := store.GetRunStatus(ctx, runID)
+	if err != nil {
+		return err
+	}
+	switch status {
+	case StatusAwaitingApproval:
+		return Approve(normaDir, runID)
+	case "":
+		return fmt.Errorf("run %s not found", runID)
+	default:
+		return fmt.Errorf("run %s is %s: %w", runID, status, ErrNotAwaitingApproval)
+	}
+}
+
+// AwaitApproval marks runID awaiting approval and waits for its sentinel. A zero
+// timeout waits until ctx is done. On approval the sentinel is removed and the
+// run status reset to status; on timeout or cancellation the run is marked stopped
+// and ErrApprovalTimeout or the context error is returned. Either outcome is
+// recorded as an approval event. store may be nil.
+func AwaitApproval(ctx context.Context, store ApprovalStore, normaDir, runID, status string, timeout time.Duration) error {
+	return awaitApproval(ctx, store, normaDir, runID, status, timeout, approvalPollInterval)
+}
+
+func awaitApproval(ctx context.Context, store ApprovalStore, normaDir, runID, status string, timeout, interval time.Duration) error {
+	setStatus := func(status, message string) {
+		if store == nil {
+			return
+		}
+		if err := store.SetRunStatus(context.WithoutCancel(ctx), runID, status, message); err != nil {
+			log.Warn().Err(err).Str("run_id", runID).Str("status", status).Msg("failed to record run status")
+		}
+		if status == StatusAwaitingApproval {
+			return
+		}
+		if err := store.AddEvent(context.WithoutCancel(ctx), runID, db.Event{Type: "approval", Message: message}); err != nil {
+			log.Warn().Err(err).Str("run_id", runID).Msg("failed to record approval event")
+		}
+	}
+
+	path := ApprovalPath(normaDir, runID)
+	setStatus(StatusAwaitingApproval, "waiting for "+path)
+	log.Info().Str("run_id", runID).Str("sentinel", path).Msg("waiting for approval to apply changes")
+
+	err := waitForFile(ctx, path, timeout, interval)
+	if err != nil {
+		setStatus(StatusStopped, fmt.Sprintf("not approved: %v", err))
+		return err
+	}
+	if rmErr := os.Remove(path); rmErr != nil {
+		log.Warn().Err(rmErr).Str("path", path).Msg("failed to remove approval sentinel")
+	}
+	setStatus(status, "approved")
+	return nil
+}
+
+// StopUnapproved marks taskID stopped in tracker after its run timed out waiting for
+// approval and returns the error reported for it by both norma run and norma loop.
+func StopUnapproved(ctx context.Context, tracker task.Tracker, taskID, runID string) error {
+	if err := tracker.MarkStatus(ctx, taskID, StatusStopped); err != nil {
+		log.Warn().Err(err).Str("task_id", taskID).Msg("failed to mark unapproved task as stopped")
+	}
+	return fmt.Errorf("task %s not approved (run %s): %w", taskID, runID, ErrApprovalTimeout)
+}
+
+// waitForFile polls for path until it exists, timeout (when nonzero) passes, or ctx is done.
+func waitForFile(ctx context.Context, path string, timeout, interval time.Duration) error {
+	var deadline <-chan time.Time
+	if timeout > 0 {
+		timer := time.NewTimer(timeout)
+		defer timer.Stop()
+		deadline = timer.C
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := os.Stat(path); err == nil {
+			return nil
+		} else if !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("check approval: %w", err)
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-deadline:
+			return ErrApprovalTimeout
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,142 @@
+package run
+
+import (
+	"context"
+	"errors"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/metalagman/norma/internal/db"
+)
+
+type recordingStatusStore struct {
+	mu       sync.Mutex
+	statuses []string
+	events   []db.Event
+}
+
+func (s *recordingStatusStore) SetRunStatus(_ context.Context, _, status, _ string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.statuses = append(s.statuses, status)
+	return nil
+}
+
+func (s *recordingStatusStore) AddEvent(_ context.Context, _ string, event db.Event) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.events = append(s.events, event)
+	return nil
+}
+
+func (s *recordingStatusStore) recordedEvents() []db.Event {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]db.Event(nil), s.events...)
+}
+
+func (s *recordingStatusStore) recorded() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.statuses...)
+}
+
+func TestAwaitApprovalGranted(t *testing.T) {
+	normaDir := t.TempDir()
+	store := &recordingStatusStore{}
+
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		if err := Approve(normaDir, "run-1"); err != nil {
+			t.Errorf("Approve() error = %v", err)
+		}
+	}()
+
+	if err := awaitApproval(context.Background(), store, normaDir, "run-1", StatusPassed, 5*time.Second, 5*time.Millisecond); err != nil {
+		t.Fatalf("awaitApproval() error = %v", err)
+	}
+	if got := store.recorded(); len(got) != 2 || got[0] != StatusAwaitingApproval || got[1] != StatusPassed {
+		t.Fatalf("statuses = %v, want [%s %s]", got, StatusAwaitingApproval, StatusPassed)
+	}
+	if events := store.recordedEvents(); len(events) != 1 || events[0].Type != "approval" || events[0].Message != "approved" {
+		t.Fatalf("events = %+v, want one approved event", events)
+	}
+	if _, err := os.Stat(ApprovalPath(normaDir, "run-1")); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("approval sentinel still present: %v", err)
+	}
+}
+
+func TestAwaitApprovalTimesOut(t *testing.T) {
+	normaDir := t.TempDir()
+	store := &recordingStatusStore{}
+
+	err := awaitApproval(context.Background(), store, normaDir, "run-1", StatusPassed, 30*time.Millisecond, 5*time.Millisecond)
+	if !errors.Is(err, ErrApprovalTimeout) {
+		t.Fatalf("awaitApproval() error = %v, want ErrApprovalTimeout", err)
+	}
+	if got := store.recorded(); len(got) != 2 || got[1] != StatusStopped {
+		t.Fatalf("statuses = %v, want run stopped", got)
+	}
+	if events := store.recordedEvents(); len(events) != 1 || events[0].Type != "approval" || !strings.Contains(events[0].Message, ErrApprovalTimeout.Error()) {
+		t.Fatalf("events = %+v, want one approval timeout event", events)
+	}
+}
+
+func TestAwaitApprovalCancelled(t *testing.T) {
+	normaDir := t.TempDir()
+	store := &recordingStatusStore{}
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(20*time.Millisecond, cancel)
+
+	err := awaitApproval(ctx, store, normaDir, "run-1", StatusPassed, 0, 5*time.Millisecond)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("awaitApproval() error = %v, want context.Canceled", err)
+	}
+	if got := store.recorded(); len(got) != 2 || got[1] != StatusStopped {
+		t.Fatalf("statuses = %v, want run stopped", got)
+	}
+}
+
+func TestApproveRejectsInvalidRunID(t *testing.T) {
+	for _, runID := range []string{"", ".", "..", "../run-1", "a/b"} {
+		if err := Approve(t.TempDir(), runID); err == nil {
+			t.Fatalf("Approve(%q) error = nil, want error", runID)
+		}
+	}
+}
+
+type fixedStatusStore string
+
+func (s fixedStatusStore) GetRunStatus(context.Context, string) (string, error) {
+	return string(s), nil
+}
+
+func TestApproveRunChecksRunStatus(t *testing.T) {
+	tests := []struct {
+		status  string
+		wantErr bool
+	}{
+		{status: StatusAwaitingApproval},
+		{status: StatusStopped, wantErr: true},
+		{status: StatusPassed, wantErr: true},
+		{status: "", wantErr: true},
+	}
+	for _, tc := range tests {
+		normaDir := t.TempDir()
+		err := ApproveRun(context.Background(), fixedStatusStore(tc.status), normaDir, "run-1")
+		if (err != nil) != tc.wantErr {
+			t.Fatalf("ApproveRun() with status %q error = %v, wantErr %v", tc.status, err, tc.wantErr)
+		}
+		_, statErr := os.Stat(ApprovalPath(normaDir, "run-1"))
+		if tc.wantErr == (statErr == nil) {
+			t.Fatalf("ApproveRun() with status %q: sentinel present = %v, want %v", tc.status, statErr == nil, !tc.wantErr)
+		}
+	}
+	err := ApproveRun(context.Background(), fixedStatusStore(StatusStopped), t.TempDir(), "run-1")
+	if !errors.Is(err, ErrNotAwaitingApproval) {
+		t.Fatalf("ApproveRun() error = %v, want ErrNotAwaitingApproval", err)
+	}
+}
@@ -0,0 +1,44 @@
+package run
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/metalagman/norma/internal/git"
+	"github.com/rs/zerolog/log"
+)
+
+// HandleBaseMoved applies policy when baseBranch moved away from recordedHead
+// while the run was in progress. An empty recordedHead skips the check.
+func HandleBaseMoved(ctx context.Context, repoRoot, baseBranch, recordedHead, taskBranch, policy string) error {
+	if strings.TrimSpace(recordedHead) == "" {
+		return nil
+	}
+	policy, err := git.NormalizeOnBaseMoved(policy)
+	if err != nil {
+		return err
+	}
+	moved, err := git.BaseHeadMoved(ctx, repoRoot, baseBranch, recordedHead)
+	if err != nil {
+		return err
+	}
+	if !moved {
+		return nil
+	}
+
+	log.Warn().
+		Str("base_branch", baseBranch).
+		Str("recorded_head", recordedHead).
+		Str("policy", policy).
+		Msg("base branch moved during run")
+
+	switch policy {
+	case git.OnBaseMovedAbort:
+		return fmt.Errorf("%w: %s is no longer at %s", git.ErrBaseMoved, baseBranch, recordedHead)
+	case git.OnBaseMovedRebase:
+		return git.RebaseBranch(ctx, repoRoot, taskBranch, baseBranch)
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,88 @@
+package run
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/metalagman/norma/internal/git"
+)
+
+func TestApplyChangesOnBaseMoved(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		policy    string
+		wantErr   error
+		wantApply bool
+	}{
+		{policy: "", wantApply: true},
+		{policy: git.OnBaseMovedProceed, wantApply: true},
+		{policy: git.OnBaseMovedAbort, wantErr: git.ErrBaseMoved},
+		{policy: git.OnBaseMovedRebase, wantApply: true},
+	}
+
+	for _, tc := range tests {
+		name := tc.policy
+		if name == "" {
+			name = "default"
+		}
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			ctx := context.Background()
+			repoRoot := t.TempDir()
+			initGitRepo(t, ctx, repoRoot)
+			runGit(t, ctx, repoRoot, "checkout", "-b", "master")
+			writeFile(t, filepath.Join(repoRoot, "base.txt"), "base\n")
+			runGit(t, ctx, repoRoot, "add", "-A")
+			runGit(t, ctx, repoRoot, "commit", "-m", "chore: initial")
+			baseHead := strings.TrimSpace(runGit(t, ctx, repoRoot, "rev-parse", "HEAD"))
+
+			runGit(t, ctx, repoRoot, "checkout", "-b", "norma/task/norma-mv")
+			writeFile(t, filepath.Join(repoRoot, "task.txt"), "task\n")
+			runGit(t, ctx, repoRoot, "add", "-A")
+			runGit(t, ctx, repoRoot, "commit", "-m", "chore: do step 1")
+			runGit(t, ctx, repoRoot, "checkout", "master")
+
+			// A developer commits to base while the run is in progress.
+			writeFile(t, filepath.Join(repoRoot, "dev.txt"), "dev\n")
+			runGit(t, ctx, repoRoot, "add", "-A")
+			runGit(t, ctx, repoRoot, "commit", "-m", "feat: developer change")
+			movedHead := strings.TrimSpace(runGit(t, ctx, repoRoot, "rev-parse", "HEAD"))
+
+			runner := &Runner{repoRoot: repoRoot, normaDir: t.TempDir()}
+			runner.cfg.Git.OnBaseMoved = tc.policy
+			err := runner.applyChanges(ctx, "run-1", "apply task", "norma-mv", baseHead, nil)
+
+			if tc.wantErr != nil {
+				if !errors.Is(err, tc.wantErr) {
+					t.Fatalf("applyChanges() error = %v, want %v", err, tc.wantErr)
+				}
+				if head := strings.TrimSpace(runGit(t, ctx, repoRoot, "rev-parse", "HEAD")); head != movedHead {
+					t.Fatalf("HEAD = %s, want unchanged %s", head, movedHead)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("applyChanges() error = %v", err)
+			}
+			files := runGit(t, ctx, repoRoot, "ls-files")
+			if !strings.Contains(files, "task.txt") || !strings.Contains(files, "dev.txt") {
+				t.Fatalf("tracked files = %q, want task.txt and dev.txt", files)
+			}
+
+			// Rebase moves the task branch on top of the developer commit.
+			isAncestor := runGitErr(ctx, repoRoot, "merge-base", "--is-ancestor", movedHead, "norma/task/norma-mv") == nil
+			if want := tc.policy == git.OnBaseMovedRebase; isAncestor != want {
+				t.Fatalf("task branch contains moved base = %v, want %v", isAncestor, want)
+			}
+		})
+	}
+}
+
+func runGitErr(ctx context.Context, repoRoot string, args ...string) error {
+	return git.GitRunCmdErr(ctx, repoRoot, "git", args...)
+}
@@ -0,0 +1,79 @@
+package run
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/metalagman/norma/internal/config"
+	"github.com/metalagman/norma/internal/git"
+	"github.com/metalagman/norma/internal/task"
+	"github.com/rs/zerolog/log"
+)
+
+// TaskBranch returns the branch a run works on for taskID.
+// With git.per_run_branches the branch is scoped to runID.
+func TaskBranch(cfg config.GitConfig, taskID, runID string) string {
+	if cfg.PerRunBranches {
+		return task.BranchName(taskID, runID)
+	}
+	return task.BranchName(taskID, "")
+}
+
+// CheckTaskBranchLayout fails when the branches of taskID cannot be created in the
+// current git.per_run_branches mode. Git stores refs as paths, so a shared task branch
+// norma/task/<slug> and run branches norma/task/<slug>/<run-id> cannot coexist.
+func CheckTaskBranchLayout(ctx context.Context, repoRoot string, cfg config.GitConfig, taskID string) error {
+	shared := task.BranchName(taskID, "")
+	out, err := git.GitRunCmdOutput(ctx, repoRoot, "git", "for-each-ref", "--format=%(refname:short)", "refs/heads/"+shared)
+	if err != nil {
+		return fmt.Errorf("list task branches: %w", err)
+	}
+	var runBranches []string
+	sharedExists := false
+	for _, ref := range strings.Fields(out) {
+		if ref == shared {
+			sharedExists = true
+		} else {
+			runBranches = append(runBranches, ref)
+		}
+	}
+	if cfg.PerRunBranches && sharedExists {
+		return fmt.Errorf("git.per_run_branches: task branch %s exists and git cannot create run branches under it; apply or delete it first", shared)
+	}
+	if !cfg.PerRunBranches && len(runBranches) > 0 {
+		return fmt.Errorf("run branches %s exist and git cannot create task branch %s next to them; apply or delete them, or enable git.per_run_branches", strings.Join(runBranches, ", "), shared)
+	}
+	return nil
+}
+
+// MigrateLegacyTaskBranch renames the task branch of taskID from its legacy unslugged
+// name, norma/task/<id>, to task.BranchName when only the legacy branch exists, so runs
+// started before branch names were slugged keep their work and can resume.
+func MigrateLegacyTaskBranch(ctx context.Context, repoRoot, taskID string) error {
+	branch := task.BranchName(taskID, "")
+	legacy := "norma/task/" + taskID
+	if legacy == branch || branchExists(ctx, repoRoot, branch) || !branchExists(ctx, repoRoot, legacy) {
+		return nil
+	}
+	if err := git.GitRunCmdErr(ctx, repoRoot, "git", "branch", "-m", legacy, branch); err != nil {
+		return fmt.Errorf("rename legacy task branch %s to %s: %w", legacy, branch, err)
+	}
+	log.Info().Str("from", legacy).Str("to", branch).Msg("renamed legacy task branch")
+	return nil
+}
+
+func branchExists(ctx context.Context, repoRoot, branch string) bool {
+	return git.GitRunCmdErr(ctx, repoRoot, "git", "rev-parse", "--verify", "--quiet", "refs/heads/"+branch) == nil
+}
+
+// CleanupRunBranch deletes a run-scoped task branch once its changes were applied.
+// Shared task branches are kept for resumption.
+func CleanupRunBranch(ctx context.Context, repoRoot string, cfg config.GitConfig, branch string) {
+	if !cfg.PerRunBranches {
+		return
+	}
+	if err := git.GitRunCmdErr(ctx, repoRoot, "git", "branch", "-D", branch); err != nil {
+		log.Warn().Err(err).Str("branch", branch).Msg("failed to delete run branch")
+	}
+}
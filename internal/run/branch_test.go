@@ -0,0 +1,288 @@
+package run
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/metalagman/norma/internal/config"
+	internaldb "github.com/metalagman/norma/internal/db"
+	"github.com/metalagman/norma/internal/git"
+	"google.golang.org/adk/session"
+)
+
+func TestTaskBranch(t *testing.T) {
+	t.Parallel()
+
+	if got, want := TaskBranch(config.GitConfig{}, "norma-a1", "run-1"), "norma/task/norma-a1"; got != want {
+		t.Fatalf("TaskBranch(shared) = %q, want %q", got, want)
+	}
+	if got, want := TaskBranch(config.GitConfig{PerRunBranches: true}, "norma-a1", "run-1"), "norma/task/norma-a1/run-1"; got != want {
+		t.Fatalf("TaskBranch(per-run) = %q, want %q", got, want)
+	}
+}
+
+func TestPerRunBranchesDoNotCollide(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	repoRoot := t.TempDir()
+	initGitRepo(t, ctx, repoRoot)
+	runGit(t, ctx, repoRoot, "checkout", "-b", "master")
+	writeFile(t, filepath.Join(repoRoot, "base.txt"), "base\n")
+	runGit(t, ctx, repoRoot, "add", "-A")
+	runGit(t, ctx, repoRoot, "commit", "-m", "chore: initial")
+
+	cfg := config.GitConfig{PerRunBranches: true}
+	runIDs := []string{"run-a", "run-b"}
+	dirs := make([]string, len(runIDs))
+	errs := make([]error, len(runIDs))
+
+	var wg sync.WaitGroup
+	for i, runID := range runIDs {
+		dirs[i] = filepath.Join(repoRoot, ".norma", "runs", runID, "workspace")
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, errs[i] = git.MountWorktree(ctx, repoRoot, dirs[i], TaskBranch(cfg, "norma-a1", runID), "master")
+		}()
+	}
+	wg.Wait()
+
+	for i, runID := range runIDs {
+		if errs[i] != nil {
+			t.Fatalf("MountWorktree(%s) error = %v", runID, errs[i])
+		}
+		got := strings.TrimSpace(runGit(t, ctx, dirs[i], "rev-parse", "--abbrev-ref", "HEAD"))
+		if want := TaskBranch(cfg, "norma-a1", runID); got != want {
+			t.Fatalf("worktree %s on branch %q, want %q", runID, got, want)
+		}
+	}
+
+	if err := git.RemoveWorktree(ctx, repoRoot, dirs[0]); err != nil {
+		t.Fatalf("RemoveWorktree(run-a) error = %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dirs[1], "base.txt")); err != nil {
+		t.Fatalf("run-b worktree disturbed by run-a cleanup: %v", err)
+	}
+}
+
+func TestApplyChangesDeletesRunBranch(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	repoRoot := t.TempDir()
+	initGitRepo(t, ctx, repoRoot)
+	runGit(t, ctx, repoRoot, "checkout", "-b", "master")
+	writeFile(t, filepath.Join(repoRoot, "base.txt"), "base\n")
+	runGit(t, ctx, repoRoot, "add", "-A")
+	runGit(t, ctx, repoRoot, "commit", "-m", "chore: initial")
+
+	for _, runID := range []string{"run-a", "run-b"} {
+		runGit(t, ctx, repoRoot, "checkout", "-b", "norma/task/norma-a1/"+runID, "master")
+		writeFile(t, filepath.Join(repoRoot, runID+".txt"), runID+"\n")
+		runGit(t, ctx, repoRoot, "add", "-A")
+		runGit(t, ctx, repoRoot, "commit", "-m", "chore: do step 1")
+	}
+	runGit(t, ctx, repoRoot, "checkout", "master")
+
+	runner := &Runner{repoRoot: repoRoot, normaDir: t.TempDir()}
+	runner.cfg.Git.PerRunBranches = true
+	if err := runner.applyChanges(ctx, "run-a", "apply task", "norma-a1", "", nil); err != nil {
+		t.Fatalf("applyChanges() error = %v", err)
+	}
+
+	if got := readFile(t, filepath.Join(repoRoot, "run-a.txt")); got != "run-a\n" {
+		t.Fatalf("run-a.txt = %q, want run-a changes applied", got)
+	}
+	if _, err := os.Stat(filepath.Join(repoRoot, "run-b.txt")); !os.IsNotExist(err) {
+		t.Fatalf("run-b.txt applied from another run: %v", err)
+	}
+	branches := runGit(t, ctx, repoRoot, "branch", "--list", "norma/task/*")
+	if strings.Contains(branches, "run-a") {
+		t.Fatalf("run-a branch not deleted: %s", branches)
+	}
+	if !strings.Contains(branches, "norma/task/norma-a1/run-b") {
+		t.Fatalf("run-b branch deleted: %s", branches)
+	}
+}
+
+// worktreeFactory mounts the run's branch in a worktree under the run dir and
+// blocks in Build until every expected run has mounted its own.
+type worktreeFactory struct {
+	cfg     config.GitConfig
+	started sync.WaitGroup
+	mu      sync.Mutex
+	mounted map[string]string
+}
+
+func (f *worktreeFactory) Name() string { return "worktree" }
+
+func (f *worktreeFactory) Build(ctx context.Context, meta RunMeta, payload TaskPayload) (AgentBuild, error) {
+	branch := TaskBranch(f.cfg, payload.ID, meta.RunID)
+	dir, err := git.MountWorktree(ctx, meta.GitRoot, filepath.Join(meta.RunDir, "workspace"), branch, meta.BaseBranch)
+	if err != nil {
+		return AgentBuild{}, err
+	}
+	f.mu.Lock()
+	f.mounted[dir] = branch
+	f.mu.Unlock()
+
+	// Both runs must be inside Build at once, which the run lock used to prevent.
+	f.started.Done()
+	done := make(chan struct{})
+	go func() { f.started.Wait(); close(done) }()
+	select {
+	case <-done:
+	case <-time.After(10 * time.Second):
+		return AgentBuild{}, errors.New("runs did not overlap")
+	}
+	return (&fakeFactory{}).Build(ctx, meta, payload)
+}
+
+func (f *worktreeFactory) Finalize(context.Context, RunMeta, TaskPayload, session.Session) (AgentOutcome, error) {
+	return AgentOutcome{Status: StatusStopped}, nil
+}
+
+func TestConcurrentRunsOfSameTask(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	repoRoot := t.TempDir()
+	initGitRepo(t, ctx, repoRoot)
+	runGit(t, ctx, repoRoot, "checkout", "-b", "master")
+	writeFile(t, filepath.Join(repoRoot, ".gitignore"), ".norma/\n")
+	runGit(t, ctx, repoRoot, "add", "-A")
+	runGit(t, ctx, repoRoot, "commit", "-m", "chore: initial")
+
+	db, err := internaldb.Open(ctx, filepath.Join(t.TempDir(), "norma.db"))
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	t.Cleanup(func() { _ = db.Close() })
+
+	cfg := config.Config{Git: config.GitConfig{PerRunBranches: true}}
+	factory := &worktreeFactory{cfg: cfg.Git, mounted: map[string]string{}}
+	factory.started.Add(2)
+	runner, err := NewADKRunner(repoRoot, cfg, internaldb.NewStore(db), &statusTracker{}, factory)
+	if err != nil {
+		t.Fatalf("NewADKRunner() error = %v", err)
+	}
+
+	results := make([]Result, 2)
+	errs := make([]error, 2)
+	var wg sync.WaitGroup
+	for i := range results {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i], errs[i] = runner.Run(ctx, "test goal", nil, "norma-a1")
+		}()
+	}
+	wg.Wait()
+
+	for i, err := range errs {
+		if err != nil {
+			t.Fatalf("Run() #%d error = %v", i, err)
+		}
+	}
+	if results[0].RunID == results[1].RunID {
+		t.Fatalf("both runs got run id %s", results[0].RunID)
+	}
+	if len(factory.mounted) != 2 {
+		t.Fatalf("mounted worktrees = %v, want one per run", factory.mounted)
+	}
+	branches := map[string]bool{}
+	for dir, branch := range factory.mounted {
+		got := strings.TrimSpace(runGit(t, ctx, dir, "rev-parse", "--abbrev-ref", "HEAD"))
+		if got != branch {
+			t.Fatalf("worktree %s on branch %q, want %q", dir, got, branch)
+		}
+		branches[branch] = true
+	}
+	if len(branches) != 2 {
+		t.Fatalf("branches = %v, want one per run", branches)
+	}
+}
+
+func TestCheckTaskBranchLayout(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	repoRoot := t.TempDir()
+	initGitRepo(t, ctx, repoRoot)
+	runGit(t, ctx, repoRoot, "checkout", "-b", "master")
+	writeFile(t, filepath.Join(repoRoot, "base.txt"), "base\n")
+	runGit(t, ctx, repoRoot, "add", "-A")
+	runGit(t, ctx, repoRoot, "commit", "-m", "chore: initial")
+
+	shared := config.GitConfig{}
+	perRun := config.GitConfig{PerRunBranches: true}
+	for _, cfg := range []config.GitConfig{shared, perRun} {
+		if err := CheckTaskBranchLayout(ctx, repoRoot, cfg, "norma-a1"); err != nil {
+			t.Fatalf("CheckTaskBranchLayout(per_run=%v) without branches error = %v", cfg.PerRunBranches, err)
+		}
+	}
+
+	runGit(t, ctx, repoRoot, "branch", "norma/task/norma-a1")
+	if err := CheckTaskBranchLayout(ctx, repoRoot, shared, "norma-a1"); err != nil {
+		t.Fatalf("CheckTaskBranchLayout(shared) error = %v", err)
+	}
+	err := CheckTaskBranchLayout(ctx, repoRoot, perRun, "norma-a1")
+	if err == nil || !strings.Contains(err.Error(), "norma/task/norma-a1 exists") {
+		t.Fatalf("CheckTaskBranchLayout(per-run) error = %v, want the shared branch conflict", err)
+	}
+	// A shared branch of another task with a common prefix does not conflict.
+	if err := CheckTaskBranchLayout(ctx, repoRoot, perRun, "norma-a"); err != nil {
+		t.Fatalf("CheckTaskBranchLayout(per-run, norma-a) error = %v", err)
+	}
+
+	runGit(t, ctx, repoRoot, "branch", "-D", "norma/task/norma-a1")
+	runGit(t, ctx, repoRoot, "branch", "norma/task/norma-a1/run-1")
+	if err := CheckTaskBranchLayout(ctx, repoRoot, perRun, "norma-a1"); err != nil {
+		t.Fatalf("CheckTaskBranchLayout(per-run) with run branches error = %v", err)
+	}
+	err = CheckTaskBranchLayout(ctx, repoRoot, shared, "norma-a1")
+	if err == nil || !strings.Contains(err.Error(), "norma/task/norma-a1/run-1") {
+		t.Fatalf("CheckTaskBranchLayout(shared) error = %v, want the run branch conflict", err)
+	}
+}
+
+func TestMigrateLegacyTaskBranch(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	repoRoot := t.TempDir()
+	initGitRepo(t, ctx, repoRoot)
+	runGit(t, ctx, repoRoot, "checkout", "-b", "master")
+	writeFile(t, filepath.Join(repoRoot, "base.txt"), "base\n")
+	runGit(t, ctx, repoRoot, "add", "-A")
+	runGit(t, ctx, repoRoot, "commit", "-m", "chore: initial")
+
+	runGit(t, ctx, repoRoot, "branch", "norma/task/norma-a1.2")
+	legacyHead := strings.TrimSpace(runGit(t, ctx, repoRoot, "rev-parse", "norma/task/norma-a1.2"))
+
+	if err := MigrateLegacyTaskBranch(ctx, repoRoot, "norma-a1.2"); err != nil {
+		t.Fatalf("MigrateLegacyTaskBranch() error = %v", err)
+	}
+	if got := strings.TrimSpace(runGit(t, ctx, repoRoot, "rev-parse", "norma/task/norma-a1-2")); got != legacyHead {
+		t.Fatalf("slugged branch head = %s, want %s", got, legacyHead)
+	}
+	if branchExists(ctx, repoRoot, "norma/task/norma-a1.2") {
+		t.Fatal("legacy branch still exists after migration")
+	}
+
+	// An existing slugged branch wins over a legacy one, which is left alone.
+	runGit(t, ctx, repoRoot, "branch", "norma/task/norma-a1.2")
+	if err := MigrateLegacyTaskBranch(ctx, repoRoot, "norma-a1.2"); err != nil {
+		t.Fatalf("MigrateLegacyTaskBranch() with both branches error = %v", err)
+	}
+	if !branchExists(ctx, repoRoot, "norma/task/norma-a1.2") {
+		t.Fatal("legacy branch removed although the slugged branch exists")
+	}
+}
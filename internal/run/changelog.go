@@ -0,0 +1,119 @@
+package run
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	"github.com/metalagman/norma/internal/config"
+	"github.com/metalagman/norma/internal/git"
+	"github.com/metalagman/norma/internal/task"
+)
+
+// DefaultChangelogTemplate renders a fragment when changelog.template is unset.
+const DefaultChangelogTemplate = `- {{.Goal}} ({{.TaskID}})
+{{- range .Criteria}}
+  - {{.Text}}
+{{- end}}
+`
+
+// ChangelogEntry is the data a changelog fragment template is rendered with.
+type ChangelogEntry struct {
+	Goal     string
+	TaskID   string
+	RunID    string
+	Criteria []task.AcceptanceCriterion
+}
+
+// RenderChangelogFragment renders entry with tmpl, or DefaultChangelogTemplate when tmpl is empty.
+// The fragment always ends with a newline.
+func RenderChangelogFragment(tmpl string, entry ChangelogEntry) (string, error) {
+	if strings.TrimSpace(tmpl) == "" {
+		tmpl = DefaultChangelogTemplate
+	}
+	t, err := template.New("changelog").Option("missingkey=error").Parse(tmpl)
+	if err != nil {
+		return "", fmt.Errorf("parse changelog template: %w", err)
+	}
+	var buf bytes.Buffer
+	if err := t.Execute(&buf, entry); err != nil {
+		return "", fmt.Errorf("render changelog template: %w", err)
+	}
+	fragment := buf.String()
+	if !strings.HasSuffix(fragment, "\n") {
+		fragment += "\n"
+	}
+	return fragment, nil
+}
+
+// CommitChangelog appends the rendered fragment for entry to cfg.Path and commits it
+// after a run was applied with strategy. Squash and merge applies created their own
+// commit, so the fragment is amended into it; a fast-forward left an agent commit
+// from the task branch at HEAD, so the fragment gets a commit of its own instead of
+// rewriting it. It does nothing when cfg.Path is empty. On failure the changelog
+// file is restored.
+func CommitChangelog(ctx context.Context, repoRoot, strategy string, cfg config.ChangelogConfig, entry ChangelogEntry) error {
+	rel := strings.TrimSpace(cfg.Path)
+	if rel == "" {
+		return nil
+	}
+	strategy, err := git.NormalizeMergeStrategy(strategy)
+	if err != nil {
+		return err
+	}
+	if !filepath.IsLocal(rel) {
+		return fmt.Errorf("changelog path %q must be relative to the repository root", rel)
+	}
+	fragment, err := RenderChangelogFragment(cfg.Template, entry)
+	if err != nil {
+		return err
+	}
+
+	path := filepath.Join(repoRoot, rel)
+	original, err := os.ReadFile(path)
+	existed := err == nil
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("read changelog: %w", err)
+	}
+	restore := func(err error) error {
+		if existed {
+			_ = os.WriteFile(path, original, 0o644)
+		} else {
+			_ = os.Remove(path)
+		}
+		_ = git.GitRunCmdErr(ctx, repoRoot, "git", "reset", "-q", "--", rel)
+		return err
+	}
+
+	content := original
+	if len(content) > 0 && !bytes.HasSuffix(content, []byte("\n")) {
+		content = append(content, '\n')
+	}
+	content = append(content, fragment...)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("create changelog dir: %w", err)
+	}
+	if err := os.WriteFile(path, content, 0o644); err != nil {
+		return restore(fmt.Errorf("write changelog: %w", err))
+	}
+	if err := git.GitRunCmdErr(ctx, repoRoot, "git", "add", "--", rel); err != nil {
+		return restore(fmt.Errorf("stage changelog: %w", err))
+	}
+	if strategy == git.MergeStrategyFFOnly {
+		msg := fmt.Sprintf("docs: add changelog fragment for %s", entry.TaskID)
+		if err := git.GitRunCmdErr(ctx, repoRoot, "git", "commit", "-m", msg); err != nil {
+			return restore(fmt.Errorf("commit changelog: %w", err))
+		}
+		return nil
+	}
+	if err := git.GitRunCmdErr(ctx, repoRoot, "git", "commit", "--amend", "--no-edit"); err != nil {
+		return restore(fmt.Errorf("amend changelog into apply commit: %w", err))
+	}
+	return nil
+}
@@ -0,0 +1,146 @@
+package run
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/metalagman/norma/internal/config"
+	"github.com/metalagman/norma/internal/task"
+)
+
+func TestApplyChangesAppendsChangelogFragment(t *testing.T) {
+	t.Parallel()
+
+	for _, strategy := range []string{"squash", "merge"} {
+		t.Run(strategy, func(t *testing.T) {
+			t.Parallel()
+
+			ctx := context.Background()
+			repoRoot := t.TempDir()
+			initGitRepo(t, ctx, repoRoot)
+			runGit(t, ctx, repoRoot, "checkout", "-b", "master")
+			writeFile(t, filepath.Join(repoRoot, "CHANGELOG.md"), "# Changelog\n")
+			writeFile(t, filepath.Join(repoRoot, "app.txt"), "base\n")
+			runGit(t, ctx, repoRoot, "add", "-A")
+			runGit(t, ctx, repoRoot, "commit", "-m", "chore: initial")
+			before := strings.TrimSpace(runGit(t, ctx, repoRoot, "rev-parse", "HEAD"))
+
+			runGit(t, ctx, repoRoot, "checkout", "-b", "norma/task/norma-cl")
+			writeFile(t, filepath.Join(repoRoot, "app.txt"), "task\n")
+			runGit(t, ctx, repoRoot, "commit", "-am", "chore: do step 1")
+			runGit(t, ctx, repoRoot, "checkout", "master")
+
+			runner := &Runner{repoRoot: repoRoot, normaDir: t.TempDir(), cfg: config.Config{
+				Git:       config.GitConfig{MergeStrategy: strategy},
+				Changelog: config.ChangelogConfig{Path: "CHANGELOG.md"},
+			}}
+			passed := []task.AcceptanceCriterion{{ID: "AC1", Text: "app prints task"}}
+			if err := runner.applyChanges(ctx, "run-1", "Add task output", "norma-cl", "", passed); err != nil {
+				t.Fatalf("applyChanges() error = %v", err)
+			}
+
+			want := "# Changelog\n- Add task output (norma-cl)\n  - app prints task\n"
+			if got := readFile(t, filepath.Join(repoRoot, "CHANGELOG.md")); got != want {
+				t.Fatalf("CHANGELOG.md = %q, want %q", got, want)
+			}
+			if parent := strings.TrimSpace(runGit(t, ctx, repoRoot, "rev-parse", "HEAD^1")); parent != before {
+				t.Fatalf("HEAD^1 = %s, want the apply commit directly on %s", parent, before)
+			}
+			files := runGit(t, ctx, repoRoot, "diff", "--name-only", before, "HEAD")
+			if !strings.Contains(files, "CHANGELOG.md") || !strings.Contains(files, "app.txt") {
+				t.Fatalf("apply commit files = %q, want CHANGELOG.md and app.txt", files)
+			}
+			if status := strings.TrimSpace(runGit(t, ctx, repoRoot, "status", "--porcelain")); status != "" {
+				t.Fatalf("git status = %q, want a clean tree", status)
+			}
+		})
+	}
+}
+
+func TestApplyChangesCommitsChangelogAfterFastForward(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	repoRoot := t.TempDir()
+	initGitRepo(t, ctx, repoRoot)
+	runGit(t, ctx, repoRoot, "checkout", "-b", "master")
+	writeFile(t, filepath.Join(repoRoot, "CHANGELOG.md"), "# Changelog\n")
+	writeFile(t, filepath.Join(repoRoot, "app.txt"), "base\n")
+	runGit(t, ctx, repoRoot, "add", "-A")
+	runGit(t, ctx, repoRoot, "commit", "-m", "chore: initial")
+
+	runGit(t, ctx, repoRoot, "checkout", "-b", "norma/task/norma-cl")
+	writeFile(t, filepath.Join(repoRoot, "app.txt"), "task\n")
+	runGit(t, ctx, repoRoot, "commit", "-am", "chore: do step 1")
+	doCommit := strings.TrimSpace(runGit(t, ctx, repoRoot, "rev-parse", "HEAD"))
+	runGit(t, ctx, repoRoot, "checkout", "master")
+
+	runner := &Runner{repoRoot: repoRoot, normaDir: t.TempDir(), cfg: config.Config{
+		Git:       config.GitConfig{MergeStrategy: "ff-only"},
+		Changelog: config.ChangelogConfig{Path: "CHANGELOG.md"},
+	}}
+	if err := runner.applyChanges(ctx, "run-1", "Add task output", "norma-cl", "", nil); err != nil {
+		t.Fatalf("applyChanges() error = %v", err)
+	}
+
+	// The agent commit stays as it is on the task branch; the fragment is committed on top.
+	if parent := strings.TrimSpace(runGit(t, ctx, repoRoot, "rev-parse", "HEAD^1")); parent != doCommit {
+		t.Fatalf("HEAD^1 = %s, want the task branch commit %s", parent, doCommit)
+	}
+	if files := strings.TrimSpace(runGit(t, ctx, repoRoot, "diff", "--name-only", doCommit, "HEAD")); files != "CHANGELOG.md" {
+		t.Fatalf("changelog commit files = %q, want only CHANGELOG.md", files)
+	}
+	if got := readFile(t, filepath.Join(repoRoot, "CHANGELOG.md")); got != "# Changelog\n- Add task output (norma-cl)\n" {
+		t.Fatalf("CHANGELOG.md = %q", got)
+	}
+}
+
+func TestApplyChangesRollsBackWhenChangelogFails(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	repoRoot := t.TempDir()
+	initGitRepo(t, ctx, repoRoot)
+	runGit(t, ctx, repoRoot, "checkout", "-b", "master")
+	writeFile(t, filepath.Join(repoRoot, "app.txt"), "base\n")
+	runGit(t, ctx, repoRoot, "add", "-A")
+	runGit(t, ctx, repoRoot, "commit", "-m", "chore: initial")
+	before := strings.TrimSpace(runGit(t, ctx, repoRoot, "rev-parse", "HEAD"))
+
+	runGit(t, ctx, repoRoot, "checkout", "-b", "norma/task/norma-cl")
+	writeFile(t, filepath.Join(repoRoot, "app.txt"), "task\n")
+	runGit(t, ctx, repoRoot, "commit", "-am", "chore: do step 1")
+	runGit(t, ctx, repoRoot, "checkout", "master")
+
+	runner := &Runner{repoRoot: repoRoot, normaDir: t.TempDir(), cfg: config.Config{
+		Changelog: config.ChangelogConfig{Path: "CHANGELOG.md", Template: "{{.Missing}}"},
+	}}
+	if err := runner.applyChanges(ctx, "run-1", "Add task output", "norma-cl", "", nil); err == nil {
+		t.Fatal("applyChanges() error = nil, want the changelog failure")
+	}
+	if head := strings.TrimSpace(runGit(t, ctx, repoRoot, "rev-parse", "HEAD")); head != before {
+		t.Fatalf("HEAD = %s, want the apply rolled back to %s", head, before)
+	}
+	if got := readFile(t, filepath.Join(repoRoot, "app.txt")); got != "base\n" {
+		t.Fatalf("app.txt = %q, want the base content", got)
+	}
+}
+
+func TestRenderChangelogFragment(t *testing.T) {
+	t.Parallel()
+
+	entry := ChangelogEntry{Goal: "Fix login", TaskID: "norma-1", RunID: "run-1"}
+	got, err := RenderChangelogFragment("* {{.Goal}} [{{.TaskID}}/{{.RunID}}]", entry)
+	if err != nil {
+		t.Fatalf("RenderChangelogFragment() error = %v", err)
+	}
+	if got != "* Fix login [norma-1/run-1]\n" {
+		t.Fatalf("RenderChangelogFragment() = %q", got)
+	}
+
+	if _, err := RenderChangelogFragment("{{.Missing}}", entry); err == nil {
+		t.Fatalf("RenderChangelogFragment(unknown field) error = nil, want render error")
+	}
+}
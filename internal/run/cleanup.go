@@ -0,0 +1,110 @@
+package run
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/metalagman/norma/internal/git"
+	"github.com/metalagman/norma/internal/task"
+	"github.com/rs/zerolog/log"
+)
+
+// CleanupOption configures CleanupTask.
+type CleanupOption func(*cleanupOptions)
+
+type cleanupOptions struct {
+	deleteBranches bool
+}
+
+// WithDeleteBranches makes CleanupTask delete the task branches as well.
+// Without it the branches are kept so the work can still be inspected or merged by hand.
+func WithDeleteBranches() CleanupOption {
+	return func(o *cleanupOptions) {
+		o.deleteBranches = true
+	}
+}
+
+// CleanupTask abandons taskID: running runs are marked stopped, the worktrees of its
+// task branches are removed, and norma labels and state are cleared from the task,
+// which is left stopped. Agent processes of a run still executing are not signalled.
+// Cleanup continues past individual failures and returns them joined.
+func (r *Runner) CleanupTask(ctx context.Context, taskID string, opts ...CleanupOption) error {
+	if err := task.ValidateID(taskID); err != nil {
+		return err
+	}
+	var o cleanupOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	var errs []error
+	stopped, err := r.store.StopRunningRunsForTask(ctx, taskID, "task cleaned up")
+	if err != nil {
+		errs = append(errs, fmt.Errorf("stop task runs: %w", err))
+	}
+	for _, runID := range stopped {
+		log.Info().Str("task_id", taskID).Str("run_id", runID).Msg("run stopped by task cleanup")
+	}
+
+	branches, err := taskBranches(ctx, r.repoRoot, taskID)
+	if err != nil {
+		errs = append(errs, err)
+	}
+	for _, branch := range branches {
+		for _, worktree := range git.BranchWorktrees(ctx, r.repoRoot, branch) {
+			if err := git.RemoveWorktree(ctx, r.repoRoot, worktree); err != nil {
+				errs = append(errs, fmt.Errorf("remove worktree %s: %w", worktree, err))
+			}
+		}
+	}
+	if err := git.GitRunCmdErr(ctx, r.repoRoot, "git", "worktree", "prune"); err != nil {
+		errs = append(errs, fmt.Errorf("prune worktrees: %w", err))
+	}
+	if o.deleteBranches {
+		for _, branch := range branches {
+			if err := git.GitRunCmdErr(ctx, r.repoRoot, "git", "branch", "-D", branch); err != nil {
+				errs = append(errs, fmt.Errorf("delete branch %s: %w", branch, err))
+			}
+		}
+	}
+
+	if err := clearTaskState(ctx, r.tracker, taskID); err != nil {
+		errs = append(errs, err)
+	}
+	return errors.Join(errs...)
+}
+
+// taskBranches lists the shared and run-scoped task branches of taskID.
+func taskBranches(ctx context.Context, repoRoot, taskID string) ([]string, error) {
+	out, err := git.GitRunCmdOutput(ctx, repoRoot, "git", "for-each-ref", "--format=%(refname:short)", "refs/heads/"+task.BranchName(taskID, ""))
+	if err != nil {
+		return nil, fmt.Errorf("list task branches: %w", err)
+	}
+	return strings.Fields(out), nil
+}
+
+// clearTaskState removes norma labels and the stored run state from taskID and marks it stopped.
+func clearTaskState(ctx context.Context, tracker task.Tracker, taskID string) error {
+	item, err := tracker.Task(ctx, taskID)
+	if err != nil {
+		return fmt.Errorf("load task %s: %w", taskID, err)
+	}
+	var errs []error
+	for _, label := range item.Labels {
+		if !strings.HasPrefix(label, "norma-") {
+			continue
+		}
+		if err := tracker.RemoveLabel(ctx, taskID, label); err != nil {
+			errs = append(errs, fmt.Errorf("remove label %s: %w", label, err))
+		}
+	}
+	if err := tracker.SetNotes(ctx, taskID, ""); err != nil {
+		errs = append(errs, fmt.Errorf("clear task state: %w", err))
+	}
+	if err := tracker.MarkStatus(ctx, taskID, StatusStopped); err != nil {
+		errs = append(errs, fmt.Errorf("mark task stopped: %w", err))
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,118 @@
+package run
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/metalagman/norma/internal/config"
+	internaldb "github.com/metalagman/norma/internal/db"
+	"github.com/metalagman/norma/internal/task"
+)
+
+// cleanupTracker is a task.Tracker fake recording the state CleanupTask clears.
+type cleanupTracker struct {
+	task.Tracker
+	item     task.Task
+	removed  []string
+	notes    *string
+	statuses []string
+}
+
+func (c *cleanupTracker) Task(context.Context, string) (task.Task, error) {
+	return c.item, nil
+}
+
+func (c *cleanupTracker) RemoveLabel(_ context.Context, _ string, label string) error {
+	c.removed = append(c.removed, label)
+	return nil
+}
+
+func (c *cleanupTracker) SetNotes(_ context.Context, _ string, notes string) error {
+	c.notes = &notes
+	return nil
+}
+
+func (c *cleanupTracker) MarkStatus(_ context.Context, _ string, status string) error {
+	c.statuses = append(c.statuses, status)
+	return nil
+}
+
+func TestCleanupTask(t *testing.T) {
+	t.Parallel()
+
+	for _, deleteBranches := range []bool{false, true} {
+		ctx := context.Background()
+		repoRoot := t.TempDir()
+		initGitRepo(t, ctx, repoRoot)
+		runGit(t, ctx, repoRoot, "checkout", "-b", "master")
+		writeFile(t, filepath.Join(repoRoot, ".gitignore"), ".norma/\n")
+		runGit(t, ctx, repoRoot, "add", "-A")
+		runGit(t, ctx, repoRoot, "commit", "-m", "chore: initial")
+		runGit(t, ctx, repoRoot, "branch", "norma/task/norma-other")
+
+		workspace := filepath.Join(repoRoot, ".norma", "runs", "run-1", "steps", "002-do", "workspace")
+		if err := os.MkdirAll(filepath.Dir(workspace), 0o700); err != nil {
+			t.Fatalf("mkdir step dir: %v", err)
+		}
+		runGit(t, ctx, repoRoot, "worktree", "add", "-b", "norma/task/norma-cl", workspace, "master")
+
+		db, err := internaldb.Open(ctx, filepath.Join(t.TempDir(), "norma.db"))
+		if err != nil {
+			t.Fatalf("open db: %v", err)
+		}
+		t.Cleanup(func() { _ = db.Close() })
+		store := internaldb.NewStore(db)
+		for _, run := range []struct{ runID, taskID string }{{"run-1", "norma-cl"}, {"run-2", "norma-other"}} {
+			if err := store.CreateRun(ctx, run.runID, run.taskID, "goal", t.TempDir(), 1); err != nil {
+				t.Fatalf("CreateRun(%s) error = %v", run.runID, err)
+			}
+		}
+
+		tracker := &cleanupTracker{item: task.Task{ID: "norma-cl", Labels: []string{"norma-has-plan", "backend", LabelPartial}}}
+		runner, err := NewADKRunner(repoRoot, config.Config{}, store, tracker, nil)
+		if err != nil {
+			t.Fatalf("NewADKRunner() error = %v", err)
+		}
+
+		var opts []CleanupOption
+		if deleteBranches {
+			opts = append(opts, WithDeleteBranches())
+		}
+		if err := runner.CleanupTask(ctx, "norma-cl", opts...); err != nil {
+			t.Fatalf("CleanupTask(delete_branches=%v) error = %v", deleteBranches, err)
+		}
+
+		for runID, want := range map[string]string{"run-1": StatusStopped, "run-2": "running"} {
+			if got, _ := store.GetRunStatus(ctx, runID); got != want {
+				t.Fatalf("run %s status = %q, want %q", runID, got, want)
+			}
+		}
+		if _, err := os.Stat(workspace); !os.IsNotExist(err) {
+			t.Fatalf("workspace still exists: %v", err)
+		}
+		if worktrees := runGit(t, ctx, repoRoot, "worktree", "list"); strings.Contains(worktrees, "workspace") {
+			t.Fatalf("worktree still registered:\n%s", worktrees)
+		}
+		branches := runGit(t, ctx, repoRoot, "branch", "--list", "norma/task/*")
+		if got := strings.Contains(branches, "norma/task/norma-cl"); got == deleteBranches {
+			t.Fatalf("delete_branches=%v: task branch present = %v", deleteBranches, got)
+		}
+		if !strings.Contains(branches, "norma/task/norma-other") {
+			t.Fatalf("unrelated task branch removed:\n%s", branches)
+		}
+
+		if want := []string{"norma-has-plan", LabelPartial}; !slices.Equal(tracker.removed, want) {
+			t.Fatalf("removed labels = %v, want %v", tracker.removed, want)
+		}
+		if tracker.notes == nil || *tracker.notes != "" {
+			t.Fatalf("task notes were not cleared")
+		}
+		if want := []string{StatusStopped}; !slices.Equal(tracker.statuses, want) {
+			t.Fatalf("task statuses = %v, want %v", tracker.statuses, want)
+		}
+	}
+}
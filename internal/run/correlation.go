@@ -0,0 +1,49 @@
+package run
+
+import (
+	"context"
+
+	"github.com/rs/zerolog"
+)
+
+// CorrelationEnvVar carries a run's correlation ID into agent processes.
+const CorrelationEnvVar = "NORMA_CORRELATION_ID"
+
+// correlationLogField is the log field holding the correlation ID.
+const correlationLogField = "correlation_id"
+
+type correlationKey struct{}
+
+// NewCorrelationID returns a random ID for correlating one run across logs and processes.
+func NewCorrelationID() (string, error) {
+	return randomHex(8)
+}
+
+// WithCorrelationID returns ctx carrying id for agent environments and context loggers.
+func WithCorrelationID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, correlationKey{}, id)
+}
+
+// CorrelationID returns the correlation ID carried by ctx, or "" if there is none.
+func CorrelationID(ctx context.Context) string {
+	id, _ := ctx.Value(correlationKey{}).(string)
+	return id
+}
+
+// CorrelationEnv returns the environment entries exposing the correlation ID in ctx to a child process.
+func CorrelationEnv(ctx context.Context) []string {
+	id := CorrelationID(ctx)
+	if id == "" {
+		return nil
+	}
+	return []string{CorrelationEnvVar + "=" + id}
+}
+
+// ContextLogger returns base with the correlation ID from ctx attached to every line.
+func ContextLogger(ctx context.Context, base zerolog.Logger) zerolog.Logger {
+	id := CorrelationID(ctx)
+	if id == "" {
+		return base
+	}
+	return base.With().Str(correlationLogField, id).Logger()
+}
@@ -0,0 +1,38 @@
+package run
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/rs/zerolog"
+)
+
+func TestCorrelationContext(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	if env := CorrelationEnv(ctx); env != nil {
+		t.Fatalf("CorrelationEnv() without id = %v, want nil", env)
+	}
+
+	id, err := NewCorrelationID()
+	if err != nil {
+		t.Fatalf("NewCorrelationID() error = %v", err)
+	}
+	ctx = WithCorrelationID(ctx, id)
+	if got := CorrelationID(ctx); got != id {
+		t.Fatalf("CorrelationID() = %q, want %q", got, id)
+	}
+	if env := CorrelationEnv(ctx); len(env) != 1 || env[0] != CorrelationEnvVar+"="+id {
+		t.Fatalf("CorrelationEnv() = %v, want %s=%s", env, CorrelationEnvVar, id)
+	}
+
+	var buf bytes.Buffer
+	l := ContextLogger(ctx, zerolog.New(&buf))
+	l.Info().Msg("hello")
+	if !strings.Contains(buf.String(), `"correlation_id":"`+id+`"`) {
+		t.Fatalf("log line = %s, want correlation_id %s", buf.String(), id)
+	}
+}
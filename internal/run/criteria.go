@@ -0,0 +1,35 @@
+package run
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/metalagman/norma/internal/task"
+)
+
+// LabelNeedsAC marks tasks refused because they have no acceptance criteria.
+const LabelNeedsAC = "norma-needs-ac"
+
+// ImplicitGoalCriterionID is the id of the criterion generated for tasks without acceptance criteria.
+const ImplicitGoalCriterionID = "AC-GOAL"
+
+// ErrNoAcceptanceCriteria is returned when a task without acceptance criteria must not run.
+var ErrNoAcceptanceCriteria = errors.New("task has no acceptance criteria")
+
+// EnsureAcceptanceCriteria returns ac unchanged when it is not empty.
+// For an empty list it returns ErrNoAcceptanceCriteria when required is set,
+// and a single implicit "goal achieved" criterion otherwise.
+func EnsureAcceptanceCriteria(ac []task.AcceptanceCriterion, goal string, required bool) ([]task.AcceptanceCriterion, error) {
+	if len(ac) > 0 {
+		return ac, nil
+	}
+	if required {
+		return nil, ErrNoAcceptanceCriteria
+	}
+	text := "The task goal is achieved"
+	if goal = strings.TrimSpace(goal); goal != "" {
+		text = fmt.Sprintf("The task goal is achieved: %s", goal)
+	}
+	return []task.AcceptanceCriterion{{ID: ImplicitGoalCriterionID, Text: text}}, nil
+}
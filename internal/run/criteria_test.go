@@ -0,0 +1,94 @@
+package run
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/metalagman/norma/internal/config"
+	internaldb "github.com/metalagman/norma/internal/db"
+	"github.com/metalagman/norma/internal/task"
+)
+
+func TestEnsureAcceptanceCriteria(t *testing.T) {
+	t.Parallel()
+
+	existing := []task.AcceptanceCriterion{{ID: "AC1", Text: "works"}}
+	got, err := EnsureAcceptanceCriteria(existing, "goal", true)
+	if err != nil || len(got) != 1 || got[0].ID != "AC1" {
+		t.Fatalf("EnsureAcceptanceCriteria(existing) = %v, %v; want unchanged", got, err)
+	}
+
+	if _, err := EnsureAcceptanceCriteria(nil, "goal", true); !errors.Is(err, ErrNoAcceptanceCriteria) {
+		t.Fatalf("EnsureAcceptanceCriteria(required) error = %v, want %v", err, ErrNoAcceptanceCriteria)
+	}
+
+	got, err = EnsureAcceptanceCriteria(nil, "add a flag", false)
+	if err != nil {
+		t.Fatalf("EnsureAcceptanceCriteria(implicit) error = %v", err)
+	}
+	if len(got) != 1 || got[0].ID != ImplicitGoalCriterionID || got[0].Text != "The task goal is achieved: add a flag" {
+		t.Fatalf("EnsureAcceptanceCriteria(implicit) = %+v", got)
+	}
+}
+
+func TestRunTaskWithoutAcceptanceCriteria(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		required  bool
+		wantErr   bool
+		wantLabel bool
+	}{
+		{name: "required refuses", required: true, wantErr: true, wantLabel: true},
+		{name: "implicit criterion", required: false},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctx := context.Background()
+			repoRoot := t.TempDir()
+			initGitRepo(t, ctx, repoRoot)
+			writeFile(t, filepath.Join(repoRoot, "base.txt"), "base\n")
+			runGit(t, ctx, repoRoot, "add", "-A")
+			runGit(t, ctx, repoRoot, "commit", "-m", "chore: initial")
+
+			db, err := internaldb.Open(ctx, filepath.Join(t.TempDir(), "norma.db"))
+			if err != nil {
+				t.Fatalf("Open() error = %v", err)
+			}
+			t.Cleanup(func() { _ = db.Close() })
+
+			tracker := &labelTracker{}
+			factory := &fakeFactory{outcome: AgentOutcome{Status: StatusStopped}}
+			cfg := config.Config{RequireAcceptanceCriteria: tc.required}
+			runner, err := NewADKRunner(repoRoot, cfg, internaldb.NewStore(db), tracker, factory)
+			if err != nil {
+				t.Fatalf("NewADKRunner() error = %v", err)
+			}
+
+			_, err = runner.Run(ctx, "add a flag", nil, "norma-noac")
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("Run() error = %v, wantErr %v", err, tc.wantErr)
+			}
+			if tc.wantErr && !errors.Is(err, ErrNoAcceptanceCriteria) {
+				t.Fatalf("Run() error = %v, want %v", err, ErrNoAcceptanceCriteria)
+			}
+			if got := slices.Contains(tracker.labels, LabelNeedsAC); got != tc.wantLabel {
+				t.Fatalf("labels = %v, want %s present = %v", tracker.labels, LabelNeedsAC, tc.wantLabel)
+			}
+			if tc.wantErr {
+				return
+			}
+			ac := factory.payload.AcceptanceCriteria
+			if len(ac) != 1 || ac[0].ID != ImplicitGoalCriterionID {
+				t.Fatalf("payload acceptance criteria = %+v, want implicit %s", ac, ImplicitGoalCriterionID)
+			}
+		})
+	}
+}
@@ -0,0 +1,47 @@
+package run
+
+import "errors"
+
+// FailureKind classifies why a run did not pass.
+type FailureKind string
+
+// Supported failure kinds.
+const (
+	// FailureInfrastructure marks failures of norma or its environment (git, filesystem, store, tracker).
+	FailureInfrastructure FailureKind = "infrastructure"
+	// FailureAgentError marks agents that could not run or returned an unusable response.
+	FailureAgentError FailureKind = "agent_error"
+	// FailureTaskNotMet marks runs where agents worked but the task was not completed.
+	FailureTaskNotMet FailureKind = "task_not_met"
+)
+
+// FailureError attaches a FailureKind to an error.
+type FailureError struct {
+	Kind FailureKind
+	Err  error
+}
+
+func (e *FailureError) Error() string { return e.Err.Error() }
+
+func (e *FailureError) Unwrap() error { return e.Err }
+
+// WithFailureKind classifies err with kind. Errors that are already classified keep their kind.
+func WithFailureKind(kind FailureKind, err error) error {
+	if err == nil {
+		return nil
+	}
+	var classified *FailureError
+	if errors.As(err, &classified) {
+		return err
+	}
+	return &FailureError{Kind: kind, Err: err}
+}
+
+// FailureKindOf returns the kind attached to err, or fallback if err is not classified.
+func FailureKindOf(err error, fallback FailureKind) FailureKind {
+	var classified *FailureError
+	if errors.As(err, &classified) {
+		return classified.Kind
+	}
+	return fallback
+}
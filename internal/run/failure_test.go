@@ -0,0 +1,290 @@
+package run
+
+import (
+	"context"
+	"errors"
+	"iter"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/metalagman/norma/internal/config"
+	internaldb "github.com/metalagman/norma/internal/db"
+	"github.com/metalagman/norma/internal/task"
+	"google.golang.org/adk/agent"
+	"google.golang.org/adk/session"
+)
+
+type fakeFactory struct {
+	buildErr error
+	agentErr error
+	outcome  AgentOutcome
+	payload  TaskPayload
+	// correlationID is the correlation ID Build saw in its context.
+	correlationID string
+	// onFinalize, when set, is called by Finalize.
+	onFinalize func()
+}
+
+func (f *fakeFactory) Name() string { return "fake" }
+
+func (f *fakeFactory) Build(ctx context.Context, _ RunMeta, payload TaskPayload) (AgentBuild, error) {
+	f.payload = payload
+	f.correlationID = CorrelationID(ctx)
+	if f.buildErr != nil {
+		return AgentBuild{}, f.buildErr
+	}
+	ag, err := agent.New(agent.Config{
+		Name: "fake",
+		Run: func(agent.InvocationContext) iter.Seq2[*session.Event, error] {
+			return func(yield func(*session.Event, error) bool) {
+				if f.agentErr != nil {
+					yield(nil, f.agentErr)
+				}
+			}
+		},
+	})
+	if err != nil {
+		return AgentBuild{}, err
+	}
+	return AgentBuild{Agent: ag}, nil
+}
+
+func (f *fakeFactory) Finalize(context.Context, RunMeta, TaskPayload, session.Session) (AgentOutcome, error) {
+	if f.onFinalize != nil {
+		f.onFinalize()
+	}
+	return f.outcome, nil
+}
+
+type noopTracker struct {
+	task.Tracker
+}
+
+func TestRunClassifiesFailures(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		factory  *fakeFactory
+		wantErr  bool
+		wantKind FailureKind
+	}{
+		{
+			name:     "build error is infrastructure",
+			factory:  &fakeFactory{buildErr: errors.New("compile agent instructions: template not found")},
+			wantErr:  true,
+			wantKind: FailureInfrastructure,
+		},
+		{
+			name:     "agent error",
+			factory:  &fakeFactory{agentErr: errors.New("malformed response")},
+			wantErr:  true,
+			wantKind: FailureAgentError,
+		},
+		{
+			name:     "classified agent error keeps its kind",
+			factory:  &fakeFactory{agentErr: WithFailureKind(FailureInfrastructure, errors.New("write step input"))},
+			wantErr:  true,
+			wantKind: FailureInfrastructure,
+		},
+		{
+			name:     "failed verdict is task not met",
+			factory:  &fakeFactory{outcome: AgentOutcome{Status: StatusFailed}},
+			wantKind: FailureTaskNotMet,
+		},
+		{
+			name:    "stopped run has no failure kind",
+			factory: &fakeFactory{outcome: AgentOutcome{Status: StatusStopped}},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctx := context.Background()
+			repoRoot := t.TempDir()
+			initGitRepo(t, ctx, repoRoot)
+			writeFile(t, filepath.Join(repoRoot, "base.txt"), "base\n")
+			runGit(t, ctx, repoRoot, "add", "-A")
+			runGit(t, ctx, repoRoot, "commit", "-m", "chore: initial")
+
+			db, err := internaldb.Open(ctx, filepath.Join(t.TempDir(), "norma.db"))
+			if err != nil {
+				t.Fatalf("Open() error = %v", err)
+			}
+			t.Cleanup(func() { _ = db.Close() })
+			store := internaldb.NewStore(db)
+
+			runner, err := NewADKRunner(repoRoot, config.Config{}, store, noopTracker{}, tc.factory)
+			if err != nil {
+				t.Fatalf("NewADKRunner() error = %v", err)
+			}
+
+			res, err := runner.Run(ctx, "test goal", nil, "norma-abc")
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("Run() error = %v, wantErr %v", err, tc.wantErr)
+			}
+			if res.FailureKind != tc.wantKind {
+				t.Fatalf("Result.FailureKind = %q, want %q", res.FailureKind, tc.wantKind)
+			}
+			if err != nil {
+				if got := FailureKindOf(err, ""); got != tc.wantKind {
+					t.Fatalf("FailureKindOf(err) = %q, want %q", got, tc.wantKind)
+				}
+			}
+
+			stored, err := store.GetRunFailureKind(ctx, res.RunID)
+			if err != nil {
+				t.Fatalf("GetRunFailureKind() error = %v", err)
+			}
+			if stored != string(tc.wantKind) {
+				t.Fatalf("stored failure kind = %q, want %q", stored, tc.wantKind)
+			}
+
+			correlationID, err := store.RunCorrelationID(ctx, res.RunID)
+			if err != nil {
+				t.Fatalf("RunCorrelationID() error = %v", err)
+			}
+			if correlationID == "" || correlationID != tc.factory.correlationID {
+				t.Fatalf("stored correlation id = %q, want the id passed to the factory %q", correlationID, tc.factory.correlationID)
+			}
+		})
+	}
+}
+
+func TestRunClassifiesFailedApprovalWait(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		// setup prepares repoRoot and returns the hook run when the agent finishes.
+		setup func(t *testing.T, repoRoot string, cancel context.CancelFunc) func()
+	}{
+		{
+			name: "cancelled",
+			setup: func(_ *testing.T, _ string, cancel context.CancelFunc) func() {
+				return cancel
+			},
+		},
+		{
+			name: "sentinel check fails",
+			setup: func(t *testing.T, repoRoot string, _ context.CancelFunc) func() {
+				// A file where the approve directory belongs makes checking the sentinel fail.
+				if err := os.MkdirAll(filepath.Join(repoRoot, ".norma"), 0o700); err != nil {
+					t.Fatalf("create .norma: %v", err)
+				}
+				writeFile(t, filepath.Join(repoRoot, ".norma", "approve"), "not a directory\n")
+				return nil
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			repoRoot := t.TempDir()
+			initGitRepo(t, ctx, repoRoot)
+			writeFile(t, filepath.Join(repoRoot, ".gitignore"), ".norma/\n")
+			runGit(t, ctx, repoRoot, "add", "-A")
+			runGit(t, ctx, repoRoot, "commit", "-m", "chore: initial")
+
+			db, err := internaldb.Open(context.Background(), filepath.Join(t.TempDir(), "norma.db"))
+			if err != nil {
+				t.Fatalf("Open() error = %v", err)
+			}
+			t.Cleanup(func() { _ = db.Close() })
+			store := internaldb.NewStore(db)
+
+			verdict := "PASS"
+			factory := &fakeFactory{outcome: AgentOutcome{Status: StatusPassed, Verdict: &verdict}}
+			factory.onFinalize = tc.setup(t, repoRoot, cancel)
+			cfg := config.Config{RequireApprovalToApply: true, ApprovalTimeout: 60}
+
+			runner, err := NewADKRunner(repoRoot, cfg, store, noopTracker{}, factory)
+			if err != nil {
+				t.Fatalf("NewADKRunner() error = %v", err)
+			}
+
+			res, err := runner.Run(ctx, "test goal", nil, "norma-abc")
+			if err == nil {
+				t.Fatal("Run() error = nil, want the approval wait to fail")
+			}
+			if res.FailureKind != FailureInfrastructure || FailureKindOf(err, "") != FailureInfrastructure {
+				t.Fatalf("failure kind = %q (err %v), want %q", res.FailureKind, err, FailureInfrastructure)
+			}
+			stored, err := store.GetRunFailureKind(context.Background(), res.RunID)
+			if err != nil {
+				t.Fatalf("GetRunFailureKind() error = %v", err)
+			}
+			if stored != string(FailureInfrastructure) {
+				t.Fatalf("stored failure kind = %q, want %q", stored, FailureInfrastructure)
+			}
+		})
+	}
+}
+
+// statusTracker records the statuses a run sets on its task.
+type statusTracker struct {
+	noopTracker
+	statuses []string
+}
+
+func (t *statusTracker) MarkStatus(_ context.Context, _, status string) error {
+	t.statuses = append(t.statuses, status)
+	return nil
+}
+
+func TestRunStopsUnapprovedTask(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	repoRoot := t.TempDir()
+	initGitRepo(t, ctx, repoRoot)
+	writeFile(t, filepath.Join(repoRoot, ".gitignore"), ".norma/\n")
+	runGit(t, ctx, repoRoot, "add", "-A")
+	runGit(t, ctx, repoRoot, "commit", "-m", "chore: initial")
+
+	db, err := internaldb.Open(ctx, filepath.Join(t.TempDir(), "norma.db"))
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	t.Cleanup(func() { _ = db.Close() })
+	store := internaldb.NewStore(db)
+
+	verdict := "PASS"
+	factory := &fakeFactory{outcome: AgentOutcome{Status: StatusPassed, Verdict: &verdict}}
+	tracker := &statusTracker{}
+	cfg := config.Config{RequireApprovalToApply: true, ApprovalTimeout: 1}
+	runner, err := NewADKRunner(repoRoot, cfg, store, tracker, factory)
+	if err != nil {
+		t.Fatalf("NewADKRunner() error = %v", err)
+	}
+
+	res, err := runner.Run(ctx, "test goal", nil, "norma-abc")
+	if !errors.Is(err, ErrApprovalTimeout) {
+		t.Fatalf("Run() error = %v, want ErrApprovalTimeout", err)
+	}
+	if res.Status != StatusStopped || res.FailureKind != "" {
+		t.Fatalf("Result = %+v, want stopped without a failure kind", res)
+	}
+	if len(tracker.statuses) != 1 || tracker.statuses[0] != StatusStopped {
+		t.Fatalf("task statuses = %v, want [%s]", tracker.statuses, StatusStopped)
+	}
+
+	var message string
+	if err := db.QueryRowContext(ctx, `SELECT message FROM events WHERE run_id=? AND type=?`, res.RunID, "approval").Scan(&message); err != nil {
+		t.Fatalf("read approval event: %v", err)
+	}
+	if !strings.Contains(message, ErrApprovalTimeout.Error()) {
+		t.Fatalf("approval event = %q, want the timeout", message)
+	}
+	if err := ApproveRun(ctx, store, filepath.Join(repoRoot, ".norma"), res.RunID); !errors.Is(err, ErrNotAwaitingApproval) {
+		t.Fatalf("ApproveRun() after timeout error = %v, want ErrNotAwaitingApproval", err)
+	}
+}
@@ -2,31 +2,135 @@
 package run
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
 	"syscall"
+	"time"
+
+	"github.com/metalagman/norma/internal/config"
 )
 
 // Lock handles exclusive access to norma loop.
 type Lock struct {
 	f *os.File
+	// exclusive is set while the lock is held exclusively.
+	exclusive bool
+	// shared makes Downgrade turn an exclusive lock into a shared one.
+	shared bool
 }
 
 // AcquireRunLock tries to acquire the run lock.
 func AcquireRunLock(normaDir string) (*Lock, error) {
+	f, err := openLockFile(normaDir, "run.lock")
+	if err != nil {
+		return nil, err
+	}
+	if err := syscall.Flock(int(f.Fd()), syscall.LOCK_EX|syscall.LOCK_NB); err != nil {
+		_ = f.Close()
+		return nil, fmt.Errorf("acquire flock: %w", err)
+	}
+	return &Lock{f: f, exclusive: true}, nil
+}
+
+// AcquireTaskRunLock takes the run lock for a run of a task. Runs on shared task
+// branches take it exclusively, as AcquireRunLock does. With git.per_run_branches
+// every run has its own branch and worktrees, so runs of the same or other tasks
+// share the lock and proceed side by side, while prune, verdict overrides and
+// shared-branch runs still exclude them. When no other run holds the lock it is
+// first taken exclusively, so run-wide maintenance can happen before Downgrade;
+// a run that finds another one in that phase waits up to sharedRunLockWait.
+func AcquireTaskRunLock(ctx context.Context, normaDir string, cfg config.GitConfig) (*Lock, error) {
+	if !cfg.PerRunBranches {
+		return AcquireRunLock(normaDir)
+	}
+	f, err := openLockFile(normaDir, "run.lock")
+	if err != nil {
+		return nil, err
+	}
+	if err := syscall.Flock(int(f.Fd()), syscall.LOCK_EX|syscall.LOCK_NB); err == nil {
+		return &Lock{f: f, exclusive: true, shared: true}, nil
+	}
+	if err := pollFlock(ctx, f, syscall.LOCK_SH, sharedRunLockWait); err != nil {
+		_ = f.Close()
+		return nil, fmt.Errorf("acquire flock: %w", err)
+	}
+	return &Lock{f: f, shared: true}, nil
+}
+
+// sharedRunLockWait bounds how long a per-run-branch run waits for the run lock.
+const sharedRunLockWait = 30 * time.Second
+
+// AcquireApplyLock waits for the lock serialising changes to the base checkout.
+// Runs that share the run lock take it around applying their changes.
+func AcquireApplyLock(ctx context.Context, normaDir string) (*Lock, error) {
+	f, err := openLockFile(normaDir, "apply.lock")
+	if err != nil {
+		return nil, err
+	}
+	if err := pollFlock(ctx, f, syscall.LOCK_EX, 0); err != nil {
+		_ = f.Close()
+		return nil, fmt.Errorf("acquire apply flock: %w", err)
+	}
+	return &Lock{f: f, exclusive: true}, nil
+}
+
+// lockPollInterval is how often a held lock is retried.
+const lockPollInterval = 100 * time.Millisecond
+
+// pollFlock retries a non-blocking flock of how until it succeeds, ctx is done or,
+// with a positive timeout, timeout passes.
+func pollFlock(ctx context.Context, f *os.File, how int, timeout time.Duration) error {
+	var deadline <-chan time.Time
+	if timeout > 0 {
+		timer := time.NewTimer(timeout)
+		defer timer.Stop()
+		deadline = timer.C
+	}
+	for {
+		err := syscall.Flock(int(f.Fd()), how|syscall.LOCK_NB)
+		if err == nil || !errors.Is(err, syscall.EWOULDBLOCK) {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-deadline:
+			return err
+		case <-time.After(lockPollInterval):
+		}
+	}
+}
+
+func openLockFile(normaDir, name string) (*os.File, error) {
 	if err := os.MkdirAll(filepath.Join(normaDir, "locks"), 0o700); err != nil {
 		return nil, fmt.Errorf("create locks dir: %w", err)
 	}
-	f, err := os.OpenFile(filepath.Join(normaDir, "locks", "run.lock"), os.O_CREATE|os.O_RDWR, 0o600)
+	f, err := os.OpenFile(filepath.Join(normaDir, "locks", name), os.O_CREATE|os.O_RDWR, 0o600)
 	if err != nil {
 		return nil, fmt.Errorf("open lock file: %w", err)
 	}
-	if err := syscall.Flock(int(f.Fd()), syscall.LOCK_EX|syscall.LOCK_NB); err != nil {
-		_ = f.Close()
-		return nil, fmt.Errorf("acquire flock: %w", err)
+	return f, nil
+}
+
+// Exclusive reports whether no other run can hold the lock.
+func (l *Lock) Exclusive() bool {
+	return l.exclusive
+}
+
+// Downgrade turns the exclusive lock of a per-run-branch run into a shared one,
+// letting other such runs start. It does nothing for other locks.
+func (l *Lock) Downgrade() error {
+	if !l.shared || !l.exclusive {
+		return nil
+	}
+	if err := syscall.Flock(int(l.f.Fd()), syscall.LOCK_SH); err != nil {
+		return fmt.Errorf("downgrade flock: %w", err)
 	}
-	return &Lock{f: f}, nil
+	l.exclusive = false
+	return nil
 }
 
 // TryAcquireRunLock tries to acquire the run lock without blocking.
@@ -0,0 +1,55 @@
+package run
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/metalagman/norma/internal/task"
+	"github.com/rs/zerolog/log"
+)
+
+// CloseCompletedParents marks the feature or epic above a finished task done once all
+// of its children are done, then repeats for that parent's own parent.
+func CloseCompletedParents(ctx context.Context, tracker task.Tracker, taskID string) error {
+	item, err := tracker.Task(ctx, taskID)
+	if err != nil {
+		return fmt.Errorf("fetch task %s: %w", taskID, err)
+	}
+	return closeCompletedAncestors(ctx, tracker, item.ParentID)
+}
+
+func closeCompletedAncestors(ctx context.Context, tracker task.Tracker, parentID string) error {
+	if strings.TrimSpace(parentID) == "" {
+		return nil
+	}
+
+	parent, err := tracker.Task(ctx, parentID)
+	if err != nil {
+		return fmt.Errorf("fetch parent %s: %w", parentID, err)
+	}
+
+	// Only features and epics are closed on behalf of their children.
+	if parent.Type != "feature" && parent.Type != "epic" {
+		return nil
+	}
+
+	children, err := tracker.Children(ctx, parentID)
+	if err != nil {
+		return fmt.Errorf("list children for parent %s: %w", parentID, err)
+	}
+	if len(children) == 0 {
+		return nil
+	}
+	for _, child := range children {
+		if child.Status != "done" {
+			return nil
+		}
+	}
+
+	log.Info().Str("id", parentID).Str("type", parent.Type).Msg("all children completed, closing parent")
+	if err := tracker.MarkStatus(ctx, parentID, "done"); err != nil {
+		return fmt.Errorf("mark parent %s as done: %w", parentID, err)
+	}
+	return closeCompletedAncestors(ctx, tracker, parent.ParentID)
+}
@@ -0,0 +1,94 @@
+package run
+
+import (
+	"context"
+	"testing"
+
+	"github.com/metalagman/norma/internal/task"
+)
+
+// treeTracker is a task.Tracker fake holding a task hierarchy.
+type treeTracker struct {
+	task.Tracker
+	tasks map[string]task.Task
+}
+
+func newTreeTracker(items ...task.Task) *treeTracker {
+	tr := &treeTracker{tasks: make(map[string]task.Task)}
+	for _, item := range items {
+		tr.tasks[item.ID] = item
+	}
+	return tr
+}
+
+func (t *treeTracker) Task(_ context.Context, id string) (task.Task, error) {
+	item, ok := t.tasks[id]
+	if !ok {
+		return task.Task{}, task.ErrTaskNotFound
+	}
+	return item, nil
+}
+
+func (t *treeTracker) Children(_ context.Context, parentID string) ([]task.Task, error) {
+	var out []task.Task
+	for _, item := range t.tasks {
+		if item.ParentID == parentID {
+			out = append(out, item)
+		}
+	}
+	return out, nil
+}
+
+func (t *treeTracker) MarkStatus(_ context.Context, id, status string) error {
+	item := t.tasks[id]
+	item.Status = status
+	t.tasks[id] = item
+	return nil
+}
+
+func TestCloseCompletedParents(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		siblingDone bool
+		want        map[string]string
+	}{
+		{
+			name:        "last child closes feature and epic",
+			siblingDone: true,
+			want:        map[string]string{"norma-f1": "done", "norma-e1": "done"},
+		},
+		{
+			name: "open sibling keeps parents open",
+			want: map[string]string{"norma-f1": "todo", "norma-e1": "todo"},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			siblingStatus := "todo"
+			if tc.siblingDone {
+				siblingStatus = "done"
+			}
+			tracker := newTreeTracker(
+				task.Task{ID: "norma-e1", Type: "epic", Status: "todo"},
+				task.Task{ID: "norma-f1", Type: "feature", ParentID: "norma-e1", Status: "todo"},
+				task.Task{ID: "norma-f2", Type: "feature", ParentID: "norma-e1", Status: "done"},
+				task.Task{ID: "norma-t1", Type: "task", ParentID: "norma-f1", Status: "done"},
+				task.Task{ID: "norma-t2", Type: "task", ParentID: "norma-f1", Status: siblingStatus},
+			)
+
+			if err := CloseCompletedParents(context.Background(), tracker, "norma-t1"); err != nil {
+				t.Fatalf("CloseCompletedParents() error = %v", err)
+			}
+			for id, want := range tc.want {
+				if got := tracker.tasks[id].Status; got != want {
+					t.Fatalf("%s status = %q, want %q", id, got, want)
+				}
+			}
+		})
+	}
+}
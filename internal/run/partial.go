@@ -0,0 +1,38 @@
+package run
+
+import (
+	"context"
+
+	"github.com/metalagman/norma/internal/config"
+	"github.com/metalagman/norma/internal/task"
+	"github.com/rs/zerolog/log"
+)
+
+// LabelPartial marks tasks whose changes were applied on a PARTIAL verdict.
+const LabelPartial = "norma-partial"
+
+// ShouldApplyPartial reports whether a PARTIAL outcome qualifies for applying changes.
+// At least one required acceptance criterion must pass even if the policy sets no minimum.
+func ShouldApplyPartial(policy config.PartialApplyPolicy, outcome AgentOutcome) bool {
+	if !policy.Enabled || outcome.Verdict == nil || *outcome.Verdict != "PARTIAL" {
+		return false
+	}
+	return outcome.PassedRequired >= max(policy.MinPassedRequired, 1)
+}
+
+// StopPartial ends a run whose changes were applied on a PARTIAL verdict. The run is
+// recorded as stopped rather than failed and the task is labeled norma-partial and
+// marked stopped, so it is neither closed nor counted against failure limits.
+func StopPartial(ctx context.Context, store RunStatusSetter, tracker task.Tracker, taskID, runID string) {
+	if store != nil {
+		if err := store.SetRunStatus(ctx, runID, StatusStopped, "partial changes applied"); err != nil {
+			log.Warn().Err(err).Str("run_id", runID).Msg("failed to record partially applied run")
+		}
+	}
+	if err := tracker.AddLabel(ctx, taskID, LabelPartial); err != nil {
+		log.Warn().Err(err).Str("task_id", taskID).Str("label", LabelPartial).Msg("failed to add label to task")
+	}
+	if err := tracker.MarkStatus(ctx, taskID, StatusStopped); err != nil {
+		log.Warn().Err(err).Str("task_id", taskID).Msg("failed to mark partially applied task as stopped")
+	}
+}
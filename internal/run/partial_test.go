@@ -0,0 +1,140 @@
+package run
+
+import (
+	"context"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/metalagman/norma/internal/config"
+	internaldb "github.com/metalagman/norma/internal/db"
+	"github.com/metalagman/norma/internal/task"
+)
+
+type labelTracker struct {
+	task.Tracker
+	labels   []string
+	statuses []string
+}
+
+func (l *labelTracker) AddLabel(_ context.Context, _ string, label string) error {
+	l.labels = append(l.labels, label)
+	return nil
+}
+
+func (l *labelTracker) MarkStatus(_ context.Context, _ string, status string) error {
+	l.statuses = append(l.statuses, status)
+	return nil
+}
+
+func TestShouldApplyPartial(t *testing.T) {
+	t.Parallel()
+
+	partial := "PARTIAL"
+	pass := "PASS"
+	tests := []struct {
+		name    string
+		policy  config.PartialApplyPolicy
+		outcome AgentOutcome
+		want    bool
+	}{
+		{name: "disabled", policy: config.PartialApplyPolicy{}, outcome: AgentOutcome{Verdict: &partial, PassedRequired: 3}},
+		{name: "enabled default minimum", policy: config.PartialApplyPolicy{Enabled: true}, outcome: AgentOutcome{Verdict: &partial, PassedRequired: 1}, want: true},
+		{name: "nothing passed", policy: config.PartialApplyPolicy{Enabled: true}, outcome: AgentOutcome{Verdict: &partial}},
+		{name: "below minimum", policy: config.PartialApplyPolicy{Enabled: true, MinPassedRequired: 2}, outcome: AgentOutcome{Verdict: &partial, PassedRequired: 1}},
+		{name: "meets minimum", policy: config.PartialApplyPolicy{Enabled: true, MinPassedRequired: 2}, outcome: AgentOutcome{Verdict: &partial, PassedRequired: 2}, want: true},
+		{name: "not partial", policy: config.PartialApplyPolicy{Enabled: true}, outcome: AgentOutcome{Verdict: &pass, PassedRequired: 2}},
+		{name: "no verdict", policy: config.PartialApplyPolicy{Enabled: true}, outcome: AgentOutcome{PassedRequired: 2}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			if got := ShouldApplyPartial(tc.policy, tc.outcome); got != tc.want {
+				t.Fatalf("ShouldApplyPartial() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestRunAppliesChangesOnPartialWhenEnabled(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		enabled    bool
+		status     string
+		wantApply  bool
+		wantStatus string
+	}{
+		{name: "enabled", enabled: true, status: StatusStopped, wantApply: true, wantStatus: StatusStopped},
+		{name: "disabled", enabled: false, status: StatusStopped, wantApply: false, wantStatus: StatusStopped},
+		{name: "enabled after failed outcome", enabled: true, status: StatusFailed, wantApply: true, wantStatus: StatusStopped},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctx := context.Background()
+			repoRoot := t.TempDir()
+			initGitRepo(t, ctx, repoRoot)
+			writeFile(t, filepath.Join(repoRoot, "base.txt"), "base\n")
+			runGit(t, ctx, repoRoot, "add", "-A")
+			runGit(t, ctx, repoRoot, "commit", "-m", "chore: initial")
+			runGit(t, ctx, repoRoot, "checkout", "-b", "norma/task/norma-part")
+			writeFile(t, filepath.Join(repoRoot, "base.txt"), "base\npartial\n")
+			runGit(t, ctx, repoRoot, "commit", "-am", "feat: partial work")
+			runGit(t, ctx, repoRoot, "checkout", "master")
+			// Keep the run directory out of git status so it does not trigger a stash.
+			writeFile(t, filepath.Join(repoRoot, ".git", "info", "exclude"), ".norma/\n")
+
+			db, err := internaldb.Open(ctx, filepath.Join(t.TempDir(), "norma.db"))
+			if err != nil {
+				t.Fatalf("Open() error = %v", err)
+			}
+			t.Cleanup(func() { _ = db.Close() })
+
+			verdict := "PARTIAL"
+			tracker := &labelTracker{}
+			cfg := config.Config{ApplyOnPartial: config.PartialApplyPolicy{Enabled: tc.enabled}}
+			factory := &fakeFactory{outcome: AgentOutcome{Status: tc.status, Verdict: &verdict, PassedRequired: 1}}
+			runner, err := NewADKRunner(repoRoot, cfg, internaldb.NewStore(db), tracker, factory)
+			if err != nil {
+				t.Fatalf("NewADKRunner() error = %v", err)
+			}
+
+			res, err := runner.Run(ctx, "partial goal", nil, "norma-part")
+			if err != nil {
+				t.Fatalf("Run() error = %v", err)
+			}
+			if res.Status != tc.wantStatus || res.FailureKind != "" {
+				t.Fatalf("Result = %+v, want status %q without a failure kind", res, tc.wantStatus)
+			}
+
+			applied := strings.Contains(readFile(t, filepath.Join(repoRoot, "base.txt")), "partial")
+			if applied != tc.wantApply {
+				t.Fatalf("changes applied = %v, want %v", applied, tc.wantApply)
+			}
+			if got := slices.Contains(tracker.labels, LabelPartial); got != tc.wantApply {
+				t.Fatalf("labels = %v, want %s present = %v", tracker.labels, LabelPartial, tc.wantApply)
+			}
+			if slices.Contains(tracker.statuses, "done") {
+				t.Fatalf("statuses = %v, partial run must not close the task", tracker.statuses)
+			}
+			if tc.wantApply {
+				if got := slices.Contains(tracker.statuses, StatusStopped); !got {
+					t.Fatalf("statuses = %v, want the partially applied task stopped", tracker.statuses)
+				}
+				runStatus, err := internaldb.NewStore(db).GetRunStatus(ctx, res.RunID)
+				if err != nil {
+					t.Fatalf("GetRunStatus() error = %v", err)
+				}
+				if runStatus != StatusStopped {
+					t.Fatalf("run status = %q, want %q", runStatus, StatusStopped)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,78 @@
+package run
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/metalagman/norma/internal/config"
+	"github.com/metalagman/norma/internal/git"
+	"github.com/rs/zerolog/log"
+)
+
+// Policies for a task branch whose diff exceeds budgets.max_changed_files or budgets.max_patch_kb.
+const (
+	// PatchOverflowStop refuses to apply the task branch (default).
+	PatchOverflowStop = "stop"
+	// PatchOverflowWarn logs a warning and applies the task branch anyway.
+	PatchOverflowWarn = "warn"
+)
+
+// ErrPatchBudgetExceeded reports a task branch diff larger than the configured budgets.
+var ErrPatchBudgetExceeded = errors.New("patch budget exceeded")
+
+// CheckPatchBudget compares the diff of branch against its merge base with HEAD to
+// budgets.max_changed_files and budgets.max_patch_kb. Zero limits are unlimited.
+// Exceeding a limit returns ErrPatchBudgetExceeded, classified as FailureTaskNotMet,
+// unless budgets.patch_overflow is warn.
+func CheckPatchBudget(ctx context.Context, repoRoot, branch string, budgets config.Budgets) error {
+	if budgets.MaxChangedFiles <= 0 && budgets.MaxPatchKB <= 0 {
+		return nil
+	}
+	policy := strings.ToLower(strings.TrimSpace(budgets.PatchOverflow))
+	switch policy {
+	case "":
+		policy = PatchOverflowStop
+	case PatchOverflowStop, PatchOverflowWarn:
+	default:
+		return fmt.Errorf("unsupported patch_overflow policy %q", budgets.PatchOverflow)
+	}
+
+	diffRange := "HEAD..." + branch
+	names, err := git.GitRunCmdOutput(ctx, repoRoot, "git", "diff", "--name-only", diffRange)
+	if err != nil {
+		return fmt.Errorf("list changed files: %w", err)
+	}
+	changedFiles := 0
+	if names = strings.TrimSpace(names); names != "" {
+		changedFiles = len(strings.Split(names, "\n"))
+	}
+	patch, err := git.GitRunCmdOutput(ctx, repoRoot, "git", "diff", "--binary", diffRange)
+	if err != nil {
+		return fmt.Errorf("diff task branch: %w", err)
+	}
+	patchKB := (len(patch) + 1023) / 1024
+
+	var exceeded []string
+	if budgets.MaxChangedFiles > 0 && changedFiles > budgets.MaxChangedFiles {
+		exceeded = append(exceeded, fmt.Sprintf("%d changed files > max_changed_files %d", changedFiles, budgets.MaxChangedFiles))
+	}
+	if budgets.MaxPatchKB > 0 && patchKB > budgets.MaxPatchKB {
+		exceeded = append(exceeded, fmt.Sprintf("%d KB patch > max_patch_kb %d", patchKB, budgets.MaxPatchKB))
+	}
+	if len(exceeded) == 0 {
+		return nil
+	}
+
+	log.Warn().
+		Str("branch", branch).
+		Int("changed_files", changedFiles).
+		Int("patch_kb", patchKB).
+		Str("policy", policy).
+		Msg("task branch exceeds patch budget")
+	if policy == PatchOverflowWarn {
+		return nil
+	}
+	return WithFailureKind(FailureTaskNotMet, fmt.Errorf("%w: %s", ErrPatchBudgetExceeded, strings.Join(exceeded, ", ")))
+}
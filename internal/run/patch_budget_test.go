@@ -0,0 +1,85 @@
+package run
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/metalagman/norma/internal/config"
+)
+
+func TestApplyChangesPatchBudget(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		budgets   config.Budgets
+		wantErr   error
+		wantApply bool
+	}{
+		{name: "unlimited", wantApply: true},
+		{name: "under_limits", budgets: config.Budgets{MaxChangedFiles: 3, MaxPatchKB: 16}, wantApply: true},
+		{name: "too_many_files", budgets: config.Budgets{MaxChangedFiles: 2}, wantErr: ErrPatchBudgetExceeded},
+		{name: "patch_too_large", budgets: config.Budgets{MaxPatchKB: 1}, wantErr: ErrPatchBudgetExceeded},
+		{name: "warn", budgets: config.Budgets{MaxChangedFiles: 2, PatchOverflow: PatchOverflowWarn}, wantApply: true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctx := context.Background()
+			repoRoot := t.TempDir()
+			initGitRepo(t, ctx, repoRoot)
+			runGit(t, ctx, repoRoot, "checkout", "-b", "master")
+			writeFile(t, filepath.Join(repoRoot, "base.txt"), "base\n")
+			runGit(t, ctx, repoRoot, "add", "-A")
+			runGit(t, ctx, repoRoot, "commit", "-m", "chore: initial")
+			baseHead := strings.TrimSpace(runGit(t, ctx, repoRoot, "rev-parse", "HEAD"))
+
+			// Three files, about 3 KB of diff.
+			runGit(t, ctx, repoRoot, "checkout", "-b", "norma/task/norma-big")
+			for i := range 3 {
+				writeFile(t, filepath.Join(repoRoot, fmt.Sprintf("file%d.txt", i)), strings.Repeat("x", 1000)+"\n")
+			}
+			runGit(t, ctx, repoRoot, "add", "-A")
+			runGit(t, ctx, repoRoot, "commit", "-m", "chore: do step 1")
+			runGit(t, ctx, repoRoot, "checkout", "master")
+
+			runner := &Runner{repoRoot: repoRoot, normaDir: t.TempDir()}
+			runner.cfg.Budgets = tc.budgets
+			err := runner.applyChanges(ctx, "run-1", "apply task", "norma-big", baseHead, nil)
+
+			if tc.wantErr != nil {
+				if !errors.Is(err, tc.wantErr) {
+					t.Fatalf("applyChanges() error = %v, want %v", err, tc.wantErr)
+				}
+				if kind := FailureKindOf(err, FailureInfrastructure); kind != FailureTaskNotMet {
+					t.Fatalf("failure kind = %q, want %q", kind, FailureTaskNotMet)
+				}
+				if head := strings.TrimSpace(runGit(t, ctx, repoRoot, "rev-parse", "HEAD")); head != baseHead {
+					t.Fatalf("HEAD = %s, want unchanged %s", head, baseHead)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("applyChanges() error = %v", err)
+			}
+			if files := runGit(t, ctx, repoRoot, "ls-files"); !strings.Contains(files, "file2.txt") {
+				t.Fatalf("tracked files = %q, want task changes applied", files)
+			}
+		})
+	}
+}
+
+func TestCheckPatchBudgetRejectsUnknownPolicy(t *testing.T) {
+	t.Parallel()
+
+	err := CheckPatchBudget(context.Background(), t.TempDir(), "norma/task/norma-1", config.Budgets{MaxPatchKB: 1, PatchOverflow: "ignore"})
+	if err == nil || !strings.Contains(err.Error(), "unsupported patch_overflow policy") {
+		t.Fatalf("CheckPatchBudget() error = %v, want unsupported policy", err)
+	}
+}
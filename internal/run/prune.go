@@ -66,7 +66,7 @@ func PruneRuns(ctx context.Context, db *sql.DB, runsDir string, policy Retention
 	res := PruneResult{Considered: len(runs)}
 	for idx, row := range runs {
 		keep := false
-		if row.status == "running" {
+		if row.status == "running" || row.status == StatusAwaitingApproval {
 			keep = true
 		}
 		if !keep && policy.KeepLast > 0 && idx < policy.KeepLast {
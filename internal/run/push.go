@@ -0,0 +1,51 @@
+package run
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/metalagman/norma/internal/config"
+	"github.com/metalagman/norma/internal/db"
+	"github.com/metalagman/norma/internal/git"
+	"github.com/rs/zerolog/log"
+)
+
+// PushFailureError makes a failed git.push_on_pass push fail the run.
+const PushFailureError = "error"
+
+// RunEventRecorder records a run timeline event.
+type RunEventRecorder interface {
+	AddEvent(ctx context.Context, runID string, event db.Event) error
+}
+
+// PushTaskBranch pushes the task branch of a passed run to git.push_on_pass and
+// records the result as a push event. A failed push is logged and returned as an
+// error only when git.push_failure is error. store may be nil.
+func PushTaskBranch(ctx context.Context, store RunEventRecorder, repoRoot string, cfg config.GitConfig, runID, branch string) error {
+	remote := strings.TrimSpace(cfg.PushOnPass)
+	if remote == "" {
+		return nil
+	}
+
+	pushErr := git.PushBranch(ctx, repoRoot, remote, branch)
+	event := db.Event{Type: "push", Message: fmt.Sprintf("pushed %s to %s", branch, remote)}
+	if pushErr != nil {
+		event.Message = fmt.Sprintf("failed to push %s to %s: %v", branch, remote, pushErr)
+	}
+	if store != nil {
+		if err := store.AddEvent(ctx, runID, event); err != nil {
+			log.Warn().Err(err).Str("run_id", runID).Msg("failed to record push result")
+		}
+	}
+
+	if pushErr == nil {
+		log.Info().Str("branch", branch).Str("remote", remote).Msg("pushed task branch")
+		return nil
+	}
+	if strings.EqualFold(strings.TrimSpace(cfg.PushFailure), PushFailureError) {
+		return pushErr
+	}
+	log.Warn().Err(pushErr).Str("branch", branch).Str("remote", remote).Msg("failed to push task branch")
+	return nil
+}
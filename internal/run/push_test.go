@@ -0,0 +1,90 @@
+package run
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/metalagman/norma/internal/config"
+	"github.com/metalagman/norma/internal/db"
+)
+
+type recordingEventStore struct {
+	events []db.Event
+}
+
+func (s *recordingEventStore) AddEvent(_ context.Context, _ string, event db.Event) error {
+	s.events = append(s.events, event)
+	return nil
+}
+
+func newPushRepo(t *testing.T, ctx context.Context) (repoRoot, remote string) {
+	t.Helper()
+	repoRoot = t.TempDir()
+	initGitRepo(t, ctx, repoRoot)
+	writeFile(t, filepath.Join(repoRoot, "base.txt"), "base\n")
+	runGit(t, ctx, repoRoot, "add", "-A")
+	runGit(t, ctx, repoRoot, "commit", "-m", "chore: initial")
+	runGit(t, ctx, repoRoot, "branch", "norma/task/norma-a1")
+
+	remote = t.TempDir()
+	runGit(t, ctx, remote, "init", "--bare")
+	runGit(t, ctx, repoRoot, "remote", "add", "origin", remote)
+	return repoRoot, remote
+}
+
+func TestPushTaskBranchPushesToRemote(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	repoRoot, remote := newPushRepo(t, ctx)
+	store := &recordingEventStore{}
+
+	cfg := config.GitConfig{PushOnPass: "origin"}
+	if err := PushTaskBranch(ctx, store, repoRoot, cfg, "run-1", "norma/task/norma-a1"); err != nil {
+		t.Fatalf("PushTaskBranch() error = %v", err)
+	}
+
+	local := strings.TrimSpace(runGit(t, ctx, repoRoot, "rev-parse", "norma/task/norma-a1"))
+	pushed := strings.TrimSpace(runGit(t, ctx, remote, "rev-parse", "refs/heads/norma/task/norma-a1"))
+	if pushed != local {
+		t.Fatalf("remote branch at %s, want %s", pushed, local)
+	}
+	if len(store.events) != 1 || store.events[0].Type != "push" || !strings.HasPrefix(store.events[0].Message, "pushed ") {
+		t.Fatalf("events = %+v, want one push event", store.events)
+	}
+}
+
+func TestPushTaskBranchFailurePolicy(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	repoRoot, _ := newPushRepo(t, ctx)
+
+	store := &recordingEventStore{}
+	warn := config.GitConfig{PushOnPass: "missing"}
+	if err := PushTaskBranch(ctx, store, repoRoot, warn, "run-1", "norma/task/norma-a1"); err != nil {
+		t.Fatalf("PushTaskBranch(warn) error = %v, want nil", err)
+	}
+	if len(store.events) != 1 || !strings.HasPrefix(store.events[0].Message, "failed to push") {
+		t.Fatalf("events = %+v, want one failed push event", store.events)
+	}
+
+	fatal := config.GitConfig{PushOnPass: "missing", PushFailure: PushFailureError}
+	if err := PushTaskBranch(ctx, nil, repoRoot, fatal, "run-1", "norma/task/norma-a1"); err == nil {
+		t.Fatal("PushTaskBranch(error) error = nil, want push failure")
+	}
+}
+
+func TestPushTaskBranchDisabled(t *testing.T) {
+	t.Parallel()
+
+	store := &recordingEventStore{}
+	if err := PushTaskBranch(context.Background(), store, t.TempDir(), config.GitConfig{}, "run-1", "norma/task/norma-a1"); err != nil {
+		t.Fatalf("PushTaskBranch() error = %v", err)
+	}
+	if len(store.events) != 0 {
+		t.Fatalf("events = %+v, want none", store.events)
+	}
+}
@@ -0,0 +1,44 @@
+package run
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// ErrRerunUnsupported reports that the runner's agent factory cannot rerun steps.
+var ErrRerunUnsupported = errors.New("agent factory does not support step reruns")
+
+// StepRerunResult describes the output of a single rerun step.
+type StepRerunResult struct {
+	// Dir is the directory holding the rerun's input, output, and logs.
+	Dir     string
+	Role    string
+	Status  string
+	Summary string
+}
+
+// StepRerunner is implemented by agent factories that can replay a recorded step.
+type StepRerunner interface {
+	RerunStep(ctx context.Context, meta RunMeta, stepIndex int) (StepRerunResult, error)
+}
+
+// RerunStep runs step stepIndex of runID again from its recorded input, writing
+// the results next to the original step without changing run or task state.
+func (r *Runner) RerunStep(ctx context.Context, runID string, stepIndex int) (StepRerunResult, error) {
+	rerunner, ok := r.factory.(StepRerunner)
+	if !ok {
+		return StepRerunResult{}, fmt.Errorf("%s: %w", r.factory.Name(), ErrRerunUnsupported)
+	}
+	runDir := filepath.Join(r.normaDir, "runs", runID)
+	if _, err := os.Stat(runDir); err != nil {
+		return StepRerunResult{}, fmt.Errorf("run %s: %w", runID, err)
+	}
+	return rerunner.RerunStep(ctx, RunMeta{
+		RunID:   runID,
+		RunDir:  runDir,
+		GitRoot: r.repoRoot,
+	}, stepIndex)
+}
@@ -0,0 +1,62 @@
+package run
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/metalagman/norma/internal/config"
+)
+
+type fakeRerunFactory struct {
+	fakeFactory
+	meta      RunMeta
+	stepIndex int
+}
+
+func (f *fakeRerunFactory) RerunStep(_ context.Context, meta RunMeta, stepIndex int) (StepRerunResult, error) {
+	f.meta = meta
+	f.stepIndex = stepIndex
+	return StepRerunResult{Dir: filepath.Join(meta.RunDir, "rerun"), Status: "ok"}, nil
+}
+
+func TestRunnerRerunStep(t *testing.T) {
+	t.Parallel()
+
+	repoRoot := t.TempDir()
+	runDir := filepath.Join(repoRoot, ".norma", "runs", "run-1")
+	if err := os.MkdirAll(runDir, 0o700); err != nil {
+		t.Fatal(err)
+	}
+
+	factory := &fakeRerunFactory{}
+	runner, err := NewADKRunner(repoRoot, config.Config{}, nil, noopTracker{}, factory)
+	if err != nil {
+		t.Fatalf("NewADKRunner() error = %v", err)
+	}
+	res, err := runner.RerunStep(context.Background(), "run-1", 3)
+	if err != nil {
+		t.Fatalf("RerunStep() error = %v", err)
+	}
+	if res.Status != "ok" || factory.stepIndex != 3 || factory.meta.RunDir != runDir || factory.meta.RunID != "run-1" {
+		t.Fatalf("RerunStep() = %+v with meta %+v step %d", res, factory.meta, factory.stepIndex)
+	}
+
+	if _, err := runner.RerunStep(context.Background(), "missing", 3); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("RerunStep(missing) error = %v, want not exist", err)
+	}
+}
+
+func TestRunnerRerunStepUnsupported(t *testing.T) {
+	t.Parallel()
+
+	runner, err := NewADKRunner(t.TempDir(), config.Config{}, nil, noopTracker{}, &fakeFactory{})
+	if err != nil {
+		t.Fatalf("NewADKRunner() error = %v", err)
+	}
+	if _, err := runner.RerunStep(context.Background(), "run-1", 1); !errors.Is(err, ErrRerunUnsupported) {
+		t.Fatalf("RerunStep() error = %v, want ErrRerunUnsupported", err)
+	}
+}
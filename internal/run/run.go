@@ -6,10 +6,10 @@ import (
 	"crypto/rand"
 	"database/sql"
 	"encoding/hex"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
-	"regexp"
 	"strings"
 	"time"
 
@@ -29,8 +29,6 @@ const (
 	StatusStopped = "stopped"
 )
 
-var taskIDPattern = regexp.MustCompile(`^norma-[a-z0-9]+(?:\.[a-z0-9]+)*$`)
-
 // Runner executes an ADK agent run for a task.
 type Runner struct {
 	repoRoot string
@@ -43,8 +41,9 @@ type Runner struct {
 
 // Result summarizes a completed run.
 type Result struct {
-	RunID  string
-	Status string
+	RunID       string
+	Status      string
+	FailureKind FailureKind
 }
 
 // NewADKRunner constructs a Runner with an ADK agent factory.
@@ -64,14 +63,10 @@ func NewADKRunner(repoRoot string, cfg config.Config, store *db.Store, tracker t
 	}, nil
 }
 
-func (r *Runner) validateTaskID(id string) bool {
-	return taskIDPattern.MatchString(id)
-}
-
 // Run starts a new run with the given goal and acceptance criteria.
 func (r *Runner) Run(ctx context.Context, goal string, ac []task.AcceptanceCriterion, taskID string) (res Result, err error) {
-	if !r.validateTaskID(taskID) {
-		return Result{}, fmt.Errorf("invalid task id: %s", taskID)
+	if err := task.ValidateID(taskID); err != nil {
+		return Result{}, err
 	}
 
 	startedAt := time.Now().UTC()
@@ -80,57 +75,104 @@ func (r *Runner) Run(ctx context.Context, goal string, ac []task.AcceptanceCrite
 		return Result{}, err
 	}
 	res.RunID = runID
+	correlationID, err := NewCorrelationID()
+	if err != nil {
+		return Result{}, err
+	}
+	ctx = WithCorrelationID(ctx, correlationID)
+	l := ContextLogger(ctx, log.Logger)
 
 	defer func() {
 		status := res.Status
 		if status == "" && err != nil {
 			status = StatusError
 		}
-		event := log.Info().
+		event := l.Info().
 			Str("run_id", runID).
 			Str("status", status).
 			Str("duration", time.Since(startedAt).String())
 
+		if res.FailureKind != "" {
+			event = event.Str("failure_kind", string(res.FailureKind))
+		}
 		if err != nil {
 			event = event.Err(err)
 		}
 		event.Msg("run finished")
 	}()
 
-	lock, err := AcquireRunLock(r.normaDir)
+	runCreated := false
+	fail := func(kind FailureKind, err error) (Result, error) {
+		res.FailureKind = FailureKindOf(err, kind)
+		if runCreated {
+			// Record the failure even when it was caused by cancelling ctx.
+			if mErr := r.store.MarkRunFailed(context.WithoutCancel(ctx), runID, string(res.FailureKind), err.Error()); mErr != nil {
+				l.Warn().Err(mErr).Str("run_id", runID).Msg("failed to record run failure kind")
+			}
+		}
+		return res, WithFailureKind(res.FailureKind, err)
+	}
+
+	ac, err = EnsureAcceptanceCriteria(ac, goal, r.cfg.RequireAcceptanceCriteria)
 	if err != nil {
-		return res, fmt.Errorf("acquire run lock: %w", err)
+		if lErr := r.tracker.AddLabel(ctx, taskID, LabelNeedsAC); lErr != nil {
+			l.Warn().Err(lErr).Str("label", LabelNeedsAC).Msg("failed to add label to task")
+		}
+		return fail(FailureTaskNotMet, fmt.Errorf("task %s: %w", taskID, err))
+	}
+
+	lock, err := AcquireTaskRunLock(ctx, r.normaDir, r.cfg.Git)
+	if err != nil {
+		return fail(FailureInfrastructure, fmt.Errorf("acquire run lock: %w", err))
 	}
 	defer func() {
 		if lErr := lock.Release(); lErr != nil {
-			log.Warn().Err(lErr).Msg("failed to release run lock")
+			l.Warn().Err(lErr).Msg("failed to release run lock")
 		}
 	}()
 
 	if err := os.MkdirAll(r.normaDir, 0o700); err != nil {
-		return res, fmt.Errorf("create .norma: %w", err)
+		return fail(FailureInfrastructure, fmt.Errorf("create .norma: %w", err))
 	}
 
 	baseBranch, err := git.CurrentBranch(ctx, r.repoRoot)
 	if err != nil {
-		return res, fmt.Errorf("resolve base branch: %w", err)
+		return fail(FailureInfrastructure, fmt.Errorf("resolve base branch: %w", err))
+	}
+	l.Info().Str("base_branch", baseBranch).Msg("using local base branch for task sync")
+	baseHead, err := git.GitRunCmdOutput(ctx, r.repoRoot, "git", "rev-parse", "HEAD")
+	if err != nil {
+		return fail(FailureInfrastructure, fmt.Errorf("resolve base HEAD: %w", err))
 	}
-	log.Info().Str("base_branch", baseBranch).Msg("using local base branch for task sync")
+	baseHead = strings.TrimSpace(baseHead)
 
 	// Prune stalled worktrees
 	_ = git.GitRunCmdErr(ctx, r.repoRoot, "git", "worktree", "prune")
 
-	if err := reconcile.Run(ctx, r.store.DB(), r.normaDir); err != nil {
-		return res, err
+	// Reconciling while other runs are active would mistake their in-flight steps for lost ones.
+	if lock.Exclusive() {
+		if err := reconcile.Run(ctx, r.store.DB(), r.normaDir); err != nil {
+			return fail(FailureInfrastructure, err)
+		}
+	}
+	if err := lock.Downgrade(); err != nil {
+		return fail(FailureInfrastructure, err)
 	}
 
 	runDir := filepath.Join(r.normaDir, "runs", runID)
 	if err := os.MkdirAll(runDir, 0o700); err != nil {
-		return res, fmt.Errorf("create run dir: %w", err)
+		return fail(FailureInfrastructure, fmt.Errorf("create run dir: %w", err))
 	}
 
-	if err := r.store.CreateRun(ctx, runID, goal, runDir, 1); err != nil {
-		return res, fmt.Errorf("create run in store: %w", err)
+	if err := r.store.CreateRun(ctx, runID, taskID, goal, runDir, 1); err != nil {
+		return fail(FailureInfrastructure, fmt.Errorf("create run in store: %w", err))
+	}
+	runCreated = true
+	if err := r.store.SetRunCorrelationID(ctx, runID, correlationID); err != nil {
+		return fail(FailureInfrastructure, err)
+	}
+	if err := SnapshotAcceptanceCriteria(ctx, r.store, runID, ac); err != nil {
+		return fail(FailureInfrastructure, err)
 	}
 
 	meta := RunMeta{
@@ -147,10 +189,10 @@ func (r *Runner) Run(ctx context.Context, goal string, ac []task.AcceptanceCrite
 
 	build, err := r.factory.Build(ctx, meta, payload)
 	if err != nil {
-		return res, fmt.Errorf("build run agent: %w", err)
+		return fail(FailureInfrastructure, fmt.Errorf("build run agent: %w", err))
 	}
 	if build.Agent == nil {
-		return res, fmt.Errorf("build run agent: nil agent")
+		return fail(FailureInfrastructure, fmt.Errorf("build run agent: nil agent"))
 	}
 
 	finalSession, _, err := adkrunner.Run(ctx, adkrunner.RunInput{
@@ -163,112 +205,177 @@ func (r *Runner) Run(ctx context.Context, goal string, ac []task.AcceptanceCrite
 		OnEvent:        build.OnEvent,
 	})
 	if err != nil {
-		return res, fmt.Errorf("execute ADK agent: %w", err)
+		return fail(FailureAgentError, fmt.Errorf("execute ADK agent: %w", err))
 	}
 
 	outcome, err := r.factory.Finalize(ctx, meta, payload, finalSession)
 	if err != nil {
-		return res, fmt.Errorf("finalize run: %w", err)
+		return fail(FailureInfrastructure, fmt.Errorf("finalize run: %w", err))
 	}
 
 	res.Status = outcome.Status
 
+	if err := CheckACChange(ctx, r.store, r.tracker, r.cfg.ACChangePolicy, runID, taskID); errors.Is(err, ErrACChanged) {
+		l.Warn().Str("run_id", runID).Msg("acceptance criteria changed during run, stopping with replan_required")
+		res.Status = StatusStopped
+		if sErr := r.store.SetRunStatus(ctx, runID, StatusStopped, err.Error()); sErr != nil {
+			l.Warn().Err(sErr).Str("run_id", runID).Msg("failed to record stopped run")
+		}
+		return res, nil
+	} else if err != nil {
+		l.Warn().Err(err).Str("run_id", runID).Msg("failed to check acceptance criteria for changes")
+	}
+
+	if outcome.Verdict != nil && *outcome.Verdict == "PASS" && r.cfg.RequireApprovalToApply {
+		timeout := time.Duration(r.cfg.ApprovalTimeout) * time.Second
+		if err := AwaitApproval(ctx, r.store, r.normaDir, runID, outcome.Status, timeout); err != nil {
+			res.Status = StatusStopped
+			if errors.Is(err, ErrApprovalTimeout) {
+				l.Warn().Str("run_id", runID).Msg("run was not approved in time, changes not applied")
+				return res, StopUnapproved(ctx, r.tracker, taskID, runID)
+			}
+			return fail(FailureInfrastructure, fmt.Errorf("await approval: %w", err))
+		}
+	}
+
 	if outcome.Verdict != nil && *outcome.Verdict == "PASS" {
-		log.Info().Msg("verdict is PASS, applying changes")
-		err = r.applyChanges(ctx, runID, goal, taskID)
+		if err := PushTaskBranch(ctx, r.store, r.repoRoot, r.cfg.Git, runID, TaskBranch(r.cfg.Git, taskID, runID)); err != nil {
+			return fail(FailureInfrastructure, fmt.Errorf("push task branch: %w", err))
+		}
+		l.Info().Msg("verdict is PASS, applying changes")
+		err = r.applyChanges(ctx, runID, goal, taskID, baseHead, outcome.PassedCriteria)
 		if err != nil {
-			log.Error().Err(err).Msg("failed to apply changes")
-			return res, fmt.Errorf("apply changes: %w", err)
+			l.Error().Err(err).Msg("failed to apply changes")
+			return fail(FailureInfrastructure, fmt.Errorf("apply changes: %w", err))
 		}
 		// Close task in Beads as per spec
 		if err := r.tracker.MarkStatus(ctx, taskID, "done"); err != nil {
-			log.Warn().Err(err).Msg("failed to mark task as done in beads")
+			l.Warn().Err(err).Msg("failed to mark task as done in beads")
+		} else if r.cfg.AutoCloseParents {
+			if err := CloseCompletedParents(ctx, r.tracker, taskID); err != nil {
+				l.Warn().Err(err).Msg("failed to close completed parents")
+			}
 		}
 		res.Status = StatusPassed
+	} else if ShouldApplyPartial(r.cfg.ApplyOnPartial, outcome) {
+		l.Info().Int("passed_required", outcome.PassedRequired).Msg("verdict is PARTIAL, applying changes")
+		if err := r.applyChanges(ctx, runID, goal, taskID, baseHead, outcome.PassedCriteria); err != nil {
+			l.Error().Err(err).Msg("failed to apply partial changes")
+			return fail(FailureInfrastructure, fmt.Errorf("apply partial changes: %w", err))
+		}
+		StopPartial(ctx, r.store, r.tracker, taskID, runID)
+		res.Status = StatusStopped
+		return res, nil
+	}
+
+	if res.Status == StatusFailed {
+		res.FailureKind = FailureTaskNotMet
+		if err := r.store.MarkRunFailed(ctx, runID, string(FailureTaskNotMet), "task acceptance criteria not met"); err != nil {
+			l.Warn().Err(err).Str("run_id", runID).Msg("failed to record run failure kind")
+		}
 	}
 
 	return res, nil
 }
 
-func (r *Runner) applyChanges(ctx context.Context, runID, goal, taskID string) error {
-	branchName := fmt.Sprintf("norma/task/%s", taskID)
+// applyChanges merges the task branch into the checked out base branch.
+// baseHead is the base HEAD recorded at run start; empty skips the moved-base check.
+// passed lists the acceptance criteria met, recorded in the changelog fragment.
+func (r *Runner) applyChanges(ctx context.Context, runID, goal, taskID, baseHead string, passed []task.AcceptanceCriterion) error {
+	l := ContextLogger(ctx, log.Logger)
+	applyLock, err := AcquireApplyLock(ctx, r.normaDir)
+	if err != nil {
+		return fmt.Errorf("acquire apply lock: %w", err)
+	}
+	defer func() {
+		if lErr := applyLock.Release(); lErr != nil {
+			l.Warn().Err(lErr).Msg("failed to release apply lock")
+		}
+	}()
+	branchName := TaskBranch(r.cfg.Git, taskID, runID)
 	stepIndex, err := r.currentStepIndex(ctx, runID)
 	if err != nil {
 		return err
 	}
-	commitMsg := BuildApplyCommitMessage(goal, runID, stepIndex, taskID)
+	commitMsg := BuildApplyCommitMessage(goal, runID, stepIndex, taskID, ApplyCommitTrailers(r.cfg.Git, runID, taskID, stepIndex)...)
 
-	log.Info().Str("branch", branchName).Msg("applying changes from workspace")
+	if err := git.CheckApplyBranch(ctx, r.repoRoot, r.cfg.Git.AllowedApplyBranches); err != nil {
+		return err
+	}
+	if baseHead != "" {
+		baseBranch, err := git.CurrentBranch(ctx, r.repoRoot)
+		if err != nil {
+			return err
+		}
+		if err := HandleBaseMoved(ctx, r.repoRoot, baseBranch, baseHead, branchName, r.cfg.Git.OnBaseMoved); err != nil {
+			return err
+		}
+	}
+
+	if err := CheckPatchBudget(ctx, r.repoRoot, branchName, r.cfg.Budgets); err != nil {
+		return err
+	}
+
+	l.Info().Str("branch", branchName).Msg("applying changes from workspace")
 
 	// Ensure a clean working tree before merge to avoid clobbering local changes.
-	dirty := strings.TrimSpace(git.GitRunCmd(ctx, r.repoRoot, "git", "status", "--porcelain"))
-	stashed := false
-	if dirty != "" {
-		log.Info().Msg("stashing local changes before merge")
-		if err := git.GitRunCmdErr(ctx, r.repoRoot, "git", "stash", "push", "-u", "-m", fmt.Sprintf("norma pre-apply %s", runID)); err != nil {
-			return fmt.Errorf("git stash push: %w", err)
-		}
-		stashed = true
+	stash, err := git.StashLocalChanges(ctx, r.repoRoot, r.cfg.Git.StashPolicy, fmt.Sprintf("norma pre-apply %s", runID))
+	if err != nil {
+		return err
+	}
+	if stash != "" {
+		l.Info().Msg("stashed local changes before merge")
 	}
 
 	restoreStash := func() error {
-		if !stashed {
+		if stash == "" {
 			return nil
 		}
-		if err := git.GitRunCmdErr(ctx, r.repoRoot, "git", "stash", "pop"); err != nil {
-			return fmt.Errorf("git stash pop: %w", err)
+		if err := git.StashPop(ctx, r.repoRoot, stash); err != nil {
+			l.Error().Err(err).Msg("failed to restore stashed local changes")
+			return err
 		}
-		stashed = false
+		stash = ""
 		return nil
 	}
 
 	// record git status/hash "before"
 	beforeHash := strings.TrimSpace(git.GitRunCmd(ctx, r.repoRoot, "git", "rev-parse", "HEAD"))
 
-	// merge --squash
-	if err := git.GitRunCmdErr(ctx, r.repoRoot, "git", "merge", "--squash", branchName); err != nil {
-		_ = git.GitRunCmdErr(ctx, r.repoRoot, "git", "reset", "--hard", beforeHash)
-		if restoreErr := restoreStash(); restoreErr != nil {
-			return fmt.Errorf("git merge --squash: %w (failed to restore stashed changes: %w)", err, restoreErr)
-		}
-		return fmt.Errorf("git merge --squash: %w", err)
-	}
-
-	if err := git.GitRunCmdErr(ctx, r.repoRoot, "git", "add", "-A"); err != nil {
-		_ = git.GitRunCmdErr(ctx, r.repoRoot, "git", "reset", "--hard", beforeHash)
+	committed, err := git.MergeBranch(ctx, r.repoRoot, branchName, r.cfg.Git.MergeStrategy, commitMsg)
+	if err != nil {
+		l.Error().Err(err).Msg("failed to merge task branch, rolled back")
 		if restoreErr := restoreStash(); restoreErr != nil {
-			return fmt.Errorf("git add -A: %w (failed to restore stashed changes: %w)", err, restoreErr)
+			return fmt.Errorf("%w (failed to restore stashed changes: %w)", err, restoreErr)
 		}
-		return fmt.Errorf("git add -A: %w", err)
+		return err
 	}
-
-	// check if there are changes to commit
-	status := git.GitRunCmd(ctx, r.repoRoot, "git", "status", "--porcelain")
-	log.Debug().Str("git_status", status).Msg("git status after merge")
-	if strings.TrimSpace(status) == "" {
-		if err := restoreStash(); err != nil {
+	if committed {
+		entry := ChangelogEntry{Goal: goal, TaskID: taskID, RunID: runID, Criteria: passed}
+		if err := CommitChangelog(ctx, r.repoRoot, r.cfg.Git.MergeStrategy, r.cfg.Changelog, entry); err != nil {
+			l.Error().Err(err).Str("path", r.cfg.Changelog.Path).Msg("failed to write changelog fragment, rolling back apply")
+			err = fmt.Errorf("write changelog fragment: %w", err)
+			if resetErr := git.GitRunCmdErr(ctx, r.repoRoot, "git", "reset", "--hard", beforeHash); resetErr != nil {
+				err = fmt.Errorf("%w (rollback failed: %w)", err, resetErr)
+			}
+			if restoreErr := restoreStash(); restoreErr != nil {
+				return fmt.Errorf("%w (failed to restore stashed changes: %w)", err, restoreErr)
+			}
 			return err
 		}
-		log.Info().Msg("nothing to commit after merge")
-		return nil
-	}
-
-	// commit using Conventional Commits
-	if err := git.GitRunCmdErr(ctx, r.repoRoot, "git", "commit", "-m", commitMsg); err != nil {
-		log.Error().Err(err).Msg("failed to commit merged changes, rolling back")
-		_ = git.GitRunCmdErr(ctx, r.repoRoot, "git", "reset", "--hard", beforeHash)
-		if restoreErr := restoreStash(); restoreErr != nil {
-			return fmt.Errorf("git commit: %w (failed to restore stashed changes: %w)", err, restoreErr)
-		}
-		return fmt.Errorf("git commit: %w", err)
 	}
 
 	if err := restoreStash(); err != nil {
-		return err
+		return fmt.Errorf("merged %s but did not restore local changes: %w", branchName, err)
+	}
+	CleanupRunBranch(ctx, r.repoRoot, r.cfg.Git, branchName)
+	if !committed {
+		l.Info().Msg("nothing to commit after merge")
+		return nil
 	}
 
 	afterHash := strings.TrimSpace(git.GitRunCmd(ctx, r.repoRoot, "git", "rev-parse", "HEAD"))
-	log.Info().
+	l.Info().
 		Str("before_hash", beforeHash).
 		Str("after_hash", afterHash).
 		Msg("changes applied and committed successfully")
@@ -310,13 +417,16 @@ func (r *Runner) currentStepIndex(ctx context.Context, runID string) (int, error
 	return stepIndex, nil
 }
 
-func BuildApplyCommitMessage(goal, runID string, stepIndex int, taskID string) string {
+// BuildApplyCommitMessage returns the commit message for applying a run's changes,
+// ending with trailers when any are given.
+func BuildApplyCommitMessage(goal, runID string, stepIndex int, taskID string, trailers ...Trailer) string {
 	commitType := CommitTypeForGoal(goal)
 	summary := strings.TrimSpace(goal)
 	if summary == "" {
 		summary = "apply workspace changes"
 	}
-	return fmt.Sprintf("%s: %s\n\nrun_id: %s\nstep_index: %d\ntask_id: %s", commitType, summary, runID, stepIndex, taskID)
+	msg := fmt.Sprintf("%s: %s\n\nrun_id: %s\nstep_index: %d\ntask_id: %s", commitType, summary, runID, stepIndex, taskID)
+	return appendTrailers(msg, trailers)
 }
 
 func CommitTypeForGoal(goal string) string {
@@ -2,11 +2,14 @@ package run
 
 import (
 	"context"
+	"errors"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"strings"
 	"testing"
+
+	"github.com/metalagman/norma/internal/git"
 )
 
 func TestApplyChangesDoesNotCommitRestoredLocalChanges(t *testing.T) {
@@ -32,8 +35,8 @@ func TestApplyChangesDoesNotCommitRestoredLocalChanges(t *testing.T) {
 	writeFile(t, filepath.Join(repoRoot, "local.txt"), "dirty-local\n")
 	writeFile(t, filepath.Join(repoRoot, "scratch.txt"), "scratch\n")
 
-	runner := &Runner{repoRoot: repoRoot}
-	if err := runner.applyChanges(ctx, "run-1", "merge branch", "norma-wzw"); err != nil {
+	runner := &Runner{repoRoot: repoRoot, normaDir: t.TempDir()}
+	if err := runner.applyChanges(ctx, "run-1", "merge branch", "norma-wzw", "", nil); err != nil {
 		t.Fatalf("applyChanges() error = %v", err)
 	}
 
@@ -71,6 +74,64 @@ func TestApplyChangesDoesNotCommitRestoredLocalChanges(t *testing.T) {
 	}
 }
 
+func TestApplyChangesRespectsAllowedApplyBranches(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		allowed     []string
+		wantBlocked bool
+	}{
+		{name: "no_allowlist", allowed: nil, wantBlocked: false},
+		{name: "allowed", allowed: []string{"develop", "master"}, wantBlocked: false},
+		{name: "disallowed", allowed: []string{"develop"}, wantBlocked: true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctx := context.Background()
+			repoRoot := t.TempDir()
+			initGitRepo(t, ctx, repoRoot)
+			runGit(t, ctx, repoRoot, "checkout", "-b", "master")
+			writeFile(t, filepath.Join(repoRoot, "base.txt"), "base\n")
+			runGit(t, ctx, repoRoot, "add", "-A")
+			runGit(t, ctx, repoRoot, "commit", "-m", "chore: initial")
+			runGit(t, ctx, repoRoot, "checkout", "-b", "norma/task/norma-abc")
+			writeFile(t, filepath.Join(repoRoot, "base.txt"), "base\nbranch\n")
+			runGit(t, ctx, repoRoot, "commit", "-am", "feat: branch change")
+			runGit(t, ctx, repoRoot, "checkout", "master")
+			before := strings.TrimSpace(runGit(t, ctx, repoRoot, "rev-parse", "HEAD"))
+
+			runner := &Runner{repoRoot: repoRoot, normaDir: t.TempDir()}
+			runner.cfg.Git.AllowedApplyBranches = tc.allowed
+			err := runner.applyChanges(ctx, "run-1", "apply branch", "norma-abc", "", nil)
+			after := strings.TrimSpace(runGit(t, ctx, repoRoot, "rev-parse", "HEAD"))
+
+			if tc.wantBlocked {
+				var notAllowed *git.ApplyBranchNotAllowedError
+				if !errors.As(err, &notAllowed) {
+					t.Fatalf("applyChanges() error = %v, want ApplyBranchNotAllowedError", err)
+				}
+				if notAllowed.Branch != "master" {
+					t.Fatalf("blocked branch = %q, want master", notAllowed.Branch)
+				}
+				if after != before {
+					t.Fatalf("HEAD moved from %s to %s on disallowed branch", before, after)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("applyChanges() error = %v", err)
+			}
+			if after == before {
+				t.Fatal("HEAD unchanged, want applied commit")
+			}
+		})
+	}
+}
+
 func initGitRepo(t *testing.T, ctx context.Context, repoRoot string) {
 	t.Helper()
 	runGit(t, ctx, repoRoot, "init")
@@ -133,31 +194,3 @@ func TestBuildApplyCommitMessageUsesFeatForNonFixGoals(t *testing.T) {
 		t.Fatalf("unexpected commit subject: %q", msg)
 	}
 }
-
-func TestValidateTaskID(t *testing.T) {
-	t.Parallel()
-
-	runner := &Runner{}
-
-	tests := []struct {
-		name string
-		id   string
-		want bool
-	}{
-		{name: "flat", id: "norma-a3f2dd", want: true},
-		{name: "single segment with digits", id: "norma-01", want: true},
-		{name: "hierarchical dotted", id: "norma-4pm.1.1", want: true},
-		{name: "uppercase rejected", id: "norma-ABC", want: false},
-		{name: "wrong prefix rejected", id: "task-a3f2dd", want: false},
-		{name: "double dot rejected", id: "norma-a..1", want: false},
-	}
-
-	for _, tc := range tests {
-		t.Run(tc.name, func(t *testing.T) {
-			t.Parallel()
-			if got := runner.validateTaskID(tc.id); got != tc.want {
-				t.Fatalf("validateTaskID(%q)=%v, want %v", tc.id, got, tc.want)
-			}
-		})
-	}
-}
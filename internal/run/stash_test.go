@@ -0,0 +1,45 @@
+package run
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/metalagman/norma/internal/git"
+)
+
+func TestApplyChangesReportsStashPopConflict(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	repoRoot := t.TempDir()
+	initGitRepo(t, ctx, repoRoot)
+	runGit(t, ctx, repoRoot, "checkout", "-b", "master")
+	writeFile(t, filepath.Join(repoRoot, "app.txt"), "base\n")
+	runGit(t, ctx, repoRoot, "add", "-A")
+	runGit(t, ctx, repoRoot, "commit", "-m", "chore: initial")
+
+	runGit(t, ctx, repoRoot, "checkout", "-b", "norma/task/norma-st")
+	writeFile(t, filepath.Join(repoRoot, "app.txt"), "task\n")
+	runGit(t, ctx, repoRoot, "commit", "-am", "chore: do step 1")
+	runGit(t, ctx, repoRoot, "checkout", "master")
+
+	// An uncommitted local edit to the same line conflicts with the task change.
+	writeFile(t, filepath.Join(repoRoot, "app.txt"), "local\n")
+
+	runner := &Runner{repoRoot: repoRoot, normaDir: t.TempDir()}
+	err := runner.applyChanges(ctx, "run-1", "apply task", "norma-st", "", nil)
+
+	var conflict *git.StashConflictError
+	if !errors.As(err, &conflict) {
+		t.Fatalf("applyChanges() error = %v, want *git.StashConflictError", err)
+	}
+	if !strings.Contains(err.Error(), conflict.Ref) || !strings.Contains(err.Error(), "norma/task/norma-st") {
+		t.Fatalf("applyChanges() error = %q, want it to name the stash and the merged branch", err)
+	}
+	if list := runGit(t, ctx, repoRoot, "stash", "list"); !strings.Contains(list, "norma pre-apply run-1") {
+		t.Fatalf("stash list = %q, want the local changes kept", list)
+	}
+}
@@ -0,0 +1,82 @@
+package run
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/metalagman/norma/internal/config"
+)
+
+// Git trailers norma appends to apply commits when git.commit_trailers is set.
+const (
+	TrailerRunID     = "Norma-Run-Id"
+	TrailerTaskID    = "Norma-Task-Id"
+	TrailerStepIndex = "Norma-Step-Index"
+)
+
+// normaTrailerPrefix marks the trailers ParseNormaTrailers returns.
+const normaTrailerPrefix = "Norma-"
+
+// Trailer is a git trailer line, "Key: Value", at the end of a commit message.
+type Trailer struct {
+	Key   string
+	Value string
+}
+
+// ApplyCommitTrailers returns the trailers configured for the apply commit of runID:
+// the Norma trailers when enabled, followed by the extra trailers sorted by key.
+func ApplyCommitTrailers(cfg config.GitConfig, runID, taskID string, stepIndex int) []Trailer {
+	var trailers []Trailer
+	if cfg.CommitTrailers {
+		trailers = append(trailers, Trailer{Key: TrailerRunID, Value: runID})
+		if taskID != "" {
+			trailers = append(trailers, Trailer{Key: TrailerTaskID, Value: taskID})
+		}
+		trailers = append(trailers, Trailer{Key: TrailerStepIndex, Value: strconv.Itoa(stepIndex)})
+	}
+	keys := make([]string, 0, len(cfg.ExtraTrailers))
+	for key := range cfg.ExtraTrailers {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	for _, key := range keys {
+		trailers = append(trailers, Trailer{Key: key, Value: cfg.ExtraTrailers[key]})
+	}
+	return trailers
+}
+
+// appendTrailers adds trailers to msg as a final paragraph.
+func appendTrailers(msg string, trailers []Trailer) string {
+	if len(trailers) == 0 {
+		return msg
+	}
+	var b strings.Builder
+	b.WriteString(strings.TrimRight(msg, "\n"))
+	b.WriteString("\n")
+	for _, trailer := range trailers {
+		fmt.Fprintf(&b, "\n%s: %s", trailer.Key, strings.TrimSpace(trailer.Value))
+	}
+	return b.String()
+}
+
+// ParseNormaTrailers returns the Norma-* trailers in the last paragraph of msg, keyed by trailer name.
+// It returns an empty map when msg has none.
+func ParseNormaTrailers(msg string) map[string]string {
+	trailers := map[string]string{}
+	paragraphs := strings.Split(strings.TrimSpace(strings.ReplaceAll(msg, "\r\n", "\n")), "\n\n")
+	last := paragraphs[len(paragraphs)-1]
+	for line := range strings.SplitSeq(last, "\n") {
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		key = strings.TrimSpace(key)
+		if !strings.HasPrefix(key, normaTrailerPrefix) {
+			continue
+		}
+		trailers[key] = strings.TrimSpace(value)
+	}
+	return trailers
+}
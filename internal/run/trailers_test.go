@@ -0,0 +1,61 @@
+package run
+
+import (
+	"maps"
+	"strings"
+	"testing"
+
+	"github.com/metalagman/norma/internal/config"
+)
+
+func TestBuildApplyCommitMessageAppendsTrailers(t *testing.T) {
+	t.Parallel()
+
+	cfg := config.GitConfig{
+		CommitTrailers: true,
+		ExtraTrailers:  map[string]string{"Signed-off-by": "Bot <bot@example.com>", "Norma-Profile": "fast"},
+	}
+	msg := BuildApplyCommitMessage("Fix panic in workflow", "run-123", 7, "norma-agf", ApplyCommitTrailers(cfg, "run-123", "norma-agf", 7)...)
+
+	wantTail := "\n\nNorma-Run-Id: run-123\nNorma-Task-Id: norma-agf\nNorma-Step-Index: 7\nNorma-Profile: fast\nSigned-off-by: Bot <bot@example.com>"
+	if !strings.HasSuffix(msg, wantTail) {
+		t.Fatalf("commit message = %q, want suffix %q", msg, wantTail)
+	}
+	if !strings.HasPrefix(msg, "fix: Fix panic in workflow\n\nrun_id: run-123") {
+		t.Fatalf("commit message lost its subject or body: %q", msg)
+	}
+
+	got := ParseNormaTrailers(msg)
+	want := map[string]string{
+		TrailerRunID:     "run-123",
+		TrailerTaskID:    "norma-agf",
+		TrailerStepIndex: "7",
+		"Norma-Profile":  "fast",
+	}
+	if !maps.Equal(got, want) {
+		t.Fatalf("ParseNormaTrailers() = %v, want %v", got, want)
+	}
+}
+
+func TestApplyCommitTrailersDisabled(t *testing.T) {
+	t.Parallel()
+
+	if got := ApplyCommitTrailers(config.GitConfig{}, "run-1", "norma-a1", 2); len(got) != 0 {
+		t.Fatalf("ApplyCommitTrailers() = %v, want none", got)
+	}
+	msg := BuildApplyCommitMessage("Add endpoint", "run-1", 2, "norma-a1")
+	if got := ParseNormaTrailers(msg); len(got) != 0 {
+		t.Fatalf("ParseNormaTrailers() = %v, want empty", got)
+	}
+}
+
+func TestParseNormaTrailersOnlyReadsLastParagraph(t *testing.T) {
+	t.Parallel()
+
+	msg := "feat: thing\n\nNorma-Run-Id: in-body\n\nNorma-Task-Id: norma-a1\r\nReviewed-by: someone\n"
+	got := ParseNormaTrailers(msg)
+	want := map[string]string{TrailerTaskID: "norma-a1"}
+	if !maps.Equal(got, want) {
+		t.Fatalf("ParseNormaTrailers() = %v, want %v", got, want)
+	}
+}
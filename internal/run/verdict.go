@@ -0,0 +1,56 @@
+package run
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+)
+
+// SetVerdict overrides the verdict of a finished run, e.g. after manual verification.
+// verdict is PASS, FAIL, or PARTIAL. With apply, a PASS verdict also merges the
+// run's task branch into the checked out base branch and marks the task done.
+func (r *Runner) SetVerdict(ctx context.Context, runID, verdict, reason string, apply bool) error {
+	verdict = strings.ToUpper(strings.TrimSpace(verdict))
+	switch verdict {
+	case "PASS", "FAIL", "PARTIAL":
+	default:
+		return fmt.Errorf("invalid verdict %q: want PASS, FAIL, or PARTIAL", verdict)
+	}
+
+	lock, err := AcquireRunLock(r.normaDir)
+	if err != nil {
+		return fmt.Errorf("acquire run lock: %w", err)
+	}
+	defer func() {
+		if lErr := lock.Release(); lErr != nil {
+			log.Warn().Err(lErr).Msg("failed to release run lock")
+		}
+	}()
+
+	if err := r.store.SetVerdict(ctx, runID, verdict, reason); err != nil {
+		return err
+	}
+	if !apply || verdict != "PASS" {
+		return nil
+	}
+
+	rec, err := r.store.GetRun(ctx, runID)
+	if err != nil {
+		return err
+	}
+	if rec == nil || rec.TaskID == "" {
+		return fmt.Errorf("apply run %s: run has no task", runID)
+	}
+	if err := r.applyChanges(ctx, runID, rec.Goal, rec.TaskID, "", nil); err != nil {
+		return fmt.Errorf("apply changes of run %s: %w", runID, err)
+	}
+	if err := r.store.SetRunStatus(ctx, runID, StatusPassed, "changes applied after manual verdict"); err != nil {
+		return err
+	}
+	if err := r.tracker.MarkStatus(ctx, rec.TaskID, "done"); err != nil {
+		log.Warn().Err(err).Str("task_id", rec.TaskID).Msg("failed to mark task as done in beads")
+	}
+	return nil
+}
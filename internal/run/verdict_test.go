@@ -0,0 +1,74 @@
+package run
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/metalagman/norma/internal/config"
+	"github.com/metalagman/norma/internal/db"
+)
+
+func TestRunnerSetVerdictAppliesManualPass(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	repoRoot := t.TempDir()
+	initGitRepo(t, ctx, repoRoot)
+	writeFile(t, filepath.Join(repoRoot, "base.txt"), "base\n")
+	runGit(t, ctx, repoRoot, "add", "-A")
+	runGit(t, ctx, repoRoot, "commit", "-m", "chore: initial")
+
+	branchName := TaskBranch(config.GitConfig{}, "norma-a1", "run-1")
+	runGit(t, ctx, repoRoot, "checkout", "-b", branchName)
+	writeFile(t, filepath.Join(repoRoot, "base.txt"), "base\nverified\n")
+	runGit(t, ctx, repoRoot, "commit", "-am", "feat: branch change")
+	runGit(t, ctx, repoRoot, "checkout", "master")
+
+	sqlDB, err := db.Open(ctx, filepath.Join(t.TempDir(), "norma.db"))
+	if err != nil {
+		t.Fatalf("db.Open() error = %v", err)
+	}
+	t.Cleanup(func() { _ = sqlDB.Close() })
+	store := db.NewStore(sqlDB)
+	if err := store.CreateRun(ctx, "run-1", "norma-a1", "add verification", t.TempDir(), 1); err != nil {
+		t.Fatalf("CreateRun() error = %v", err)
+	}
+
+	tracker := &cleanupTracker{}
+	runner, err := NewADKRunner(repoRoot, config.Config{}, store, tracker, &fakeFactory{})
+	if err != nil {
+		t.Fatalf("NewADKRunner() error = %v", err)
+	}
+
+	if err := runner.SetVerdict(ctx, "run-1", "PASS", "checked by hand", true); !errors.Is(err, db.ErrRunRunning) {
+		t.Fatalf("SetVerdict() on running run error = %v, want %v", err, db.ErrRunRunning)
+	}
+	if err := store.MarkRunFailed(ctx, "run-1", string(FailureTaskNotMet), "task acceptance criteria not met"); err != nil {
+		t.Fatalf("MarkRunFailed() error = %v", err)
+	}
+	if err := runner.SetVerdict(ctx, "run-1", "maybe", "", true); err == nil {
+		t.Fatal("SetVerdict() with invalid verdict error = nil")
+	}
+
+	if err := runner.SetVerdict(ctx, "run-1", "pass", "checked by hand", true); err != nil {
+		t.Fatalf("SetVerdict() error = %v", err)
+	}
+
+	rec, err := store.GetRun(ctx, "run-1")
+	if err != nil {
+		t.Fatalf("GetRun() error = %v", err)
+	}
+	if rec.Verdict != "PASS" || rec.Status != StatusPassed {
+		t.Fatalf("run = (%q, %q), want (PASS, %s)", rec.Verdict, rec.Status, StatusPassed)
+	}
+	if got := readFile(t, filepath.Join(repoRoot, "base.txt")); !strings.Contains(got, "verified") {
+		t.Fatalf("base.txt = %q, want branch change applied", got)
+	}
+	if want := []string{"done"}; !slices.Equal(tracker.statuses, want) {
+		t.Fatalf("task statuses = %v, want %v", tracker.statuses, want)
+	}
+}
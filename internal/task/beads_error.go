@@ -0,0 +1,91 @@
+package task
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+)
+
+// ErrTaskNotFound reports a task id the tracker does not know.
+var ErrTaskNotFound = errors.New("task not found")
+
+// ErrTrackerUnavailable marks a tracker failure that may pass on retry, such as bd
+// failing to start or exiting without a structured error (e.g. a locked database).
+var ErrTrackerUnavailable = errors.New("tracker unavailable")
+
+// Beads error codes.
+const (
+	BeadsErrorNotFound        = "not_found"
+	BeadsErrorConflict        = "conflict"
+	BeadsErrorInvalidArgument = "invalid_argument"
+	BeadsErrorUnknown         = "unknown"
+)
+
+// BeadsError is a structured error reported by bd in --json mode.
+type BeadsError struct {
+	Code    string
+	Message string
+}
+
+func (e *BeadsError) Error() string {
+	return "bd " + e.Code + ": " + e.Message
+}
+
+// parseBeadsError extracts a {"error": "..."} body from bd stderr or stdout.
+// It returns nil when neither stream holds a JSON error.
+func parseBeadsError(stderr, stdout []byte) *BeadsError {
+	for _, out := range [][]byte{stderr, stdout} {
+		out = bytes.TrimSpace(out)
+		if len(out) == 0 || out[0] != '{' {
+			continue
+		}
+		var body struct {
+			Error string `json:"error"`
+			Code  string `json:"code"`
+		}
+		if err := json.Unmarshal(out, &body); err != nil || strings.TrimSpace(body.Error) == "" {
+			continue
+		}
+		code := normalizeBeadsErrorCode(body.Code)
+		if code == BeadsErrorUnknown {
+			code = classifyBeadsError(body.Error)
+		}
+		return &BeadsError{Code: code, Message: strings.TrimSpace(body.Error)}
+	}
+	return nil
+}
+
+func normalizeBeadsErrorCode(code string) string {
+	switch strings.ToLower(strings.TrimSpace(code)) {
+	case "not_found", "notfound":
+		return BeadsErrorNotFound
+	case "conflict":
+		return BeadsErrorConflict
+	case "invalid_argument", "invalid", "validation":
+		return BeadsErrorInvalidArgument
+	default:
+		return BeadsErrorUnknown
+	}
+}
+
+// classifyBeadsError derives a code from the message when bd does not send one.
+func classifyBeadsError(msg string) string {
+	msg = strings.ToLower(msg)
+	switch {
+	case strings.Contains(msg, "not found"), strings.Contains(msg, "no issue found"):
+		return BeadsErrorNotFound
+	case strings.Contains(msg, "conflict"), strings.Contains(msg, "already exists"):
+		return BeadsErrorConflict
+	case strings.Contains(msg, "invalid"), strings.Contains(msg, "required"), strings.Contains(msg, "unknown flag"):
+		return BeadsErrorInvalidArgument
+	default:
+		return BeadsErrorUnknown
+	}
+}
+
+// isBeadsNotFound reports whether err carries a bd not-found error.
+func isBeadsNotFound(err error) bool {
+	var be *BeadsError
+	return errors.As(err, &be) && be.Code == BeadsErrorNotFound
+}
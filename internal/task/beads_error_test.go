@@ -0,0 +1,109 @@
+package task
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// fakeFailingBeads writes a bd stand-in that prints stderr and exits with status 1.
+func fakeFailingBeads(t *testing.T, stderr string) string {
+	t.Helper()
+	binPath := filepath.Join(t.TempDir(), "bd")
+	script := "#!/bin/sh\ncat >&2 <<'JSON'\n" + stderr + "\nJSON\nexit 1\n"
+	if err := os.WriteFile(binPath, []byte(script), 0o700); err != nil {
+		t.Fatalf("write fake bd: %v", err)
+	}
+	return binPath
+}
+
+func TestBeadsTrackerTaskNotFound(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		bin  func(t *testing.T) string
+	}{
+		{name: "json_error", bin: func(t *testing.T) string {
+			return fakeFailingBeads(t, `{"error": "no issue found matching \"norma-zz\""}`)
+		}},
+		{name: "empty_result", bin: func(t *testing.T) string {
+			bin, _ := fakeBeads(t, "[]")
+			return bin
+		}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := NewBeadsTracker(tc.bin(t)).Task(context.Background(), "norma-zz")
+			if !errors.Is(err, ErrTaskNotFound) {
+				t.Fatalf("Task() error = %v, want ErrTaskNotFound", err)
+			}
+		})
+	}
+}
+
+func TestBeadsTrackerStructuredErrors(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		stderr   string
+		wantCode string
+		wantMsg  string
+	}{
+		{
+			name:     "validation",
+			stderr:   `{"error": "invalid status \"shipped\""}`,
+			wantCode: BeadsErrorInvalidArgument,
+			wantMsg:  `invalid status "shipped"`,
+		},
+		{
+			name:     "explicit_code",
+			stderr:   `{"error": "issue was modified concurrently", "code": "conflict"}`,
+			wantCode: BeadsErrorConflict,
+			wantMsg:  "issue was modified concurrently",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := NewBeadsTracker(fakeFailingBeads(t, tc.stderr)).MarkStatus(context.Background(), "norma-1", normaStatusDone)
+			var be *BeadsError
+			if !errors.As(err, &be) {
+				t.Fatalf("MarkStatus() error = %v, want *BeadsError", err)
+			}
+			if be.Code != tc.wantCode || be.Message != tc.wantMsg {
+				t.Fatalf("BeadsError = {%q, %q}, want {%q, %q}", be.Code, be.Message, tc.wantCode, tc.wantMsg)
+			}
+			if errors.Is(err, ErrTaskNotFound) {
+				t.Fatalf("MarkStatus() error = %v, must not be ErrTaskNotFound", err)
+			}
+			if errors.Is(err, ErrTrackerUnavailable) {
+				t.Fatalf("MarkStatus() error = %v, must not be ErrTrackerUnavailable", err)
+			}
+		})
+	}
+}
+
+func TestBeadsTrackerUnstructuredErrorKeepsStderr(t *testing.T) {
+	t.Parallel()
+
+	err := NewBeadsTracker(fakeFailingBeads(t, "database locked")).MarkStatus(context.Background(), "norma-1", normaStatusDone)
+	if err == nil {
+		t.Fatal("MarkStatus() error = nil, want error")
+	}
+	var be *BeadsError
+	if errors.As(err, &be) {
+		t.Fatalf("MarkStatus() error = %v, want plain exec error", err)
+	}
+	if !errors.Is(err, ErrTrackerUnavailable) {
+		t.Fatalf("MarkStatus() error = %v, want ErrTrackerUnavailable", err)
+	}
+}
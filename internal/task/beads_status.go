@@ -0,0 +1,88 @@
+package task
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// normaStatusOrder is the order used to resolve a beads status back to a norma status
+// when several norma statuses map to the same beads status.
+var normaStatusOrder = []string{
+	normaStatusTodo,
+	normaStatusDoing,
+	normaStatusDone,
+	normaStatusStopped,
+	normaStatusFailed,
+	normaStatusPlanning,
+	normaStatusChecking,
+	normaStatusActing,
+}
+
+// builtinBeadsStatuses are the statuses beads supports without custom configuration.
+var builtinBeadsStatuses = []string{statusOpen, statusInProgress, statusBlocked, statusDeferred, statusClosed}
+
+// ValidateStatusMap checks a norma to beads status mapping. Keys must be norma statuses
+// and values must be builtin beads statuses or one of customStatuses.
+func ValidateStatusMap(statusMap map[string]string, customStatuses []string) error {
+	errs := make([]string, 0)
+	for normaStatus, beadsStatus := range statusMap {
+		if !slices.Contains(normaStatusOrder, normaStatus) {
+			errs = append(errs, fmt.Sprintf("unknown norma status %q", normaStatus))
+			continue
+		}
+		if !slices.Contains(builtinBeadsStatuses, beadsStatus) && !slices.Contains(customStatuses, beadsStatus) {
+			errs = append(errs, fmt.Sprintf("%s maps to unknown beads status %q", normaStatus, beadsStatus))
+		}
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+	sort.Strings(errs)
+	return fmt.Errorf("invalid beads status map: %s", strings.Join(errs, "; "))
+}
+
+// beadsStatus returns the beads status used for a norma status.
+func (t *BeadsTracker) beadsStatus(status string) string {
+	if mapped := strings.TrimSpace(t.StatusMap[status]); mapped != "" {
+		return mapped
+	}
+	switch status {
+	case normaStatusTodo:
+		return statusOpen
+	case normaStatusPlanning, normaStatusDoing, normaStatusChecking, normaStatusActing:
+		return statusInProgress
+	case normaStatusDone:
+		return statusClosed
+	case normaStatusFailed:
+		// Beads doesn't have failed. Map to open by default.
+		return statusOpen
+	case normaStatusStopped:
+		return statusDeferred
+	default:
+		return status
+	}
+}
+
+// normaStatus returns the norma status for a beads status.
+// Granular workflow statuses resolve to doing; unknown statuses resolve to todo.
+func (t *BeadsTracker) normaStatus(beadsStatus string) string {
+	for _, status := range normaStatusOrder {
+		if t.beadsStatus(status) != beadsStatus {
+			continue
+		}
+		switch status {
+		case normaStatusPlanning, normaStatusChecking, normaStatusActing:
+			return normaStatusDoing
+		default:
+			return status
+		}
+	}
+	switch beadsStatus {
+	case normaStatusPlanning, normaStatusDoing, normaStatusChecking, normaStatusActing:
+		return normaStatusDoing
+	default:
+		return normaStatusTodo
+	}
+}
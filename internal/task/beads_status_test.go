@@ -0,0 +1,139 @@
+package task
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// fakeBeads writes a bd stand-in that logs its args and prints output.
+func fakeBeads(t *testing.T, output string) (binPath, argsLog string) {
+	t.Helper()
+	dir := t.TempDir()
+	argsLog = filepath.Join(dir, "args.log")
+	binPath = filepath.Join(dir, "bd")
+	script := "#!/bin/sh\necho \"$@\" >> '" + argsLog + "'\ncat <<'JSON'\n" + output + "\nJSON\n"
+	if err := os.WriteFile(binPath, []byte(script), 0o700); err != nil {
+		t.Fatalf("write fake bd: %v", err)
+	}
+	return binPath, argsLog
+}
+
+func readArgsLog(t *testing.T, path string) string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read args log: %v", err)
+	}
+	return string(data)
+}
+
+func TestBeadsTrackerStatusMapToBeads(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		call   func(context.Context, *BeadsTracker) error
+		status string
+	}{
+		{name: "mark_failed", status: "--status needs_attention", call: func(ctx context.Context, tr *BeadsTracker) error {
+			return tr.MarkStatus(ctx, "norma-1", normaStatusFailed)
+		}},
+		{name: "mark_done", status: "--status review", call: func(ctx context.Context, tr *BeadsTracker) error {
+			return tr.MarkDone(ctx, "norma-1")
+		}},
+		{name: "workflow_state", status: "--status active", call: func(ctx context.Context, tr *BeadsTracker) error {
+			return tr.UpdateWorkflowState(ctx, "norma-1", normaStatusPlanning)
+		}},
+		{name: "list_filter", status: "--status review", call: func(ctx context.Context, tr *BeadsTracker) error {
+			status := normaStatusDone
+			_, err := tr.List(ctx, &status)
+			return err
+		}},
+		{name: "default_stopped", status: "--status deferred", call: func(ctx context.Context, tr *BeadsTracker) error {
+			return tr.MarkStatus(ctx, "norma-1", normaStatusStopped)
+		}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			bin, argsLog := fakeBeads(t, "[]")
+			tr := NewBeadsTracker(bin)
+			tr.StatusMap = map[string]string{
+				normaStatusFailed:   "needs_attention",
+				normaStatusDone:     "review",
+				normaStatusPlanning: "active",
+			}
+			if err := tc.call(context.Background(), tr); err != nil {
+				t.Fatalf("call error = %v", err)
+			}
+			if got := readArgsLog(t, argsLog); !strings.Contains(got, tc.status) {
+				t.Fatalf("bd args = %q, want %q", got, tc.status)
+			}
+		})
+	}
+}
+
+func TestBeadsTrackerStatusMapFromBeads(t *testing.T) {
+	t.Parallel()
+
+	tr := &BeadsTracker{StatusMap: map[string]string{
+		normaStatusFailed:   "needs_attention",
+		normaStatusDone:     "review",
+		normaStatusPlanning: "active",
+	}}
+
+	tests := []struct {
+		beads string
+		want  string
+	}{
+		{beads: "needs_attention", want: normaStatusFailed},
+		{beads: "review", want: normaStatusDone},
+		{beads: "active", want: normaStatusDoing},
+		{beads: statusOpen, want: normaStatusTodo},
+		{beads: statusInProgress, want: normaStatusDoing},
+		{beads: statusDeferred, want: normaStatusStopped},
+		{beads: statusClosed, want: normaStatusTodo},
+	}
+	for _, tc := range tests {
+		if got := tr.toTask(BeadsIssue{ID: "norma-1", Status: tc.beads}).Status; got != tc.want {
+			t.Fatalf("toTask(status %q).Status = %q, want %q", tc.beads, got, tc.want)
+		}
+	}
+}
+
+func TestBeadsTrackerDefaultStatusMapping(t *testing.T) {
+	t.Parallel()
+
+	tr := NewBeadsTracker("")
+	tests := map[string]string{
+		statusOpen:       normaStatusTodo,
+		statusInProgress: normaStatusDoing,
+		statusClosed:     normaStatusDone,
+		statusDeferred:   normaStatusStopped,
+		statusBlocked:    normaStatusTodo,
+	}
+	for beads, want := range tests {
+		if got := tr.normaStatus(beads); got != want {
+			t.Fatalf("normaStatus(%q) = %q, want %q", beads, got, want)
+		}
+	}
+}
+
+func TestValidateStatusMap(t *testing.T) {
+	t.Parallel()
+
+	if err := ValidateStatusMap(map[string]string{"failed": "blocked", "done": "review"}, []string{"review"}); err != nil {
+		t.Fatalf("ValidateStatusMap() error = %v", err)
+	}
+	if err := ValidateStatusMap(map[string]string{"done": "review"}, nil); err == nil {
+		t.Fatal("ValidateStatusMap() error = nil, want unknown beads status")
+	}
+	if err := ValidateStatusMap(map[string]string{"shipped": "closed"}, nil); err == nil {
+		t.Fatal("ValidateStatusMap() error = nil, want unknown norma status")
+	}
+}
@@ -14,6 +14,7 @@ import (
 const (
 	statusOpen       = "open"
 	statusInProgress = "in_progress"
+	statusBlocked    = "blocked"
 	statusClosed     = "closed"
 	statusDeferred   = "deferred"
 
@@ -31,6 +32,8 @@ const (
 type BeadsTracker struct {
 	// Optional: path to bd executable. If empty, uses "bd" from PATH.
 	BinPath string
+	// Optional: norma status to beads status overrides for custom beads workflows.
+	StatusMap map[string]string
 }
 
 // NewBeadsTracker creates a new beads tracker.
@@ -139,22 +142,7 @@ func (t *BeadsTracker) AddFeatureDetailed(ctx context.Context, epicID, title, de
 func (t *BeadsTracker) List(ctx context.Context, status *string) ([]Task, error) {
 	args := []string{"list", "--json", "--quiet", "--limit", "0"}
 	if status != nil {
-		// Map norma status to beads status
-		beadsStatus := *status
-		switch *status {
-		case normaStatusTodo:
-			beadsStatus = statusOpen
-		case normaStatusPlanning, normaStatusDoing, normaStatusChecking, normaStatusActing:
-			beadsStatus = statusInProgress
-		case normaStatusDone:
-			beadsStatus = statusClosed
-		case normaStatusFailed:
-			// Beads doesn't have failed. Map to open for now.
-			beadsStatus = statusOpen
-		case normaStatusStopped:
-			beadsStatus = statusDeferred
-		}
-		args = append(args, "--status", beadsStatus)
+		args = append(args, "--status", t.beadsStatus(*status))
 	} else {
 		args = append(args, "--all")
 	}
@@ -232,7 +220,9 @@ func (t *BeadsTracker) Task(ctx context.Context, id string) (Task, error) {
 	args := []string{"show", id, "--json", "--quiet"}
 	out, err := t.exec(ctx, args...)
 	if err != nil {
-		// bd show returns error if not found?
+		if isBeadsNotFound(err) {
+			return Task{}, fmt.Errorf("task %s: %w (%w)", id, ErrTaskNotFound, err)
+		}
 		return Task{}, fmt.Errorf("bd show: %w", err)
 	}
 
@@ -242,7 +232,7 @@ func (t *BeadsTracker) Task(ctx context.Context, id string) (Task, error) {
 		return Task{}, fmt.Errorf("parse bd show: %w", err)
 	}
 	if len(issues) == 0 {
-		return Task{}, fmt.Errorf("task %s not found", id)
+		return Task{}, fmt.Errorf("task %s: %w", id, ErrTaskNotFound)
 	}
 	return t.toTask(issues[0]), nil
 }
@@ -254,7 +244,7 @@ func (t *BeadsTracker) MarkDone(ctx context.Context, id string) error {
 		"norma-has-plan", "norma-has-do", "norma-has-check",
 	}
 	args := make([]string, 0, 6+2*len(allLabels))
-	args = append(args, "update", id, "--status", statusClosed, "--json", "--quiet")
+	args = append(args, "update", id, "--status", t.beadsStatus(normaStatusDone), "--json", "--quiet")
 	for _, l := range allLabels {
 		args = append(args, "--remove-label", l)
 	}
@@ -264,23 +254,16 @@ func (t *BeadsTracker) MarkDone(ctx context.Context, id string) error {
 
 // MarkStatus updates task status.
 func (t *BeadsTracker) MarkStatus(ctx context.Context, id string, status string) error {
-	beadsStatus := status
 	removeLabels := []string{normaStatusPlanning, normaStatusDoing, normaStatusChecking, normaStatusActing}
 	switch status {
 	case normaStatusTodo:
-		beadsStatus = statusOpen
 		// Also remove skip labels for a clean reset
 		removeLabels = append(removeLabels, "norma-has-plan", "norma-has-do", "norma-has-check")
 	case normaStatusPlanning, normaStatusDoing, normaStatusChecking, normaStatusActing:
 		// When using these granular statuses, we also update labels
 		return t.UpdateWorkflowState(ctx, id, status)
-	case normaStatusDone:
-		beadsStatus = statusClosed
-	case normaStatusFailed:
-		beadsStatus = statusOpen
-	case normaStatusStopped:
-		beadsStatus = statusDeferred
 	}
+	beadsStatus := t.beadsStatus(status)
 
 	args := []string{"update", id, "--status", beadsStatus, "--json", "--quiet"}
 	for _, label := range removeLabels {
@@ -294,7 +277,7 @@ func (t *BeadsTracker) MarkStatus(ctx context.Context, id string, status string)
 // UpdateWorkflowState updates the granular workflow state using labels.
 func (t *BeadsTracker) UpdateWorkflowState(ctx context.Context, id string, state string) error {
 	allStates := []string{normaStatusPlanning, normaStatusDoing, normaStatusChecking, normaStatusActing}
-	args := []string{"update", id, "--status", statusInProgress, "--json", "--quiet"}
+	args := []string{"update", id, "--status", t.beadsStatus(state), "--json", "--quiet"}
 
 	for _, s := range allStates {
 		if s == state {
@@ -391,24 +374,19 @@ func (t *BeadsTracker) exec(ctx context.Context, args ...string) ([]byte, error)
 	cmd.Env = os.Environ()
 
 	if err := cmd.Run(); err != nil {
-		return nil, fmt.Errorf("exec %s %v: %w (stderr: %s)", t.BinPath, args, err, stderr.String())
+		if be := parseBeadsError(stderr.Bytes(), stdout.Bytes()); be != nil {
+			return nil, fmt.Errorf("exec %s %v: %w", t.BinPath, args, be)
+		}
+		if ctx.Err() != nil {
+			return nil, fmt.Errorf("exec %s %v: %w (stderr: %s)", t.BinPath, args, err, stderr.String())
+		}
+		return nil, fmt.Errorf("exec %s %v: %w: %w (stderr: %s)", t.BinPath, args, ErrTrackerUnavailable, err, stderr.String())
 	}
 	return stdout.Bytes(), nil
 }
 
 func (t *BeadsTracker) toTask(issue BeadsIssue) Task {
-	status := normaStatusTodo
-	switch issue.Status {
-	case statusOpen:
-		status = normaStatusTodo
-	case statusInProgress, normaStatusPlanning, normaStatusDoing, normaStatusChecking, normaStatusActing:
-		status = normaStatusDoing
-	case statusClosed:
-		status = normaStatusDone
-	case statusDeferred:
-		status = normaStatusStopped
-		// default keeps "todo"
-	}
+	status := t.normaStatus(issue.Status)
 
 	goal := strings.TrimSpace(issue.Description)
 	goal, legacyAC := splitLegacyAC(goal)
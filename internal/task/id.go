@@ -0,0 +1,33 @@
+package task
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// idPattern matches norma task IDs, including hierarchical dotted IDs like norma-4pm.1.1.
+var idPattern = regexp.MustCompile(`^norma-[a-z0-9]+(?:\.[a-z0-9]+)*$`)
+
+// ValidateID reports an error if id is not a valid norma task ID.
+func ValidateID(id string) error {
+	if !idPattern.MatchString(id) {
+		return fmt.Errorf("invalid task id: %s", id)
+	}
+	return nil
+}
+
+// BranchSlug returns a branch-safe form of a task ID with dots replaced by dashes.
+// Valid IDs only contain dashes in their prefix, so slugs of valid IDs never collide.
+func BranchSlug(id string) string {
+	return strings.ReplaceAll(id, ".", "-")
+}
+
+// BranchName returns the git branch holding work for a task.
+// A non-empty runID scopes the branch to that run: norma/task/<slug>/<run-id>.
+func BranchName(id, runID string) string {
+	if runID = strings.TrimSpace(runID); runID != "" {
+		return fmt.Sprintf("norma/task/%s/%s", BranchSlug(id), runID)
+	}
+	return fmt.Sprintf("norma/task/%s", BranchSlug(id))
+}
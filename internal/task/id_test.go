@@ -0,0 +1,71 @@
+package task
+
+import "testing"
+
+func TestValidateID(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		id   string
+		want bool
+	}{
+		{name: "flat", id: "norma-a3f2dd", want: true},
+		{name: "single segment with digits", id: "norma-01", want: true},
+		{name: "hierarchical dotted", id: "norma-4pm.1.1", want: true},
+		{name: "uppercase rejected", id: "norma-ABC", want: false},
+		{name: "wrong prefix rejected", id: "task-a3f2dd", want: false},
+		{name: "double dot rejected", id: "norma-a..1", want: false},
+		{name: "trailing dot rejected", id: "norma-a.", want: false},
+		{name: "inner dash rejected", id: "norma-a-1", want: false},
+		{name: "empty rejected", id: "", want: false},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			if got := ValidateID(tc.id) == nil; got != tc.want {
+				t.Fatalf("ValidateID(%q) ok = %v, want %v", tc.id, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestBranchSlug(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		id   string
+		want string
+	}{
+		{id: "norma-a3f2dd", want: "norma-a3f2dd"},
+		{id: "norma-4pm.1", want: "norma-4pm-1"},
+		{id: "norma-4pm.1.12", want: "norma-4pm-1-12"},
+	}
+
+	for _, tc := range tests {
+		if got := BranchSlug(tc.id); got != tc.want {
+			t.Fatalf("BranchSlug(%q) = %q, want %q", tc.id, got, tc.want)
+		}
+	}
+}
+
+func TestBranchName(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		id    string
+		runID string
+		want  string
+	}{
+		{id: "norma-4pm.1", want: "norma/task/norma-4pm-1"},
+		{id: "norma-4pm.1", runID: "20260101-120000-abc123", want: "norma/task/norma-4pm-1/20260101-120000-abc123"},
+		{id: "norma-a1", runID: "  ", want: "norma/task/norma-a1"},
+	}
+
+	for _, tc := range tests {
+		if got := BranchName(tc.id, tc.runID); got != tc.want {
+			t.Fatalf("BranchName(%q, %q) = %q, want %q", tc.id, tc.runID, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package verify
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/metalagman/norma/internal/agents/pdca/roles/check"
+	"github.com/metalagman/norma/internal/agents/pdca/roles/plan"
+	"github.com/metalagman/norma/internal/git"
+	"github.com/rs/zerolog/log"
+)
+
+// VerifyTask runs the checks of acs against branch outside of a run. The branch is checked
+// out as a detached worktree in a temporary directory, so checks can neither move the branch
+// nor touch the caller's workspace; the worktree is removed before returning.
+// Up to parallelism checks run at once, as in RunAcceptanceChecks; callers running under a
+// config pass its check_parallelism and OptionsFromConfig.
+//
+// The worktree is not read-only: checks that write to it succeed, and their changes are
+// only logged as a warning before being discarded with the worktree.
+func VerifyTask(ctx context.Context, repoRoot, branch string, acs []plan.EffectiveAcceptanceCriteria, parallelism int, opts ...Option) ([]check.CheckAcceptanceResult, error) {
+	if !git.Available(ctx, repoRoot) {
+		return nil, fmt.Errorf("not a git repository: %s", repoRoot)
+	}
+	commit, err := git.GitRunCmdOutput(ctx, repoRoot, "git", "rev-parse", "--verify", branch+"^{commit}")
+	if err != nil {
+		return nil, fmt.Errorf("resolve branch %s: %w", branch, err)
+	}
+	commit = strings.TrimSpace(commit)
+
+	worktreeDir, err := os.MkdirTemp("", "norma-verify-*")
+	if err != nil {
+		return nil, fmt.Errorf("create verify worktree dir: %w", err)
+	}
+	defer func() { _ = os.RemoveAll(worktreeDir) }()
+
+	if err := git.GitRunCmdErr(ctx, repoRoot, "git", "worktree", "add", "--detach", worktreeDir, commit); err != nil {
+		return nil, fmt.Errorf("git worktree add %s: %w", branch, err)
+	}
+	defer func() {
+		// Use a fresh context so a cancelled run still removes the worktree.
+		if err := git.GitRunCmdErr(context.WithoutCancel(ctx), repoRoot, "git", "worktree", "remove", "--force", worktreeDir); err != nil {
+			log.Warn().Err(err).Str("worktree", worktreeDir).Msg("failed to remove verify worktree")
+		}
+	}()
+
+	results := RunAcceptanceChecks(ctx, worktreeDir, acs, parallelism, opts...)
+
+	if status, err := git.GitRunCmdOutput(ctx, worktreeDir, "git", "status", "--porcelain"); err == nil && strings.TrimSpace(status) != "" {
+		log.Warn().Str("branch", branch).Str("status", strings.TrimSpace(status)).Msg("acceptance checks modified the verify worktree")
+	}
+	return results, nil
+}
@@ -0,0 +1,84 @@
+package verify
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/metalagman/norma/internal/agents/pdca/roles/plan"
+)
+
+func TestVerifyTask(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	repo := t.TempDir()
+	runTaskGit(t, repo, "init", "-b", "master")
+	runTaskGit(t, repo, "config", "user.name", "Norma Test")
+	runTaskGit(t, repo, "config", "user.email", "norma-test@example.com")
+	writeTaskFile(t, filepath.Join(repo, "README.md"), "base\n")
+	runTaskGit(t, repo, "add", "-A")
+	runTaskGit(t, repo, "commit", "-m", "base")
+	runTaskGit(t, repo, "checkout", "-b", "norma/task/norma-1")
+	writeTaskFile(t, filepath.Join(repo, "feature.txt"), "done\n")
+	runTaskGit(t, repo, "add", "-A")
+	runTaskGit(t, repo, "commit", "-m", "feature")
+	runTaskGit(t, repo, "checkout", "master")
+
+	acs := []plan.EffectiveAcceptanceCriteria{
+		{Id: "AC1", Checks: []plan.CriterionCheck{{Id: "CHK-1", Cmd: "grep -q done feature.txt", ExpectExitCodes: []int64{0}}}},
+		{Id: "AC2", Checks: []plan.CriterionCheck{{Id: "CHK-2", Cmd: "test -f missing.txt", ExpectExitCodes: []int64{0}}}},
+	}
+
+	results, err := VerifyTask(ctx, repo, "norma/task/norma-1", acs, 2)
+	if err != nil {
+		t.Fatalf("VerifyTask() error = %v", err)
+	}
+	if len(results) != 2 || results[0].Result != "PASS" || results[1].Result != "FAIL" {
+		t.Fatalf("VerifyTask() = %+v, want AC1 PASS and AC2 FAIL", results)
+	}
+	if !strings.Contains(results[1].Notes, "exited 1") {
+		t.Fatalf("AC2 notes = %q, want exit code", results[1].Notes)
+	}
+
+	// The base branch lacks the feature, so the same checks fail there.
+	results, err = VerifyTask(ctx, repo, "master", acs[:1], 1)
+	if err != nil {
+		t.Fatalf("VerifyTask(master) error = %v", err)
+	}
+	if results[0].Result != "FAIL" {
+		t.Fatalf("VerifyTask(master) = %+v, want AC1 FAIL", results)
+	}
+
+	if worktrees := runTaskGit(t, repo, "worktree", "list", "--porcelain"); strings.Count(worktrees, "worktree ") != 1 {
+		t.Fatalf("verify worktrees left behind:\n%s", worktrees)
+	}
+	if _, err := os.Stat(filepath.Join(repo, "feature.txt")); !os.IsNotExist(err) {
+		t.Fatalf("caller checkout changed: stat feature.txt error = %v", err)
+	}
+
+	if _, err := VerifyTask(ctx, repo, "no-such-branch", acs, 1); err == nil {
+		t.Fatal("VerifyTask() error = nil for an unknown branch")
+	}
+}
+
+func runTaskGit(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("git %s: %v\n%s", strings.Join(args, " "), err, out)
+	}
+	return string(out)
+}
+
+func writeTaskFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("write %s: %v", path, err)
+	}
+}
@@ -0,0 +1,195 @@
+// Package verify runs deterministic acceptance checks against a task workspace.
+package verify
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os/exec"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/metalagman/norma/internal/agents/pdca/roles/check"
+	"github.com/metalagman/norma/internal/agents/pdca/roles/plan"
+	"github.com/metalagman/norma/internal/config"
+)
+
+// maxNotesOutput caps how much command output is kept in failure notes.
+const maxNotesOutput = 2048
+
+// commandFunc runs cmd in dir and returns its exit code and combined output.
+type commandFunc func(ctx context.Context, dir, cmd string) (int, string, error)
+
+// Check modes of a plan.CriterionCheck. An empty mode is ModeCommand.
+const (
+	// ModeCommand runs the check's cmd in the workspace and compares its exit code.
+	ModeCommand = "command"
+	// ModeWebhook posts the acceptance criterion to the check's url and reads the verdict from the response.
+	ModeWebhook = "webhook"
+)
+
+// Option configures RunAcceptanceChecks.
+type Option func(*checker)
+
+// WithWebhooks allows checks in webhook mode, posted with client.
+// A nil client uses one with defaultWebhookTimeout.
+// Without this option webhook checks fail without being sent.
+func WithWebhooks(client *http.Client) Option {
+	return func(c *checker) {
+		if client == nil {
+			client = &http.Client{Timeout: defaultWebhookTimeout}
+		}
+		c.webhookClient = client
+	}
+}
+
+// OptionsFromConfig returns the options enabled by cfg.
+func OptionsFromConfig(cfg config.Config) []Option {
+	var opts []Option
+	if cfg.AllowWebhookChecks {
+		opts = append(opts, WithWebhooks(nil))
+	}
+	return opts
+}
+
+// checker runs individual checks.
+type checker struct {
+	run           commandFunc
+	webhookClient *http.Client
+}
+
+// RunAcceptanceChecks runs the checks of every effective acceptance criterion in workspaceDir.
+// Up to parallelism checks run at once; values below 1 run checks sequentially.
+// Results are returned in criteria order regardless of completion order.
+// Commands are expected to be read-only: they inspect the workspace and must not modify it.
+func RunAcceptanceChecks(ctx context.Context, workspaceDir string, criteria []plan.EffectiveAcceptanceCriteria, parallelism int, opts ...Option) []check.CheckAcceptanceResult {
+	c := &checker{run: runShell}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c.runAcceptanceChecks(ctx, workspaceDir, criteria, parallelism)
+}
+
+type checkJob struct {
+	acIndex    int
+	checkIndex int
+	check      plan.CriterionCheck
+}
+
+type checkOutcome struct {
+	passed bool
+	notes  string
+}
+
+func runAcceptanceChecks(ctx context.Context, workspaceDir string, criteria []plan.EffectiveAcceptanceCriteria, parallelism int, run commandFunc) []check.CheckAcceptanceResult {
+	return (&checker{run: run}).runAcceptanceChecks(ctx, workspaceDir, criteria, parallelism)
+}
+
+func (c *checker) runAcceptanceChecks(ctx context.Context, workspaceDir string, criteria []plan.EffectiveAcceptanceCriteria, parallelism int) []check.CheckAcceptanceResult {
+	outcomes := make([][]checkOutcome, len(criteria))
+	jobs := make([]checkJob, 0)
+	for i, ac := range criteria {
+		outcomes[i] = make([]checkOutcome, len(ac.Checks))
+		for j, chk := range ac.Checks {
+			jobs = append(jobs, checkJob{acIndex: i, checkIndex: j, check: chk})
+		}
+	}
+
+	workers := max(parallelism, 1)
+	sem := make(chan struct{}, workers)
+	var wg sync.WaitGroup
+	for _, job := range jobs {
+		sem <- struct{}{}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			// Each job writes only its own slot, so no further locking is needed.
+			outcomes[job.acIndex][job.checkIndex] = c.runCheck(ctx, workspaceDir, criteria[job.acIndex], job.check)
+		}()
+	}
+	wg.Wait()
+
+	results := make([]check.CheckAcceptanceResult, 0, len(criteria))
+	for i, ac := range criteria {
+		result := check.CheckAcceptanceResult{AcId: ac.Id, Result: "PASS"}
+		if len(ac.Checks) == 0 {
+			result.Result = "FAIL"
+			result.Notes = "no checks defined"
+			results = append(results, result)
+			continue
+		}
+		notes := make([]string, 0)
+		for _, outcome := range outcomes[i] {
+			if !outcome.passed {
+				result.Result = "FAIL"
+			}
+			if outcome.notes != "" {
+				notes = append(notes, outcome.notes)
+			}
+		}
+		result.Notes = strings.Join(notes, "\n")
+		results = append(results, result)
+	}
+	return results
+}
+
+func (c *checker) runCheck(ctx context.Context, dir string, ac plan.EffectiveAcceptanceCriteria, chk plan.CriterionCheck) checkOutcome {
+	if err := ctx.Err(); err != nil {
+		return checkOutcome{notes: fmt.Sprintf("%s: not run: %v", chk.Id, err)}
+	}
+	switch chk.Mode {
+	case "", ModeCommand:
+	case ModeWebhook:
+		if c.webhookClient == nil {
+			return checkOutcome{notes: fmt.Sprintf("%s: webhook checks are disabled (allow_webhook_checks)", chk.Id)}
+		}
+		return runWebhookCheck(ctx, c.webhookClient, ac, chk)
+	default:
+		return checkOutcome{notes: fmt.Sprintf("%s: unsupported check mode %q", chk.Id, chk.Mode)}
+	}
+	exitCode, output, err := c.run(ctx, dir, chk.Cmd)
+	if err != nil {
+		return checkOutcome{notes: fmt.Sprintf("%s: run %q: %v", chk.Id, chk.Cmd, err)}
+	}
+
+	expected := chk.ExpectExitCodes
+	if len(expected) == 0 {
+		expected = []int64{0}
+	}
+	if slices.Contains(expected, int64(exitCode)) {
+		return checkOutcome{passed: true}
+	}
+
+	notes := fmt.Sprintf("%s: %q exited %d, want %v", chk.Id, chk.Cmd, exitCode, expected)
+	if output = strings.TrimSpace(output); output != "" {
+		notes += "\n" + truncateNotes(output)
+	}
+	return checkOutcome{notes: notes}
+}
+
+// RunCommand runs cmd with sh -c in dir and returns its exit code and combined output.
+// A nonzero exit is reported through the exit code; err is set only when cmd could not run.
+func RunCommand(ctx context.Context, dir, cmd string) (int, string, error) {
+	return runShell(ctx, dir, cmd)
+}
+
+func runShell(ctx context.Context, dir, cmd string) (int, string, error) {
+	c := exec.CommandContext(ctx, "sh", "-c", cmd)
+	c.Dir = dir
+	var out bytes.Buffer
+	c.Stdout = &out
+	c.Stderr = &out
+	err := c.Run()
+	if err == nil {
+		return 0, out.String(), nil
+	}
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return exitErr.ExitCode(), out.String(), nil
+	}
+	return -1, out.String(), err
+}
@@ -0,0 +1,132 @@
+package verify
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/metalagman/norma/internal/agents/pdca/roles/plan"
+)
+
+func TestRunAcceptanceChecksShell(t *testing.T) {
+	t.Parallel()
+
+	criteria := []plan.EffectiveAcceptanceCriteria{
+		{Id: "AC1", Checks: []plan.CriterionCheck{{Id: "CHK-1", Cmd: "test -f marker.txt", ExpectExitCodes: []int64{0}}}},
+		{Id: "AC2", Checks: []plan.CriterionCheck{{Id: "CHK-2", Cmd: "echo boom; exit 3", ExpectExitCodes: []int64{0}}}},
+		{Id: "AC3", Checks: []plan.CriterionCheck{{Id: "CHK-3", Cmd: "exit 3", ExpectExitCodes: []int64{3}}}},
+		{Id: "AC4"},
+	}
+	dir := t.TempDir()
+	writeMarker(t, dir)
+
+	got := RunAcceptanceChecks(context.Background(), dir, criteria, 4)
+
+	want := []string{"PASS", "FAIL", "PASS", "FAIL"}
+	if len(got) != len(want) {
+		t.Fatalf("len(results) = %d, want %d", len(got), len(want))
+	}
+	for i, w := range want {
+		if got[i].AcId != criteria[i].Id || got[i].Result != w {
+			t.Fatalf("results[%d] = %+v, want %s %s", i, got[i], criteria[i].Id, w)
+		}
+	}
+	if !strings.Contains(got[1].Notes, "exited 3") || !strings.Contains(got[1].Notes, "boom") {
+		t.Fatalf("AC2 notes = %q, want exit code and output", got[1].Notes)
+	}
+}
+
+func TestRunAcceptanceChecksOrderedAndBounded(t *testing.T) {
+	t.Parallel()
+
+	// Earlier checks sleep longer so they complete last.
+	criteria := make([]plan.EffectiveAcceptanceCriteria, 0, 6)
+	delays := map[string]time.Duration{}
+	for i := range 6 {
+		id := string(rune('A' + i))
+		delays[id] = time.Duration(6-i) * 5 * time.Millisecond
+		criteria = append(criteria, plan.EffectiveAcceptanceCriteria{
+			Id:     "AC-" + id,
+			Checks: []plan.CriterionCheck{{Id: "CHK-" + id, Cmd: id}},
+		})
+	}
+
+	const limit = 2
+	var inFlight, peak atomic.Int32
+	var mu sync.Mutex
+	completed := make([]string, 0, len(criteria))
+	run := func(_ context.Context, _ string, cmd string) (int, string, error) {
+		n := inFlight.Add(1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		time.Sleep(delays[cmd])
+		inFlight.Add(-1)
+		mu.Lock()
+		completed = append(completed, cmd)
+		mu.Unlock()
+		if cmd == "C" {
+			return 1, "", nil
+		}
+		return 0, "", nil
+	}
+
+	got := runAcceptanceChecks(context.Background(), "", criteria, limit, run)
+
+	if p := peak.Load(); p > limit {
+		t.Fatalf("peak concurrency = %d, want <= %d", p, limit)
+	}
+	if len(got) != len(criteria) {
+		t.Fatalf("len(results) = %d, want %d", len(got), len(criteria))
+	}
+	for i, ac := range criteria {
+		if got[i].AcId != ac.Id {
+			t.Fatalf("results[%d].AcId = %q, want %q (completion order %v)", i, got[i].AcId, ac.Id, completed)
+		}
+		want := "PASS"
+		if ac.Id == "AC-C" {
+			want = "FAIL"
+		}
+		if got[i].Result != want {
+			t.Fatalf("results[%d].Result = %q, want %q", i, got[i].Result, want)
+		}
+	}
+}
+
+func TestRunAcceptanceChecksSequentialByDefault(t *testing.T) {
+	t.Parallel()
+
+	criteria := []plan.EffectiveAcceptanceCriteria{
+		{Id: "AC1", Checks: []plan.CriterionCheck{{Id: "CHK-1", Cmd: "a"}, {Id: "CHK-2", Cmd: "b"}}},
+		{Id: "AC2", Checks: []plan.CriterionCheck{{Id: "CHK-3", Cmd: "c"}}},
+	}
+	var inFlight, peak atomic.Int32
+	run := func(context.Context, string, string) (int, string, error) {
+		n := inFlight.Add(1)
+		if n > peak.Load() {
+			peak.Store(n)
+		}
+		time.Sleep(time.Millisecond)
+		inFlight.Add(-1)
+		return 0, "", nil
+	}
+
+	runAcceptanceChecks(context.Background(), "", criteria, 0, run)
+
+	if p := peak.Load(); p != 1 {
+		t.Fatalf("peak concurrency = %d, want 1", p)
+	}
+}
+
+func writeMarker(t *testing.T, dir string) {
+	t.Helper()
+	if _, _, err := runShell(context.Background(), dir, "touch marker.txt"); err != nil {
+		t.Fatalf("create marker: %v", err)
+	}
+}
@@ -0,0 +1,92 @@
+package verify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/metalagman/norma/internal/agents/pdca/roles/plan"
+)
+
+// defaultWebhookTimeout bounds a webhook check when no client is given.
+const defaultWebhookTimeout = 30 * time.Second
+
+// maxWebhookResponse caps how much of a webhook response body is read.
+const maxWebhookResponse = 1 << 20
+
+// WebhookRequest is the JSON body posted to a webhook check.
+type WebhookRequest struct {
+	AcID    string `json:"ac_id"`
+	AcText  string `json:"ac_text"`
+	CheckID string `json:"check_id"`
+	Cmd     string `json:"cmd,omitempty"`
+}
+
+// WebhookResponse is the JSON verdict a webhook check returns.
+type WebhookResponse struct {
+	Pass  bool   `json:"pass"`
+	Notes string `json:"notes"`
+}
+
+func runWebhookCheck(ctx context.Context, client *http.Client, ac plan.EffectiveAcceptanceCriteria, chk plan.CriterionCheck) checkOutcome {
+	url := strings.TrimSpace(chk.Url)
+	if url == "" {
+		return checkOutcome{notes: fmt.Sprintf("%s: webhook check has no url", chk.Id)}
+	}
+	resp, err := postWebhook(ctx, client, url, WebhookRequest{AcID: ac.Id, AcText: ac.Text, CheckID: chk.Id, Cmd: chk.Cmd})
+	if err != nil {
+		return checkOutcome{notes: fmt.Sprintf("%s: webhook %s: %v", chk.Id, url, err)}
+	}
+	notes := strings.TrimSpace(resp.Notes)
+	if notes != "" {
+		notes = fmt.Sprintf("%s: %s", chk.Id, truncateNotes(notes))
+	}
+	if !resp.Pass && notes == "" {
+		notes = fmt.Sprintf("%s: webhook %s reported failure", chk.Id, url)
+	}
+	return checkOutcome{passed: resp.Pass, notes: notes}
+}
+
+func postWebhook(ctx context.Context, client *http.Client, url string, body WebhookRequest) (WebhookResponse, error) {
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return WebhookResponse{}, fmt.Errorf("marshal request: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(payload))
+	if err != nil {
+		return WebhookResponse{}, fmt.Errorf("build request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	httpResp, err := client.Do(req)
+	if err != nil {
+		return WebhookResponse{}, err
+	}
+	defer func() { _ = httpResp.Body.Close() }()
+
+	data, err := io.ReadAll(io.LimitReader(httpResp.Body, maxWebhookResponse))
+	if err != nil {
+		return WebhookResponse{}, fmt.Errorf("read response: %w", err)
+	}
+	if httpResp.StatusCode < 200 || httpResp.StatusCode > 299 {
+		return WebhookResponse{}, fmt.Errorf("status %d: %s", httpResp.StatusCode, truncateNotes(strings.TrimSpace(string(data))))
+	}
+	var resp WebhookResponse
+	if err := json.Unmarshal(data, &resp); err != nil {
+		return WebhookResponse{}, fmt.Errorf("decode response: %w", err)
+	}
+	return resp, nil
+}
+
+// truncateNotes keeps the last maxNotesOutput bytes of s.
+func truncateNotes(s string) string {
+	if len(s) > maxNotesOutput {
+		return s[len(s)-maxNotesOutput:]
+	}
+	return s
+}
@@ -0,0 +1,90 @@
+package verify
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/metalagman/norma/internal/agents/pdca/roles/plan"
+	"github.com/metalagman/norma/internal/config"
+)
+
+func TestRunAcceptanceChecksWebhook(t *testing.T) {
+	t.Parallel()
+
+	var mu sync.Mutex
+	received := map[string]WebhookRequest{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req WebhookRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		mu.Lock()
+		received[req.CheckID] = req
+		mu.Unlock()
+		switch r.URL.Path {
+		case "/pass":
+			_ = json.NewEncoder(w).Encode(WebhookResponse{Pass: true})
+		case "/fail":
+			_ = json.NewEncoder(w).Encode(WebhookResponse{Pass: false, Notes: "deploy check red"})
+		default:
+			http.Error(w, "no such check", http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	criteria := []plan.EffectiveAcceptanceCriteria{
+		{Id: "AC1", Text: "CI is green", Checks: []plan.CriterionCheck{{Id: "CHK-1", Mode: ModeWebhook, Url: srv.URL + "/pass"}}},
+		{Id: "AC2", Text: "deploy works", Checks: []plan.CriterionCheck{{Id: "CHK-2", Mode: ModeWebhook, Url: srv.URL + "/fail"}}},
+		{Id: "AC3", Text: "missing", Checks: []plan.CriterionCheck{{Id: "CHK-3", Mode: ModeWebhook, Url: srv.URL + "/missing"}}},
+	}
+
+	got := RunAcceptanceChecks(context.Background(), t.TempDir(), criteria, 2, WithWebhooks(srv.Client()))
+
+	want := []string{"PASS", "FAIL", "FAIL"}
+	for i, w := range want {
+		if got[i].AcId != criteria[i].Id || got[i].Result != w {
+			t.Fatalf("results[%d] = %+v, want %s %s", i, got[i], criteria[i].Id, w)
+		}
+	}
+	if !strings.Contains(got[1].Notes, "deploy check red") {
+		t.Fatalf("AC2 notes = %q, want webhook notes", got[1].Notes)
+	}
+	if !strings.Contains(got[2].Notes, "status 404") {
+		t.Fatalf("AC3 notes = %q, want HTTP status", got[2].Notes)
+	}
+	if req := received["CHK-1"]; req.AcID != "AC1" || req.AcText != "CI is green" {
+		t.Fatalf("posted request = %+v, want AC1 context", req)
+	}
+}
+
+func TestRunAcceptanceChecksWebhookDisabled(t *testing.T) {
+	t.Parallel()
+
+	called := false
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		called = true
+		_ = json.NewEncoder(w).Encode(WebhookResponse{Pass: true})
+	}))
+	t.Cleanup(srv.Close)
+
+	criteria := []plan.EffectiveAcceptanceCriteria{
+		{Id: "AC1", Checks: []plan.CriterionCheck{{Id: "CHK-1", Mode: ModeWebhook, Url: srv.URL}}},
+	}
+	got := RunAcceptanceChecks(context.Background(), t.TempDir(), criteria, 1, OptionsFromConfig(config.Config{})...)
+
+	if got[0].Result != "FAIL" || !strings.Contains(got[0].Notes, "allow_webhook_checks") {
+		t.Fatalf("result = %+v, want FAIL with disabled notes", got[0])
+	}
+	if called {
+		t.Fatal("webhook was called while webhook checks are disabled")
+	}
+	if opts := OptionsFromConfig(config.Config{AllowWebhookChecks: true}); len(opts) != 1 {
+		t.Fatalf("OptionsFromConfig(allowed) = %d options, want 1", len(opts))
+	}
+}